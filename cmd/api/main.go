@@ -2,16 +2,74 @@ package main
 
 import (
 	"car-price-prediction/internal/api"
+	"car-price-prediction/internal/auth"
+	"car-price-prediction/internal/cache"
+	"car-price-prediction/internal/catalog"
+	"car-price-prediction/internal/depreciation"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/drift"
+	"car-price-prediction/internal/feedback"
+	"car-price-prediction/internal/grpcapi"
+	"car-price-prediction/internal/imputation"
+	"car-price-prediction/internal/onnxmodel"
 	"car-price-prediction/internal/prediction"
+	"car-price-prediction/internal/predlog"
+	"car-price-prediction/internal/pricing"
+	"car-price-prediction/internal/rules"
+	"context"
+	"errors"
+	"flag"
+	"io/fs"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"syscall"
+	"time"
 
 	_ "car-price-prediction/docs" // docs is generated by Swag CLI
+	"github.com/prometheus/client_golang/prometheus"
 	onnx "github.com/yalue/onnxruntime_go"
 )
 
+// runtimeModels returns the ONNX models among the model at path and, for an
+// ensemble, its members that are run by onnxruntime. Bundles are loaded to fail
+// early if they are broken.
+func runtimeModels(path string) []string {
+	switch {
+	case prediction.IsEnsemble(path):
+		spec, err := prediction.LoadEnsembleSpec(path)
+		if err != nil {
+			log.Fatalf("Failed to load ensemble: %v", err)
+		}
+		// Members are served like the model file they name, so linear ONNX
+		// members run in onnxruntime too
+		var paths []string
+		for i, m := range spec.Members {
+			if !prediction.IsBundle(spec.MemberPath(i)) {
+				paths = append(paths, spec.MemberPath(i))
+			} else if _, err := prediction.LoadBundle(spec.MemberPath(i)); err != nil {
+				log.Fatalf("Failed to load model bundle of ensemble member %s: %v", m.Name, err)
+			}
+		}
+		log.Printf("Loaded an ensemble of %d models (%s) from %s", len(spec.Members), spec.Aggregation, path)
+		return paths
+	case prediction.IsBundle(path):
+		if _, err := prediction.LoadBundle(path); err != nil {
+			log.Fatalf("Failed to load model bundle %s: %v", path, err)
+		}
+		return nil
+	case onnxmodel.IsLinear(path):
+		return nil
+	default:
+		return []string{path}
+	}
+}
+
 // getSharedLibPath returns the path to the ONNX runtime shared library based on the OS and architecture
 func getSharedLibPath() string {
 	cwd, err := os.Getwd()
@@ -52,65 +110,330 @@ func getSharedLibPath() string {
 
 // @host localhost:8080
 // @BasePath /
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description OIDC access token as "Bearer <token>".
 func main() {
+	// Parse command-line flags
+	addr := flag.String("addr", ":8080", "address for the HTTP server to listen on")
+	modelFile := flag.String("model", "model/best_model.onnx", "model to serve: an ONNX model, a model bundle (.json) written by cmd/train, or an ensemble of models (.ensemble.json)")
+	grpcAddr := flag.String("grpc-addr", ":9090", "address for the gRPC server to listen on")
+	predictionTimeout := flag.Duration("prediction-timeout", api.DefaultPredictionTimeout, "maximum duration of a single prediction")
+	authDir := flag.String("auth-dir", "", "directory with keys.json and usage.json; enables API key authentication")
+	jwksFile := flag.String("jwks-file", "", "JWKS file with the token issuer's public keys; enables bearer token authentication")
+	jwtIssuer := flag.String("jwt-issuer", "", "required issuer (iss) of bearer tokens")
+	jwtAudience := flag.String("jwt-audience", "", "required audience (aud) of bearer tokens")
+	cacheSize := flag.Int("cache-size", 10000, "number of predictions to cache in process; 0 disables the cache")
+	cacheTTL := flag.Duration("cache-ttl", 15*time.Minute, "how long a cached prediction is served")
+	predictionLogDir := flag.String("prediction-log-dir", "", "directory to record every prediction in; enables /v1/predictions/{id}")
+	predictionLogRetention := flag.Duration("prediction-log-retention", 0, "delete recorded predictions older than this (0 keeps them forever)")
+	predictionLogMaxBytes := flag.Int64("prediction-log-max-bytes", 0, "delete the oldest recorded predictions beyond this size (0 is unlimited)")
+	feedbackFile := flag.String("feedback-file", "", "file to keep reported sale prices in across restarts (kept in memory if empty)")
+	maeThreshold := flag.Float64("accuracy-mae-threshold", 0, "alert when the MAE of a model version on reported sales exceeds this many dollars (0 disables alerts)")
+	alertWindow := flag.Duration("accuracy-alert-window", feedback.DefaultAlertWindow, "window the alert MAE is computed over")
+	alertMinSamples := flag.Int("accuracy-alert-min-samples", feedback.DefaultAlertMinSamples, "reported sales needed before an accuracy alert can fire")
+	driftProfile := flag.String("drift-profile", "model/reference_profile.json", "training data profile to compare prediction inputs with; drift is not monitored if the file does not exist")
+	driftMaxSamples := flag.Int("drift-max-samples", drift.DefaultMaxSamples, "recent prediction inputs kept for drift monitoring")
+	driftMinSamples := flag.Int("drift-min-samples", drift.DefaultMinSamples, "prediction inputs a window needs before its drift is reported")
+	catalogFile := flag.String("catalog", "model/catalog.csv", "vehicle catalog for /v1/catalog and catalog_id in prediction requests: the CarPrice CSV or a JSON list of entries; disabled if the file does not exist")
+	imputationTable := flag.String("imputation-table", "model/imputation.json", "training data statistics to fill in missing fields of requests with \"impute\": true; imputation is disabled if the file does not exist")
+	exchangeRates := flag.String("exchange-rates", "model/exchange_rates.json", "exchange rates against USD for the currency query parameter, re-read when the file changes; prices are only quoted in USD if the file does not exist")
+	priceBaseYear := flag.Int("price-base-year", pricing.DefaultBaseYear, "year the training prices are in, for inflation adjustment with the year query parameter")
+	regionRules := flag.String("region-rules", "model/region_rules.yaml", "regional price adjustment rules for the region query parameter, re-read when the file changes; disabled if the file does not exist")
+	depreciationCurves := flag.String("depreciation-curves", "model/depreciation.yaml", "depreciation curves by brand and body type for /v1/predict/depreciation; the bundled curves are used if the file does not exist")
+	modelList := flag.String("models", "", "additional models requests can select with the X-Model header, as comma-separated name=path pairs, e.g. linear=model/ridge.json")
+	auditLog := flag.String("audit-log", "", "file to append rejected requests to as JSON lines (defaults to the standard log)")
+	flag.Parse()
+
 	// Define the model path
-	modelPath := "model/best_model.onnx"
-
-	// Set the path to the ONNX runtime shared library
-	onnx.SetSharedLibraryPath(getSharedLibPath())
-
-	// Initialize the ONNX runtime environment
-	// This is required before creating any ONNX sessions
-	if err := onnx.InitializeEnvironment(); err != nil {
-		log.Fatalf("Failed to initialize ONNX environment: %v", err)
-	}
-	defer onnx.DestroyEnvironment()
-
-	// Create a new session with the model
-	// Note: The onnxruntime library must be installed on the system.
-	// For macOS: brew install onnxruntime
-	// For Linux: sudo apt-get install libonnxruntime
-	// We'll create a simple session without pre-allocating tensors
-	// The actual tensors will be created in the Predict method
-	// Create input and output tensors for the session
-	// We'll create empty tensors with the expected shapes
-	// The actual data will be filled in the Predict method
-	inputTensor, err := onnx.NewEmptyTensor[float32]([]int64{1, int64(prediction.ModelInputSize)})
-	if err != nil {
-		log.Fatalf("Failed to create input tensor: %v", err)
+	modelPath := *modelFile
+
+	// Load the models requests can select by name besides the default model.
+	models := map[string]string{}
+	if *modelList != "" {
+		for _, pair := range strings.Split(*modelList, ",") {
+			name, path, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				log.Fatalf("Invalid -models entry %q, want name=path", pair)
+			}
+			models[name] = path
+		}
+	}
+
+	// Model bundles and linear ONNX models are served in Go and need no
+	// onnxruntime. Ensembles need it if any of their members is an ONNX model.
+	onnxModels := runtimeModels(modelPath)
+	for _, path := range models {
+		onnxModels = append(onnxModels, runtimeModels(path)...)
+	}
+
+	if len(onnxModels) > 0 {
+		// Set the path to the ONNX runtime shared library
+		onnx.SetSharedLibraryPath(getSharedLibPath())
+
+		// Initialize the ONNX runtime environment
+		// This is required before creating any ONNX sessions
+		if err := onnx.InitializeEnvironment(); err != nil {
+			log.Fatalf("Failed to initialize ONNX environment: %v", err)
+		}
+		defer onnx.DestroyEnvironment()
+
+		// Create a new session with the model
+		// Note: The onnxruntime library must be installed on the system.
+		// For macOS: brew install onnxruntime
+		// For Linux: sudo apt-get install libonnxruntime
+		// We'll create a simple session without pre-allocating tensors
+		// The actual tensors will be created in the Predict method
+		// Create input and output tensors for the session
+		// We'll create empty tensors with the expected shapes
+		// The actual data will be filled in the Predict method
+		inputTensor, err := onnx.NewEmptyTensor[float32]([]int64{1, int64(prediction.ModelInputSize)})
+		if err != nil {
+			log.Fatalf("Failed to create input tensor: %v", err)
+		}
+		defer inputTensor.Destroy()
+
+		outputTensor, err := onnx.NewEmptyTensor[float32]([]int64{1, 1})
+		if err != nil {
+			log.Fatalf("Failed to create output tensor: %v", err)
+		}
+		defer outputTensor.Destroy()
+
+		// Create a session with each model to check that it loads
+		// We'll use NewAdvancedSession to specify the input and output tensors
+		for _, path := range onnxModels {
+			session, err := onnx.NewAdvancedSession(
+				path,
+				[]string{prediction.InputTensor},
+				[]string{prediction.OutputTensor},
+				[]onnx.ArbitraryTensor{inputTensor},
+				[]onnx.ArbitraryTensor{outputTensor},
+				nil,
+			)
+			if err != nil {
+				log.Fatalf("Failed to create ONNX session for %s: %v", path, err)
+			}
+			session.Destroy()
+		}
+	}
+
+	// Create a new prediction service with just the model path.
+	model := onnxmodel.Open(modelPath)
+	var predictionService domain.PredictionService = model
+
+	// Cache popular configurations in front of the model.
+	if *cacheSize > 0 {
+		predictionService = cache.New(model, cache.Config{
+			Size:       *cacheSize,
+			TTL:        *cacheTTL,
+			Registerer: prometheus.DefaultRegisterer,
+		})
+		log.Printf("Prediction cache enabled (%d entries, TTL %s)", *cacheSize, *cacheTTL)
+	}
+
+	routerOpts := []api.Option{
+		api.WithPredictionTimeout(*predictionTimeout),
+		api.WithMetrics(prometheus.DefaultGatherer),
+	}
+	grpcOpts := grpcapi.WithPredictionTimeout(*predictionTimeout)
+
+	// Let requests select one of the additional models with the X-Model header.
+	if len(models) > 0 {
+		registry := prediction.NewRegistry()
+		for name, path := range models {
+			if err := registry.Register(name, onnxmodel.Open(path)); err != nil {
+				log.Fatalf("Failed to register model: %v", err)
+			}
+		}
+		routerOpts = append(routerOpts, api.WithModels(registry))
+		log.Printf("Model selection enabled (%s)", strings.Join(registry.Names(), ", "))
+	}
+
+	// Require API keys if a key directory is configured.
+	authenticator := &auth.Authenticator{}
+	if *authDir != "" {
+		manager, err := auth.Open(*authDir, 10*time.Second)
+		if err != nil {
+			log.Fatalf("Failed to open API key store: %v", err)
+		}
+		defer func() {
+			if err := manager.Close(); err != nil {
+				log.Printf("Failed to save API key usage: %v", err)
+			}
+		}()
+		authenticator.APIKeys = manager
+		log.Printf("API key authentication enabled (%s)", *authDir)
+	}
+
+	// Accept bearer tokens if the issuer's key set is configured.
+	if *jwksFile != "" {
+		keys, err := auth.OpenJWKS(*jwksFile)
+		if err != nil {
+			log.Fatalf("Failed to load JWKS: %v", err)
+		}
+		authenticator.JWT = auth.NewJWTVerifier(keys, auth.JWTOptions{Issuer: *jwtIssuer, Audience: *jwtAudience})
+		log.Printf("Bearer token authentication enabled (%s)", *jwksFile)
 	}
-	defer inputTensor.Destroy()
 
-	outputTensor, err := onnx.NewEmptyTensor[float32]([]int64{1, 1})
+	if *auditLog != "" {
+		f, err := os.OpenFile(*auditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			log.Fatalf("Failed to open audit log: %v", err)
+		}
+		defer f.Close()
+		authenticator.Audit = auth.NewAuditLog(f)
+	}
+
+	if authenticator.APIKeys != nil || authenticator.JWT != nil {
+		routerOpts = append(routerOpts, api.WithAuth(authenticator))
+		grpcOpts = append(grpcOpts, grpcapi.WithAuth(authenticator)...)
+	}
+
+	// Record every prediction, keeping a copy of each model for replays.
+	if *predictionLogDir != "" {
+		store, err := predlog.Open(*predictionLogDir, predlog.Options{
+			Retention:     *predictionLogRetention,
+			MaxTotalBytes: *predictionLogMaxBytes,
+		})
+		if err != nil {
+			log.Fatalf("Failed to open prediction log: %v", err)
+		}
+		defer store.Close()
+		archive := onnxmodel.NewArchive(filepath.Join(*predictionLogDir, "models"))
+		recorder := predlog.NewRecorder(store, model, archive)
+		routerOpts = append(routerOpts, api.WithPredictionLog(recorder))
+		grpcOpts = append(grpcOpts, grpcapi.WithPredictionLog(recorder)...)
+		log.Printf("Prediction log enabled (%s)", *predictionLogDir)
+	}
+
+	// Track the accuracy of predictions against reported sale prices.
+	tracker, err := feedback.Open(feedback.Config{
+		Path:            *feedbackFile,
+		MAEThreshold:    *maeThreshold,
+		AlertWindow:     *alertWindow,
+		AlertMinSamples: *alertMinSamples,
+		Registerer:      prometheus.DefaultRegisterer,
+	})
 	if err != nil {
-		log.Fatalf("Failed to create output tensor: %v", err)
-	}
-	defer outputTensor.Destroy()
-
-	// Create a session with the model
-	// We'll use NewAdvancedSession to specify the input and output tensors
-	session, err := onnx.NewAdvancedSession(
-		modelPath,
-		[]string{"float_input"},  // Correct input tensor name from model inspection
-		[]string{"variable"},  // Correct output tensor name from model inspection
-		[]onnx.ArbitraryTensor{inputTensor},
-		[]onnx.ArbitraryTensor{outputTensor},
-		nil,
-	)
+		log.Fatalf("Failed to open feedback file: %v", err)
+	}
+	defer tracker.Close()
+	routerOpts = append(routerOpts, api.WithFeedback(tracker))
+
+	// Compare prediction inputs with the training data, if its profile was built.
+	if profile, err := drift.LoadProfile(*driftProfile); err == nil {
+		monitor := drift.NewMonitor(profile, drift.Config{
+			MaxSamples: *driftMaxSamples,
+			MinSamples: *driftMinSamples,
+			Registerer: prometheus.DefaultRegisterer,
+		})
+		routerOpts = append(routerOpts, api.WithDriftMonitor(monitor))
+		grpcOpts = append(grpcOpts, grpcapi.WithDriftMonitor(monitor)...)
+		log.Printf("Drift monitoring enabled (%s, %d training rows)", *driftProfile, profile.Samples)
+	} else if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Drift monitoring disabled: %s not found, build it with cmd/driftprofile", *driftProfile)
+	} else {
+		log.Fatalf("Failed to load drift profile: %v", err)
+	}
+
+	// Serve the vehicle catalog, if one is shipped with the model.
+	if cat, err := catalog.Load(*catalogFile); err == nil {
+		routerOpts = append(routerOpts, api.WithCatalog(cat))
+		log.Printf("Vehicle catalog enabled (%s, %d entries)", *catalogFile, cat.Len())
+	} else if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Vehicle catalog disabled: %s not found", *catalogFile)
+	} else {
+		log.Fatalf("Failed to load vehicle catalog: %v", err)
+	}
+
+	// Fill in missing request fields, if the imputation table was built.
+	if table, err := imputation.LoadTable(*imputationTable); err == nil {
+		routerOpts = append(routerOpts, api.WithImputation(table))
+		log.Printf("Imputation enabled (%s, %d training rows)", *imputationTable, table.Samples)
+	} else if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Imputation disabled: %s not found, build it with cmd/imputation", *imputationTable)
+	} else {
+		log.Fatalf("Failed to load imputation table: %v", err)
+	}
+
+	// Quote prices in other years with the bundled CPI, and in other currencies if exchange rates are configured.
+	rates, err := pricing.LoadRates(*exchangeRates, pricing.BaseCurrency)
+	if err == nil {
+		log.Printf("Currency conversion enabled (%s, currencies %s)", *exchangeRates, strings.Join(rates.Currencies(), ", "))
+	} else if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Currency conversion disabled: %s not found", *exchangeRates)
+	} else {
+		log.Fatalf("Failed to load exchange rates: %v", err)
+	}
+	pricer, err := pricing.NewPricer(*priceBaseYear, pricing.DefaultCPI(), rates)
 	if err != nil {
-		log.Fatalf("Failed to create ONNX session: %v", err)
+		log.Fatalf("Failed to set up price quotes: %v", err)
 	}
-	defer session.Destroy()
+	routerOpts = append(routerOpts, api.WithPricing(pricer))
 
-	// Create a new prediction service with just the model path.
-	predictionService := prediction.NewPredictionService(modelPath)
+	// Project used car values with the configured depreciation curves, or the bundled ones.
+	if curves, err := depreciation.Load(*depreciationCurves); err == nil {
+		routerOpts = append(routerOpts, api.WithDepreciation(curves))
+		log.Printf("Depreciation curves loaded from %s", *depreciationCurves)
+	} else if errors.Is(err, fs.ErrNotExist) {
+		routerOpts = append(routerOpts, api.WithDepreciation(depreciation.Default()))
+		log.Printf("Using the bundled depreciation curves: %s not found", *depreciationCurves)
+	} else {
+		log.Fatalf("Failed to load depreciation curves: %v", err)
+	}
+
+	// Adjust prices for regional markets, if rules are configured.
+	if engine, err := rules.Load(*regionRules); err == nil {
+		routerOpts = append(routerOpts, api.WithRegionalRules(engine))
+		set := engine.Current()
+		log.Printf("Regional rules enabled (%s, version %s, regions %s)", *regionRules, set.Version, strings.Join(set.IDs(), ", "))
+	} else if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Regional rules disabled: %s not found", *regionRules)
+	} else {
+		log.Fatalf("Failed to load regional rules: %v", err)
+	}
 
 	// Set up the Gin router.
-	router := api.SetupRouter(predictionService)
+	router := api.SetupRouter(predictionService, routerOpts...)
+
+	// Start the gRPC server on its own port, backed by the same prediction service.
+	listener, err := net.Listen("tcp", *grpcAddr)
+	if err != nil {
+		log.Fatalf("Failed to listen on %s: %v", *grpcAddr, err)
+	}
+	grpcServer := grpcapi.NewServer(predictionService, grpcOpts...)
+	defer grpcServer.GracefulStop()
+	go func() {
+		log.Printf("Starting gRPC server on %s", *grpcAddr)
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
 
 	// Start the server.
-	log.Println("Starting server on :8080")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	server := &http.Server{Addr: *addr, Handler: router}
+	go func() {
+		log.Printf("Starting server on %s", *addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for an interrupt, then shut down gracefully so that deferred cleanup runs.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shut down server: %v", err)
 	}
-}
\ No newline at end of file
+}
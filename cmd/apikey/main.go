@@ -0,0 +1,123 @@
+// Command apikey issues, revokes and lists API keys for the car price prediction API.
+//
+// Usage:
+//
+//	apikey [-dir data/auth] issue -name NAME [-rate 5] [-burst 10] [-quota 10000] [-admin]
+//	apikey [-dir data/auth] revoke ID
+//	apikey [-dir data/auth] list
+//
+// A running server picks up changes to the key file without a restart.
+package main
+
+import (
+	"car-price-prediction/internal/auth"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+func main() {
+	dir := flag.String("dir", "data/auth", "directory with keys.json, the same as the server's -auth-dir")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	store, err := auth.OpenKeyStore(filepath.Join(*dir, "keys.json"))
+	if err != nil {
+		log.Fatalf("Failed to open key store: %v", err)
+	}
+
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "issue":
+		err = issue(store, args)
+	case "revoke":
+		err = revoke(store, args)
+	case "list":
+		err = list(store)
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// usage prints the command-line help.
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  apikey [-dir DIR] issue -name NAME [-rate RPS] [-burst N] [-quota N] [-admin]
+  apikey [-dir DIR] revoke ID
+  apikey [-dir DIR] list`)
+	flag.PrintDefaults()
+}
+
+// issue creates a key and prints its token, which is shown only once.
+func issue(store *auth.KeyStore, args []string) error {
+	fs := flag.NewFlagSet("issue", flag.ExitOnError)
+	name := fs.String("name", "", "name of the key owner, e.g. the dealership")
+	rps := fs.Float64("rate", 5, "sustained requests per second (0 = unlimited)")
+	burst := fs.Int("burst", 10, "maximum burst of requests")
+	quota := fs.Int64("quota", 0, "monthly prediction quota (0 = unlimited)")
+	admin := fs.Bool("admin", false, "allow the key to see the usage of all keys")
+	fs.Parse(args)
+
+	if *name == "" {
+		return fmt.Errorf("issue: -name is required")
+	}
+
+	token, key, err := store.Issue(auth.KeyOptions{
+		Name:          *name,
+		RatePerSecond: *rps,
+		Burst:         *burst,
+		MonthlyQuota:  *quota,
+		Admin:         *admin,
+	})
+	if err != nil {
+		return fmt.Errorf("issue: %w", err)
+	}
+
+	fmt.Printf("Issued key %s for %q. Store this token now, it cannot be shown again:\n%s\n", key.ID, key.Name, token)
+	return nil
+}
+
+// revoke revokes the key with the given ID.
+func revoke(store *auth.KeyStore, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("revoke: expected exactly one key ID")
+	}
+	if err := store.Revoke(args[0]); err != nil {
+		return fmt.Errorf("revoke: %w", err)
+	}
+	fmt.Printf("Revoked key %s\n", args[0])
+	return nil
+}
+
+// list prints all keys as a table.
+func list(store *auth.KeyStore) error {
+	keys, err := store.List()
+	if err != nil {
+		return fmt.Errorf("list: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tRATE\tBURST\tQUOTA\tADMIN\tCREATED\tREVOKED")
+	for _, key := range keys {
+		revoked := "-"
+		if key.RevokedAt != nil {
+			revoked = key.RevokedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%g\t%d\t%d\t%t\t%s\t%s\n",
+			key.ID, key.Name, key.RatePerSecond, key.Burst, key.MonthlyQuota, key.Admin,
+			key.CreatedAt.Format(time.RFC3339), revoked)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"car-price-prediction/internal/bench"
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/onnxmodel"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// benchResult is the load test of one backend at one batch size.
+type benchResult struct {
+	Backend string `json:"backend"`
+	*bench.Result
+}
+
+func benchmark(args []string, _ io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), `Usage: carprice bench [flags]
+
+Load-tests the inference path with synthetic inputs drawn from the training data:
+random rows of -data with their numeric fields perturbed by -jitter. Each backend
+is driven in process, or the API at -url over HTTP, at every batch size in turn,
+and the throughput and latency percentiles are reported.
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+	modelPath := fs.String("model", "model/best_model.onnx", "model to load in process: an ONNX model, or a model bundle (.json) written by cmd/train")
+	backends := fs.String("backends", onnxmodel.RuntimeGo, "comma-separated backends to compare: go, onnxruntime (a session per call) and onnxruntime-pooled")
+	lib := fs.String("onnxruntime-lib", os.Getenv("ONNXRUNTIME_LIB"), "onnxruntime shared library, for the onnxruntime backends")
+	url := fs.String("url", "", "base URL of an API server to drive instead of an in-process backend")
+	apiKey := fs.String("api-key", os.Getenv("CARPRICE_API_KEY"), "API key sent to -url")
+	data := fs.String("data", "CarPrice_Assignment.csv", "training data set the inputs are drawn from")
+	jitter := fs.Float64("jitter", bench.DefaultJitter, "relative noise added to numeric fields")
+	batchSizes := fs.String("batch-sizes", "1", "comma-separated numbers of inputs per request")
+	concurrency := fs.Int("concurrency", 4, "number of requests in flight")
+	rps := fs.Float64("rps", 0, "requests started per second (0 is unlimited)")
+	duration := fs.Duration("duration", 10*time.Second, "duration of each load test")
+	requests := fs.Int("requests", 0, "number of requests of each load test, instead of -duration")
+	seed := fs.Uint64("seed", 1, "seed of the synthetic inputs")
+	output := fs.String("output", outputTable, "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	if *output != outputTable && *output != outputJSON {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+	sizes, err := parseSizes(*batchSizes)
+	if err != nil {
+		return fmt.Errorf("invalid -batch-sizes: %w", err)
+	}
+	if *requests > 0 {
+		*duration = 0
+	}
+
+	samples, err := dataset.ReadFile(*data)
+	if err != nil {
+		return err
+	}
+	gen, err := bench.NewGenerator(samples, *jitter, *seed)
+	if err != nil {
+		return err
+	}
+
+	// Each backend is a named target
+	type backend struct {
+		name   string
+		target bench.Target
+	}
+	var targets []backend
+	if *url != "" {
+		targets = append(targets, backend{*url, bench.HTTPTarget(*url, *apiKey, &http.Client{Timeout: time.Minute})})
+	} else {
+		for _, name := range strings.Split(*backends, ",") {
+			name = strings.TrimSpace(name)
+			service, err := onnxmodel.OpenService(*modelPath, name, *lib)
+			if err != nil {
+				return fmt.Errorf("backend %s: %w", name, err)
+			}
+			targets = append(targets, backend{name, bench.ServiceTarget(service)})
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var results []benchResult
+	for _, b := range targets {
+		for _, size := range sizes {
+			result, err := bench.Run(ctx, b.target, gen, bench.Config{
+				BatchSize:   size,
+				Concurrency: *concurrency,
+				RPS:         *rps,
+				Duration:    *duration,
+				Requests:    *requests,
+				Seed:        *seed,
+			})
+			if err != nil {
+				return err
+			}
+			results = append(results, benchResult{Backend: b.name, Result: result})
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+
+	if *output == outputJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	return writeBench(stdout, results)
+}
+
+// parseSizes parses comma-separated positive numbers.
+func parseSizes(s string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 {
+			return nil, fmt.Errorf("batch size %d is not positive", n)
+		}
+		sizes = append(sizes, n)
+	}
+	return sizes, nil
+}
+
+// writeBench writes the load test results as a table.
+func writeBench(w io.Writer, results []benchResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "BACKEND\tBATCH\tCONC\tREQUESTS\tERRORS\tREQ/S\tROWS/S\tMEAN\tP50\tP90\tP99\tMAX\t")
+	for _, r := range results {
+		l := r.Latency
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.1f\t%.1f\t%s\t%s\t%s\t%s\t%s\t\n",
+			r.Backend, r.BatchSize, r.Concurrency, r.Requests, r.Errors, r.Throughput, r.RowsPerSecond,
+			round(l.Mean), round(l.P50), round(l.P90), round(l.P99), round(l.Max))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.FirstError != "" {
+			fmt.Fprintf(w, "%s batch %d: %d errors, e.g. %s\n", r.Backend, r.BatchSize, r.Errors, r.FirstError)
+		}
+	}
+	return nil
+}
+
+// round shortens a latency for display.
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(100 * time.Nanosecond)
+	}
+}
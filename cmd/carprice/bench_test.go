@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchmark(t *testing.T) {
+	var stdout bytes.Buffer
+	args := []string{"-model", servedModel, "-data", sampleData, "-batch-sizes", "1,5", "-requests", "20", "-concurrency", "2", "-output", "json"}
+	require.NoError(t, benchmark(args, nil, &stdout))
+
+	var results []benchResult
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &results))
+	require.Len(t, results, 2)
+	for i, size := range []int{1, 5} {
+		r := results[i]
+		assert.Equal(t, "go", r.Backend)
+		assert.Equal(t, size, r.BatchSize)
+		assert.Equal(t, 20, r.Requests)
+		assert.Zero(t, r.Errors)
+		assert.Greater(t, r.RowsPerSecond, 0.0)
+	}
+
+	stdout.Reset()
+	require.NoError(t, benchmark(append(args[:len(args)-2], "-batch-sizes", "2"), nil, &stdout))
+	assert.Contains(t, stdout.String(), "BACKEND  BATCH  CONC  REQUESTS")
+
+	assert.ErrorContains(t, benchmark([]string{"-batch-sizes", "0"}, nil, &stdout), "not positive")
+	assert.ErrorContains(t, benchmark([]string{"-model", servedModel, "-data", sampleData, "-backends", "gpu"}, nil, &stdout), `unknown runtime "gpu"`)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/validation"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Input formats.
+const (
+	formatAuto = "auto"
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// field is a UserInput field, named like in the API's JSON.
+type field struct {
+	name    string
+	numeric bool
+}
+
+// inputFields lists the UserInput fields in declaration order.
+var inputFields = func() []field {
+	t := reflect.TypeFor[domain.UserInput]()
+	fields := make([]field, t.NumField())
+	for i := range fields {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		kind := f.Type.Kind()
+		fields[i] = field{name: name, numeric: kind != reflect.String}
+	}
+	return fields
+}()
+
+// record is an input read from the command line or a file, or the reason it could
+// not be read.
+type record struct {
+	// Row is the 1-based position of the input.
+	Row   int
+	Input domain.UserInput
+	Err   error
+}
+
+// parseFields converts field values given as text, such as CSV cells or flags, into
+// an input. Fields are decoded like the API's JSON, so invalid numbers fail the same
+// way; empty values are treated as missing.
+func parseFields(values map[string]string) (domain.UserInput, error) {
+	object := make(map[string]any, len(values))
+	for _, f := range inputFields {
+		v := strings.TrimSpace(values[f.name])
+		if v == "" {
+			continue
+		}
+		if !f.numeric {
+			object[f.name] = v
+			continue
+		}
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return domain.UserInput{}, domain.NewValidationError([]domain.Violation{{
+				Field: f.name, Code: domain.CodeValidationFailed, Message: fmt.Sprintf("must be a number, got %q", v),
+			}})
+		}
+		object[f.name] = json.Number(v)
+	}
+	data, err := json.Marshal(object)
+	if err != nil {
+		return domain.UserInput{}, err
+	}
+	return decodeInput(data)
+}
+
+// decodeInput decodes and validates a JSON input with the REST API's binding rules.
+func decodeInput(data []byte) (domain.UserInput, error) {
+	var input domain.UserInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		return input, validation.Translate(err)
+	}
+	if err := validation.Struct(input); err != nil {
+		return input, err
+	}
+	return input, nil
+}
+
+// readInputs reads inputs in format from r. The auto format reads CSV if the data
+// does not start with a JSON object or array.
+func readInputs(r io.Reader, format string) ([]record, error) {
+	br := bufio.NewReader(r)
+	if format == formatAuto {
+		format = formatCSV
+		if b, err := peekNonSpace(br); err == nil && (b == '{' || b == '[') {
+			format = formatJSON
+		}
+	}
+	switch format {
+	case formatJSON:
+		return readJSON(br)
+	case formatCSV:
+		return readCSV(br)
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+}
+
+// peekNonSpace returns the first byte of r that is not white space, without
+// consuming it.
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b)) {
+			return b, r.UnreadByte()
+		}
+	}
+}
+
+// readJSON reads a stream of JSON values: input objects, one per line as NDJSON or
+// not, arrays of inputs, or batch requests like {"inputs": [...]}.
+func readJSON(r io.Reader) ([]record, error) {
+	var records []record
+	add := func(data json.RawMessage) {
+		input, err := decodeInput(data)
+		records = append(records, record{Row: len(records) + 1, Input: input, Err: err})
+	}
+
+	dec := json.NewDecoder(r)
+	for {
+		var value json.RawMessage
+		if err := dec.Decode(&value); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid JSON after %d inputs: %w", len(records), err)
+		}
+
+		var batch struct {
+			Inputs []json.RawMessage `json:"inputs"`
+		}
+		switch {
+		case bytes.HasPrefix(value, []byte("[")):
+			if err := json.Unmarshal(value, &batch.Inputs); err != nil {
+				return nil, fmt.Errorf("invalid JSON array after %d inputs: %w", len(records), err)
+			}
+		case json.Unmarshal(value, &batch) == nil && batch.Inputs != nil:
+		default:
+			batch.Inputs = []json.RawMessage{value}
+		}
+		for _, data := range batch.Inputs {
+			add(data)
+		}
+	}
+	return records, nil
+}
+
+// readCSV reads inputs from CSV with a header row naming the fields. Other columns
+// are ignored, and the brand is taken from CarName if there is no brand column, so
+// rows of the training data set can be priced as they are.
+func readCSV(r io.Reader) ([]record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+	}
+
+	var records []record
+	for {
+		cells, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		values := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(cells) {
+				values[name] = cells[i]
+			}
+		}
+		if values["brand"] == "" && values["CarName"] != "" {
+			values["brand"] = dataset.Brand(values["CarName"])
+		}
+		input, err := parseFields(values)
+		records = append(records, record{Row: len(records) + 1, Input: input, Err: err})
+	}
+	return records, nil
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bufio"
+	"car-price-prediction/internal/forest"
+	"car-price-prediction/internal/onnxmodel"
+	"car-price-prediction/internal/prediction"
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxColumnMismatches is the number of differing column positions reported.
+const maxColumnMismatches = 10
+
+// inspection describes a model file.
+type inspection struct {
+	Model  string `json:"model"`
+	Format string `json:"format"`
+	// ONNX is the graph structure of ONNX models.
+	ONNX  *onnxmodel.Description `json:"onnx,omitempty"`
+	Trees *treeStats             `json:"trees,omitempty"`
+	// Schema names the model's input columns and SchemaSource where the names
+	// come from.
+	Schema       []string `json:"schema,omitempty"`
+	SchemaSource string   `json:"schema_source,omitempty"`
+	// Splits counts the splits on each schema column, most used first.
+	Splits []featureSplits `json:"splits,omitempty"`
+	// Unused lists the schema columns no tree splits on.
+	Unused []string `json:"unused,omitempty"`
+	// Problems lists mismatches between the model, the serving code and the
+	// reference column list.
+	Problems []string `json:"problems,omitempty"`
+}
+
+// treeStats describes the trees of a forest.
+type treeStats struct {
+	Count  int `json:"count"`
+	Nodes  int `json:"nodes"`
+	Leaves int `json:"leaves"`
+	// Depths maps each depth to the number of trees that deep.
+	Depths   map[int]int `json:"depths"`
+	MinDepth int         `json:"min_depth"`
+	MaxDepth int         `json:"max_depth"`
+	// MedianDepth is the depth of the middle tree.
+	MedianDepth int `json:"median_depth"`
+}
+
+// featureSplits is how often the trees split on a column.
+type featureSplits struct {
+	Feature string `json:"feature"`
+	Splits  int    `json:"splits"`
+}
+
+func inspect(args []string, _ io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), `Usage: carprice inspect [flags] [model]
+
+Describes a model: the ONNX opsets, inputs, outputs and nodes, and for random
+forests the trees and the columns they split on. Columns no tree splits on, tensor
+names the server cannot bind to and input columns that differ from the reference
+column list are reported as problems; the command then exits with status 1.
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+	columnsPath := fs.String("columns", "docs/model/model_column.txt", "reference list of the model's input columns, one per line")
+	output := fs.String("output", outputTable, "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	modelPath := "model/best_model.onnx"
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		modelPath = fs.Arg(0)
+	default:
+		return fmt.Errorf("unexpected arguments %q", fs.Args()[1:])
+	}
+
+	columns, err := readColumns(*columnsPath)
+	if err != nil {
+		return err
+	}
+	in, err := inspectModel(modelPath, columns)
+	if err != nil {
+		return err
+	}
+
+	switch *output {
+	case outputJSON:
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(in)
+	case outputTable:
+		err = writeInspection(stdout, in)
+	default:
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+	if err != nil {
+		return err
+	}
+	if len(in.Problems) > 0 {
+		return errFailed
+	}
+	return nil
+}
+
+// readColumns reads a column list with one name per line.
+func readColumns(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read column list: %w", err)
+	}
+	defer f.Close()
+
+	var columns []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if name := strings.TrimSpace(s.Text()); name != "" {
+			columns = append(columns, name)
+		}
+	}
+	return columns, s.Err()
+}
+
+// inspectModel describes the model at path and checks it against the reference
+// columns.
+func inspectModel(path string, columns []string) (*inspection, error) {
+	in := &inspection{Model: path}
+	var f *forest.Forest
+	if prediction.IsBundle(path) {
+		b, err := prediction.LoadBundle(path)
+		if err != nil {
+			return nil, err
+		}
+		in.Format = "bundle (" + b.Kind + ")"
+		in.Schema, in.SchemaSource = b.Features, "bundle"
+		f = b.Forest
+	} else {
+		m, err := onnxmodel.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		in.Format = "onnx"
+		in.ONNX = onnxmodel.Describe(m)
+		in.Problems = append(in.Problems, tensorProblems(in.ONNX)...)
+
+		// ONNX models are fed by Transform unless they name their columns.
+		in.Schema, in.SchemaSource = prediction.FeatureNames(), "Transform"
+		if schema, ok := onnxmodel.Metadata(m, onnxmodel.FeatureSchemaKey); ok {
+			in.Schema, in.SchemaSource = strings.Split(schema, ","), onnxmodel.FeatureSchemaKey+" metadata"
+		}
+		if width := onnxmodel.InputWidth(m); width != len(in.Schema) {
+			in.Problems = append(in.Problems, fmt.Sprintf("the model reads %d columns but %s has %d", width, in.SchemaSource, len(in.Schema)))
+		}
+		if onnxmodel.TreeEnsemble(m) != nil {
+			if f, err = onnxmodel.ToForest(m); err != nil {
+				return nil, err
+			}
+		}
+	}
+	in.Problems = append(in.Problems, columnProblems(in.Schema, in.SchemaSource, columns)...)
+
+	if f != nil {
+		in.Trees = describeTrees(f)
+		counts := f.SplitCounts()
+		for i, n := range counts {
+			name := fmt.Sprintf("column %d", i)
+			if i < len(in.Schema) {
+				name = in.Schema[i]
+			}
+			if n == 0 {
+				in.Unused = append(in.Unused, name)
+			} else {
+				in.Splits = append(in.Splits, featureSplits{Feature: name, Splits: n})
+			}
+		}
+		slices.SortStableFunc(in.Splits, func(a, b featureSplits) int { return cmp.Compare(b.Splits, a.Splits) })
+		if len(in.Unused) > 0 {
+			in.Problems = append(in.Problems, fmt.Sprintf("no tree splits on %d columns: %s", len(in.Unused), strings.Join(in.Unused, ", ")))
+		}
+	}
+	return in, nil
+}
+
+// tensorProblems reports graph inputs and outputs the server cannot bind to.
+func tensorProblems(d *onnxmodel.Description) []string {
+	var problems []string
+	check := func(kind string, tensors []onnxmodel.Tensor, want string) {
+		if len(tensors) != 1 || tensors[0].Name != want {
+			names := make([]string, len(tensors))
+			for i, t := range tensors {
+				names[i] = t.Name
+			}
+			problems = append(problems, fmt.Sprintf("the server binds the %s %q, but the model's %ss are %q", kind, want, kind, names))
+		}
+	}
+	check("input", d.Inputs, prediction.InputTensor)
+	check("output", d.Outputs, prediction.OutputTensor)
+	return problems
+}
+
+// columnProblems compares the model's schema with the reference columns.
+func columnProblems(schema []string, source string, columns []string) []string {
+	if slices.Equal(schema, columns) {
+		return nil
+	}
+	var problems []string
+	var missing, extra []string
+	for _, c := range columns {
+		if !slices.Contains(schema, c) {
+			missing = append(missing, c)
+		}
+	}
+	for _, c := range schema {
+		if !slices.Contains(columns, c) {
+			extra = append(extra, c)
+		}
+	}
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("%s lacks the reference columns %s", source, strings.Join(missing, ", ")))
+	}
+	if len(extra) > 0 {
+		problems = append(problems, fmt.Sprintf("%s has columns not in the reference list: %s", source, strings.Join(extra, ", ")))
+	}
+	if len(missing) == 0 && len(extra) == 0 {
+		var moved []string
+		for i := range schema {
+			if schema[i] != columns[i] && len(moved) < maxColumnMismatches {
+				moved = append(moved, fmt.Sprintf("%d is %s, not %s", i, schema[i], columns[i]))
+			}
+		}
+		problems = append(problems, fmt.Sprintf("%s orders the reference columns differently: column %s", source, strings.Join(moved, "; column ")))
+	}
+	return problems
+}
+
+// describeTrees summarizes the trees of a forest.
+func describeTrees(f *forest.Forest) *treeStats {
+	depths := f.Depths()
+	s := &treeStats{
+		Count:       len(f.Trees),
+		Depths:      map[int]int{},
+		MinDepth:    depths[0],
+		MaxDepth:    depths[len(depths)-1],
+		MedianDepth: depths[len(depths)/2],
+	}
+	for _, d := range depths {
+		s.Depths[d]++
+	}
+	for i := range f.Trees {
+		s.Nodes += len(f.Trees[i].Nodes)
+		s.Leaves += f.Trees[i].Leaves()
+	}
+	return s
+}
+
+// writeInspection writes the description for people.
+func writeInspection(w io.Writer, in *inspection) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Model:\t%s\n", in.Model)
+	fmt.Fprintf(tw, "Format:\t%s\n", in.Format)
+	if d := in.ONNX; d != nil {
+		fmt.Fprintf(tw, "IR version:\t%d\n", d.IRVersion)
+		if d.Producer != "" {
+			fmt.Fprintf(tw, "Producer:\t%s %s\n", d.Producer, d.ProducerVersion)
+		}
+		for _, o := range d.Opsets {
+			domain := o.Domain
+			if domain == onnxmodel.DomainONNX {
+				domain = "ai.onnx"
+			}
+			fmt.Fprintf(tw, "Opset:\t%s %d\n", domain, o.Version)
+		}
+		for _, t := range d.Inputs {
+			fmt.Fprintf(tw, "Input:\t%s\n", t)
+		}
+		for _, t := range d.Outputs {
+			fmt.Fprintf(tw, "Output:\t%s\n", t)
+		}
+		for _, n := range d.Nodes {
+			op := n.OpType
+			if n.Domain != "" {
+				op = n.Domain + "." + op
+			}
+			fmt.Fprintf(tw, "Node:\t%s (%s -> %s)\n", op, strings.Join(n.Inputs, ", "), strings.Join(n.Outputs, ", "))
+		}
+	}
+	fmt.Fprintf(tw, "Schema:\t%d columns from %s\n", len(in.Schema), in.SchemaSource)
+
+	if t := in.Trees; t != nil {
+		fmt.Fprintf(tw, "Trees:\t%d (%d nodes, %d leaves)\n", t.Count, t.Nodes, t.Leaves)
+		fmt.Fprintf(tw, "Depth:\tmin %d, median %d, max %d\n", t.MinDepth, t.MedianDepth, t.MaxDepth)
+		depths := slices.Sorted(maps.Keys(t.Depths))
+		for _, d := range depths {
+			fmt.Fprintf(tw, "\t%3d: %d trees\n", d, t.Depths[d])
+		}
+		fmt.Fprintf(tw, "Splits:\t%d columns used\n", len(in.Splits))
+		for _, s := range in.Splits {
+			fmt.Fprintf(tw, "\t%s\t%d\n", s.Feature, s.Splits)
+		}
+	}
+	if len(in.Problems) == 0 {
+		fmt.Fprintf(tw, "Problems:\tnone\n")
+	}
+	for _, p := range in.Problems {
+		fmt.Fprintf(tw, "Problem:\t%s\n", p)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"car-price-prediction/internal/forest"
+	"car-price-prediction/internal/onnxmodel"
+	"car-price-prediction/internal/prediction"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const modelColumns = "../../docs/model/model_column.txt"
+
+func TestInspect_ServedModel(t *testing.T) {
+	var stdout bytes.Buffer
+	require.NoError(t, inspect([]string{"-columns", modelColumns, "-output", "json", servedModel}, nil, &stdout))
+
+	var in inspection
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &in))
+	assert.Equal(t, "onnx", in.Format)
+	assert.Equal(t, prediction.InputTensor, in.ONNX.Inputs[0].Name)
+	assert.Equal(t, "Transform", in.SchemaSource)
+	assert.Equal(t, 100, in.Trees.Count)
+	assert.Equal(t, 100, sum(in.Trees.Depths))
+	assert.Equal(t, "curbweight", in.Splits[0].Feature)
+	assert.Empty(t, in.Unused)
+	assert.Empty(t, in.Problems)
+
+	stdout.Reset()
+	require.NoError(t, inspect([]string{"-columns", modelColumns, servedModel}, nil, &stdout))
+	assert.Contains(t, stdout.String(), "Input:       float_input FLOAT[?, 64]\n")
+	assert.Contains(t, stdout.String(), "Problems:    none\n")
+}
+
+func sum(m map[int]int) int {
+	var n int
+	for _, v := range m {
+		n += v
+	}
+	return n
+}
+
+func TestInspect_ReportsProblems(t *testing.T) {
+	// The forest only splits on horsepower, and its schema misses a column.
+	features := prediction.FeatureNames()[:prediction.ModelInputSize-1]
+	hp := 10
+	require.Equal(t, "horsepower", features[hp])
+	f := &forest.Forest{Features: len(features), Trees: []forest.Tree{{Nodes: []forest.Node{
+		{Feature: hp, Threshold: 100, Left: 1, Right: 2},
+		{Feature: forest.Leaf, Value: 8000},
+		{Feature: forest.Leaf, Value: 20000},
+	}}}}
+	b, err := prediction.NewForestBundle(features, f, prediction.BundleMetadata{})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "model.json")
+	require.NoError(t, b.WriteFile(path))
+
+	columns, err := readColumns(modelColumns)
+	require.NoError(t, err)
+	in, err := inspectModel(path, columns)
+	require.NoError(t, err)
+	assert.Equal(t, []featureSplits{{Feature: "horsepower", Splits: 1}}, in.Splits)
+	assert.Len(t, in.Unused, len(features)-1)
+	require.Len(t, in.Problems, 2)
+	assert.Equal(t, "bundle lacks the reference columns brand_volvo", in.Problems[0])
+	assert.Contains(t, in.Problems[1], "no tree splits on 62 columns: symboling, ")
+
+	// The exported ONNX model names its columns
+	m, err := onnxmodel.ExportBundle(b)
+	require.NoError(t, err)
+	onnxPath := filepath.Join(t.TempDir(), "model.onnx")
+	require.NoError(t, onnxmodel.WriteFile(onnxPath, m))
+	in, err = inspectModel(onnxPath, columns)
+	require.NoError(t, err)
+	assert.Equal(t, onnxmodel.FeatureSchemaKey+" metadata", in.SchemaSource)
+	assert.Len(t, in.Schema, prediction.ModelInputSize)
+}
+
+func TestColumnProblems(t *testing.T) {
+	assert.Empty(t, columnProblems([]string{"a", "b"}, "model", []string{"a", "b"}))
+	assert.Equal(t, []string{
+		"model lacks the reference columns c",
+		"model has columns not in the reference list: d",
+	}, columnProblems([]string{"a", "d"}, "model", []string{"a", "c"}))
+	assert.Equal(t, []string{
+		"model orders the reference columns differently: column 0 is b, not a; column 1 is a, not b",
+	}, columnProblems([]string{"b", "a"}, "model", []string{"a", "b"}))
+}
+
+func TestTensorProblems(t *testing.T) {
+	d := &onnxmodel.Description{
+		Inputs:  []onnxmodel.Tensor{{Name: "input"}},
+		Outputs: []onnxmodel.Tensor{{Name: prediction.OutputTensor}},
+	}
+	assert.Equal(t, []string{`the server binds the input "float_input", but the model's inputs are ["input"]`}, tensorProblems(d))
+}
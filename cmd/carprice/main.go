@@ -0,0 +1,63 @@
+// Command carprice prices cars from the command line with a local model, without
+// running the API server.
+//
+// Usage:
+//
+//	carprice predict [flags] [-input file]
+//	carprice inspect [flags] [model]
+//	carprice bench [flags]
+//
+// Run a subcommand with -h for its flags.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// errFailed reports that the command already printed why it failed.
+var errFailed = errors.New("failed")
+
+// commands are the subcommands by name.
+var commands = map[string]func(args []string, stdin io.Reader, stdout io.Writer) error{
+	"predict": predict,
+	"inspect": inspect,
+	"bench":   benchmark,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "carprice: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err := command(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		if !errors.Is(err, errFailed) {
+			fmt.Fprintf(os.Stderr, "carprice %s: %v\n", os.Args[1], err)
+		}
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, `Usage: carprice <command> [flags]
+
+Commands:
+  predict   predict prices from flags, JSON, NDJSON or CSV
+  inspect   describe a model's graph, trees and input columns
+  bench     load-test the inference path with synthetic inputs
+
+Run "carprice <command> -h" for the flags of a command.
+`)
+}
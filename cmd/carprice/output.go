@@ -0,0 +1,158 @@
+package main
+
+import (
+	"car-price-prediction/internal/domain"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// topFactors is the number of contributions shown per car in tables.
+const topFactors = 3
+
+// writeResults writes the results in the configured output format.
+func writeResults(w io.Writer, results []predicted, cfg predictConfig) error {
+	switch cfg.output {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		for _, r := range results {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	case outputCSV:
+		return writeCSV(w, results, cfg)
+	default:
+		return writeTable(w, results, cfg)
+	}
+}
+
+// writeTable writes the results as an aligned table for people.
+func writeTable(w io.Writer, results []predicted, cfg predictConfig) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := []string{"ROW", "BRAND", "CARBODY", "PRICE"}
+	if cfg.interval > 0 {
+		header = append(header, fmt.Sprintf("%g%% INTERVAL", 100*cfg.interval))
+	}
+	if cfg.explain {
+		header = append(header, "TOP FACTORS")
+	}
+	header = append(header, "ERROR")
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, r := range results {
+		cells := []string{strconv.Itoa(r.Row), "-", "-", "-"}
+		if r.Input != nil {
+			cells[1], cells[2] = r.Input.Brand, r.Input.Carbody
+		}
+		if r.Price != nil {
+			cells[3] = fmt.Sprintf("$%.2f", *r.Price)
+		}
+		if cfg.interval > 0 {
+			cells = append(cells, "-")
+			if r.Interval != nil {
+				cells[len(cells)-1] = fmt.Sprintf("$%.0f - $%.0f", r.Interval.Lower, r.Interval.Upper)
+			}
+		}
+		if cfg.explain {
+			cells = append(cells, "-")
+			if r.Explanation != nil {
+				cells[len(cells)-1] = factors(r.Explanation.Contributions)
+			}
+		}
+		if r.Error != nil {
+			cells = append(cells, r.Error.String())
+		} else {
+			cells = append(cells, "")
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// factors summarizes the largest contributions, e.g. "horsepower +1520, brand -830".
+func factors(contributions []domain.FeatureContribution) string {
+	sorted := slices.Clone(contributions)
+	slices.SortStableFunc(sorted, func(a, b domain.FeatureContribution) int {
+		return cmp.Compare(abs(b.Contribution), abs(a.Contribution))
+	})
+	var parts []string
+	for _, c := range sorted[:min(topFactors, len(sorted))] {
+		if c.Contribution != 0 {
+			parts = append(parts, fmt.Sprintf("%s %+.0f", c.Feature, c.Contribution))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// writeCSV writes the results as CSV for scripts, with one contribution column per
+// input field when explaining.
+func writeCSV(w io.Writer, results []predicted, cfg predictConfig) error {
+	cw := csv.NewWriter(w)
+	header := []string{"row", "predicted_price"}
+	if cfg.interval > 0 {
+		header = append(header, "lower", "upper")
+	}
+	if cfg.explain {
+		header = append(header, "baseline_price")
+		for _, f := range inputFields {
+			header = append(header, "contribution_"+f.name)
+		}
+	}
+	header = append(header, "error_code", "error")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		cells := make([]string, len(header))
+		cells[0] = strconv.Itoa(r.Row)
+		if r.Price != nil {
+			cells[1] = formatPrice(*r.Price)
+		}
+		i := 2
+		if cfg.interval > 0 {
+			if r.Interval != nil {
+				cells[i], cells[i+1] = formatPrice(r.Interval.Lower), formatPrice(r.Interval.Upper)
+			}
+			i += 2
+		}
+		if cfg.explain {
+			if r.Explanation != nil {
+				cells[i] = formatPrice(r.Explanation.BaselinePrice)
+				for _, c := range r.Explanation.Contributions {
+					if j := slices.IndexFunc(inputFields, func(f field) bool { return f.name == c.Feature }); j >= 0 {
+						cells[i+1+j] = formatPrice(c.Contribution)
+					}
+				}
+			}
+			i += 1 + len(inputFields)
+		}
+		if r.Error != nil {
+			cells[i], cells[i+1] = string(r.Error.Code), r.Error.String()
+		}
+		if err := cw.Write(cells); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatPrice(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', 2, 32)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/onnxmodel"
+	"car-price-prediction/internal/prediction"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Output formats.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+// predictConfig is how predictions are made and written.
+type predictConfig struct {
+	explain bool
+	// interval is the coverage of the price interval; 0 disables intervals.
+	interval float64
+	output   string
+}
+
+// predicted is the outcome of pricing one input.
+type predicted struct {
+	Row         int                 `json:"row"`
+	Input       *domain.UserInput   `json:"input,omitempty"`
+	Price       *float32            `json:"predicted_price,omitempty"`
+	Interval    *interval           `json:"interval,omitempty"`
+	Explanation *domain.Explanation `json:"explanation,omitempty"`
+	Error       *problem            `json:"error,omitempty"`
+}
+
+// interval is the range of prices predicted by the central Coverage fraction of
+// the trees.
+type interval struct {
+	Lower    float32 `json:"lower"`
+	Upper    float32 `json:"upper"`
+	Coverage float64 `json:"coverage"`
+}
+
+// problem is an error in the shape of the API's problem details.
+type problem struct {
+	Code       domain.ErrorCode   `json:"code"`
+	Message    string             `json:"message"`
+	Violations []domain.Violation `json:"violations,omitempty"`
+}
+
+func (p *problem) String() string {
+	if len(p.Violations) == 0 {
+		return p.Message
+	}
+	parts := make([]string, len(p.Violations))
+	for i, v := range p.Violations {
+		parts[i] = v.Field + ": " + v.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// newProblem describes err the way the API would.
+func newProblem(err error) *problem {
+	var derr *domain.Error
+	if errors.As(err, &derr) {
+		return &problem{Code: derr.Code, Message: derr.Message, Violations: derr.Violations}
+	}
+	return &problem{Code: domain.CodeInferenceFailed, Message: err.Error()}
+}
+
+func predict(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("predict", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), `Usage: carprice predict [flags]
+
+Predicts the prices of cars given as field flags (e.g. -brand toyota -horsepower 92),
+or read from -input: a JSON object, an array of objects, a batch request, NDJSON, or
+CSV with a header row of field names. Without field flags or -input, stdin is read.
+Every input is validated like the API's. The command exits with status 1 if any
+input fails.
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+	modelPath := fs.String("model", "model/best_model.onnx", "model to predict with: an ONNX model, or a model bundle (.json) written by cmd/train")
+	runtime := fs.String("runtime", onnxmodel.RuntimeGo, "runtime of ONNX models: go (tree ensembles only), onnxruntime or onnxruntime-pooled")
+	lib := fs.String("onnxruntime-lib", os.Getenv("ONNXRUNTIME_LIB"), "onnxruntime shared library, for -runtime onnxruntime")
+	inputPath := fs.String("input", "", "file to read inputs from, - for stdin")
+	inputFormat := fs.String("input-format", formatAuto, "format of -input: auto, json (also NDJSON) or csv")
+	var cfg predictConfig
+	fs.StringVar(&cfg.output, "output", outputTable, "output format: table, json (one object per line) or csv")
+	fs.BoolVar(&cfg.explain, "explain", false, "attribute each price to the input fields, like POST /explain")
+	fs.Float64Var(&cfg.interval, "interval", 0, "also report the range of prices predicted by this central fraction of the trees, e.g. 0.9 (random forests only)")
+	values := make(map[string]*string, len(inputFields))
+	for _, f := range inputFields {
+		values[f.name] = fs.String(f.name, "", "input field "+f.name)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	switch cfg.output {
+	case outputTable, outputJSON, outputCSV:
+	default:
+		return fmt.Errorf("unknown output format %q", cfg.output)
+	}
+	if cfg.interval < 0 || cfg.interval >= 1 {
+		return fmt.Errorf("-interval must be in [0, 1), got %v", cfg.interval)
+	}
+
+	// Read the inputs
+	var records []record
+	fields := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		if v, ok := values[f.Name]; ok {
+			fields[f.Name] = *v
+		}
+	})
+	switch {
+	case len(fields) > 0 && *inputPath != "":
+		return errors.New("give either field flags or -input, not both")
+	case len(fields) > 0:
+		input, err := parseFields(fields)
+		records = []record{{Row: 1, Input: input, Err: err}}
+	default:
+		r, format, err := openInput(*inputPath, *inputFormat, stdin)
+		if err != nil {
+			return err
+		}
+		records, err = readInputs(r, format)
+		if c, ok := r.(io.Closer); ok && r != stdin {
+			c.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	service, err := onnxmodel.OpenService(*modelPath, *runtime, *lib)
+	if err != nil {
+		return fmt.Errorf("failed to load model: %w", err)
+	}
+	results, err := predictAll(service, records, cfg)
+	if err != nil {
+		return err
+	}
+	if err := writeResults(stdout, results, cfg); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.Error != nil {
+			return errFailed
+		}
+	}
+	return nil
+}
+
+// openInput opens the input file, or stdin for "" and "-", and resolves the auto
+// format from the file extension.
+func openInput(path, format string, stdin io.Reader) (io.Reader, string, error) {
+	if path == "" || path == "-" {
+		return stdin, format, nil
+	}
+	if format == formatAuto {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			format = formatCSV
+		case ".json", ".ndjson", ".jsonl":
+			format = formatJSON
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, format, nil
+}
+
+// predictAll prices every input that could be read.
+func predictAll(service domain.PredictionService, records []record, cfg predictConfig) ([]predicted, error) {
+	explainer, _ := service.(domain.Explainer)
+	if cfg.explain && explainer == nil {
+		return nil, errors.New("the model does not support explanations")
+	}
+	bundle, _ := service.(*prediction.Bundle)
+	if cfg.interval > 0 && bundle == nil {
+		return nil, errors.New("intervals need a random forest: a model bundle, or an ONNX tree ensemble with -runtime go")
+	}
+
+	results := make([]predicted, len(records))
+	for i, rec := range records {
+		r := &results[i]
+		r.Row = rec.Row
+		if rec.Err != nil {
+			r.Error = newProblem(rec.Err)
+			continue
+		}
+		r.Input = &rec.Input
+
+		var price float32
+		if cfg.explain {
+			explanation, err := explainer.Explain(rec.Input)
+			if err != nil {
+				r.Error = newProblem(err)
+				continue
+			}
+			r.Explanation, price = explanation, explanation.PredictedPrice
+		} else {
+			result, err := service.Predict(rec.Input)
+			if err != nil {
+				r.Error = newProblem(err)
+				continue
+			}
+			price = result.PredictedPrice
+		}
+		r.Price = &price
+
+		if cfg.interval > 0 {
+			lower, upper, err := bundle.Interval(rec.Input, cfg.interval)
+			if err != nil {
+				r.Error = newProblem(err)
+				continue
+			}
+			r.Interval = &interval{Lower: lower, Upper: upper, Coverage: cfg.interval}
+		}
+	}
+	return results, nil
+}
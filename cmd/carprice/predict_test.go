@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"car-price-prediction/internal/domain"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	servedModel = "../../model/best_model.onnx"
+	sampleData  = "../../internal/parity/testdata/raw.csv"
+)
+
+// carFields are the fields of a valid input as text.
+var carFields = map[string]string{
+	"symboling": "3", "wheelbase": "88.6", "carlength": "168.8", "carwidth": "64.1",
+	"carheight": "48.8", "curbweight": "2548", "enginesize": "130", "boreratio": "3.47",
+	"stroke": "2.68", "compressionratio": "9", "horsepower": "111", "peakrpm": "5000",
+	"citympg": "21", "highwaympg": "27", "fueltype": "gas", "aspiration": "std",
+	"doornumber": "two", "carbody": "convertible", "drivewheel": "rwd",
+	"enginelocation": "front", "enginetype": "dohc", "cylindernumber": "four",
+	"fuelsystem": "mpfi", "brand": "alfa-romero",
+}
+
+func carJSON(t *testing.T) string {
+	input, err := parseFields(carFields)
+	require.NoError(t, err)
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestParseFields(t *testing.T) {
+	input, err := parseFields(carFields)
+	require.NoError(t, err)
+	assert.Equal(t, float32(88.6), input.Wheelbase)
+	assert.Equal(t, 2548, input.Curbweight)
+	assert.Equal(t, "alfa-romero", input.Brand)
+
+	fields := map[string]string{"horsepower": "lots", "brand": "bmw"}
+	_, err = parseFields(fields)
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, "horsepower", derr.Violations[0].Field)
+
+	fields["horsepower"] = "111"
+	_, err = parseFields(fields)
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeValidationFailed, derr.Code)
+	assert.Contains(t, newProblem(err).String(), "wheelbase: is required")
+}
+
+func TestReadInputs_JSON(t *testing.T) {
+	car := carJSON(t)
+	data := car + "\n" + car + "\n[" + car + `, {"brand": "bmw"}]` + `{"inputs": [` + car + "]}"
+
+	records, err := readInputs(strings.NewReader(data), formatAuto)
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+	for i, r := range records {
+		assert.Equal(t, i+1, r.Row)
+	}
+	assert.NoError(t, records[2].Err)
+	assert.Error(t, records[3].Err)
+	assert.Equal(t, "alfa-romero", records[4].Input.Brand)
+
+	_, err = readInputs(strings.NewReader(car+"{"), formatJSON)
+	assert.ErrorContains(t, err, "after 1 inputs")
+}
+
+func TestReadInputs_CSV(t *testing.T) {
+	f, err := os.Open(sampleData)
+	require.NoError(t, err)
+	defer f.Close()
+
+	records, err := readInputs(f, formatAuto)
+	require.NoError(t, err)
+	require.Len(t, records, 30)
+	for _, r := range records {
+		require.NoError(t, r.Err)
+	}
+	// The brand comes from CarName
+	assert.Equal(t, "alfa-romero", records[0].Input.Brand)
+}
+
+func TestPredict(t *testing.T) {
+	stdin := strings.NewReader(carJSON(t) + `{"brand": "bmw"}`)
+	var stdout bytes.Buffer
+	err := predict([]string{"-model", servedModel, "-output", "json", "-interval", "0.8", "-explain"}, stdin, &stdout)
+	assert.ErrorIs(t, err, errFailed)
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 2)
+	var ok, failed predicted
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &ok))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &failed))
+
+	require.NotNil(t, ok.Price)
+	assert.InDelta(t, 14600.54, *ok.Price, 0.01)
+	require.NotNil(t, ok.Interval)
+	assert.LessOrEqual(t, ok.Interval.Lower, *ok.Price)
+	assert.GreaterOrEqual(t, ok.Interval.Upper, *ok.Price)
+	require.NotNil(t, ok.Explanation)
+	assert.Equal(t, *ok.Price, ok.Explanation.PredictedPrice)
+	assert.Nil(t, ok.Error)
+
+	assert.Equal(t, 2, failed.Row)
+	assert.Nil(t, failed.Price)
+	require.NotNil(t, failed.Error)
+	assert.Equal(t, domain.CodeValidationFailed, failed.Error.Code)
+}
+
+func TestPredict_Flags(t *testing.T) {
+	args := []string{"-model", servedModel, "-output", "csv"}
+	for name, value := range carFields {
+		args = append(args, "-"+name, value)
+	}
+	var stdout bytes.Buffer
+	require.NoError(t, predict(args, strings.NewReader(""), &stdout))
+	assert.Equal(t, "row,predicted_price,error_code,error\n1,14600.54,,\n", stdout.String())
+
+	err := predict(append(args, "-input", sampleData), nil, &stdout)
+	assert.ErrorContains(t, err, "not both")
+}
+
+func TestWriteTable(t *testing.T) {
+	price := float32(14600.5)
+	results := []predicted{
+		{Row: 1, Input: &domain.UserInput{Brand: "bmw", Carbody: "sedan"}, Price: &price, Interval: &interval{Lower: 12000, Upper: 16000, Coverage: 0.9}},
+		{Row: 2, Error: &problem{Code: domain.CodeUnknownCategory, Message: "The request contains invalid fields.", Violations: []domain.Violation{{Field: "brand", Message: "unknown brand"}}}},
+	}
+	var b bytes.Buffer
+	require.NoError(t, writeTable(&b, results, predictConfig{interval: 0.9}))
+	assert.Equal(t, ""+
+		"ROW  BRAND  CARBODY  PRICE      90% INTERVAL     ERROR\n"+
+		"1    bmw    sedan    $14600.50  $12000 - $16000  \n"+
+		"2    -      -        -          -                brand: unknown brand\n", b.String())
+}
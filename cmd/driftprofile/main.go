@@ -0,0 +1,42 @@
+// Command driftprofile builds the reference profile the server compares prediction
+// inputs with to detect drift, from the training data set.
+//
+// Usage:
+//
+//	driftprofile [-data CarPrice_Assignment.csv] [-out model/reference_profile.json] [-bins 10]
+//
+// Rebuild the profile whenever the model is retrained on different data.
+package main
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/drift"
+	"flag"
+	"log"
+)
+
+func main() {
+	data := flag.String("data", "CarPrice_Assignment.csv", "training data set the model was trained on")
+	out := flag.String("out", "model/reference_profile.json", "file to write the profile to, the same as the server's -drift-profile")
+	bins := flag.Int("bins", drift.DefaultBins, "number of quantile bins of numeric fields")
+	flag.Parse()
+
+	samples, err := dataset.ReadFile(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	inputs := make([]domain.UserInput, len(samples))
+	for i, s := range samples {
+		inputs[i] = s.Input
+	}
+
+	profile, err := drift.NewProfile(inputs, *bins)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := profile.WriteFile(*out); err != nil {
+		log.Fatalf("Failed to write profile: %v", err)
+	}
+	log.Printf("Wrote the profile of %d training rows to %s", profile.Samples, *out)
+}
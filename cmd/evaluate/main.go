@@ -0,0 +1,114 @@
+// Command evaluate measures the accuracy of a model on a labeled data set and
+// writes the results as JSON and Markdown reports.
+//
+// Usage:
+//
+//	evaluate [-model model/best_model.onnx] [-data CarPrice_Assignment.csv]
+//	         [-runtime go] [-onnxruntime-lib $ONNXRUNTIME_LIB]
+//	         [-json report.json] [-markdown report.md] [-worst 10]
+//	         [-buckets 10000,20000,30000]
+//
+// Every row goes through the same validation and feature encoding as the API.
+// The model is an ONNX model or a model bundle written by cmd/train. With -runtime
+// go, ONNX tree ensembles are evaluated in Go; with -runtime onnxruntime, ONNX
+// models are run by onnxruntime like the server does. The Markdown report is
+// printed when neither -json nor -markdown is given.
+package main
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/evaluation"
+	"car-price-prediction/internal/onnxmodel"
+	"car-price-prediction/internal/prediction"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	modelPath := flag.String("model", "model/best_model.onnx", "model to evaluate: an ONNX model, or a model bundle (.json) written by cmd/train")
+	data := flag.String("data", "CarPrice_Assignment.csv", "labeled data set to evaluate on")
+	runtime := flag.String("runtime", onnxmodel.RuntimeGo, "runtime of ONNX models: go (tree ensembles only), onnxruntime or onnxruntime-pooled")
+	lib := flag.String("onnxruntime-lib", os.Getenv("ONNXRUNTIME_LIB"), "onnxruntime shared library, for -runtime onnxruntime")
+	jsonOut := flag.String("json", "", "file to write the JSON report to")
+	markdownOut := flag.String("markdown", "", "file to write the Markdown report to")
+	worst := flag.Int("worst", evaluation.DefaultWorst, "number of largest residuals to report")
+	buckets := flag.String("buckets", "10000,20000,30000", "comma-separated upper bounds of the price buckets")
+	flag.Parse()
+
+	bounds, err := parseBuckets(*buckets)
+	if err != nil {
+		log.Fatalf("Invalid -buckets: %v", err)
+	}
+	samples, err := dataset.ReadFile(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	service, err := onnxmodel.OpenService(*modelPath, *runtime, *lib)
+	if err != nil {
+		log.Fatalf("Failed to load model: %v", err)
+	}
+	report := evaluation.Evaluate(service, samples, evaluation.Options{PriceBuckets: bounds, Worst: *worst})
+	report.Model = filepath.Base(*modelPath)
+	report.Version = prediction.Open(*modelPath).Model().Version
+	report.Dataset = filepath.Base(*data)
+	if !prediction.IsBundle(*modelPath) {
+		report.Runtime = *runtime
+	}
+	log.Printf("Evaluated %d rows: MAE $%.2f, RMSE $%.2f, MAPE %.2f%%",
+		report.Overall.Count, report.Overall.MAE, report.Overall.RMSE, report.Overall.MAPE)
+	if len(report.Failures) > 0 {
+		log.Printf("%d rows failed to predict", len(report.Failures))
+	}
+
+	if *jsonOut != "" {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*jsonOut, append(b, '\n'), 0o644); err != nil {
+			log.Fatalf("Failed to write JSON report: %v", err)
+		}
+		log.Printf("Wrote %s", *jsonOut)
+	}
+	if *markdownOut != "" {
+		f, err := os.Create(*markdownOut)
+		if err != nil {
+			log.Fatalf("Failed to write Markdown report: %v", err)
+		}
+		if err := report.WriteMarkdown(f); err != nil {
+			log.Fatalf("Failed to write Markdown report: %v", err)
+		}
+		if err := f.Close(); err != nil {
+			log.Fatalf("Failed to write Markdown report: %v", err)
+		}
+		log.Printf("Wrote %s", *markdownOut)
+	}
+	if *jsonOut == "" && *markdownOut == "" {
+		if err := report.WriteMarkdown(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// parseBuckets parses ascending, comma-separated price bounds.
+func parseBuckets(s string) ([]float64, error) {
+	var bounds []float64
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, err
+		}
+		if len(bounds) > 0 && v <= bounds[len(bounds)-1] {
+			return nil, fmt.Errorf("bounds must be ascending")
+		}
+		bounds = append(bounds, v)
+	}
+	return bounds, nil
+}
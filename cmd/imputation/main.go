@@ -0,0 +1,42 @@
+// Command imputation builds the table the server fills in missing fields of
+// prediction requests from, with "impute": true, out of the training data set.
+//
+// Usage:
+//
+//	imputation [-data CarPrice_Assignment.csv] [-out model/imputation.json] [-min-group-size 3]
+//
+// Rebuild the table whenever the model is retrained on different data.
+package main
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/imputation"
+	"flag"
+	"log"
+)
+
+func main() {
+	data := flag.String("data", "CarPrice_Assignment.csv", "training data set the model was trained on")
+	out := flag.String("out", "model/imputation.json", "file to write the table to, the same as the server's -imputation-table")
+	minGroupSize := flag.Int("min-group-size", imputation.DefaultMinGroupSize, "training rows a group of similar cars needs before its statistics are used")
+	flag.Parse()
+
+	samples, err := dataset.ReadFile(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	inputs := make([]domain.UserInput, len(samples))
+	for i, s := range samples {
+		inputs[i] = s.Input
+	}
+
+	table, err := imputation.NewTable(inputs, *minGroupSize)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := table.WriteFile(*out); err != nil {
+		log.Fatalf("Failed to write imputation table: %v", err)
+	}
+	log.Printf("Wrote the imputation table of %d training rows to %s", table.Samples, *out)
+}
@@ -0,0 +1,100 @@
+// Command parity checks a model for training/serving skew against golden files
+// exported from the Python pipeline (see internal/parity/testdata/export_golden.py).
+//
+// Usage:
+//
+//	parity [-model model/best_model.onnx] [-data internal/parity/testdata/raw.csv]
+//	       [-features internal/parity/testdata/features.csv]
+//	       [-predictions internal/parity/testdata/predictions.csv] [-tolerance 0.5]
+//	       [-runtime go] [-onnxruntime-lib $ONNXRUNTIME_LIB]
+//
+// The raw rows are encoded the way the server encodes them for the model, by
+// Transform for ONNX models and by the bundle's feature schema for model bundles,
+// and every column is compared with the golden features exported from pandas. The
+// model's predictions are then compared with the golden predictions; pass the
+// predictions exported for the model being checked, or -predictions "" to only
+// check the encoding. The command exits with status 1 if anything differs.
+package main
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/onnxmodel"
+	"car-price-prediction/internal/parity"
+	"car-price-prediction/internal/prediction"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// maxReported is the number of mismatches printed of each check.
+const maxReported = 20
+
+func main() {
+	modelPath := flag.String("model", "model/best_model.onnx", "model to check: an ONNX model, or a model bundle (.json) written by cmd/train")
+	data := flag.String("data", "internal/parity/testdata/raw.csv", "raw data set rows")
+	featuresPath := flag.String("features", "internal/parity/testdata/features.csv", "golden encoded features of the rows, exported from pandas")
+	predictionsPath := flag.String("predictions", "internal/parity/testdata/predictions.csv", "golden predictions of the model for the rows; empty skips the check")
+	tolerance := flag.Float64("tolerance", parity.DefaultTolerance, "largest difference in dollars between a prediction and its golden value")
+	runtime := flag.String("runtime", onnxmodel.RuntimeGo, "runtime of ONNX models: go (tree ensembles only), onnxruntime or onnxruntime-pooled")
+	lib := flag.String("onnxruntime-lib", os.Getenv("ONNXRUNTIME_LIB"), "onnxruntime shared library, for -runtime onnxruntime")
+	flag.Parse()
+
+	samples, err := dataset.ReadFile(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	features, err := parity.ReadMatrix(*featuresPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	service, err := onnxmodel.OpenService(*modelPath, *runtime, *lib)
+	if err != nil {
+		log.Fatalf("Failed to load model: %v", err)
+	}
+
+	enc := parity.TransformEncoding()
+	if bundle, ok := service.(*prediction.Bundle); ok && prediction.IsBundle(*modelPath) {
+		enc = parity.BundleEncoding(bundle)
+	}
+	mismatches, err := parity.CheckFeatures(samples, features, enc)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ok := report("features", len(samples)*len(enc.Columns), mismatches)
+
+	if *predictionsPath != "" {
+		golden, err := parity.ReadMatrix(*predictionsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		prices, err := golden.Column(parity.PredictionColumn)
+		if err != nil {
+			log.Fatal(err)
+		}
+		mismatches, err := parity.CheckPredictions(samples, prices, service, *tolerance)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ok = report("predictions", len(samples), mismatches) && ok
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// report prints the result of a check and reports whether it passed.
+func report(check string, values int, mismatches []parity.Mismatch) bool {
+	if len(mismatches) == 0 {
+		fmt.Printf("%s: %d values match\n", check, values)
+		return true
+	}
+	fmt.Printf("%s: %d of %d values differ\n", check, len(mismatches), values)
+	for _, m := range mismatches[:min(len(mismatches), maxReported)] {
+		fmt.Printf("  %s\n", m)
+	}
+	if len(mismatches) > maxReported {
+		fmt.Printf("  ... and %d more\n", len(mismatches)-maxReported)
+	}
+	return false
+}
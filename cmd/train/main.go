@@ -0,0 +1,91 @@
+// Command train trains a random forest on the CarPrice data set and writes a model
+// bundle the server can serve without onnxruntime.
+//
+// Usage:
+//
+//	train [-data CarPrice_Assignment.csv] [-out model/model.json] [-trees 100]
+//	      [-max-depth 0] [-min-samples-split 2] [-min-samples-leaf 1]
+//	      [-max-features 1] [-holdout 0.2] [-seed 42] [-onnx model/model.onnx]
+//
+// The data is cleaned and encoded like the training notebook: car_ID is dropped,
+// the brand is extracted from CarName and categorical columns are one-hot encoded
+// without their first category. Serve the bundle with `api -model model/model.json`,
+// or the ONNX export with `api -model model/model.onnx`.
+package main
+
+import (
+	"bytes"
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/forest"
+	"car-price-prediction/internal/onnxmodel"
+	"car-price-prediction/internal/training"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	defaults := forest.DefaultParams()
+	data := flag.String("data", "CarPrice_Assignment.csv", "training data set")
+	out := flag.String("out", "model/model.json", "file to write the model bundle to")
+	trees := flag.Int("trees", defaults.Trees, "number of trees")
+	maxDepth := flag.Int("max-depth", defaults.MaxDepth, "maximum depth of each tree (0 is unlimited)")
+	minSamplesSplit := flag.Int("min-samples-split", defaults.MinSamplesSplit, "minimum number of rows a node needs to be split")
+	minSamplesLeaf := flag.Int("min-samples-leaf", defaults.MinSamplesLeaf, "minimum number of rows in each leaf")
+	maxFeatures := flag.Float64("max-features", defaults.MaxFeatures, "fraction of features considered for each split")
+	holdout := flag.Float64("holdout", training.DefaultHoldout, "fraction of rows held out for evaluation (0 trains on all rows)")
+	onnxOut := flag.String("onnx", "", "also export the forest to this ONNX file, servable by onnxruntime")
+	seed := flag.Uint64("seed", defaults.Seed, "seed of the holdout split and the bootstrap samples")
+	flag.Parse()
+
+	raw, err := os.ReadFile(*data)
+	if err != nil {
+		log.Fatalf("Failed to read data set: %v", err)
+	}
+	samples, err := dataset.Read(bytes.NewReader(raw))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bundle, err := training.Train(samples, training.Config{
+		Params: forest.Params{
+			Trees:           *trees,
+			MaxDepth:        *maxDepth,
+			MinSamplesSplit: *minSamplesSplit,
+			MinSamplesLeaf:  *minSamplesLeaf,
+			MaxFeatures:     *maxFeatures,
+			Seed:            *seed,
+		},
+		Holdout: *holdout,
+	})
+	if err != nil {
+		log.Fatalf("Failed to train: %v", err)
+	}
+	sum := sha256.Sum256(raw)
+	bundle.Metadata.Dataset = filepath.Base(*data)
+	bundle.Metadata.DatasetSHA256 = hex.EncodeToString(sum[:])
+
+	m := bundle.Metadata
+	log.Printf("Trained %d trees on %d rows with %d features", len(bundle.Forest.Trees), m.TrainRows, len(bundle.Features))
+	if m.Holdout != nil {
+		log.Printf("Holdout (%d rows): MAE $%.2f, RMSE $%.2f, R2 %.3f", m.HoldoutRows, m.Holdout.MAE, m.Holdout.RMSE, m.Holdout.R2)
+	}
+	if err := bundle.WriteFile(*out); err != nil {
+		log.Fatalf("Failed to write model bundle: %v", err)
+	}
+	log.Printf("Wrote %s", *out)
+
+	if *onnxOut != "" {
+		m, err := onnxmodel.ExportBundle(bundle)
+		if err != nil {
+			log.Fatalf("Failed to export ONNX model: %v", err)
+		}
+		if err := onnxmodel.WriteFile(*onnxOut, m); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Wrote %s", *onnxOut)
+	}
+}
@@ -15,9 +15,110 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/explain": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Predict the price of a car and attribute the difference from a typical car to each input field.\nErrors use the same problem details format and codes as /predict, and the body may use ` + "`" + `catalog_id` + "`" + `, ` + "`" + `impute` + "`" + ` and units\nand the X-Model header select a model as there.\nBearer tokens need the explain:read scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Explain car price prediction",
+                "parameters": [
+                    {
+                        "description": "Car Features",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Registered model to explain, see /v1/models",
+                        "name": "X-Model",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Explanation"
+                        }
+                    },
+                    "400": {
+                        "description": "VALIDATION_FAILED, UNKNOWN_CATEGORY or OUT_OF_RANGE",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope explain:read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "RATE_LIMITED or QUOTA_EXCEEDED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "INFERENCE_FAILED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "501": {
+                        "description": "MODEL_UNAVAILABLE: the model does not support explanations",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "MODEL_UNAVAILABLE",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "TIMEOUT",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
         "/predict": {
             "post": {
-                "description": "Predict the price of a car based on its features.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Predict the price of a car based on its features.\nWhen the vehicle catalog is enabled, the body may name a ` + "`" + `catalog_id` + "`" + ` from /v1/catalog and give only the fields\nthat differ from the catalog entry; the response lists the fields taken from the entry in ` + "`" + `defaulted` + "`" + `.\nWhen imputation is enabled, a body with ` + "`" + `\"impute\": true` + "`" + ` may leave out any field. Missing fields get the median\nor most frequent value of similar training cars; the response lists them in ` + "`" + `imputed` + "`" + ` and gives the ` + "`" + `price_range` + "`" + `\ntheir plausible values span.\nLengths, curbweight, enginesize, horsepower and mileage may be given in other units with ` + "`" + `\"unit_system\": \"metric\"` + "`" + `\n(mm, kg, cc, L/100km) and/or per-field ` + "`" + `units` + "`" + `, e.g. ` + "`" + `{\"horsepower\": \"kw\"}` + "`" + `. They are converted to model units,\nand the response lists the ` + "`" + `conversions` + "`" + ` and echoes the ` + "`" + `normalized_input` + "`" + ` the model was given.\nThe ` + "`" + `currency` + "`" + ` and ` + "`" + `year` + "`" + ` query parameters add a ` + "`" + `quote` + "`" + ` of the price, which is in USD of the training data's year,\nadjusted for US inflation to ` + "`" + `year` + "`" + ` and converted at the configured exchange rate, with the index and rate used.\nThe ` + "`" + `region` + "`" + ` query parameter adjusts the price by the rules of a regional market, such as import duties, and\nadds it as ` + "`" + `regional` + "`" + ` with a line item per matching rule and the rule set version; a quote is of the adjusted price.\nThe X-Model header selects a registered model from /v1/models instead of the default model; the response names it\nin ` + "`" + `model` + "`" + `. Linear models break the price down into ` + "`" + `linear` + "`" + ` terms, coefficient times encoded value.\nErrors are returned as RFC 7807 problem details (application/problem+json) with a stable ` + "`" + `code` + "`" + `:\nVALIDATION_FAILED (400), UNKNOWN_CATEGORY (400), OUT_OF_RANGE (400), MODEL_UNAVAILABLE (503), INFERENCE_FAILED (500) and TIMEOUT (504).\nWhen authentication is enabled, an X-API-Key header or a bearer token with the predict:read scope is required,\nand UNAUTHORIZED (401), FORBIDDEN (403), RATE_LIMITED (429) and QUOTA_EXCEEDED (429) may also be returned.",
                 "consumes": [
                     "application/json"
                 ],
@@ -34,45 +135,1655 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/domain.UserInput"
                         }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/domain.PredictionResult"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Registered model to predict with, see /v1/models",
+                        "name": "X-Model",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Region to adjust the price for, see /v1/regions",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Currency to quote the price in, e.g. IDR",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Year to adjust the price for inflation to, e.g. 2024",
+                        "name": "year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PredictionResult"
+                        }
+                    },
+                    "400": {
+                        "description": "VALIDATION_FAILED, UNKNOWN_CATEGORY or OUT_OF_RANGE",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope predict:read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "RATE_LIMITED or QUOTA_EXCEEDED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "INFERENCE_FAILED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "MODEL_UNAVAILABLE or STORAGE_UNAVAILABLE",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "TIMEOUT",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/predict/batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Predict the prices of up to 1000 cars in one request. Rows are validated and predicted individually;\na failing row carries a problem details ` + "`" + `error` + "`" + ` instead of a ` + "`" + `result` + "`" + `. Each row counts against the API key quota,\nand rows that fail are not charged. Rows may use ` + "`" + `catalog_id` + "`" + `, ` + "`" + `impute` + "`" + ` and units, and the prices are adjusted\nfor ` + "`" + `region` + "`" + ` and quoted in ` + "`" + `currency` + "`" + ` and ` + "`" + `year` + "`" + `, and predicted by the X-Model, as in /predict.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Predict car prices in batch",
+                "parameters": [
+                    {
+                        "description": "Car Features",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.BatchInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Registered model to predict with, see /v1/models",
+                        "name": "X-Model",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Region to adjust the prices for, see /v1/regions",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Currency to quote the prices in, e.g. IDR",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Year to adjust the prices for inflation to, e.g. 2024",
+                        "name": "year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.BatchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "VALIDATION_FAILED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope predict:read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "RATE_LIMITED or QUOTA_EXCEEDED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "TIMEOUT",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/accuracy": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return the MAE, RMSE, MAPE and R2 of predictions against the sale prices reported to /v1/feedback\nover rolling windows (1h, 24h, 7d and 30d), overall and by model version, brand and body type,\nalong with the firing MAE alerts. Bearer tokens need the models:admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Report the accuracy of the model on real sales",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/feedback.Summary"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope models:admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/catalog": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Find car models by name, e.g. ` + "`" + `audi 100ls` + "`" + `, and return their full specs. Every word of the query must start\na word of the model name or be its brand; exact name matches come first. A prediction request can pass\nthe ` + "`" + `catalog_id` + "`" + ` of a result and only the fields it wants to change. Bearer tokens need the predict:read scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Search the vehicle catalog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Model name to search for",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of results (1-100, default 10)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.CatalogSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "VALIDATION_FAILED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope predict:read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/feedback": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Attach the price a car actually sold for to a recorded prediction (` + "`" + `prediction_id` + "`" + `, requires the\nprediction log) or to a car ` + "`" + `input` + "`" + `, which is predicted with the current model or the one the X-Model header\nselects. Exactly one of them must be set. Feedback on a prediction that already has feedback replaces it.\nFeedback on an input counts as one prediction against the caller's quota. Bearer tokens need the feedback:write scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Report an actual sale price",
+                "parameters": [
+                    {
+                        "description": "Sale price",
+                        "name": "feedback",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.FeedbackInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Registered model to predict an input with, see /v1/models",
+                        "name": "X-Model",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/feedback.Observation"
+                        }
+                    },
+                    "400": {
+                        "description": "VALIDATION_FAILED, UNKNOWN_CATEGORY or OUT_OF_RANGE",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope feedback:write",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "NOT_FOUND: unknown prediction_id",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "RATE_LIMITED or QUOTA_EXCEEDED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "INFERENCE_FAILED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "MODEL_UNAVAILABLE or STORAGE_UNAVAILABLE",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "TIMEOUT",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the models requests to /predict, /predict/batch, /explain and /v1/predict/depreciation can select\nwith the X-Model header instead of the default model. Bearer tokens need the models:admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the registered models",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.RegisteredModel"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope models:admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/current": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return the version (content hash) of the model serving predictions. Bearer tokens need the models:admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Describe the current model",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ModelInfo"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope models:admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/models/reload": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Load the model file again, e.g. after it was replaced on disk, and invalidate cached predictions.\nIf loading fails, the current model keeps serving. Bearer tokens need the models:admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Reload the model",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ModelInfo"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope models:admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "MODEL_UNAVAILABLE",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/monitoring/drift": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Compare the distribution of recent prediction inputs with the training data over sliding windows\n(1h, 24h and 7d). Numeric fields are compared with PSI and the Kolmogorov-Smirnov test, categorical\nfields with PSI and the chi-square test. A field drifts when its PSI reaches 0.25 and warns from 0.1.\nWindows with too few inputs report insufficient_data. Bearer tokens need the models:admin scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Report input drift",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/drift.Report"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope models:admin",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/predict/depreciation": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Predict the price of a car new and project its value at ` + "`" + `age_years` + "`" + ` and ` + "`" + `mileage` + "`" + ` (in miles), and at the ages 0 to 10\ndriven as much per year as so far. The depreciation curve is chosen by brand and body type and returned with the result.\nIf ` + "`" + `mileage` + "`" + ` is left out, the mileage the curve expects for the age is assumed. The body may use ` + "`" + `catalog_id` + "`" + `, ` + "`" + `impute` + "`" + `\nand units, and the X-Model header may select a model, as in /predict. Errors use the same problem details format and codes as /predict. The price new\nis recorded in the prediction log like a /predict result. Counts as one prediction against the quota; bearer tokens need the predict:read scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Project the depreciation of a used car",
+                "parameters": [
+                    {
+                        "description": "Car Features, Age and Mileage",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DepreciationInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Registered model to predict with, see /v1/models",
+                        "name": "X-Model",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.DepreciationResult"
+                        }
+                    },
+                    "400": {
+                        "description": "VALIDATION_FAILED, UNKNOWN_CATEGORY or OUT_OF_RANGE",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope predict:read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "RATE_LIMITED or QUOTA_EXCEEDED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "INFERENCE_FAILED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "MODEL_UNAVAILABLE",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "TIMEOUT",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/predictions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return the stored record of a prediction (input, encoded features, model version, output, latency)\nand predict the input again with the recorded model version to confirm the result is identical.\nCallers see their own predictions; admin keys and tokens with the models:admin scope see all.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Look up and replay a recorded prediction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Prediction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.PredictionLookupResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope predict:read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "NOT_FOUND",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "STORAGE_UNAVAILABLE",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "TIMEOUT",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/regions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the regions whose rules the ` + "`" + `region` + "`" + ` query parameter of /predict and /predict/batch can apply, with the\nversion of the rule set in effect. Bearer tokens need the predict:read scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List regional pricing rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.RegionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "FORBIDDEN: missing scope predict:read",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/usage": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Report the number of predictions made in a billing period (calendar month, UTC) against the monthly quota.\nRegular keys see their own usage; admin keys and bearer tokens with the models:admin scope see the usage of every key.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Report API key usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Billing period as YYYY-MM (defaults to the current month)",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.UsageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "VALIDATION_FAILED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "UNAUTHORIZED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "RATE_LIMITED",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.CatalogSearchResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/catalog.Entry"
+                    }
+                }
+            }
+        },
+        "api.PredictionLookupResponse": {
+            "type": "object",
+            "properties": {
+                "record": {
+                    "$ref": "#/definitions/predlog.Record"
+                },
+                "replay": {
+                    "$ref": "#/definitions/predlog.Replay"
+                }
+            }
+        },
+        "api.RegionsResponse": {
+            "type": "object",
+            "properties": {
+                "regions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rules.Region"
+                    }
+                },
+                "version": {
+                    "type": "string",
+                    "example": "2025-10-01"
+                }
+            }
+        },
+        "api.RegisteredModel": {
+            "type": "object",
+            "properties": {
+                "model": {
+                    "$ref": "#/definitions/domain.ModelInfo"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "linear"
+                }
+            }
+        },
+        "api.UsageResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.UsageReport"
+                    }
+                },
+                "period": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.UsageReport": {
+            "type": "object",
+            "properties": {
+                "key_id": {
+                    "type": "string"
+                },
+                "monthly_quota": {
+                    "description": "0 means unlimited",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "remaining": {
+                    "description": "omitted for unlimited keys",
+                    "type": "integer"
+                },
+                "revoked": {
+                    "type": "boolean"
+                },
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "catalog.Entry": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "type": "string",
+                    "example": "audi"
+                },
+                "id": {
+                    "description": "ID identifies the entry in prediction requests, e.g. \"audi-100ls\".\nModels listed more than once get a numeric suffix (\"toyota-corolla-2\").",
+                    "type": "string",
+                    "example": "audi-100ls"
+                },
+                "name": {
+                    "description": "Name is the model name as listed in the data set.",
+                    "type": "string",
+                    "example": "audi 100ls"
+                },
+                "spec": {
+                    "$ref": "#/definitions/domain.UserInput"
+                }
+            }
+        },
+        "domain.BatchInput": {
+            "type": "object",
+            "required": [
+                "inputs"
+            ],
+            "properties": {
+                "inputs": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.UserInput"
+                    }
+                }
+            }
+        },
+        "domain.BatchItem": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/domain.Problem"
+                },
+                "result": {
+                    "$ref": "#/definitions/domain.PredictionResult"
+                }
+            }
+        },
+        "domain.BatchResult": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BatchItem"
+                    }
+                }
+            }
+        },
+        "domain.DepreciationCurve": {
+            "type": "object",
+            "properties": {
+                "annual": {
+                    "description": "Annual is the fraction of the remaining value lost in every later year.",
+                    "type": "number",
+                    "example": 0.16
+                },
+                "basis": {
+                    "description": "Basis names the cars the curve is configured for, e.g. \"brand=bmw,carbody=sedan\", or \"default\".",
+                    "type": "string",
+                    "example": "brand=bmw"
+                },
+                "first_year": {
+                    "description": "FirstYear is the fraction lost in the first year.",
+                    "type": "number",
+                    "example": 0.24
+                },
+                "floor": {
+                    "description": "Floor is the fraction a car never falls below.",
+                    "type": "number",
+                    "example": 0.1
+                },
+                "mileage_per_year": {
+                    "description": "MileagePerYear is the mileage a car is expected to be driven per year.",
+                    "type": "number",
+                    "example": 12000
+                },
+                "mileage_rate": {
+                    "description": "MileageRate is the fraction lost per 10,000 miles above the expected mileage, or regained below it.",
+                    "type": "number",
+                    "example": 0.02
+                }
+            }
+        },
+        "domain.DepreciationInput": {
+            "type": "object",
+            "required": [
+                "age_years",
+                "aspiration",
+                "boreratio",
+                "brand",
+                "carbody",
+                "carheight",
+                "carlength",
+                "carwidth",
+                "citympg",
+                "compressionratio",
+                "curbweight",
+                "cylindernumber",
+                "doornumber",
+                "drivewheel",
+                "enginelocation",
+                "enginesize",
+                "enginetype",
+                "fuelsystem",
+                "fueltype",
+                "highwaympg",
+                "horsepower",
+                "peakrpm",
+                "stroke",
+                "wheelbase"
+            ],
+            "properties": {
+                "age_years": {
+                    "description": "AgeYears is the age of the car in years, from 0 to 30.",
+                    "type": "number",
+                    "example": 3
+                },
+                "aspiration": {
+                    "type": "string"
+                },
+                "boreratio": {
+                    "type": "number"
+                },
+                "brand": {
+                    "type": "string"
+                },
+                "carbody": {
+                    "type": "string"
+                },
+                "carheight": {
+                    "type": "number"
+                },
+                "carlength": {
+                    "type": "number"
+                },
+                "carwidth": {
+                    "type": "number"
+                },
+                "citympg": {
+                    "type": "integer"
+                },
+                "compressionratio": {
+                    "type": "number"
+                },
+                "curbweight": {
+                    "type": "integer"
+                },
+                "cylindernumber": {
+                    "type": "string"
+                },
+                "doornumber": {
+                    "type": "string"
+                },
+                "drivewheel": {
+                    "type": "string"
+                },
+                "enginelocation": {
+                    "type": "string"
+                },
+                "enginesize": {
+                    "type": "integer"
+                },
+                "enginetype": {
+                    "type": "string"
+                },
+                "fuelsystem": {
+                    "type": "string"
+                },
+                "fueltype": {
+                    "description": "Categorical features",
+                    "type": "string"
+                },
+                "highwaympg": {
+                    "type": "integer"
+                },
+                "horsepower": {
+                    "type": "integer"
+                },
+                "mileage": {
+                    "description": "Mileage is the distance the car has been driven, in miles. If left out,\nthe mileage its depreciation curve expects for the age is assumed.",
+                    "type": "number",
+                    "example": 45000
+                },
+                "peakrpm": {
+                    "type": "integer"
+                },
+                "stroke": {
+                    "type": "number"
+                },
+                "symboling": {
+                    "description": "Numerical features",
+                    "type": "integer"
+                },
+                "wheelbase": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.DepreciationPoint": {
+            "type": "object",
+            "properties": {
+                "mileage": {
+                    "type": "number",
+                    "example": 75000
+                },
+                "retention": {
+                    "type": "number",
+                    "example": 0.367
+                },
+                "value": {
+                    "type": "number",
+                    "example": 4953.08
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "domain.DepreciationResult": {
+            "type": "object",
+            "properties": {
+                "age_years": {
+                    "type": "number",
+                    "example": 3
+                },
+                "curve": {
+                    "$ref": "#/definitions/domain.DepreciationCurve"
+                },
+                "mileage": {
+                    "type": "number",
+                    "example": 45000
+                },
+                "mileage_assumed": {
+                    "description": "MileageAssumed is set if the request left out the mileage.",
+                    "type": "boolean"
+                },
+                "prediction": {
+                    "description": "Prediction is the price the model predicts for the car new.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.PredictionResult"
+                        }
+                    ]
+                },
+                "retention": {
+                    "description": "Retention is Value as a fraction of the predicted price.",
+                    "type": "number",
+                    "example": 0.5266
+                },
+                "schedule": {
+                    "description": "Schedule is the projected value at the ages 0 to 10, driven as much per year as so far.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DepreciationPoint"
+                    }
+                },
+                "value": {
+                    "description": "Value is the projected value of the car at its age and mileage.",
+                    "type": "number",
+                    "example": 7106.51
+                }
+            }
+        },
+        "domain.ErrorCode": {
+            "type": "string",
+            "enum": [
+                "VALIDATION_FAILED",
+                "UNKNOWN_CATEGORY",
+                "OUT_OF_RANGE",
+                "MODEL_UNAVAILABLE",
+                "INFERENCE_FAILED",
+                "TIMEOUT",
+                "UNAUTHORIZED",
+                "FORBIDDEN",
+                "RATE_LIMITED",
+                "QUOTA_EXCEEDED",
+                "NOT_FOUND",
+                "STORAGE_UNAVAILABLE"
+            ],
+            "x-enum-varnames": [
+                "CodeValidationFailed",
+                "CodeUnknownCategory",
+                "CodeOutOfRange",
+                "CodeModelUnavailable",
+                "CodeInferenceFailed",
+                "CodeTimeout",
+                "CodeUnauthorized",
+                "CodeForbidden",
+                "CodeRateLimited",
+                "CodeQuotaExceeded",
+                "CodeNotFound",
+                "CodeStorageUnavailable"
+            ]
+        },
+        "domain.ExchangeRate": {
+            "type": "object",
+            "properties": {
+                "effective_date": {
+                    "type": "string",
+                    "example": "2025-06-30"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 16250
+                },
+                "to": {
+                    "type": "string",
+                    "example": "IDR"
+                }
+            }
+        },
+        "domain.Explanation": {
+            "type": "object",
+            "properties": {
+                "baseline_price": {
+                    "type": "number"
+                },
+                "catalog_id": {
+                    "description": "CatalogID, Defaulted, Imputed, Conversions and NormalizedInput are set as in PredictionResult.",
+                    "type": "string",
+                    "example": "audi-100ls"
+                },
+                "contributions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FeatureContribution"
+                    }
+                },
+                "conversions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.UnitConversion"
+                    }
+                },
+                "defaulted": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "imputed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ImputedField"
+                    }
+                },
+                "normalized_input": {
+                    "$ref": "#/definitions/domain.UserInput"
+                },
+                "predicted_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.FeatureContribution": {
+            "type": "object",
+            "properties": {
+                "contribution": {
+                    "type": "number"
+                },
+                "feature": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FeedbackInput": {
+            "type": "object",
+            "required": [
+                "actual_price"
+            ],
+            "properties": {
+                "actual_price": {
+                    "type": "number",
+                    "example": 13950
+                },
+                "input": {
+                    "$ref": "#/definitions/domain.UserInput"
+                },
+                "prediction_id": {
+                    "type": "string",
+                    "example": "f4fc1c2a1d2da2b90dfacff13d4d8840"
+                }
+            }
+        },
+        "domain.ImputedField": {
+            "type": "object",
+            "properties": {
+                "basis": {
+                    "description": "Basis names the training cars the value was taken from, e.g. \"brand=audi,enginetype=ohc\", or \"all\".",
+                    "type": "string",
+                    "example": "brand=audi"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "stroke"
+                },
+                "value": {
+                    "description": "Value is the value the field was given: a number or a string.",
+                    "type": "string",
+                    "example": "3.4"
+                }
+            }
+        },
+        "domain.Inflation": {
+            "type": "object",
+            "properties": {
+                "base_index": {
+                    "type": "number",
+                    "example": 107.6
+                },
+                "factor": {
+                    "type": "number",
+                    "example": 2.9153
+                },
+                "index": {
+                    "type": "string",
+                    "example": "US CPI-U (BLS CUUR0000SA0, annual average)"
+                },
+                "target_index": {
+                    "type": "number",
+                    "example": 313.689
+                }
+            }
+        },
+        "domain.LineItem": {
+            "type": "object",
+            "properties": {
+                "adjust": {
+                    "type": "string",
+                    "example": "+25%"
+                },
+                "amount": {
+                    "type": "number",
+                    "example": 3373.75
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "luxury import duty"
+                },
+                "when": {
+                    "type": "string",
+                    "example": "brand in [bmw, porsche] and enginesize \u003e 180"
+                }
+            }
+        },
+        "domain.LinearBreakdown": {
+            "type": "object",
+            "properties": {
+                "intercept": {
+                    "type": "number",
+                    "example": -12040.5
+                },
+                "terms": {
+                    "description": "Terms lists the encoded features with a non-zero value, in the model's feature order.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.LinearTerm"
+                    }
+                }
+            }
+        },
+        "domain.LinearTerm": {
+            "type": "object",
+            "properties": {
+                "coefficient": {
+                    "type": "number",
+                    "example": 54.2
+                },
+                "contribution": {
+                    "description": "Contribution is Coefficient times Value.",
+                    "type": "number",
+                    "example": 6016.2
+                },
+                "feature": {
+                    "type": "string",
+                    "example": "horsepower"
+                },
+                "value": {
+                    "description": "Value is the encoded value of the feature, e.g. 1 for a one-hot category the car has.",
+                    "type": "number",
+                    "example": 111
+                }
+            }
+        },
+        "domain.MemberPrediction": {
+            "type": "object",
+            "properties": {
+                "model_version": {
+                    "type": "string",
+                    "example": "3f2a9c1b7d4e"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "random_forest"
+                },
+                "predicted_price": {
+                    "type": "number",
+                    "example": 13870.5
+                },
+                "weight": {
+                    "description": "Weight is the member's weight in the mean or its coefficient in the stacking\nmodel. A median ensemble does not weigh its members.",
+                    "type": "number",
+                    "example": 2
+                }
+            }
+        },
+        "domain.ModelInfo": {
+            "type": "object",
+            "properties": {
+                "loaded_at": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version identifies the model; it is derived from the model's content hash.",
+                    "type": "string",
+                    "example": "5b1f0c3e9a2d"
+                }
+            }
+        },
+        "domain.PredictionDebug": {
+            "type": "object",
+            "properties": {
+                "aggregation": {
+                    "description": "Aggregation is how the members' predictions were combined: mean, median or stacking.",
+                    "type": "string",
+                    "example": "mean"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.MemberPrediction"
+                    }
+                }
+            }
+        },
+        "domain.PredictionResult": {
+            "type": "object",
+            "properties": {
+                "catalog_id": {
+                    "description": "CatalogID is the catalog entry the input was completed from, if the request named one.",
+                    "type": "string",
+                    "example": "audi-100ls"
+                },
+                "conversions": {
+                    "description": "Conversions lists the input fields that were converted from the units the request gave them in.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.UnitConversion"
+                    }
+                },
+                "debug": {
+                    "description": "Debug shows how an ensemble of models arrived at the price. It is meant for\ncomparing models and may change between releases.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.PredictionDebug"
+                        }
+                    ]
+                },
+                "defaulted": {
+                    "description": "Defaulted lists the input fields that were taken from the catalog entry.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "imputed": {
+                    "description": "Imputed lists the input fields that were filled in because the request asked\nfor imputation and left them out.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ImputedField"
+                    }
+                },
+                "linear": {
+                    "description": "Linear breaks the price down into the terms of a linear model, if one made the prediction.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.LinearBreakdown"
+                        }
+                    ]
+                },
+                "model": {
+                    "description": "Model names the registered model that made the prediction, if the request selected one.",
+                    "type": "string",
+                    "example": "linear"
+                },
+                "normalized_input": {
+                    "description": "NormalizedInput is the complete input the model was given, in model units and with\nnormalized categories. It is only set if the request used a catalog entry, imputation or units.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.UserInput"
+                        }
+                    ]
+                },
+                "predicted_price": {
+                    "type": "number"
+                },
+                "prediction_id": {
+                    "description": "PredictionID identifies the recorded prediction when the prediction log is enabled.",
+                    "type": "string"
+                },
+                "price_range": {
+                    "description": "PriceRange is the span of prices the imputed fields could plausibly lead to.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.PriceRange"
                         }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                    ]
+                },
+                "quote": {
+                    "description": "Quote is the predicted price in the currency and year the request asked for, if any.\nIf the price was adjusted for a region, the adjusted price is quoted.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Quote"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                    ]
+                },
+                "regional": {
+                    "description": "Regional is the predicted price adjusted by the rules of the region the request asked for, if any.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.RegionalPrice"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.PriceRange": {
+            "type": "object",
+            "properties": {
+                "lower": {
+                    "type": "number"
+                },
+                "upper": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is the stable, machine-readable error code.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ErrorCode"
                         }
+                    ],
+                    "example": "VALIDATION_FAILED"
+                },
+                "detail": {
+                    "description": "Detail is a human-readable explanation specific to this occurrence.",
+                    "type": "string",
+                    "example": "The request contains invalid fields."
+                },
+                "instance": {
+                    "description": "Instance identifies the request path that caused the problem.",
+                    "type": "string",
+                    "example": "/predict"
+                },
+                "status": {
+                    "description": "Status is the HTTP status code.",
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "description": "Title is a short, human-readable summary of the problem type.",
+                    "type": "string",
+                    "example": "Validation failed"
+                },
+                "type": {
+                    "description": "Type is a URI identifying the problem type. It embeds the version of the error model.",
+                    "type": "string",
+                    "example": "urn:car-price-prediction:problem:v1:validation-failed"
+                },
+                "violations": {
+                    "description": "Violations lists the problems with individual request fields.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Violation"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.ErrorResponse": {
+        },
+        "domain.Quote": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string"
+                "base_currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "base_price": {
+                    "description": "BasePrice is the predicted price, or the regional price if there is one, in BaseCurrency of BaseYear.",
+                    "type": "number",
+                    "example": 13495
+                },
+                "base_year": {
+                    "type": "integer",
+                    "example": 1985
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "IDR"
+                },
+                "exchange_rate": {
+                    "description": "ExchangeRate is the rate the price was converted at, if Currency differs from BaseCurrency.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ExchangeRate"
+                        }
+                    ]
+                },
+                "inflation": {
+                    "description": "Inflation is the price index adjustment to Year, if it differs from BaseYear.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Inflation"
+                        }
+                    ]
+                },
+                "price": {
+                    "type": "number",
+                    "example": 639312612.86
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 2024
                 }
             }
         },
-        "domain.PredictionResult": {
+        "domain.RegionalPrice": {
             "type": "object",
             "properties": {
-                "predicted_price": {
-                    "type": "number"
+                "base_price": {
+                    "type": "number",
+                    "example": 13495
+                },
+                "line_items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.LineItem"
+                    }
+                },
+                "price": {
+                    "type": "number",
+                    "example": 16868.75
+                },
+                "region": {
+                    "type": "string",
+                    "example": "id"
+                },
+                "rule_set_version": {
+                    "type": "string",
+                    "example": "2025-10-01"
+                }
+            }
+        },
+        "domain.UnitConversion": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "wheelbase"
+                },
+                "normalized": {
+                    "type": "number",
+                    "example": 98.43
+                },
+                "normalized_unit": {
+                    "type": "string",
+                    "example": "in"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "mm"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 2500
                 }
             }
         },
@@ -104,10 +1815,6 @@ const docTemplate = `{
                 "wheelbase"
             ],
             "properties": {
-                "symboling": {
-                    "description": "Numerical features",
-                    "type": "integer"
-                },
                 "aspiration": {
                     "type": "string"
                 },
@@ -175,10 +1882,373 @@ const docTemplate = `{
                 "stroke": {
                     "type": "number"
                 },
+                "symboling": {
+                    "description": "Numerical features",
+                    "type": "integer"
+                },
                 "wheelbase": {
                     "type": "number"
                 }
             }
+        },
+        "domain.Violation": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "$ref": "#/definitions/domain.ErrorCode"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "drift.CategoryShift": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "type": "number",
+                    "example": 0.08
+                },
+                "reference": {
+                    "type": "number",
+                    "example": 0
+                },
+                "value": {
+                    "type": "string",
+                    "example": "tesla"
+                }
+            }
+        },
+        "drift.FeatureDrift": {
+            "type": "object",
+            "properties": {
+                "chi_square": {
+                    "description": "ChiSquare is Pearson's chi-square statistic of categorical fields.",
+                    "type": "number",
+                    "example": 84.2
+                },
+                "feature": {
+                    "type": "string",
+                    "example": "brand"
+                },
+                "kind": {
+                    "description": "Kind is \"numeric\" or \"categorical\".",
+                    "type": "string",
+                    "example": "categorical"
+                },
+                "ks": {
+                    "description": "KS is the Kolmogorov-Smirnov statistic of numeric fields.",
+                    "type": "number",
+                    "example": 0.12
+                },
+                "p_value": {
+                    "description": "PValue is the p-value of the KS or chi-square test.",
+                    "type": "number",
+                    "example": 0.0001
+                },
+                "psi": {
+                    "type": "number",
+                    "example": 0.31
+                },
+                "shifts": {
+                    "description": "Shifts lists the categories whose share changed most.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/drift.CategoryShift"
+                    }
+                },
+                "status": {
+                    "description": "Status is derived from the PSI: ok, warning (\u003e= 0.1) or drift (\u003e= 0.25).",
+                    "type": "string",
+                    "example": "drift"
+                }
+            }
+        },
+        "drift.Report": {
+            "type": "object",
+            "properties": {
+                "windows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/drift.WindowReport"
+                    }
+                }
+            }
+        },
+        "drift.WindowReport": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "description": "Features are sorted by decreasing PSI.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/drift.FeatureDrift"
+                    }
+                },
+                "samples": {
+                    "type": "integer",
+                    "example": 1250
+                },
+                "since": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is the worst status of any feature.",
+                    "type": "string",
+                    "example": "warning"
+                },
+                "window": {
+                    "type": "string",
+                    "example": "24h"
+                }
+            }
+        },
+        "feedback.Accuracy": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Count is the number of sales with feedback.",
+                    "type": "integer",
+                    "example": 120
+                },
+                "mae": {
+                    "description": "MAE is the mean absolute error in dollars.",
+                    "type": "number",
+                    "example": 1288.4
+                },
+                "mape": {
+                    "description": "MAPE is the mean absolute percentage error, in percent.",
+                    "type": "number",
+                    "example": 9.7
+                },
+                "r2": {
+                    "description": "R2 is the coefficient of determination. It is omitted for fewer than two\nsales or if all sold for the same price.",
+                    "type": "number",
+                    "example": 0.958
+                },
+                "rmse": {
+                    "description": "RMSE is the root mean squared error in dollars.",
+                    "type": "number",
+                    "example": 1835.2
+                }
+            }
+        },
+        "feedback.Alert": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "mae": {
+                    "type": "number",
+                    "example": 2350.7
+                },
+                "model_version": {
+                    "type": "string",
+                    "example": "238dbbdd6d08"
+                },
+                "since": {
+                    "description": "Since is when the alert started firing.",
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number",
+                    "example": 2000
+                },
+                "window": {
+                    "type": "string",
+                    "example": "24h0m0s"
+                }
+            }
+        },
+        "feedback.Observation": {
+            "type": "object",
+            "properties": {
+                "actual_price": {
+                    "type": "number"
+                },
+                "brand": {
+                    "type": "string"
+                },
+                "carbody": {
+                    "type": "string"
+                },
+                "model_version": {
+                    "type": "string"
+                },
+                "predicted_price": {
+                    "type": "number"
+                },
+                "prediction_id": {
+                    "description": "PredictionID is the recorded prediction the sale belongs to. It is empty for\nfeedback on an input that was predicted when the feedback arrived.",
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "feedback.Report": {
+            "type": "object",
+            "properties": {
+                "by_brand": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/feedback.Accuracy"
+                    }
+                },
+                "by_carbody": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/feedback.Accuracy"
+                    }
+                },
+                "by_model_version": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/feedback.Accuracy"
+                    }
+                },
+                "overall": {
+                    "$ref": "#/definitions/feedback.Accuracy"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "window": {
+                    "type": "string",
+                    "example": "24h"
+                }
+            }
+        },
+        "feedback.Summary": {
+            "type": "object",
+            "properties": {
+                "alerts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/feedback.Alert"
+                    }
+                },
+                "windows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/feedback.Report"
+                    }
+                }
+            }
+        },
+        "predlog.Record": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "input": {
+                    "$ref": "#/definitions/domain.UserInput"
+                },
+                "latency_us": {
+                    "type": "integer"
+                },
+                "model_sha256": {
+                    "type": "string"
+                },
+                "model_version": {
+                    "type": "string"
+                },
+                "predicted_price": {
+                    "type": "number"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "predlog.Replay": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error explains why the replay failed.",
+                    "type": "string"
+                },
+                "features_identical": {
+                    "description": "FeaturesIdentical reports whether the recorded model's preprocessing still\nencodes the input into the recorded feature vector.",
+                    "type": "boolean"
+                },
+                "identical": {
+                    "description": "Identical reports whether the replayed price equals the recorded one bit for bit.",
+                    "type": "boolean"
+                },
+                "model_version": {
+                    "type": "string"
+                },
+                "predicted_price": {
+                    "description": "PredictedPrice is the replayed prediction. It is omitted if the replay failed.",
+                    "type": "number"
+                }
+            }
+        },
+        "rules.Region": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rules.Rule"
+                    }
+                }
+            }
+        },
+        "rules.Rule": {
+            "type": "object",
+            "properties": {
+                "adjust": {
+                    "description": "Adjust is a signed percentage of the predicted price, e.g. \"+25%\", or a signed amount in USD, e.g. \"+500\".",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "when": {
+                    "description": "When is the condition a car must meet, e.g. \"brand in [bmw, porsche] and enginesize \u003e 180\".\nRules without a condition apply to every car.",
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "OIDC access token as \"Bearer \u003ctoken\u003e\".",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
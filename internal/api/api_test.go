@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"car-price-prediction/internal/domain"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -114,4 +116,167 @@ func TestPredictHandler_BadRequest_MissingFields(t *testing.T) {
 	resp, err := http.Post(server.URL+"/predict", "application/json", bytes.NewBuffer(body))
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
-}
\ No newline at end of file
+}
+
+// mockExplainingService is a mock prediction service that also supports explanations.
+type mockExplainingService struct {
+	mockPredictionService
+}
+
+// Explain implements the domain.Explainer interface for testing.
+func (m *mockExplainingService) Explain(input domain.UserInput) (*domain.Explanation, error) {
+	return &domain.Explanation{
+		PredictedPrice: 15000.0,
+		BaselinePrice:  10000.0,
+		Contributions:  []domain.FeatureContribution{{Feature: "horsepower", Contribution: 5000.0}},
+	}, nil
+}
+
+// validInput returns a complete, valid request body.
+func validInput() domain.UserInput {
+	return domain.UserInput{
+		Symboling:        3,
+		Wheelbase:        88.6,
+		Carlength:        168.8,
+		Carwidth:         64.1,
+		Carheight:        48.8,
+		Curbweight:       2548,
+		Enginesize:       130,
+		Boreratio:        3.47,
+		Stroke:           2.68,
+		Compressionratio: 9.0,
+		Horsepower:       111,
+		Peakrpm:          5000,
+		Citympg:          21,
+		Highwaympg:       27,
+		Fueltype:         "gas",
+		Aspiration:       "std",
+		Doornumber:       "two",
+		Carbody:          "convertible",
+		Drivewheel:       "rwd",
+		Enginelocation:   "front",
+		Enginetype:       "dohc",
+		Cylindernumber:   "four",
+		Fuelsystem:       "mpfi",
+		Brand:            "alfa-romero",
+	}
+}
+
+func TestExplainHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(&mockExplainingService{}))
+	defer server.Close()
+
+	body, _ := json.Marshal(validInput())
+
+	resp, err := http.Post(server.URL+"/explain", "application/json", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var explanation domain.Explanation
+	err = json.NewDecoder(resp.Body).Decode(&explanation)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(10000.0), explanation.BaselinePrice)
+	assert.Len(t, explanation.Contributions, 1)
+}
+
+func TestExplainHandler_NotImplemented(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	body, _ := json.Marshal(validInput())
+
+	resp, err := http.Post(server.URL+"/explain", "application/json", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
+
+// failingPredictionService is a mock prediction service that always returns the given error.
+type failingPredictionService struct {
+	err error
+}
+
+// Predict implements the prediction service interface for testing.
+func (m *failingPredictionService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	return nil, m.err
+}
+
+// slowPredictionService is a mock prediction service that takes longer than the test timeout.
+type slowPredictionService struct{}
+
+// Predict implements the prediction service interface for testing.
+func (m *slowPredictionService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	time.Sleep(200 * time.Millisecond)
+	return &domain.PredictionResult{PredictedPrice: 15000.0}, nil
+}
+
+// postProblem sends body to /predict on a router backed by service and decodes the problem response.
+func postProblem(t *testing.T, service domain.PredictionService, body []byte, opts ...Option) (*http.Response, domain.Problem) {
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(service, opts...))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/predict", "application/json", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+
+	var problem domain.Problem
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	return resp, problem
+}
+
+func TestPredictHandler_ProblemDetails_MissingFields(t *testing.T) {
+	resp, problem := postProblem(t, &mockPredictionService{}, []byte(`{"symboling": 3}`))
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, domain.CodeValidationFailed, problem.Code)
+	assert.Equal(t, "urn:car-price-prediction:problem:v1:validation-failed", problem.Type)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "/predict", problem.Instance)
+	assert.Contains(t, problem.Violations, domain.Violation{Field: "wheelbase", Code: domain.CodeValidationFailed, Message: "is required"})
+	assert.NotContains(t, problem.Detail, "Key: ")
+}
+
+func TestPredictHandler_ProblemDetails_UnknownCategory(t *testing.T) {
+	service := &failingPredictionService{err: domain.NewValidationError([]domain.Violation{
+		{Field: "brand", Code: domain.CodeUnknownCategory, Message: "must be one of: audi, bmw"},
+	})}
+	body, _ := json.Marshal(validInput())
+
+	resp, problem := postProblem(t, service, body)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, domain.CodeUnknownCategory, problem.Code)
+	assert.Len(t, problem.Violations, 1)
+}
+
+func TestPredictHandler_ProblemDetails_HidesInternalErrors(t *testing.T) {
+	service := &failingPredictionService{err: errors.New("onnxruntime: invalid input name float_input")}
+	body, _ := json.Marshal(validInput())
+
+	resp, problem := postProblem(t, service, body)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, domain.CodeInferenceFailed, problem.Code)
+	assert.NotContains(t, problem.Detail, "onnxruntime")
+}
+
+func TestPredictHandler_ProblemDetails_ModelUnavailable(t *testing.T) {
+	service := &failingPredictionService{err: domain.NewError(domain.CodeModelUnavailable, "The prediction model is not available.", errors.New("file not found"))}
+	body, _ := json.Marshal(validInput())
+
+	resp, problem := postProblem(t, service, body)
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, domain.CodeModelUnavailable, problem.Code)
+	assert.NotContains(t, problem.Detail, "file not found")
+}
+
+func TestPredictHandler_ProblemDetails_Timeout(t *testing.T) {
+	body, _ := json.Marshal(validInput())
+
+	resp, problem := postProblem(t, &slowPredictionService{}, body, WithPredictionTimeout(10*time.Millisecond))
+
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	assert.Equal(t, domain.CodeTimeout, problem.Code)
+}
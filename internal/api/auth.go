@@ -0,0 +1,142 @@
+package api
+
+import (
+	"car-price-prediction/internal/auth"
+	"car-price-prediction/internal/domain"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyHeader is the request header carrying the API key.
+const apiKeyHeader = "X-API-Key"
+
+// periodPattern matches a billing period such as "2026-10".
+var periodPattern = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])$`)
+
+// bearerPrefix starts an Authorization header carrying a bearer token.
+const bearerPrefix = "bearer "
+
+// Authenticate returns a middleware that authenticates requests by their
+// Authorization bearer token or X-API-Key header and applies the key's rate limit.
+// The authenticated principal is stored in the request context for authorization
+// and quota accounting.
+func Authenticate(authenticator *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		creds := auth.Credentials{APIKey: c.GetHeader(apiKeyHeader)}
+		if header := c.GetHeader("Authorization"); len(header) > len(bearerPrefix) && strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+			creds.BearerToken = strings.TrimSpace(header[len(bearerPrefix):])
+		}
+
+		principal, err := authenticator.Authenticate(creds, auditRequest(c))
+		if err != nil {
+			var derr *domain.Error
+			if errors.As(err, &derr) {
+				switch {
+				case derr.Code == domain.CodeRateLimited:
+					c.Header("Retry-After", "1")
+				case derr.Code == domain.CodeUnauthorized && authenticator.JWTEnabled():
+					c.Header("WWW-Authenticate", `Bearer realm="car-price-prediction"`)
+				}
+			}
+			writeError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// RequireScope returns a middleware that rejects authenticated callers that were
+// not granted scope. Requests without a principal pass, as authentication is disabled.
+func RequireScope(authenticator *auth.Authenticator, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.FromContext(c.Request.Context())
+		if principal == nil {
+			c.Next()
+			return
+		}
+		if err := authenticator.Authorize(principal, scope, auditRequest(c)); err != nil {
+			if authenticator.JWTEnabled() {
+				c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer realm="car-price-prediction", error="insufficient_scope", scope=%q`, scope))
+			}
+			writeError(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// auditRequest describes the request for the audit log.
+func auditRequest(c *gin.Context) auth.Request {
+	return auth.Request{Method: c.Request.Method, Resource: c.Request.URL.Path, RemoteAddr: c.ClientIP()}
+}
+
+// reserveQuota charges n predictions against the caller's monthly quota. It writes
+// a problem response and returns false if the quota is exhausted.
+func reserveQuota(c *gin.Context, n int) bool {
+	if err := auth.FromContext(c.Request.Context()).Reserve(n); err != nil {
+		writeError(c, err)
+		return false
+	}
+	return true
+}
+
+// refundQuota returns n reserved predictions that were not delivered.
+func refundQuota(c *gin.Context, n int) {
+	auth.FromContext(c.Request.Context()).Refund(n)
+}
+
+// UsageResponse represents the JSON response body for the usage API.
+type UsageResponse struct {
+	Period string             `json:"period"`
+	Keys   []auth.UsageReport `json:"keys"`
+}
+
+// UsageHandler godoc
+// @Summary Report API key usage
+// @Description Report the number of predictions made in a billing period (calendar month, UTC) against the monthly quota.
+// @Description Regular keys see their own usage; admin keys and bearer tokens with the models:admin scope see the usage of every key.
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param   period    query  string false "Billing period as YYYY-MM (defaults to the current month)"
+// @Success 200 {object} api.UsageResponse
+// @Failure 400 {object} domain.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 429 {object} domain.Problem "RATE_LIMITED"
+// @Router /v1/usage [get]
+func UsageHandler(manager *auth.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", manager.CurrentPeriod())
+		if !periodPattern.MatchString(period) {
+			writeError(c, domain.NewValidationError([]domain.Violation{{
+				Field:   "period",
+				Code:    domain.CodeValidationFailed,
+				Message: "must have the form YYYY-MM",
+			}}))
+			return
+		}
+
+		var keyIDs []string
+		principal := auth.FromContext(c.Request.Context())
+		if !principal.Admin {
+			keyIDs = []string{principal.Subject}
+		}
+
+		reports, err := manager.Usage(period, keyIDs...)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, UsageResponse{Period: period, Keys: reports})
+	}
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"bytes"
+	"car-price-prediction/internal/auth"
+	"car-price-prediction/internal/auth/authtest"
+	"car-price-prediction/internal/domain"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAuthTestServer starts a server with API keys enabled and issues one key with
+// the given quota and one admin key.
+func setupAuthTestServer(t *testing.T, quota int64) (server *httptest.Server, token, adminToken string) {
+	manager, err := auth.Open(t.TempDir(), time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(func() { manager.Close() })
+
+	token, _, err = manager.Keys().Issue(auth.KeyOptions{Name: "Dealer A", MonthlyQuota: quota})
+	require.NoError(t, err)
+	adminToken, _, err = manager.Keys().Issue(auth.KeyOptions{Name: "Ops", Admin: true})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	server = httptest.NewServer(SetupRouter(&mockPredictionService{}, WithAuth(&auth.Authenticator{APIKeys: manager, Audit: auth.NewAuditLog(io.Discard)})))
+	t.Cleanup(server.Close)
+	return server, token, adminToken
+}
+
+// doRequest sends a request with an optional API key.
+func doRequest(t *testing.T, method, url, token string, body any) *http.Response {
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(apiKeyHeader, token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestAPIKeyAuth_RequiresKey(t *testing.T) {
+	server, token, _ := setupAuthTestServer(t, 0)
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict", "", validInput())
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	var problem domain.Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, domain.CodeUnauthorized, problem.Code)
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/predict", token, validInput())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestAPIKeyAuth_BatchRowsCountAgainstQuota(t *testing.T) {
+	server, token, _ := setupAuthTestServer(t, 3)
+
+	invalid := validInput()
+	invalid.Wheelbase = 0
+	batch := domain.BatchInput{Inputs: []domain.UserInput{validInput(), invalid}}
+
+	// One row succeeds and is charged, the invalid row is refunded
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict/batch", token, batch)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.BatchResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Len(t, result.Results, 2)
+	assert.Equal(t, float32(15000.0), result.Results[0].Result.PredictedPrice)
+	require.NotNil(t, result.Results[1].Error)
+	assert.Equal(t, domain.CodeValidationFailed, result.Results[1].Error.Code)
+
+	// Two more rows fit, a third does not
+	resp = doRequest(t, http.MethodPost, server.URL+"/predict/batch", token, batch)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, server.URL+"/predict/batch", token, batch)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	var problem domain.Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, domain.CodeQuotaExceeded, problem.Code)
+
+	// The remaining prediction can still be used for a single request
+	resp = doRequest(t, http.MethodPost, server.URL+"/predict", token, validInput())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestUsageHandler(t *testing.T) {
+	server, token, adminToken := setupAuthTestServer(t, 10)
+
+	doRequest(t, http.MethodPost, server.URL+"/predict", token, validInput())
+
+	// A regular key sees only its own usage
+	resp := doRequest(t, http.MethodGet, server.URL+"/v1/usage", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var usage UsageResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&usage))
+	require.Len(t, usage.Keys, 1)
+	assert.Equal(t, "Dealer A", usage.Keys[0].Name)
+	assert.Equal(t, int64(1), usage.Keys[0].Used)
+	assert.Equal(t, int64(9), *usage.Keys[0].Remaining)
+
+	// An admin key sees every key
+	resp = doRequest(t, http.MethodGet, server.URL+"/v1/usage", adminToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&usage))
+	assert.Len(t, usage.Keys, 2)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/v1/usage?period=2026-13", token, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestPredictBatchHandler_WithoutAuth(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict/batch", "", domain.BatchInput{})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/predict/batch", "", domain.BatchInput{Inputs: []domain.UserInput{validInput()}})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// setupJWTTestServer starts a server accepting bearer tokens from a stub issuer and
+// returns the issuer and the audit log.
+func setupJWTTestServer(t *testing.T) (*httptest.Server, *authtest.Issuer, *bytes.Buffer) {
+	iss := authtest.NewIssuer(t)
+	keys, err := auth.OpenJWKS(iss.JWKSPath)
+	require.NoError(t, err)
+	var audit bytes.Buffer
+	authenticator := &auth.Authenticator{
+		JWT:   auth.NewJWTVerifier(keys, auth.JWTOptions{Issuer: iss.URL, Audience: iss.Audience}),
+		Audit: auth.NewAuditLog(&audit),
+	}
+
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(&mockPredictionService{}, WithAuth(authenticator)))
+	t.Cleanup(server.Close)
+	return server, iss, &audit
+}
+
+// doBearerRequest sends a request with a bearer token.
+func doBearerRequest(t *testing.T, method, url, token string, body any) *http.Response {
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestJWTAuth_ScopesMapToRoutes(t *testing.T) {
+	server, iss, audit := setupJWTTestServer(t)
+	predictOnly := iss.Token(t, "alice", auth.ScopePredictRead)
+
+	resp := doBearerRequest(t, http.MethodPost, server.URL+"/predict", predictOnly, validInput())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = doBearerRequest(t, http.MethodPost, server.URL+"/predict/batch", predictOnly, domain.BatchInput{Inputs: []domain.UserInput{validInput()}})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// /explain needs explain:read
+	resp = doBearerRequest(t, http.MethodPost, server.URL+"/explain", predictOnly, validInput())
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, problemContentType, resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), `error="insufficient_scope", scope="explain:read"`)
+	var problem domain.Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, domain.CodeForbidden, problem.Code)
+
+	resp = doBearerRequest(t, http.MethodPost, server.URL+"/predict", iss.Token(t, "bob", auth.ScopeExplainRead), validInput())
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// Both rejections are audit-logged with the subject
+	var events []auth.AuditEvent
+	dec := json.NewDecoder(audit)
+	for dec.More() {
+		var e auth.AuditEvent
+		require.NoError(t, dec.Decode(&e))
+		events = append(events, e)
+	}
+	require.Len(t, events, 2)
+	assert.Equal(t, "alice", events[0].Subject)
+	assert.Equal(t, "/explain", events[0].Resource)
+	assert.Equal(t, "bob", events[1].Subject)
+	assert.Equal(t, "/predict", events[1].Resource)
+}
+
+func TestJWTAuth_RejectsInvalidTokens(t *testing.T) {
+	server, _, audit := setupJWTTestServer(t)
+
+	resp := doBearerRequest(t, http.MethodPost, server.URL+"/predict", "not-a-jwt", validInput())
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, `Bearer realm="car-price-prediction"`, resp.Header.Get("WWW-Authenticate"))
+	var problem domain.Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, domain.CodeUnauthorized, problem.Code)
+	assert.NotContains(t, problem.Detail, "segment")
+
+	// API keys are not accepted when only bearer tokens are configured
+	resp = doRequest(t, http.MethodPost, server.URL+"/predict", "cpp_0123456789ab_secret", validInput())
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	assert.Equal(t, 2, strings.Count(audit.String(), `"event":"authentication_failed"`))
+}
+
+func TestAuth_APIKeysAndJWTTogether(t *testing.T) {
+	manager, err := auth.Open(t.TempDir(), time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(func() { manager.Close() })
+	token, _, err := manager.Keys().Issue(auth.KeyOptions{Name: "Dealer A"})
+	require.NoError(t, err)
+	iss := authtest.NewIssuer(t)
+	keys, err := auth.OpenJWKS(iss.JWKSPath)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(&mockPredictionService{}, WithAuth(&auth.Authenticator{
+		APIKeys: manager,
+		JWT:     auth.NewJWTVerifier(keys, auth.JWTOptions{}),
+		Audit:   auth.NewAuditLog(io.Discard),
+	})))
+	defer server.Close()
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict", token, validInput())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = doBearerRequest(t, http.MethodPost, server.URL+"/predict", iss.Token(t, "alice", auth.ScopePredictRead), validInput())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"car-price-prediction/internal/catalog"
+	"car-price-prediction/internal/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCatalogLimit caps the number of results a catalog search may ask for.
+const maxCatalogLimit = 100
+
+// Catalog returns a middleware that makes cat available to the prediction
+// handlers, which complete inputs naming a catalog_id from it.
+func Catalog(cat *catalog.Catalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(catalog.NewContext(c.Request.Context(), cat))
+		c.Next()
+	}
+}
+
+// CatalogSearchResponse represents the JSON response body for the catalog search API.
+type CatalogSearchResponse struct {
+	Results []*catalog.Entry `json:"results"`
+}
+
+// CatalogHandler godoc
+// @Summary Search the vehicle catalog
+// @Description Find car models by name, e.g. `audi 100ls`, and return their full specs. Every word of the query must start
+// @Description a word of the model name or be its brand; exact name matches come first. A prediction request can pass
+// @Description the `catalog_id` of a result and only the fields it wants to change. Bearer tokens need the predict:read scope.
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param   q      query   string  true   "Model name to search for"
+// @Param   limit  query   int     false  "Maximum number of results (1-100, default 10)"
+// @Success 200 {object} CatalogSearchResponse
+// @Failure 400 {object} domain.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope predict:read"
+// @Router /v1/catalog [get]
+func CatalogHandler(cat *catalog.Catalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var violations []domain.Violation
+		query := c.Query("q")
+		if query == "" {
+			violations = append(violations, domain.Violation{Field: "q", Code: domain.CodeValidationFailed, Message: "is required"})
+		}
+		limit := catalog.DefaultLimit
+		if s, ok := c.GetQuery("limit"); ok {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 || n > maxCatalogLimit {
+				violations = append(violations, domain.Violation{Field: "limit", Code: domain.CodeValidationFailed, Message: "must be between 1 and " + strconv.Itoa(maxCatalogLimit)})
+			}
+			limit = n
+		}
+		if len(violations) > 0 {
+			writeError(c, domain.NewValidationError(violations))
+			return
+		}
+
+		c.JSON(http.StatusOK, CatalogSearchResponse{Results: cat.Search(query, limit)})
+	}
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"car-price-prediction/internal/catalog"
+	"car-price-prediction/internal/domain"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPredictionService is a mock prediction service that remembers its inputs.
+type recordingPredictionService struct {
+	mockExplainingService
+	mu     sync.Mutex
+	inputs []domain.UserInput
+}
+
+// Predict implements the prediction service interface for testing.
+func (m *recordingPredictionService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inputs = append(m.inputs, input)
+	return m.mockExplainingService.Predict(input)
+}
+
+// testCatalog returns a catalog with two entries, the first matching validInput.
+func testCatalog(t *testing.T) *catalog.Catalog {
+	sedan := validInput()
+	sedan.Carbody = "sedan"
+	sedan.Horsepower = 154
+	cat, err := catalog.New([]catalog.Entry{
+		{Name: "alfa-romero giulia", Spec: validInput()},
+		{Name: "alfa-romero Quadrifoglio", Spec: sedan},
+	})
+	require.NoError(t, err)
+	return cat
+}
+
+func setupCatalogTestServer(t *testing.T, service domain.PredictionService) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(service, WithCatalog(testCatalog(t))))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCatalogHandler_Search(t *testing.T) {
+	server := setupCatalogTestServer(t, &mockPredictionService{})
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/v1/catalog?q=alfa%20giulia", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var body struct {
+		Results []struct {
+			ID    string           `json:"id"`
+			Name  string           `json:"name"`
+			Brand string           `json:"brand"`
+			Spec  domain.UserInput `json:"spec"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Results, 1)
+	assert.Equal(t, "alfa-romero-giulia", body.Results[0].ID)
+	assert.Equal(t, "alfa-romero", body.Results[0].Brand)
+	assert.Equal(t, validInput(), body.Results[0].Spec)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/v1/catalog?q=alfa&limit=1", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Len(t, body.Results, 1)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/v1/catalog?q=tesla", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Empty(t, body.Results)
+}
+
+func TestCatalogHandler_InvalidQuery(t *testing.T) {
+	server := setupCatalogTestServer(t, &mockPredictionService{})
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/v1/catalog?limit=0", "", nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	var problem domain.Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, domain.CodeValidationFailed, problem.Code)
+	assert.Equal(t, []domain.Violation{
+		{Field: "q", Code: domain.CodeValidationFailed, Message: "is required"},
+		{Field: "limit", Code: domain.CodeValidationFailed, Message: "must be between 1 and 100"},
+	}, problem.Violations)
+}
+
+func TestCatalogHandler_Disabled(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/v1/catalog?q=audi", "", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestPredictHandler_CatalogOverrides(t *testing.T) {
+	service := &recordingPredictionService{}
+	server := setupCatalogTestServer(t, service)
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict", "", map[string]any{
+		"catalog_id": "alfa-romero-quadrifoglio",
+		"horsepower": 200,
+		"carbody":    "hatchback",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.PredictionResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, "alfa-romero-quadrifoglio", result.CatalogID)
+	assert.Len(t, result.Defaulted, 22)
+	assert.NotContains(t, result.Defaulted, "horsepower")
+	assert.NotContains(t, result.Defaulted, "carbody")
+
+	want := validInput()
+	want.Horsepower = 200
+	want.Carbody = "hatchback"
+	assert.Equal(t, []domain.UserInput{want}, service.inputs)
+}
+
+func TestPredictHandler_CatalogErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		body    string
+		message string
+	}{
+		{"unknown entry", []Option{WithCatalog(testCatalog(t))}, `{"catalog_id": "tesla-model-3"}`, "no catalog entry has this ID"},
+		{"catalog disabled", nil, `{"catalog_id": "alfa-romero-giulia"}`, "the vehicle catalog is not enabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, problem := postProblem(t, &mockPredictionService{}, []byte(tt.body), tt.opts...)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, []domain.Violation{{Field: "catalog_id", Code: domain.CodeValidationFailed, Message: tt.message}}, problem.Violations)
+		})
+	}
+
+	// Overrides are validated like any other input
+	resp, problem := postProblem(t, &mockPredictionService{}, []byte(`{"catalog_id": "alfa-romero-giulia", "horsepower": "many"}`), WithCatalog(testCatalog(t)))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, []domain.Violation{{Field: "horsepower", Code: domain.CodeValidationFailed, Message: "must be of type int"}}, problem.Violations)
+}
+
+func TestPredictBatchHandler_Catalog(t *testing.T) {
+	service := &recordingPredictionService{}
+	server := setupCatalogTestServer(t, service)
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict/batch", "", map[string]any{
+		"inputs": []any{
+			map[string]any{"catalog_id": "alfa-romero-giulia", "symboling": 1},
+			validInput(),
+			map[string]any{"catalog_id": "tesla-model-3"},
+		},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var batch domain.BatchResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+	require.Len(t, batch.Results, 3)
+
+	require.NotNil(t, batch.Results[0].Result)
+	assert.Equal(t, "alfa-romero-giulia", batch.Results[0].Result.CatalogID)
+	assert.Len(t, batch.Results[0].Result.Defaulted, 23)
+	require.NotNil(t, batch.Results[1].Result)
+	assert.Empty(t, batch.Results[1].Result.CatalogID)
+	assert.Nil(t, batch.Results[1].Result.Defaulted)
+	require.NotNil(t, batch.Results[2].Error)
+	assert.Equal(t, "catalog_id", batch.Results[2].Error.Violations[0].Field)
+
+	want := validInput()
+	want.Symboling = 1
+	assert.Equal(t, []domain.UserInput{want, validInput()}, service.inputs)
+}
+
+func TestExplainHandler_Catalog(t *testing.T) {
+	server := setupCatalogTestServer(t, &recordingPredictionService{})
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/explain", "", map[string]any{"catalog_id": "alfa-romero-giulia"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var explanation domain.Explanation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&explanation))
+	assert.Equal(t, "alfa-romero-giulia", explanation.CatalogID)
+	assert.Len(t, explanation.Defaulted, 24)
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"car-price-prediction/internal/depreciation"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/drift"
+	"car-price-prediction/internal/validation"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits of the age and mileage of a depreciation request.
+const (
+	maxAgeYears = 30
+	maxMileage  = 1_000_000
+)
+
+// DepreciationHandler godoc
+// @Summary Project the depreciation of a used car
+// @Description Predict the price of a car new and project its value at `age_years` and `mileage` (in miles), and at the ages 0 to 10
+// @Description driven as much per year as so far. The depreciation curve is chosen by brand and body type and returned with the result.
+// @Description If `mileage` is left out, the mileage the curve expects for the age is assumed. The body may use `catalog_id`, `impute`
+// @Description and units, and the X-Model header may select a model, as in /predict. Errors use the same problem details format and codes as /predict. The price new
+// @Description is recorded in the prediction log like a /predict result. Counts as one prediction against the quota; bearer tokens need the predict:read scope.
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param   input     body    domain.DepreciationInput   true        "Car Features, Age and Mileage"
+// @Param   X-Model   header  string                     false       "Registered model to predict with, see /v1/models"
+// @Success 200 {object} domain.DepreciationResult
+// @Failure 400 {object} domain.Problem "VALIDATION_FAILED, UNKNOWN_CATEGORY or OUT_OF_RANGE"
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope predict:read"
+// @Failure 429 {object} domain.Problem "RATE_LIMITED or QUOTA_EXCEEDED"
+// @Failure 500 {object} domain.Problem "INFERENCE_FAILED"
+// @Failure 503 {object} domain.Problem "MODEL_UNAVAILABLE"
+// @Failure 504 {object} domain.Problem "TIMEOUT"
+// @Router /v1/predict/depreciation [post]
+func DepreciationHandler(service domain.PredictionService, curves *depreciation.Curves, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Read the model to predict with
+		selected, err := readModel(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		service := selected.service(service)
+
+		// Read the age and mileage, then bind the rest of the body like a prediction request
+		data, err := readBody(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		var usage struct {
+			AgeYears *float64 `json:"age_years"`
+			Mileage  *float64 `json:"mileage"`
+		}
+		if err := json.Unmarshal(data, &usage); err != nil {
+			writeError(c, validation.Translate(err))
+			return
+		}
+		if err := checkUsage(usage.AgeYears, usage.Mileage); err != nil {
+			writeError(c, err)
+			return
+		}
+		input, res, err := bindInput(c.Request.Context(), data)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		// Track the input distribution for drift monitoring
+		if res.observed() {
+			drift.FromContext(c.Request.Context()).Observe(input)
+		}
+
+		// Charge the prediction against the caller's quota
+		if !reserveQuota(c, 1) {
+			return
+		}
+
+		// Predict the price new
+		start := time.Now()
+		prediction, err := withTimeout(c, timeout, func() (*domain.PredictionResult, error) {
+			result, err := service.Predict(input)
+			if err != nil {
+				return nil, err
+			}
+			selected.apply(result)
+			return result, res.annotate(service, input, result)
+		})
+		if err == nil {
+			// A prediction that cannot be recorded is not handed out
+			err = recordPrediction(c, selected.manager(), input, prediction, time.Since(start))
+		}
+		if err != nil {
+			refundQuota(c, 1)
+			writeError(c, err)
+			return
+		}
+
+		// Project its value over the curve of the brand and body type
+		curve := curves.Curve(input)
+		result := &domain.DepreciationResult{Prediction: prediction, AgeYears: *usage.AgeYears, Curve: curve}
+		if usage.Mileage != nil {
+			result.Mileage = *usage.Mileage
+		} else {
+			result.Mileage = math.Round(curve.MileagePerYear * result.AgeYears)
+			result.MileageAssumed = true
+		}
+		result.Value, result.Retention, result.Schedule = depreciation.Project(curve, prediction.PredictedPrice, result.AgeYears, result.Mileage)
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// checkUsage validates the age and mileage of a depreciation request.
+func checkUsage(age, mileage *float64) error {
+	var violations []domain.Violation
+	if age == nil {
+		violations = append(violations, domain.Violation{Field: "age_years", Code: domain.CodeValidationFailed, Message: "is required"})
+	} else if *age < 0 || *age > maxAgeYears {
+		violations = append(violations, domain.Violation{Field: "age_years", Code: domain.CodeOutOfRange, Message: "must be between 0 and " + strconv.Itoa(maxAgeYears)})
+	}
+	if mileage != nil && (*mileage < 0 || *mileage > maxMileage) {
+		violations = append(violations, domain.Violation{Field: "mileage", Code: domain.CodeOutOfRange, Message: "must be between 0 and " + strconv.Itoa(maxMileage)})
+	}
+	if len(violations) > 0 {
+		return domain.NewValidationError(violations)
+	}
+	return nil
+}
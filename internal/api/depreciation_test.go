@@ -0,0 +1,73 @@
+package api
+
+import (
+	"car-price-prediction/internal/depreciation"
+	"car-price-prediction/internal/domain"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepreciationHandler(t *testing.T) {
+	server := setupPricingTestServer(t, WithDepreciation(depreciation.Default()))
+
+	input := validInput()
+	input.Brand = "bmw"
+	mileage := 45000.0
+	resp := doRequest(t, http.MethodPost, server.URL+"/v1/predict/depreciation", "", domain.DepreciationInput{
+		UserInput: input,
+		AgeYears:  3,
+		Mileage:   &mileage,
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.DepreciationResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, float32(15000), result.Prediction.PredictedPrice)
+	assert.Equal(t, "brand=bmw", result.Curve.Basis)
+	assert.Equal(t, 45000.0, result.Mileage)
+	assert.False(t, result.MileageAssumed)
+	assert.Equal(t, float32(7899.05), result.Value)
+	assert.Equal(t, 0.5266, result.Retention)
+	require.Len(t, result.Schedule, depreciation.ScheduleYears+1)
+	assert.Equal(t, domain.DepreciationPoint{Year: 0, Mileage: 0, Value: 15000, Retention: 1}, result.Schedule[0])
+	assert.Equal(t, result.Value, result.Schedule[3].Value)
+}
+
+func TestDepreciationHandler_AssumedMileage(t *testing.T) {
+	// Without a mileage the curve's expected mileage is assumed
+	server := setupPricingTestServer(t, WithDepreciation(depreciation.Default()))
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/v1/predict/depreciation", "", domain.DepreciationInput{UserInput: validInput(), AgeYears: 2.5})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.DepreciationResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.True(t, result.MileageAssumed)
+	assert.Equal(t, 30000.0, result.Mileage)
+	assert.Equal(t, 12000.0, result.Schedule[1].Mileage)
+}
+
+func TestDepreciationHandler_Invalid(t *testing.T) {
+	server := setupPricingTestServer(t, WithDepreciation(depreciation.Default()))
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/v1/predict/depreciation", "", map[string]any{"mileage": -5})
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	var problem domain.Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, []domain.Violation{
+		{Field: "age_years", Code: domain.CodeValidationFailed, Message: "is required"},
+		{Field: "mileage", Code: domain.CodeOutOfRange, Message: "must be between 0 and 1000000"},
+	}, problem.Violations)
+
+	// The car is validated like a prediction input
+	resp = doRequest(t, http.MethodPost, server.URL+"/v1/predict/depreciation", "", map[string]any{"age_years": 3, "brand": "bmw"})
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Contains(t, problem.Violations, domain.Violation{Field: "wheelbase", Code: domain.CodeValidationFailed, Message: "is required"})
+
+	// Without curves the endpoint does not exist
+	resp = doRequest(t, http.MethodPost, setupPricingTestServer(t).URL+"/v1/predict/depreciation", "", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"car-price-prediction/internal/drift"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DriftMonitor returns a middleware that makes monitor available to the prediction
+// handlers, which observe every input they bind.
+func DriftMonitor(monitor *drift.Monitor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(drift.NewContext(c.Request.Context(), monitor))
+		c.Next()
+	}
+}
+
+// DriftHandler godoc
+// @Summary Report input drift
+// @Description Compare the distribution of recent prediction inputs with the training data over sliding windows
+// @Description (1h, 24h and 7d). Numeric fields are compared with PSI and the Kolmogorov-Smirnov test, categorical
+// @Description fields with PSI and the chi-square test. A field drifts when its PSI reaches 0.25 and warns from 0.1.
+// @Description Windows with too few inputs report insufficient_data. Bearer tokens need the models:admin scope.
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} drift.Report
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope models:admin"
+// @Router /v1/monitoring/drift [get]
+func DriftHandler(monitor *drift.Monitor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, monitor.Report())
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/drift"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftMonitor_ObservesPredictionInputs(t *testing.T) {
+	profile, err := drift.NewProfile([]domain.UserInput{validInput()}, drift.DefaultBins)
+	require.NoError(t, err)
+	monitor := drift.NewMonitor(profile, drift.Config{MinSamples: 3})
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(&mockPredictionService{}, WithDriftMonitor(monitor)))
+	defer server.Close()
+
+	input := validInput()
+	input.Brand = "Tesla"
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict", "", input)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, server.URL+"/predict/batch", "", domain.BatchInput{Inputs: []domain.UserInput{input, validInput()}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/v1/monitoring/drift", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var report drift.Report
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	hour := report.Windows[0]
+	assert.Equal(t, 3, hour.Samples)
+	assert.Equal(t, drift.StatusDrift, hour.Status)
+	assert.Equal(t, "brand", hour.Features[0].Feature)
+	assert.Equal(t, "tesla", hour.Features[0].Shifts[0].Value)
+}
+
+func TestDriftMonitor_Disabled(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/v1/monitoring/drift", "", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
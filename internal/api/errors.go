@@ -0,0 +1,79 @@
+package api
+
+import (
+	"car-price-prediction/internal/domain"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// problemContentType is the media type for RFC 7807 problem details.
+const problemContentType = "application/problem+json"
+
+// problemTypePrefix prefixes every problem type URI. The version segment changes only
+// if the meaning of existing codes changes.
+const problemTypePrefix = "urn:car-price-prediction:problem:v1:"
+
+// problemKind holds the HTTP status and title for an error code.
+type problemKind struct {
+	status int
+	title  string
+}
+
+// problemKinds maps each domain error code to its HTTP representation.
+var problemKinds = map[domain.ErrorCode]problemKind{
+	domain.CodeValidationFailed:   {http.StatusBadRequest, "Validation failed"},
+	domain.CodeUnknownCategory:    {http.StatusBadRequest, "Unknown category"},
+	domain.CodeOutOfRange:         {http.StatusBadRequest, "Value out of range"},
+	domain.CodeModelUnavailable:   {http.StatusServiceUnavailable, "Model unavailable"},
+	domain.CodeInferenceFailed:    {http.StatusInternalServerError, "Inference failed"},
+	domain.CodeTimeout:            {http.StatusGatewayTimeout, "Prediction timed out"},
+	domain.CodeUnauthorized:       {http.StatusUnauthorized, "Unauthorized"},
+	domain.CodeForbidden:          {http.StatusForbidden, "Forbidden"},
+	domain.CodeRateLimited:        {http.StatusTooManyRequests, "Rate limit exceeded"},
+	domain.CodeQuotaExceeded:      {http.StatusTooManyRequests, "Quota exceeded"},
+	domain.CodeNotFound:           {http.StatusNotFound, "Not found"},
+	domain.CodeStorageUnavailable: {http.StatusServiceUnavailable, "Storage unavailable"},
+}
+
+// writeError writes err as a problem details response.
+func writeError(c *gin.Context, err error) {
+	derr := asDomainError(err)
+	writeProblem(c, problemKinds[derr.Code].status, derr)
+}
+
+// writeProblem writes derr as a problem details response with the given HTTP status.
+func writeProblem(c *gin.Context, status int, derr *domain.Error) {
+	c.Header("Content-Type", problemContentType)
+	c.JSON(status, newProblem(c, status, derr))
+}
+
+// asDomainError returns err as a *domain.Error. Other errors are treated as inference
+// failures so that internal messages never reach clients.
+func asDomainError(err error) *domain.Error {
+	var derr *domain.Error
+	if !errors.As(err, &derr) {
+		derr = domain.NewError(domain.CodeInferenceFailed, "The model could not produce a prediction.", err)
+	}
+	return derr
+}
+
+// newProblem builds the problem details for derr and logs server-side failures.
+func newProblem(c *gin.Context, status int, derr *domain.Error) domain.Problem {
+	if status >= http.StatusInternalServerError {
+		log.Printf("Request %s %s failed: %v", c.Request.Method, c.Request.URL.Path, derr) // Log the actual error
+	}
+
+	return domain.Problem{
+		Type:       problemTypePrefix + strings.ToLower(strings.ReplaceAll(string(derr.Code), "_", "-")),
+		Title:      problemKinds[derr.Code].title,
+		Status:     status,
+		Detail:     derr.Message,
+		Instance:   c.Request.URL.Path,
+		Code:       derr.Code,
+		Violations: derr.Violations,
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"car-price-prediction/internal/auth"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/feedback"
+	"car-price-prediction/internal/prediction"
+	"car-price-prediction/internal/predlog"
+	"car-price-prediction/internal/validation"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeedbackHandler godoc
+// @Summary Report an actual sale price
+// @Description Attach the price a car actually sold for to a recorded prediction (`prediction_id`, requires the
+// @Description prediction log) or to a car `input`, which is predicted with the current model or the one the X-Model header
+// @Description selects. Exactly one of them must be set. Feedback on a prediction that already has feedback replaces it.
+// @Description Feedback on an input counts as one prediction against the caller's quota. Bearer tokens need the feedback:write scope.
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param   feedback  body    domain.FeedbackInput  true  "Sale price"
+// @Param   X-Model   header  string                false "Registered model to predict an input with, see /v1/models"
+// @Success 200 {object} feedback.Observation
+// @Failure 400 {object} domain.Problem "VALIDATION_FAILED, UNKNOWN_CATEGORY or OUT_OF_RANGE"
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope feedback:write"
+// @Failure 404 {object} domain.Problem "NOT_FOUND: unknown prediction_id"
+// @Failure 429 {object} domain.Problem "RATE_LIMITED or QUOTA_EXCEEDED"
+// @Failure 500 {object} domain.Problem "INFERENCE_FAILED"
+// @Failure 503 {object} domain.Problem "MODEL_UNAVAILABLE or STORAGE_UNAVAILABLE"
+// @Failure 504 {object} domain.Problem "TIMEOUT"
+// @Router /v1/feedback [post]
+func FeedbackHandler(tracker *feedback.Tracker, service domain.PredictionService, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var in domain.FeedbackInput
+		if err := c.ShouldBindJSON(&in); err != nil {
+			writeError(c, validation.Translate(err))
+			return
+		}
+		if (in.PredictionID == "") == (in.Input == nil) {
+			writeError(c, domain.NewValidationError([]domain.Violation{{
+				Field:   "prediction_id",
+				Code:    domain.CodeValidationFailed,
+				Message: "exactly one of prediction_id and input is required",
+			}}))
+			return
+		}
+
+		var obs feedback.Observation
+		var err error
+		if in.PredictionID != "" {
+			obs, err = recordedObservation(c, in.PredictionID)
+		} else {
+			obs, err = newObservation(c, service, *in.Input, timeout)
+		}
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		obs.ActualPrice = in.ActualPrice
+		obs, err = tracker.Add(obs)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, obs)
+	}
+}
+
+// recordedObservation returns an observation for a recorded prediction.
+func recordedObservation(c *gin.Context, id string) (feedback.Observation, error) {
+	recorder := predlog.FromContext(c.Request.Context())
+	if recorder == nil {
+		return feedback.Observation{}, domain.NewError(domain.CodeNotFound, "Predictions are not recorded; send the input instead.", nil)
+	}
+	rec, err := lookupPrediction(c, recorder, id)
+	if err != nil {
+		return feedback.Observation{}, err
+	}
+	input := prediction.Normalize(rec.Input)
+	return feedback.Observation{
+		PredictionID:   rec.ID,
+		ModelVersion:   rec.ModelVersion,
+		Brand:          input.Brand,
+		Carbody:        input.Carbody,
+		PredictedPrice: float64(rec.PredictedPrice),
+	}, nil
+}
+
+// newObservation predicts input with the current model, or the model the request
+// selects, and returns an observation for the prediction. The prediction is
+// charged against the caller's quota.
+func newObservation(c *gin.Context, service domain.PredictionService, input domain.UserInput, timeout time.Duration) (feedback.Observation, error) {
+	selected, err := readModel(c)
+	if err != nil {
+		return feedback.Observation{}, err
+	}
+	service = selected.service(service)
+	if err := auth.FromContext(c.Request.Context()).Reserve(1); err != nil {
+		return feedback.Observation{}, err
+	}
+	result, err := withTimeout(c, timeout, func() (*domain.PredictionResult, error) {
+		return service.Predict(input)
+	})
+	if err != nil {
+		refundQuota(c, 1)
+		return feedback.Observation{}, err
+	}
+
+	obs := feedback.Observation{PredictedPrice: float64(result.PredictedPrice)}
+	if manager := selected.manager(); manager != nil {
+		obs.ModelVersion = manager.Model().Version
+	} else if manager, ok := service.(domain.ModelManager); ok {
+		obs.ModelVersion = manager.Model().Version
+	}
+	normalized := prediction.Normalize(input)
+	obs.Brand = normalized.Brand
+	obs.Carbody = normalized.Carbody
+	return obs, nil
+}
+
+// AccuracyHandler godoc
+// @Summary Report the accuracy of the model on real sales
+// @Description Return the MAE, RMSE, MAPE and R2 of predictions against the sale prices reported to /v1/feedback
+// @Description over rolling windows (1h, 24h, 7d and 30d), overall and by model version, brand and body type,
+// @Description along with the firing MAE alerts. Bearer tokens need the models:admin scope.
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} feedback.Summary
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope models:admin"
+// @Router /v1/accuracy [get]
+func AccuracyHandler(tracker *feedback.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, tracker.Summary())
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"car-price-prediction/internal/auth"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/feedback"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedback_ForRecordedPrediction(t *testing.T) {
+	tracker, err := feedback.Open(feedback.Config{})
+	require.NoError(t, err)
+	server, iss := setupPredictionLogTestServer(t, WithFeedback(tracker))
+	alice := iss.Token(t, "alice", auth.ScopePredictRead, auth.ScopeFeedbackWrite)
+
+	resp := doBearerRequest(t, http.MethodPost, server.URL+"/predict", alice, validInput())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.PredictionResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	resp = doBearerRequest(t, http.MethodPost, server.URL+"/v1/feedback", alice,
+		domain.FeedbackInput{PredictionID: result.PredictionID, ActualPrice: 16000})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var obs feedback.Observation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&obs))
+	assert.Equal(t, result.PredictionID, obs.PredictionID)
+	assert.Equal(t, "v1", obs.ModelVersion)
+	assert.Equal(t, "alfa-romero", obs.Brand)
+	assert.Equal(t, float64(15000), obs.PredictedPrice)
+
+	// Other callers cannot report sales for the prediction
+	bob := iss.Token(t, "bob", auth.ScopeFeedbackWrite)
+	resp = doBearerRequest(t, http.MethodPost, server.URL+"/v1/feedback", bob,
+		domain.FeedbackInput{PredictionID: result.PredictionID, ActualPrice: 1})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// The accuracy is reported to admins
+	resp = doBearerRequest(t, http.MethodGet, server.URL+"/v1/accuracy", alice, nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = doBearerRequest(t, http.MethodGet, server.URL+"/v1/accuracy", iss.Token(t, "ops", auth.ScopeModelsAdmin), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var summary feedback.Summary
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&summary))
+	assert.Equal(t, 1, summary.Windows[0].Overall.Count)
+	assert.InDelta(t, 1000, summary.Windows[0].ByModelVersion["v1"].MAE, 1e-9)
+}
+
+func TestFeedback_ForInput(t *testing.T) {
+	tracker, err := feedback.Open(feedback.Config{})
+	require.NoError(t, err)
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(&mockPredictionService{}, WithFeedback(tracker)))
+	defer server.Close()
+
+	input := validInput()
+	input.Brand = "Alfa-Romero"
+	resp := doRequest(t, http.MethodPost, server.URL+"/v1/feedback", "", domain.FeedbackInput{Input: &input, ActualPrice: 14000})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var obs feedback.Observation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&obs))
+	assert.Empty(t, obs.PredictionID)
+	assert.Equal(t, "alfa-romero", obs.Brand)
+	assert.Equal(t, float64(15000), obs.PredictedPrice)
+	assert.Equal(t, float64(14000), obs.ActualPrice)
+}
+
+func TestFeedback_ForInputWithSelectedModel(t *testing.T) {
+	tracker, err := feedback.Open(feedback.Config{})
+	require.NoError(t, err)
+	registry := testRegistry(t)
+	model, _ := registry.Get("linear")
+	server := setupPricingTestServer(t, WithFeedback(tracker), WithModels(registry))
+
+	input := validInput()
+	resp := doModelRequest(t, server.URL+"/v1/feedback", "linear", domain.FeedbackInput{Input: &input, ActualPrice: 14000})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var obs feedback.Observation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&obs))
+	assert.Equal(t, float64(500+100*input.Horsepower), obs.PredictedPrice)
+
+	// The sale is scored against the selected model
+	version := model.Model().Version
+	require.NotEmpty(t, version)
+	assert.Equal(t, version, obs.ModelVersion)
+	summary := tracker.Summary()
+	require.Contains(t, summary.Windows[0].ByModelVersion, version)
+	assert.Equal(t, 1, summary.Windows[0].ByModelVersion[version].Count)
+
+	resp = doModelRequest(t, server.URL+"/v1/feedback", "forest", domain.FeedbackInput{Input: &input, ActualPrice: 14000})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestFeedback_Validation(t *testing.T) {
+	tracker, err := feedback.Open(feedback.Config{})
+	require.NoError(t, err)
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(&mockPredictionService{}, WithFeedback(tracker)))
+	defer server.Close()
+	input := validInput()
+
+	for name, body := range map[string]domain.FeedbackInput{
+		"neither":        {ActualPrice: 14000},
+		"both":           {PredictionID: "0123456789abcdef", Input: &input, ActualPrice: 14000},
+		"no price":       {Input: &input},
+		"negative price": {Input: &input, ActualPrice: -1},
+	} {
+		resp := doRequest(t, http.MethodPost, server.URL+"/v1/feedback", "", body)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, name)
+	}
+
+	// Prediction IDs cannot be resolved without the prediction log
+	resp := doRequest(t, http.MethodPost, server.URL+"/v1/feedback", "", domain.FeedbackInput{PredictionID: "0123456789abcdef", ActualPrice: 14000})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
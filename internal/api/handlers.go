@@ -2,8 +2,11 @@ package api
 
 import (
 	"car-price-prediction/internal/domain"
-	"log"
+	"car-price-prediction/internal/drift"
+	"car-price-prediction/internal/validation"
+	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,31 +14,302 @@ import (
 // PredictHandler godoc
 // @Summary Predict car price
 // @Description Predict the price of a car based on its features.
+// @Description When the vehicle catalog is enabled, the body may name a `catalog_id` from /v1/catalog and give only the fields
+// @Description that differ from the catalog entry; the response lists the fields taken from the entry in `defaulted`.
+// @Description When imputation is enabled, a body with `"impute": true` may leave out any field. Missing fields get the median
+// @Description or most frequent value of similar training cars; the response lists them in `imputed` and gives the `price_range`
+// @Description their plausible values span.
+// @Description Lengths, curbweight, enginesize, horsepower and mileage may be given in other units with `"unit_system": "metric"`
+// @Description (mm, kg, cc, L/100km) and/or per-field `units`, e.g. `{"horsepower": "kw"}`. They are converted to model units,
+// @Description and the response lists the `conversions` and echoes the `normalized_input` the model was given.
+// @Description The `currency` and `year` query parameters add a `quote` of the price, which is in USD of the training data's year,
+// @Description adjusted for US inflation to `year` and converted at the configured exchange rate, with the index and rate used.
+// @Description The `region` query parameter adjusts the price by the rules of a regional market, such as import duties, and
+// @Description adds it as `regional` with a line item per matching rule and the rule set version; a quote is of the adjusted price.
+// @Description The X-Model header selects a registered model from /v1/models instead of the default model; the response names it
+// @Description in `model`. Linear models break the price down into `linear` terms, coefficient times encoded value.
+// @Description Errors are returned as RFC 7807 problem details (application/problem+json) with a stable `code`:
+// @Description VALIDATION_FAILED (400), UNKNOWN_CATEGORY (400), OUT_OF_RANGE (400), MODEL_UNAVAILABLE (503), INFERENCE_FAILED (500) and TIMEOUT (504).
+// @Description When authentication is enabled, an X-API-Key header or a bearer token with the predict:read scope is required,
+// @Description and UNAUTHORIZED (401), FORBIDDEN (403), RATE_LIMITED (429) and QUOTA_EXCEEDED (429) may also be returned.
 // @Accept  json
 // @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Param   input     body    domain.UserInput   true        "Car Features"
+// @Param   X-Model   header  string             false       "Registered model to predict with, see /v1/models"
+// @Param   region    query   string             false       "Region to adjust the price for, see /v1/regions"
+// @Param   currency  query   string             false       "Currency to quote the price in, e.g. IDR"
+// @Param   year      query   int                false       "Year to adjust the price for inflation to, e.g. 2024"
 // @Success 200 {object} domain.PredictionResult
-// @Failure 400 {object} domain.ErrorResponse
-// @Failure 500 {object} domain.ErrorResponse
+// @Failure 400 {object} domain.Problem "VALIDATION_FAILED, UNKNOWN_CATEGORY or OUT_OF_RANGE"
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope predict:read"
+// @Failure 429 {object} domain.Problem "RATE_LIMITED or QUOTA_EXCEEDED"
+// @Failure 500 {object} domain.Problem "INFERENCE_FAILED"
+// @Failure 503 {object} domain.Problem "MODEL_UNAVAILABLE or STORAGE_UNAVAILABLE"
+// @Failure 504 {object} domain.Problem "TIMEOUT"
 // @Router /predict [post]
-func PredictHandler(service domain.PredictionService) gin.HandlerFunc {
+func PredictHandler(service domain.PredictionService, timeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Bind the request body to a UserInput struct
-		var input domain.UserInput
-		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		// Read the model to predict with, the region to adjust the price for and the currency and year to quote it in
+		selected, err := readModel(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		service := selected.service(service)
+		region, err := readRegion(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		quote, err := readQuote(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		// Bind the request body to a UserInput struct, completing it from the catalog or by imputation
+		input, res, err := readInput(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		// Track the input distribution for drift monitoring
+		if res.observed() {
+			drift.FromContext(c.Request.Context()).Observe(input)
+		}
+
+		// Charge the prediction against the caller's quota
+		if !reserveQuota(c, 1) {
 			return
 		}
 
 		// Call the prediction service
-		result, err := service.Predict(input)
+		start := time.Now()
+		result, err := withTimeout(c, timeout, func() (*domain.PredictionResult, error) {
+			result, err := service.Predict(input)
+			if err != nil {
+				return nil, err
+			}
+			selected.apply(result)
+			if err := res.annotate(service, input, result); err != nil {
+				return nil, err
+			}
+			if err := region.apply(input, result); err != nil {
+				return nil, err
+			}
+			return result, quote.apply(result)
+		})
+		if err == nil {
+			// A prediction that cannot be recorded is not handed out
+			err = recordPrediction(c, selected.manager(), input, result, time.Since(start))
+		}
 		if err != nil {
-			log.Printf("Prediction error: %v", err) // Log the actual error
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Prediction failed: " + err.Error()})
+			refundQuota(c, 1)
+			writeError(c, err)
 			return
 		}
 
 		// Return the prediction result
 		c.JSON(http.StatusOK, result)
 	}
-}
\ No newline at end of file
+}
+
+// ExplainHandler godoc
+// @Summary Explain car price prediction
+// @Description Predict the price of a car and attribute the difference from a typical car to each input field.
+// @Description Errors use the same problem details format and codes as /predict, and the body may use `catalog_id`, `impute` and units
+// @Description and the X-Model header select a model as there.
+// @Description Bearer tokens need the explain:read scope.
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param   input     body    domain.UserInput   true        "Car Features"
+// @Param   X-Model   header  string             false       "Registered model to explain, see /v1/models"
+// @Success 200 {object} domain.Explanation
+// @Failure 400 {object} domain.Problem "VALIDATION_FAILED, UNKNOWN_CATEGORY or OUT_OF_RANGE"
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope explain:read"
+// @Failure 429 {object} domain.Problem "RATE_LIMITED or QUOTA_EXCEEDED"
+// @Failure 500 {object} domain.Problem "INFERENCE_FAILED"
+// @Failure 501 {object} domain.Problem "MODEL_UNAVAILABLE: the model does not support explanations"
+// @Failure 503 {object} domain.Problem "MODEL_UNAVAILABLE"
+// @Failure 504 {object} domain.Problem "TIMEOUT"
+// @Router /explain [post]
+func ExplainHandler(service domain.PredictionService, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Read the model to explain
+		selected, err := readModel(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		service := selected.service(service)
+
+		// Explanations are optional for prediction services
+		explainer, ok := service.(domain.Explainer)
+		if !ok {
+			writeProblem(c, http.StatusNotImplemented, domain.NewError(domain.CodeModelUnavailable, "The prediction model does not support explanations.", nil))
+			return
+		}
+
+		// Bind the request body to a UserInput struct, completing it from the catalog or by imputation
+		input, res, err := readInput(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		// Charge the explanation as one prediction against the caller's quota
+		if !reserveQuota(c, 1) {
+			return
+		}
+
+		// Call the explainer
+		explanation, err := withTimeout(c, timeout, func() (*domain.Explanation, error) {
+			return explainer.Explain(input)
+		})
+		if err != nil {
+			refundQuota(c, 1)
+			writeError(c, err)
+			return
+		}
+
+		// Return the explanation
+		res.annotateExplanation(input, explanation)
+		c.JSON(http.StatusOK, explanation)
+	}
+}
+
+// withTimeout runs fn and returns a TIMEOUT error if it does not finish within the
+// timeout or before the client goes away. fn keeps running in the background in that case.
+func withTimeout[T any](c *gin.Context, timeout time.Duration, fn func() (T, error)) (T, error) {
+	return domain.WithTimeout(c.Request.Context(), timeout, fn)
+}
+
+// PredictBatchHandler godoc
+// @Summary Predict car prices in batch
+// @Description Predict the prices of up to 1000 cars in one request. Rows are validated and predicted individually;
+// @Description a failing row carries a problem details `error` instead of a `result`. Each row counts against the API key quota,
+// @Description and rows that fail are not charged. Rows may use `catalog_id`, `impute` and units, and the prices are adjusted
+// @Description for `region` and quoted in `currency` and `year`, and predicted by the X-Model, as in /predict.
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param   input     body    domain.BatchInput   true        "Car Features"
+// @Param   X-Model   header  string              false       "Registered model to predict with, see /v1/models"
+// @Param   region    query   string              false       "Region to adjust the prices for, see /v1/regions"
+// @Param   currency  query   string              false       "Currency to quote the prices in, e.g. IDR"
+// @Param   year      query   int                 false       "Year to adjust the prices for inflation to, e.g. 2024"
+// @Success 200 {object} domain.BatchResult
+// @Failure 400 {object} domain.Problem "VALIDATION_FAILED"
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope predict:read"
+// @Failure 429 {object} domain.Problem "RATE_LIMITED or QUOTA_EXCEEDED"
+// @Failure 504 {object} domain.Problem "TIMEOUT"
+// @Router /predict/batch [post]
+func PredictBatchHandler(service domain.PredictionService, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Bind the request body to a BatchInput struct, keeping the rows raw until
+		// they are completed from the catalog and validated one by one
+		var batch struct {
+			Inputs []json.RawMessage `json:"inputs" binding:"required,min=1,max=1000"`
+		}
+		if err := c.ShouldBindJSON(&batch); err != nil {
+			writeError(c, validation.Translate(err))
+			return
+		}
+
+		// Read the model to predict with, the region to adjust the prices for and the currency and year to quote them in
+		selected, err := readModel(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		service := selected.service(service)
+		region, err := readRegion(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		quote, err := readQuote(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		// Every row counts against the quota; failed rows are refunded below
+		if !reserveQuota(c, len(batch.Inputs)) {
+			return
+		}
+
+		// Predict the rows one by one, collecting per-row errors
+		type row struct {
+			input   domain.UserInput
+			result  *domain.PredictionResult
+			err     error
+			latency time.Duration
+		}
+		ctx := c.Request.Context()
+		monitor := drift.FromContext(ctx)
+		rows, err := withTimeout(c, timeout, func() ([]row, error) {
+			rows := make([]row, len(batch.Inputs))
+			for i, data := range batch.Inputs {
+				input, res, err := bindInput(ctx, data)
+				if err != nil {
+					rows[i].err = err
+					continue
+				}
+				rows[i].input = input
+				if res.observed() {
+					monitor.Observe(input)
+				}
+				start := time.Now()
+				rows[i].result, rows[i].err = service.Predict(input)
+				if rows[i].err == nil {
+					selected.apply(rows[i].result)
+					rows[i].err = res.annotate(service, input, rows[i].result)
+				}
+				if rows[i].err == nil {
+					rows[i].err = region.apply(input, rows[i].result)
+				}
+				if rows[i].err == nil {
+					rows[i].err = quote.apply(rows[i].result)
+				}
+				rows[i].latency = time.Since(start)
+			}
+			return rows, nil
+		})
+		if err != nil {
+			refundQuota(c, len(batch.Inputs))
+			writeError(c, err)
+			return
+		}
+
+		// Failed rows are reported as problems and not charged
+		result := &domain.BatchResult{Results: make([]domain.BatchItem, len(rows))}
+		failed := 0
+		for i, r := range rows {
+			if r.err == nil {
+				r.err = recordPrediction(c, selected.manager(), r.input, r.result, r.latency)
+			}
+			if r.err != nil {
+				derr := asDomainError(r.err)
+				problem := newProblem(c, problemKinds[derr.Code].status, derr)
+				result.Results[i].Error = &problem
+				failed++
+				continue
+			}
+			result.Results[i].Result = r.result
+		}
+		refundQuota(c, failed)
+
+		// Return the batch result
+		c.JSON(http.StatusOK, result)
+	}
+}
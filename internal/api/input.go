@@ -0,0 +1,169 @@
+package api
+
+import (
+	"car-price-prediction/internal/catalog"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/imputation"
+	"car-price-prediction/internal/prediction"
+	"car-price-prediction/internal/units"
+	"car-price-prediction/internal/validation"
+	"context"
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Imputer returns a middleware that makes table available to the prediction
+// handlers, which fill in the fields of inputs that ask for imputation from it.
+func Imputer(table *imputation.Table) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(imputation.NewContext(c.Request.Context(), table))
+		c.Next()
+	}
+}
+
+// inputOptions are the request fields besides the UserInput fields that control
+// how a prediction input is completed.
+type inputOptions struct {
+	CatalogID  string            `json:"catalog_id"`
+	Impute     bool              `json:"impute"`
+	UnitSystem string            `json:"unit_system"`
+	Units      map[string]string `json:"units"`
+}
+
+// resolution records how a prediction input was completed before it was predicted.
+type resolution struct {
+	catalogID   string
+	defaulted   []string
+	imputed     []imputation.Imputation
+	conversions []domain.UnitConversion
+}
+
+// readInput reads the JSON request body of a single prediction and binds it with bindInput.
+func readInput(c *gin.Context) (domain.UserInput, *resolution, error) {
+	data, err := readBody(c)
+	if err != nil {
+		return domain.UserInput{}, nil, err
+	}
+	return bindInput(c.Request.Context(), data)
+}
+
+// readBody reads a JSON request body without decoding it.
+func readBody(c *gin.Context) (json.RawMessage, error) {
+	var data json.RawMessage
+	if err := json.NewDecoder(c.Request.Body).Decode(&data); err != nil {
+		return nil, validation.Translate(err)
+	}
+	return data, nil
+}
+
+// bindInput decodes and validates a prediction input. Fields given in other
+// units are converted to model units first. If the input names a catalog_id,
+// the catalog entry's spec is used for the fields it leaves out; if it asks to
+// impute, those fields are filled in from the training data instead. The
+// resolution is nil for inputs that use none of these options.
+func bindInput(ctx context.Context, data json.RawMessage) (domain.UserInput, *resolution, error) {
+	var input domain.UserInput
+	var opts inputOptions
+	if err := json.Unmarshal(data, &opts); err != nil {
+		return input, nil, validation.Translate(err)
+	}
+	if opts.CatalogID == "" && !opts.Impute && opts.UnitSystem == "" && opts.Units == nil {
+		if err := json.Unmarshal(data, &input); err != nil {
+			return input, nil, validation.Translate(err)
+		}
+		return input, nil, validation.Struct(&input)
+	}
+
+	res := &resolution{}
+	spec, err := units.NewSpec(opts.UnitSystem, opts.Units)
+	if err != nil {
+		return input, nil, err
+	}
+	if data, res.conversions, err = spec.Convert(data); err != nil {
+		return input, nil, validation.Translate(err)
+	}
+
+	var missing []string
+	if opts.CatalogID != "" {
+		cat := catalog.FromContext(ctx)
+		if cat == nil {
+			return input, nil, optionViolation("catalog_id", "the vehicle catalog is not enabled")
+		}
+		entry, ok := cat.Get(opts.CatalogID)
+		if !ok {
+			return input, nil, optionViolation("catalog_id", "no catalog entry has this ID")
+		}
+		if input, res.defaulted, err = entry.Merge(data); err != nil {
+			return input, nil, validation.Translate(err)
+		}
+		res.catalogID = entry.ID
+	} else {
+		if missing, err = validation.MissingFields(data); err != nil {
+			return input, nil, validation.Translate(err)
+		}
+		if err := json.Unmarshal(data, &input); err != nil {
+			return input, nil, validation.Translate(err)
+		}
+	}
+
+	if opts.Impute && len(missing) > 0 {
+		table := imputation.FromContext(ctx)
+		if table == nil {
+			return input, nil, optionViolation("impute", "imputation is not enabled")
+		}
+		input, res.imputed = table.Impute(input, missing)
+	}
+	return input, res, validation.Struct(&input)
+}
+
+// optionViolation returns a VALIDATION_FAILED error for an input option field.
+func optionViolation(field, message string) error {
+	return domain.NewValidationError([]domain.Violation{{Field: field, Code: domain.CodeValidationFailed, Message: message}})
+}
+
+// observed reports whether drift monitoring should see the input. Imputed
+// inputs are left out, because their filled-in values mimic the training data.
+// r may be nil.
+func (r *resolution) observed() bool {
+	return r == nil || len(r.imputed) == 0
+}
+
+// imputedFields returns the imputed fields as reported to clients.
+func (r *resolution) imputedFields() []domain.ImputedField {
+	var fields []domain.ImputedField
+	for _, imp := range r.imputed {
+		fields = append(fields, imp.ImputedField)
+	}
+	return fields
+}
+
+// annotate reports the resolution on a prediction result. If fields were imputed
+// it also predicts their alternative values to find the price range they span.
+// r may be nil.
+func (r *resolution) annotate(service domain.PredictionService, input domain.UserInput, result *domain.PredictionResult) error {
+	if r == nil {
+		return nil
+	}
+	normalized := prediction.Normalize(input)
+	result.CatalogID, result.Defaulted, result.Conversions, result.NormalizedInput = r.catalogID, r.defaulted, r.conversions, &normalized
+	if len(r.imputed) == 0 {
+		return nil
+	}
+	priceRange, err := imputation.PriceRange(service, input, result.PredictedPrice, r.imputed)
+	if err != nil {
+		return err
+	}
+	result.Imputed, result.PriceRange = r.imputedFields(), priceRange
+	return nil
+}
+
+// annotateExplanation reports the resolution on an explanation. r may be nil.
+func (r *resolution) annotateExplanation(input domain.UserInput, explanation *domain.Explanation) {
+	if r == nil {
+		return
+	}
+	normalized := prediction.Normalize(input)
+	explanation.CatalogID, explanation.Defaulted, explanation.Imputed = r.catalogID, r.defaulted, r.imputedFields()
+	explanation.Conversions, explanation.NormalizedInput = r.conversions, &normalized
+}
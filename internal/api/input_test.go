@@ -0,0 +1,217 @@
+package api
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/drift"
+	"car-price-prediction/internal/imputation"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// horsepowerPricingService is a mock prediction service that prices cars by horsepower.
+type horsepowerPricingService struct {
+	recordingPredictionService
+}
+
+// Predict implements the prediction service interface for testing.
+func (m *horsepowerPricingService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	m.recordingPredictionService.Predict(input)
+	return &domain.PredictionResult{PredictedPrice: 100 * float32(input.Horsepower)}, nil
+}
+
+// testImputationTable returns a table built from variants of validInput.
+func testImputationTable(t *testing.T) *imputation.Table {
+	var inputs []domain.UserInput
+	for _, hp := range []int{90, 100, 111, 120, 150} {
+		input := validInput()
+		input.Horsepower = hp
+		inputs = append(inputs, input)
+	}
+	table, err := imputation.NewTable(inputs, imputation.DefaultMinGroupSize)
+	require.NoError(t, err)
+	return table
+}
+
+func TestPredictHandler_Impute(t *testing.T) {
+	service := &horsepowerPricingService{}
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(service, WithImputation(testImputationTable(t))))
+	defer server.Close()
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict", "", map[string]any{
+		"impute":     true,
+		"brand":      "alfa-romero",
+		"carbody":    "convertible",
+		"enginesize": 130,
+		"citympg":    21,
+		"fueltype":   "gas",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.PredictionResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	// The median horsepower of the brand and engine type's cars is used
+	assert.Equal(t, float32(11100), result.PredictedPrice)
+	assert.Len(t, result.Imputed, 19)
+	assert.Contains(t, result.Imputed, domain.ImputedField{Field: "horsepower", Value: 111.0, Basis: "brand=alfa-romero"})
+	// The range spans the 10th and 90th percentile of horsepower
+	assert.Equal(t, &domain.PriceRange{Lower: 9400, Upper: 13800}, result.PriceRange)
+	assert.Equal(t, validInput(), service.inputs[0])
+}
+
+func TestPredictHandler_ImputeNothingMissing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(&mockPredictionService{}))
+	defer server.Close()
+
+	// A complete input needs no table
+	body := map[string]any{"impute": true}
+	data, _ := json.Marshal(validInput())
+	require.NoError(t, json.Unmarshal(data, &body))
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict", "", body)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.PredictionResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Empty(t, result.Imputed)
+	assert.Nil(t, result.PriceRange)
+}
+
+func TestPredictHandler_ImputeDisabled(t *testing.T) {
+	resp, problem := postProblem(t, &mockPredictionService{}, []byte(`{"impute": true, "brand": "audi"}`))
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, []domain.Violation{{Field: "impute", Code: domain.CodeValidationFailed, Message: "imputation is not enabled"}}, problem.Violations)
+}
+
+func TestPredictHandler_WithoutImputeFieldsAreRequired(t *testing.T) {
+	resp, problem := postProblem(t, &mockPredictionService{}, []byte(`{"brand": "audi"}`), WithImputation(testImputationTable(t)))
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, problem.Violations, domain.Violation{Field: "wheelbase", Code: domain.CodeValidationFailed, Message: "is required"})
+}
+
+func TestPredictBatchHandler_Impute(t *testing.T) {
+	profile, err := drift.NewProfile([]domain.UserInput{validInput()}, drift.DefaultBins)
+	require.NoError(t, err)
+	monitor := drift.NewMonitor(profile, drift.Config{MinSamples: 1})
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(&horsepowerPricingService{}, WithImputation(testImputationTable(t)), WithDriftMonitor(monitor)))
+	defer server.Close()
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict/batch", "", map[string]any{
+		"inputs": []any{
+			map[string]any{"impute": true, "horsepower": 150},
+			validInput(),
+		},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var batch domain.BatchResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+	require.NotNil(t, batch.Results[0].Result)
+	assert.Len(t, batch.Results[0].Result.Imputed, 23)
+	assert.Equal(t, &domain.PriceRange{Lower: 15000, Upper: 15000}, batch.Results[0].Result.PriceRange)
+	require.NotNil(t, batch.Results[1].Result)
+	assert.Empty(t, batch.Results[1].Result.Imputed)
+
+	// Imputed inputs are not observed for drift monitoring
+	assert.Equal(t, 1, monitor.Report().Windows[0].Samples)
+}
+
+func TestExplainHandler_Impute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(&mockExplainingService{}, WithImputation(testImputationTable(t))))
+	defer server.Close()
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/explain", "", map[string]any{"impute": true, "horsepower": 150})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var explanation domain.Explanation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&explanation))
+	assert.Len(t, explanation.Imputed, 23)
+}
+
+// metricInput returns validInput as a metric front-end would send it.
+func metricInput() map[string]any {
+	body := map[string]any{}
+	data, _ := json.Marshal(validInput())
+	_ = json.Unmarshal(data, &body)
+	body["unit_system"] = "metric"
+	body["wheelbase"], body["carlength"], body["carwidth"] = 2250, 4288, 1628
+	body["curbweight"], body["enginesize"] = 1156, 2130
+	body["citympg"], body["highwaympg"] = 11.2, 8.7
+	body["units"] = map[string]string{"carheight": "cm"}
+	body["carheight"] = 123.95
+	return body
+}
+
+func TestPredictHandler_Units(t *testing.T) {
+	service := &recordingPredictionService{}
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(service))
+	defer server.Close()
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict", "", metricInput())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.PredictionResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	assert.Len(t, result.Conversions, 8)
+	assert.Equal(t, domain.UnitConversion{Field: "carheight", Value: 123.95, Unit: "cm", Normalized: 48.8, NormalizedUnit: "in"}, result.Conversions[3])
+	want := validInput()
+	want.Wheelbase, want.Carlength, want.Carwidth = 88.58, 168.82, 64.09
+	want.Curbweight = 2549
+	assert.Equal(t, &want, result.NormalizedInput)
+	assert.Equal(t, []domain.UserInput{want}, service.inputs)
+}
+
+func TestPredictHandler_UnitSanityErrors(t *testing.T) {
+	body := metricInput()
+	body["wheelbase"] = 88.6
+	data, _ := json.Marshal(body)
+
+	resp, problem := postProblem(t, &mockPredictionService{}, data)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, domain.CodeOutOfRange, problem.Code)
+	assert.Equal(t, []domain.Violation{{Field: "wheelbase", Code: domain.CodeOutOfRange, Message: "88.6 mm is 3.49 in; must be between 1524 and 3810 mm"}}, problem.Violations)
+
+	resp, problem = postProblem(t, &mockPredictionService{}, []byte(`{"unit_system": "imperial"}`))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, []domain.Violation{{Field: "unit_system", Code: domain.CodeValidationFailed, Message: "must be one of: us, metric"}}, problem.Violations)
+}
+
+func TestPredictHandler_UnitsWithCatalogAndImputation(t *testing.T) {
+	service := &horsepowerPricingService{}
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(service, WithCatalog(testCatalog(t)), WithImputation(testImputationTable(t))))
+	defer server.Close()
+
+	// Catalog overrides are converted before they are merged
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict", "", map[string]any{
+		"catalog_id": "alfa-romero-giulia",
+		"units":      map[string]string{"horsepower": "kw"},
+		"horsepower": 100,
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.PredictionResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, float32(13400), result.PredictedPrice)
+	assert.Equal(t, 134, result.NormalizedInput.Horsepower)
+
+	// Imputation fills in what the converted input leaves out
+	resp = doRequest(t, http.MethodPost, server.URL+"/predict", "", map[string]any{
+		"impute":      true,
+		"unit_system": "metric",
+		"curbweight":  1156,
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	result = domain.PredictionResult{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Len(t, result.Conversions, 1)
+	assert.Len(t, result.Imputed, 23)
+	assert.Equal(t, 2549, result.NormalizedInput.Curbweight)
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ModelHeader is the request header selecting a registered model instead of the
+// default model.
+const ModelHeader = "X-Model"
+
+// Models returns a middleware that makes registry available to the prediction
+// handlers, which predict with the model a request selects from it.
+func Models(registry *prediction.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(prediction.NewRegistryContext(c.Request.Context(), registry))
+		c.Next()
+	}
+}
+
+// modelRequest is the registered model a request selects.
+type modelRequest struct {
+	name  string
+	model prediction.Model
+}
+
+// readModel reads the X-Model header. It returns nil if the request does not set
+// it, and a VALIDATION_FAILED error if no model is registered under the name.
+func readModel(c *gin.Context) (*modelRequest, error) {
+	name := strings.ToLower(strings.TrimSpace(c.GetHeader(ModelHeader)))
+	if name == "" {
+		return nil, nil
+	}
+	registry := prediction.RegistryFromContext(c.Request.Context())
+	if registry == nil {
+		return nil, optionViolation(ModelHeader, "model selection is not enabled")
+	}
+	model, ok := registry.Get(name)
+	if !ok {
+		return nil, optionViolation(ModelHeader, "must be one of: "+strings.Join(registry.Names(), ", "))
+	}
+	return &modelRequest{name: name, model: model}, nil
+}
+
+// service returns the selected model, or fallback if the request selects none.
+func (m *modelRequest) service(fallback domain.PredictionService) domain.PredictionService {
+	if m == nil {
+		return fallback
+	}
+	return m.model
+}
+
+// manager returns the selected model, or nil if the request selects none.
+func (m *modelRequest) manager() domain.ModelManager {
+	if m == nil {
+		return nil
+	}
+	return m.model
+}
+
+// apply names the selected model in a prediction result. m may be nil.
+func (m *modelRequest) apply(result *domain.PredictionResult) {
+	if m != nil {
+		result.Model = m.name
+	}
+}
+
+// RegisteredModel describes a model requests can select with the X-Model header.
+type RegisteredModel struct {
+	Name  string           `json:"name" example:"linear"`
+	Model domain.ModelInfo `json:"model"`
+}
+
+// ModelsHandler godoc
+// @Summary List the registered models
+// @Description List the models requests to /predict, /predict/batch, /explain and /v1/predict/depreciation can select
+// @Description with the X-Model header instead of the default model. Bearer tokens need the models:admin scope.
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {array} RegisteredModel
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope models:admin"
+// @Router /v1/models [get]
+func ModelsHandler(registry *prediction.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		models := []RegisteredModel{}
+		for _, name := range registry.Names() {
+			model, _ := registry.Get(name)
+			models = append(models, RegisteredModel{Name: name, Model: model.Model()})
+		}
+		c.JSON(http.StatusOK, models)
+	}
+}
+
+// ModelHandler godoc
+// @Summary Describe the current model
+// @Description Return the version (content hash) of the model serving predictions. Bearer tokens need the models:admin scope.
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} domain.ModelInfo
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope models:admin"
+// @Router /v1/models/current [get]
+func ModelHandler(manager domain.ModelManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, manager.Model())
+	}
+}
+
+// ReloadModelHandler godoc
+// @Summary Reload the model
+// @Description Load the model file again, e.g. after it was replaced on disk, and invalidate cached predictions.
+// @Description If loading fails, the current model keeps serving. Bearer tokens need the models:admin scope.
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} domain.ModelInfo
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope models:admin"
+// @Failure 503 {object} domain.Problem "MODEL_UNAVAILABLE"
+// @Router /v1/models/reload [post]
+func ReloadModelHandler(manager domain.ModelManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		previous := manager.Model()
+		info, err := manager.Reload()
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		log.Printf("Model reloaded: version %s (was %s)", info.Version, previous.Version)
+		c.JSON(http.StatusOK, info)
+	}
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"car-price-prediction/internal/auth"
+	"car-price-prediction/internal/auth/authtest"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reloadableService is a mock prediction service whose model can be reloaded.
+type reloadableService struct {
+	mockPredictionService
+	version string
+	fail    bool
+}
+
+// Model implements the domain.ModelManager interface for testing.
+func (s *reloadableService) Model() domain.ModelInfo {
+	return domain.ModelInfo{Version: s.version}
+}
+
+// Reload implements the domain.ModelManager interface for testing.
+func (s *reloadableService) Reload() (domain.ModelInfo, error) {
+	if s.fail {
+		return domain.ModelInfo{}, domain.NewError(domain.CodeModelUnavailable, "The prediction model could not be loaded.", errors.New("file not found"))
+	}
+	s.version = "v2"
+	return s.Model(), nil
+}
+
+func TestModelHandlers_RequireModelsAdmin(t *testing.T) {
+	iss := authtest.NewIssuer(t)
+	keys, err := auth.OpenJWKS(iss.JWKSPath)
+	require.NoError(t, err)
+	service := &reloadableService{version: "v1"}
+
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(service, WithAuth(&auth.Authenticator{
+		JWT:   auth.NewJWTVerifier(keys, auth.JWTOptions{}),
+		Audit: auth.NewAuditLog(io.Discard),
+	})))
+	defer server.Close()
+
+	resp := doBearerRequest(t, http.MethodPost, server.URL+"/v1/models/reload", iss.Token(t, "alice", auth.ScopePredictRead), nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, "v1", service.version)
+
+	admin := iss.Token(t, "ops", auth.ScopeModelsAdmin)
+	resp = doBearerRequest(t, http.MethodPost, server.URL+"/v1/models/reload", admin, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var info domain.ModelInfo
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+	assert.Equal(t, "v2", info.Version)
+
+	resp = doBearerRequest(t, http.MethodGet, server.URL+"/v1/models/current", admin, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+	assert.Equal(t, "v2", info.Version)
+
+	// A failed reload keeps the model and reports MODEL_UNAVAILABLE
+	service.fail = true
+	resp = doBearerRequest(t, http.MethodPost, server.URL+"/v1/models/reload", admin, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	var problem domain.Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, domain.CodeModelUnavailable, problem.Code)
+}
+
+func TestModelHandlers_OnlyForReloadableServices(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/v1/models/current", "", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "carprice_test_total", Help: "Test counter."})
+	reg.MustRegister(counter)
+	counter.Inc()
+
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(&mockPredictionService{}, WithMetrics(reg)))
+	defer server.Close()
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/metrics", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "carprice_test_total 1")
+}
+
+// testRegistry registers a linear model pricing cars at 100 dollars per horsepower
+// under the name "linear".
+func testRegistry(t *testing.T) *prediction.Registry {
+	bundle, err := prediction.NewLinearBundle(nil, &prediction.LinearModel{
+		Method:       "ridge",
+		Intercept:    500,
+		Coefficients: map[string]float64{"horsepower": 100},
+	}, prediction.BundleMetadata{})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "linear.json")
+	require.NoError(t, bundle.WriteFile(path))
+
+	registry := prediction.NewRegistry()
+	require.NoError(t, registry.Register("linear", prediction.Open(path)))
+	return registry
+}
+
+// doModelRequest posts body to url, selecting model with the X-Model header.
+func doModelRequest(t *testing.T, url, model string, body any) *http.Response {
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(string(data)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(ModelHeader, model)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestPredictHandler_SelectsModel(t *testing.T) {
+	server := setupPricingTestServer(t, WithModels(testRegistry(t)))
+	input := validInput()
+
+	resp := doModelRequest(t, server.URL+"/predict", "Linear", input)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.PredictionResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, "linear", result.Model)
+	assert.Equal(t, float32(500+100*input.Horsepower), result.PredictedPrice)
+	require.NotNil(t, result.Linear)
+	assert.Equal(t, 500.0, result.Linear.Intercept)
+
+	// Without the header the default model predicts
+	resp = doRequest(t, http.MethodPost, server.URL+"/predict", "", input)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	result = domain.PredictionResult{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Empty(t, result.Model)
+	assert.Equal(t, float32(15000), result.PredictedPrice)
+	assert.Nil(t, result.Linear)
+
+	resp = doModelRequest(t, server.URL+"/predict/batch", "linear", domain.BatchInput{Inputs: []domain.UserInput{input}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var batch domain.BatchResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+	require.Len(t, batch.Results, 1)
+	assert.Equal(t, "linear", batch.Results[0].Result.Model)
+}
+
+func TestPredictHandler_UnknownModel(t *testing.T) {
+	server := setupPricingTestServer(t, WithModels(testRegistry(t)))
+	resp := doModelRequest(t, server.URL+"/predict", "lasso", validInput())
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	var problem domain.Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, []domain.Violation{{Field: ModelHeader, Code: domain.CodeValidationFailed, Message: "must be one of: linear"}}, problem.Violations)
+
+	// Servers without registered models reject the header
+	server = setupPricingTestServer(t)
+	resp = doModelRequest(t, server.URL+"/predict", "linear", validInput())
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	problem = domain.Problem{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, "model selection is not enabled", problem.Violations[0].Message)
+}
+
+func TestModelsHandler(t *testing.T) {
+	registry := testRegistry(t)
+	server := setupPricingTestServer(t, WithModels(registry))
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/v1/models", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var models []RegisteredModel
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&models))
+	linear, _ := registry.Get("linear")
+	assert.Equal(t, []RegisteredModel{{Name: "linear", Model: linear.Model()}}, models)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"car-price-prediction/internal/auth"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/predlog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PredictionLog returns a middleware that makes recorder available to the
+// prediction handlers, which record every prediction they return.
+func PredictionLog(recorder *predlog.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(predlog.NewContext(c.Request.Context(), recorder))
+		c.Next()
+	}
+}
+
+// recordPrediction stores a successful prediction in the prediction log, if it is
+// enabled, and sets the prediction ID on result. model is the model the request
+// selected, or nil for the default model.
+func recordPrediction(c *gin.Context, model domain.ModelManager, input domain.UserInput, result *domain.PredictionResult, latency time.Duration) error {
+	p := predlog.Prediction{
+		RequestID: c.GetString(requestIDKey),
+		Input:     input,
+		Result:    result,
+		Latency:   latency,
+		Model:     model,
+	}
+	if principal := auth.FromContext(c.Request.Context()); principal != nil {
+		p.Subject = principal.Subject
+	}
+
+	id, err := predlog.FromContext(c.Request.Context()).Record(p)
+	if err != nil {
+		return err
+	}
+	result.PredictionID = id
+	return nil
+}
+
+// PredictionLookupResponse represents the JSON response body for the prediction lookup API.
+type PredictionLookupResponse struct {
+	Record predlog.Record `json:"record"`
+	Replay predlog.Replay `json:"replay"`
+}
+
+// PredictionLookupHandler godoc
+// @Summary Look up and replay a recorded prediction
+// @Description Return the stored record of a prediction (input, encoded features, model version, output, latency)
+// @Description and predict the input again with the recorded model version to confirm the result is identical.
+// @Description Callers see their own predictions; admin keys and tokens with the models:admin scope see all.
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param   id  path  string  true  "Prediction ID"
+// @Success 200 {object} api.PredictionLookupResponse
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope predict:read"
+// @Failure 404 {object} domain.Problem "NOT_FOUND"
+// @Failure 503 {object} domain.Problem "STORAGE_UNAVAILABLE"
+// @Failure 504 {object} domain.Problem "TIMEOUT"
+// @Router /v1/predictions/{id} [get]
+func PredictionLookupHandler(recorder *predlog.Recorder, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rec, err := lookupPrediction(c, recorder, c.Param("id"))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		replay, err := withTimeout(c, timeout, func() (predlog.Replay, error) {
+			return recorder.Replay(rec), nil
+		})
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, PredictionLookupResponse{Record: *rec, Replay: replay})
+	}
+}
+
+// lookupPrediction returns the recorded prediction with the given ID if the caller
+// may see it. Other callers' predictions are reported as not found so that their
+// existence is not revealed.
+func lookupPrediction(c *gin.Context, recorder *predlog.Recorder, id string) (*predlog.Record, error) {
+	rec, err := recorder.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	principal := auth.FromContext(c.Request.Context())
+	if principal != nil && !principal.Admin && principal.Subject != rec.Subject {
+		return nil, domain.NewError(domain.CodeNotFound, "The prediction does not exist or has expired.", nil)
+	}
+	return rec, nil
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"car-price-prediction/internal/auth"
+	"car-price-prediction/internal/auth/authtest"
+	"car-price-prediction/internal/depreciation"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/predlog"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryArchive serves every recorded model version with the same service.
+type memoryArchive struct {
+	service domain.PredictionService
+}
+
+func (a *memoryArchive) Save(domain.ModelInfo) error { return nil }
+
+func (a *memoryArchive) Open(string) (domain.PredictionService, error) { return a.service, nil }
+
+func setupPredictionLogTestServer(t *testing.T, opts ...Option) (*httptest.Server, *authtest.Issuer) {
+	store, err := predlog.Open(t.TempDir(), predlog.Options{})
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	iss := authtest.NewIssuer(t)
+	keys, err := auth.OpenJWKS(iss.JWKSPath)
+	require.NoError(t, err)
+
+	service := &reloadableService{version: "v1"}
+	recorder := predlog.NewRecorder(store, service, &memoryArchive{service: service})
+
+	gin.SetMode(gin.TestMode)
+	opts = append(opts,
+		WithAuth(&auth.Authenticator{JWT: auth.NewJWTVerifier(keys, auth.JWTOptions{}), Audit: auth.NewAuditLog(io.Discard)}),
+		WithPredictionLog(recorder),
+	)
+	server := httptest.NewServer(SetupRouter(service, opts...))
+	t.Cleanup(server.Close)
+	return server, iss
+}
+
+func TestPredictionLog_RecordAndLookup(t *testing.T) {
+	server, iss := setupPredictionLogTestServer(t)
+	alice := iss.Token(t, "alice", auth.ScopePredictRead)
+
+	body, _ := json.Marshal(validInput())
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/predict", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+alice)
+	req.Header.Set("X-Request-ID", "quote-42")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "quote-42", resp.Header.Get("X-Request-ID"))
+
+	var result domain.PredictionResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.NotEmpty(t, result.PredictionID)
+
+	resp = doBearerRequest(t, http.MethodGet, server.URL+"/v1/predictions/"+result.PredictionID, alice, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var lookup PredictionLookupResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&lookup))
+	assert.Equal(t, "quote-42", lookup.Record.RequestID)
+	assert.Equal(t, "alice", lookup.Record.Subject)
+	assert.Equal(t, "v1", lookup.Record.ModelVersion)
+	assert.Equal(t, result.PredictedPrice, lookup.Record.PredictedPrice)
+	assert.True(t, lookup.Replay.Identical)
+	assert.True(t, lookup.Replay.FeaturesIdentical)
+
+	// Other callers cannot see the prediction, admins can
+	resp = doBearerRequest(t, http.MethodGet, server.URL+"/v1/predictions/"+result.PredictionID, iss.Token(t, "bob", auth.ScopePredictRead), nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	admin := iss.Token(t, "ops", auth.ScopePredictRead, auth.ScopeModelsAdmin)
+	resp = doBearerRequest(t, http.MethodGet, server.URL+"/v1/predictions/"+result.PredictionID, admin, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestPredictionLog_LookupUnknown(t *testing.T) {
+	server, iss := setupPredictionLogTestServer(t)
+
+	resp := doBearerRequest(t, http.MethodGet, server.URL+"/v1/predictions/0123456789abcdef", iss.Token(t, "alice", auth.ScopePredictRead), nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	var problem domain.Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, domain.CodeNotFound, problem.Code)
+}
+
+func TestPredictionLog_BatchRowsAreRecorded(t *testing.T) {
+	server, iss := setupPredictionLogTestServer(t)
+
+	resp := doBearerRequest(t, http.MethodPost, server.URL+"/predict/batch", iss.Token(t, "alice", auth.ScopePredictRead),
+		domain.BatchInput{Inputs: []domain.UserInput{validInput(), validInput()}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var batch domain.BatchResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+	require.Len(t, batch.Results, 2)
+	assert.NotEmpty(t, batch.Results[0].Result.PredictionID)
+	assert.NotEqual(t, batch.Results[0].Result.PredictionID, batch.Results[1].Result.PredictionID)
+}
+
+func TestPredictionLog_DepreciationIsRecorded(t *testing.T) {
+	server, iss := setupPredictionLogTestServer(t, WithDepreciation(depreciation.Default()))
+	alice := iss.Token(t, "alice", auth.ScopePredictRead)
+
+	resp := doBearerRequest(t, http.MethodPost, server.URL+"/v1/predict/depreciation", alice,
+		domain.DepreciationInput{UserInput: validInput(), AgeYears: 3})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.DepreciationResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.NotEmpty(t, result.Prediction.PredictionID)
+
+	resp = doBearerRequest(t, http.MethodGet, server.URL+"/v1/predictions/"+result.Prediction.PredictionID, alice, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var lookup PredictionLookupResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&lookup))
+	assert.Equal(t, result.Prediction.PredictedPrice, lookup.Record.PredictedPrice)
+	assert.True(t, lookup.Replay.Identical)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/pricing"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pricing returns a middleware that makes pricer available to the prediction
+// handlers, which quote prices in the currency and year a request asks for with it.
+func Pricing(pricer *pricing.Pricer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(pricing.NewContext(c.Request.Context(), pricer))
+		c.Next()
+	}
+}
+
+// quoteRequest is the currency and year a request asks prices to be quoted in.
+type quoteRequest struct {
+	pricer   *pricing.Pricer
+	currency string
+	year     int
+}
+
+// readQuote reads the currency and year query parameters. It returns nil if the
+// request sets neither, and a VALIDATION_FAILED error if they cannot be quoted.
+func readQuote(c *gin.Context) (*quoteRequest, error) {
+	currency, hasCurrency := c.GetQuery("currency")
+	yearParam, hasYear := c.GetQuery("year")
+	if !hasCurrency && !hasYear {
+		return nil, nil
+	}
+	q := &quoteRequest{pricer: pricing.FromContext(c.Request.Context()), currency: currency}
+	if hasYear {
+		year, err := strconv.Atoi(yearParam)
+		if err != nil {
+			return nil, optionViolation("year", "must be a whole number")
+		}
+		q.year = year
+	}
+	if q.pricer == nil {
+		field := "currency"
+		if !hasCurrency {
+			field = "year"
+		}
+		return nil, optionViolation(field, "price quotes are not enabled")
+	}
+	if err := q.pricer.Check(q.currency, q.year); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// apply sets the quote of a prediction result, quoting the regional price if
+// there is one. q may be nil.
+func (q *quoteRequest) apply(result *domain.PredictionResult) error {
+	if q == nil {
+		return nil
+	}
+	price := result.PredictedPrice
+	if result.Regional != nil {
+		price = result.Regional.Price
+	}
+	quote, err := q.pricer.Quote(price, q.currency, q.year)
+	if err != nil {
+		return err
+	}
+	result.Quote = quote
+	return nil
+}
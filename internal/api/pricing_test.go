@@ -0,0 +1,108 @@
+package api
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/pricing"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPricer returns a pricer with an IDR exchange rate.
+func testPricer(t *testing.T) *pricing.Pricer {
+	path := filepath.Join(t.TempDir(), "exchange_rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"base": "USD", "rates": [{"currency": "IDR", "rate": 16000, "effective_date": "2025-01-01"}]}`), 0o644))
+	rates, err := pricing.LoadRates(path, pricing.BaseCurrency)
+	require.NoError(t, err)
+	pricer, err := pricing.NewPricer(pricing.DefaultBaseYear, pricing.DefaultCPI(), rates)
+	require.NoError(t, err)
+	return pricer
+}
+
+func setupPricingTestServer(t *testing.T, opts ...Option) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(SetupRouter(&mockPredictionService{}, opts...))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPredictHandler_Quote(t *testing.T) {
+	server := setupPricingTestServer(t, WithPricing(testPricer(t)))
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict?currency=IDR&year=2024", "", validInput())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.PredictionResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, float32(15000), result.PredictedPrice)
+	require.NotNil(t, result.Quote)
+	assert.Equal(t, float32(15000), result.Quote.BasePrice)
+	assert.Equal(t, "IDR", result.Quote.Currency)
+	assert.Equal(t, 2024, result.Quote.Year)
+	assert.Equal(t, 699678066.91, result.Quote.Price)
+	assert.Equal(t, &domain.ExchangeRate{From: "USD", To: "IDR", Rate: 16000, EffectiveDate: "2025-01-01"}, result.Quote.ExchangeRate)
+	assert.Equal(t, 2.9153, result.Quote.Inflation.Factor)
+
+	// Without the query parameters the price is not quoted
+	resp = doRequest(t, http.MethodPost, server.URL+"/predict", "", validInput())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	result = domain.PredictionResult{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Nil(t, result.Quote)
+}
+
+func TestPredictHandler_QuoteErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		query      string
+		violations []domain.Violation
+	}{
+		{"unknown currency", []Option{WithPricing(testPricer(t))}, "currency=EUR", []domain.Violation{
+			{Field: "currency", Code: domain.CodeValidationFailed, Message: "must be one of: IDR, USD"},
+		}},
+		{"year out of range", []Option{WithPricing(testPricer(t))}, "year=1970", []domain.Violation{
+			{Field: "year", Code: domain.CodeValidationFailed, Message: "must be between 1985 and 2024"},
+		}},
+		{"year not a number", []Option{WithPricing(testPricer(t))}, "year=recent", []domain.Violation{
+			{Field: "year", Code: domain.CodeValidationFailed, Message: "must be a whole number"},
+		}},
+		{"quotes disabled", nil, "currency=IDR", []domain.Violation{
+			{Field: "currency", Code: domain.CodeValidationFailed, Message: "price quotes are not enabled"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := setupPricingTestServer(t, tt.opts...)
+			resp := doRequest(t, http.MethodPost, server.URL+"/predict?"+tt.query, "", validInput())
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			var problem domain.Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+			assert.Equal(t, tt.violations, problem.Violations)
+		})
+	}
+}
+
+func TestPredictBatchHandler_Quote(t *testing.T) {
+	server := setupPricingTestServer(t, WithPricing(testPricer(t)))
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict/batch?currency=idr", "", domain.BatchInput{
+		Inputs: []domain.UserInput{validInput(), validInput()},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var batch domain.BatchResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+	require.Len(t, batch.Results, 2)
+	for _, item := range batch.Results {
+		require.NotNil(t, item.Result)
+		require.NotNil(t, item.Result.Quote)
+		assert.Equal(t, 240000000.0, item.Result.Quote.Price)
+		assert.Equal(t, 1985, item.Result.Quote.Year)
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/rules"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Rules returns a middleware that makes engine available to the prediction
+// handlers, which adjust prices for the region a request asks for with it.
+func Rules(engine *rules.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(rules.NewContext(c.Request.Context(), engine))
+		c.Next()
+	}
+}
+
+// regionRequest is the region a request asks prices to be adjusted for.
+type regionRequest struct {
+	set    *rules.RuleSet
+	region string
+}
+
+// readRegion reads the region query parameter. It returns nil if the request
+// does not set it, and a VALIDATION_FAILED error if the region has no rules.
+// The rule set in effect is kept, so that every row of a batch uses the same version.
+func readRegion(c *gin.Context) (*regionRequest, error) {
+	region, ok := c.GetQuery("region")
+	if !ok {
+		return nil, nil
+	}
+	engine := rules.FromContext(c.Request.Context())
+	if engine == nil {
+		return nil, optionViolation("region", "regional rules are not enabled")
+	}
+	set := engine.Current()
+	if _, ok := set.Regions[strings.ToLower(strings.TrimSpace(region))]; !ok {
+		return nil, optionViolation("region", "must be one of: "+strings.Join(set.IDs(), ", "))
+	}
+	return &regionRequest{set: set, region: region}, nil
+}
+
+// apply sets the regional price of a prediction result. r may be nil.
+func (r *regionRequest) apply(input domain.UserInput, result *domain.PredictionResult) error {
+	if r == nil {
+		return nil
+	}
+	regional, err := r.set.Apply(r.region, input, result.PredictedPrice)
+	if err != nil {
+		return err
+	}
+	result.Regional = regional
+	return nil
+}
+
+// RegionsResponse represents the JSON response body for the regions API.
+type RegionsResponse struct {
+	Version string          `json:"version" example:"2025-10-01"`
+	Regions []*rules.Region `json:"regions"`
+}
+
+// RegionsHandler godoc
+// @Summary List regional pricing rules
+// @Description List the regions whose rules the `region` query parameter of /predict and /predict/batch can apply, with the
+// @Description version of the rule set in effect. Bearer tokens need the predict:read scope.
+// @Produce  json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} RegionsResponse
+// @Failure 401 {object} domain.Problem "UNAUTHORIZED"
+// @Failure 403 {object} domain.Problem "FORBIDDEN: missing scope predict:read"
+// @Router /v1/regions [get]
+func RegionsHandler(engine *rules.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		set := engine.Current()
+		response := RegionsResponse{Version: set.Version, Regions: []*rules.Region{}}
+		for _, id := range set.IDs() {
+			response.Regions = append(response.Regions, set.Regions[id])
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
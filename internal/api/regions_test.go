@@ -0,0 +1,108 @@
+package api
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/rules"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRules returns an engine with a 10% duty on porsches in region "id".
+func testRules(t *testing.T) *rules.Engine {
+	path := filepath.Join(t.TempDir(), "region_rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+version: "2025-10-01"
+regions:
+  id:
+    name: Indonesia
+    rules:
+      - {name: luxury import duty, when: brand = porsche, adjust: +10%}
+      - {name: registration fee, adjust: "+500"}
+`), 0o644))
+	engine, err := rules.Load(path)
+	require.NoError(t, err)
+	return engine
+}
+
+func TestPredictHandler_Region(t *testing.T) {
+	server := setupPricingTestServer(t, WithRegionalRules(testRules(t)), WithPricing(testPricer(t)))
+
+	input := validInput()
+	input.Brand = "porsche"
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict?region=ID&currency=IDR", "", input)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result domain.PredictionResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, float32(15000), result.PredictedPrice)
+	assert.Equal(t, &domain.RegionalPrice{
+		Region:         "id",
+		RuleSetVersion: "2025-10-01",
+		BasePrice:      15000,
+		Price:          17000,
+		LineItems: []domain.LineItem{
+			{Rule: "luxury import duty", When: "brand = porsche", Adjust: "+10%", Amount: 1500},
+			{Rule: "registration fee", Adjust: "+500", Amount: 500},
+		},
+	}, result.Regional)
+	// The quote is of the regional price
+	require.NotNil(t, result.Quote)
+	assert.Equal(t, float32(17000), result.Quote.BasePrice)
+	assert.Equal(t, 272000000.0, result.Quote.Price)
+}
+
+func TestPredictHandler_RegionErrors(t *testing.T) {
+	resp, problem := postProblemURL(t, "/predict?region=us", WithRegionalRules(testRules(t)))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, []domain.Violation{{Field: "region", Code: domain.CodeValidationFailed, Message: "must be one of: id"}}, problem.Violations)
+
+	resp, problem = postProblemURL(t, "/predict?region=id")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, []domain.Violation{{Field: "region", Code: domain.CodeValidationFailed, Message: "regional rules are not enabled"}}, problem.Violations)
+}
+
+// postProblemURL posts validInput to path on a router with opts and decodes the problem response.
+func postProblemURL(t *testing.T, path string, opts ...Option) (*http.Response, domain.Problem) {
+	server := setupPricingTestServer(t, opts...)
+	resp := doRequest(t, http.MethodPost, server.URL+path, "", validInput())
+	var problem domain.Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	return resp, problem
+}
+
+func TestPredictBatchHandler_Region(t *testing.T) {
+	server := setupPricingTestServer(t, WithRegionalRules(testRules(t)))
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/predict/batch?region=id", "", domain.BatchInput{
+		Inputs: []domain.UserInput{validInput()},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var batch domain.BatchResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+	require.NotNil(t, batch.Results[0].Result.Regional)
+	assert.Equal(t, float32(15500), batch.Results[0].Result.Regional.Price)
+	assert.Len(t, batch.Results[0].Result.Regional.LineItems, 1)
+}
+
+func TestRegionsHandler(t *testing.T) {
+	server := setupPricingTestServer(t, WithRegionalRules(testRules(t)))
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/v1/regions", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var body RegionsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "2025-10-01", body.Version)
+	require.Len(t, body.Regions, 1)
+	assert.Equal(t, "id", body.Regions[0].ID)
+	assert.Equal(t, "Indonesia", body.Regions[0].Name)
+	assert.Equal(t, "brand = porsche", body.Regions[0].Rules[0].When)
+
+	// Without rules the endpoint does not exist
+	resp = doRequest(t, http.MethodGet, setupPricingTestServer(t).URL+"/v1/regions", "", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
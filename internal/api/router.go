@@ -1,23 +1,250 @@
 package api
 
 import (
+	"car-price-prediction/internal/auth"
+	"car-price-prediction/internal/catalog"
+	"car-price-prediction/internal/depreciation"
 	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/drift"
+	"car-price-prediction/internal/feedback"
+	"car-price-prediction/internal/imputation"
+	"car-price-prediction/internal/prediction"
+	"car-price-prediction/internal/predlog"
+	"car-price-prediction/internal/pricing"
+	"car-price-prediction/internal/rules"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// DefaultPredictionTimeout is how long a single prediction may take unless configured otherwise.
+const DefaultPredictionTimeout = 10 * time.Second
+
+// Option configures optional router behaviour.
+type Option func(*options)
+
+// options holds the router configuration assembled from Options.
+type options struct {
+	predictionTimeout time.Duration
+	auth              *auth.Authenticator
+	metrics           prometheus.Gatherer
+	predictions       *predlog.Recorder
+	feedback          *feedback.Tracker
+	drift             *drift.Monitor
+	catalog           *catalog.Catalog
+	imputation        *imputation.Table
+	pricer            *pricing.Pricer
+	rules             *rules.Engine
+	depreciation      *depreciation.Curves
+	models            *prediction.Registry
+}
+
+// WithPredictionTimeout limits how long a single prediction may take before the
+// request fails with a TIMEOUT error.
+func WithPredictionTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.predictionTimeout = timeout
+	}
+}
+
+// WithAuth requires valid credentials on every prediction endpoint and checks that
+// they grant the endpoint's scope: predict:read for /predict, /predict/batch,
+// /v1/predict/depreciation, /v1/catalog and /v1/regions, explain:read for /explain,
+// feedback:write for /v1/feedback and models:admin for /v1/models, /v1/accuracy and
+// /v1/monitoring/drift. API keys additionally get their rate limit and quota
+// enforced and enable the /v1/usage endpoint.
+func WithAuth(authenticator *auth.Authenticator) Option {
+	return func(o *options) {
+		o.auth = authenticator
+	}
+}
+
+// WithMetrics exposes the metrics collected by gatherer at GET /metrics in the
+// Prometheus text format.
+func WithMetrics(gatherer prometheus.Gatherer) Option {
+	return func(o *options) {
+		o.metrics = gatherer
+	}
+}
+
+// WithPredictionLog records every prediction returned by /predict,
+// /predict/batch and /v1/predict/depreciation and enables the
+// /v1/predictions/{id} lookup.
+func WithPredictionLog(recorder *predlog.Recorder) Option {
+	return func(o *options) {
+		o.predictions = recorder
+	}
+}
+
+// WithFeedback accepts actual sale prices at /v1/feedback and reports the accuracy
+// they imply at /v1/accuracy.
+func WithFeedback(tracker *feedback.Tracker) Option {
+	return func(o *options) {
+		o.feedback = tracker
+	}
+}
+
+// WithDriftMonitor compares the inputs of /predict and /predict/batch with the
+// training data and reports their drift at /v1/monitoring/drift.
+func WithDriftMonitor(monitor *drift.Monitor) Option {
+	return func(o *options) {
+		o.drift = monitor
+	}
+}
+
+// WithCatalog serves catalog searches at /v1/catalog and lets /predict,
+// /predict/batch and /explain inputs name a catalog_id whose spec fills in the
+// fields they leave out.
+func WithCatalog(cat *catalog.Catalog) Option {
+	return func(o *options) {
+		o.catalog = cat
+	}
+}
+
+// WithImputation lets /predict, /predict/batch and /explain inputs that set
+// "impute": true leave out fields, which are filled in from table.
+func WithImputation(table *imputation.Table) Option {
+	return func(o *options) {
+		o.imputation = table
+	}
+}
+
+// WithPricing lets /predict and /predict/batch requests ask for prices quoted
+// in another currency and year with the currency and year query parameters.
+func WithPricing(pricer *pricing.Pricer) Option {
+	return func(o *options) {
+		o.pricer = pricer
+	}
+}
+
+// WithRegionalRules lets /predict and /predict/batch requests ask for prices
+// adjusted by the rules of a region with the region query parameter, and lists
+// the regions at /v1/regions.
+func WithRegionalRules(engine *rules.Engine) Option {
+	return func(o *options) {
+		o.rules = engine
+	}
+}
+
+// WithDepreciation projects the value of used cars over curves at
+// /v1/predict/depreciation.
+func WithDepreciation(curves *depreciation.Curves) Option {
+	return func(o *options) {
+		o.depreciation = curves
+	}
+}
+
+// WithModels lets prediction requests select a model of registry with the
+// X-Model header instead of the default model, and lists them at /v1/models.
+func WithModels(registry *prediction.Registry) Option {
+	return func(o *options) {
+		o.models = registry
+	}
+}
+
 // SetupRouter configures the Gin router and defines the API endpoints.
-func SetupRouter(service domain.PredictionService) *gin.Engine {
+func SetupRouter(service domain.PredictionService, opts ...Option) *gin.Engine {
+	o := options{predictionTimeout: DefaultPredictionTimeout}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	// Create a new Gin router with default middleware.
 	r := gin.Default()
+	r.Use(RequestID())
+
+	// Prediction endpoints require credentials when authentication is enabled.
+	protected := r.Group("/")
+	if o.auth != nil {
+		protected.Use(Authenticate(o.auth))
+	}
+	if o.predictions != nil {
+		protected.Use(PredictionLog(o.predictions))
+	}
+	if o.drift != nil {
+		protected.Use(DriftMonitor(o.drift))
+	}
+	if o.catalog != nil {
+		protected.Use(Catalog(o.catalog))
+	}
+	if o.imputation != nil {
+		protected.Use(Imputer(o.imputation))
+	}
+	if o.pricer != nil {
+		protected.Use(Pricing(o.pricer))
+	}
+	if o.rules != nil {
+		protected.Use(Rules(o.rules))
+	}
+	if o.models != nil {
+		protected.Use(Models(o.models))
+	}
+
+	// Define the /predict endpoints.
+	protected.POST("/predict", RequireScope(o.auth, auth.ScopePredictRead), PredictHandler(service, o.predictionTimeout))
+	protected.POST("/predict/batch", RequireScope(o.auth, auth.ScopePredictRead), PredictBatchHandler(service, o.predictionTimeout))
+
+	// Define the /v1/predict/depreciation endpoint.
+	if o.depreciation != nil {
+		protected.POST("/v1/predict/depreciation", RequireScope(o.auth, auth.ScopePredictRead), DepreciationHandler(service, o.depreciation, o.predictionTimeout))
+	}
+
+	// Define the /explain endpoint.
+	protected.POST("/explain", RequireScope(o.auth, auth.ScopeExplainRead), ExplainHandler(service, o.predictionTimeout))
+
+	// Define the /v1/catalog endpoint.
+	if o.catalog != nil {
+		protected.GET("/v1/catalog", RequireScope(o.auth, auth.ScopePredictRead), CatalogHandler(o.catalog))
+	}
+
+	// Define the /v1/regions endpoint.
+	if o.rules != nil {
+		protected.GET("/v1/regions", RequireScope(o.auth, auth.ScopePredictRead), RegionsHandler(o.rules))
+	}
+
+	// Define the /v1/usage endpoint.
+	if o.auth != nil && o.auth.APIKeys != nil {
+		protected.GET("/v1/usage", UsageHandler(o.auth.APIKeys))
+	}
+
+	// Define the /v1/predictions endpoint.
+	if o.predictions != nil {
+		protected.GET("/v1/predictions/:id", RequireScope(o.auth, auth.ScopePredictRead), PredictionLookupHandler(o.predictions, o.predictionTimeout))
+	}
+
+	// Define the /v1/feedback and /v1/accuracy endpoints.
+	if o.feedback != nil {
+		protected.POST("/v1/feedback", RequireScope(o.auth, auth.ScopeFeedbackWrite), FeedbackHandler(o.feedback, service, o.predictionTimeout))
+		protected.GET("/v1/accuracy", RequireScope(o.auth, auth.ScopeModelsAdmin), AccuracyHandler(o.feedback))
+	}
+
+	// Define the /v1/monitoring/drift endpoint.
+	if o.drift != nil {
+		protected.GET("/v1/monitoring/drift", RequireScope(o.auth, auth.ScopeModelsAdmin), DriftHandler(o.drift))
+	}
+
+	// Define the /v1/models endpoints for services whose model can be reloaded.
+	if manager, ok := service.(domain.ModelManager); ok {
+		protected.GET("/v1/models/current", RequireScope(o.auth, auth.ScopeModelsAdmin), ModelHandler(manager))
+		protected.POST("/v1/models/reload", RequireScope(o.auth, auth.ScopeModelsAdmin), ReloadModelHandler(manager))
+	}
+
+	// Define the /v1/models endpoint listing the models requests can select.
+	if o.models != nil {
+		protected.GET("/v1/models", RequireScope(o.auth, auth.ScopeModelsAdmin), ModelsHandler(o.models))
+	}
 
-	// Define the /predict endpoint.
-	r.POST("/predict", PredictHandler(service))
+	// Expose metrics for scraping.
+	if o.metrics != nil {
+		r.GET("/metrics", gin.WrapH(promhttp.HandlerFor(o.metrics, promhttp.HandlerOpts{})))
+	}
 
 	// Add Swagger UI
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	return r
-}
\ No newline at end of file
+}
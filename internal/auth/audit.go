@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"car-price-prediction/internal/domain"
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// AuditEvent records a request that was rejected by authentication or authorization.
+type AuditEvent struct {
+	Time       time.Time        `json:"time"`
+	Event      string           `json:"event"`             // "authentication_failed" or "authorization_failed"
+	Subject    string           `json:"subject,omitempty"` // unverified for authentication failures
+	Credential string           `json:"credential,omitempty"`
+	Method     string           `json:"method"`
+	Resource   string           `json:"resource"`
+	RemoteAddr string           `json:"remote_addr,omitempty"`
+	Code       domain.ErrorCode `json:"code"`
+	Reason     string           `json:"reason"`
+}
+
+// AuditLog writes audit events as JSON lines.
+type AuditLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	now func() time.Time
+}
+
+// NewAuditLog creates an audit log writing to w.
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{enc: json.NewEncoder(w), now: time.Now}
+}
+
+// Record writes e, filling in the time if it is unset. A nil log writes to the
+// standard logger so that rejections are never dropped silently.
+func (l *AuditLog) Record(e AuditEvent) {
+	if l == nil {
+		data, _ := json.Marshal(e)
+		log.Printf("audit: %s", data)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e.Time.IsZero() {
+		e.Time = l.now().UTC()
+	}
+	if err := l.enc.Encode(e); err != nil {
+		log.Printf("Failed to write audit event: %v", err)
+	}
+}
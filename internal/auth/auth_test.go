@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"car-price-prediction/internal/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestManager opens a manager on a temporary directory.
+func openTestManager(t *testing.T) *Manager {
+	m, err := Open(t.TempDir(), time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func TestKeyStore_IssueLookupRevoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	store, err := OpenKeyStore(path)
+	require.NoError(t, err)
+
+	token, key, err := store.Issue(KeyOptions{Name: "Dealer A", RatePerSecond: 5, Burst: 10, MonthlyQuota: 100})
+	require.NoError(t, err)
+	assert.Contains(t, token, tokenPrefix+key.ID+"_")
+
+	// The file stores only the hash of the token
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), token)
+
+	found, err := store.Lookup(token)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "Dealer A", found.Name)
+
+	found, err = store.Lookup(token + "x")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	require.NoError(t, store.Revoke(key.ID))
+	found, err = store.Lookup(token)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestKeyStore_PicksUpChangesFromOtherProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	server, err := OpenKeyStore(path)
+	require.NoError(t, err)
+	cli, err := OpenKeyStore(path)
+	require.NoError(t, err)
+
+	token, _, err := cli.Issue(KeyOptions{Name: "Dealer B"})
+	require.NoError(t, err)
+
+	found, err := server.Lookup(token)
+	require.NoError(t, err)
+	assert.NotNil(t, found)
+}
+
+func TestManager_Authenticate(t *testing.T) {
+	m := openTestManager(t)
+	token, _, err := m.Keys().Issue(KeyOptions{Name: "Dealer A"})
+	require.NoError(t, err)
+
+	principal, err := m.Authenticate(token)
+	require.NoError(t, err)
+	assert.Equal(t, "Dealer A", principal.Name)
+
+	for _, bad := range []string{"", "cpp_nope_nope", "not-a-key"} {
+		_, err := m.Authenticate(bad)
+		var derr *domain.Error
+		require.ErrorAs(t, err, &derr)
+		assert.Equal(t, domain.CodeUnauthorized, derr.Code)
+	}
+}
+
+func TestManager_RateLimit(t *testing.T) {
+	m := openTestManager(t)
+	token, _, err := m.Keys().Issue(KeyOptions{Name: "Dealer A", RatePerSecond: 0.001, Burst: 2})
+	require.NoError(t, err)
+
+	_, err = m.Authenticate(token)
+	require.NoError(t, err)
+	_, err = m.Authenticate(token)
+	require.NoError(t, err)
+
+	_, err = m.Authenticate(token)
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeRateLimited, derr.Code)
+}
+
+func TestManager_QuotaAndUsage(t *testing.T) {
+	m := openTestManager(t)
+	m.now = func() time.Time { return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC) }
+	token, key, err := m.Keys().Issue(KeyOptions{Name: "Dealer A", MonthlyQuota: 10})
+	require.NoError(t, err)
+	principal, err := m.Authenticate(token)
+	require.NoError(t, err)
+
+	require.NoError(t, principal.Reserve(8))
+	principal.Refund(2)
+	require.NoError(t, principal.Reserve(4))
+
+	err = principal.Reserve(1)
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeQuotaExceeded, derr.Code)
+
+	reports, err := m.Usage("2026-10", key.ID)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, int64(10), reports[0].Used)
+	assert.Equal(t, int64(0), *reports[0].Remaining)
+
+	// A new month starts with a fresh quota
+	m.now = func() time.Time { return time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC) }
+	assert.NoError(t, principal.Reserve(1))
+}
+
+func TestManager_UsageIsPersisted(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Open(dir, time.Hour)
+	require.NoError(t, err)
+	token, key, err := m.Keys().Issue(KeyOptions{Name: "Dealer A"})
+	require.NoError(t, err)
+	principal, err := m.Authenticate(token)
+	require.NoError(t, err)
+	require.NoError(t, principal.Reserve(3))
+	require.NoError(t, m.Close())
+
+	reopened, err := Open(dir, time.Hour)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	reports, err := reopened.Usage(reopened.CurrentPeriod(), key.ID)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, int64(3), reports[0].Used)
+	assert.Nil(t, reports[0].Remaining)
+}
+
+func TestPrincipal_NilIsUnlimited(t *testing.T) {
+	var principal *Principal
+
+	assert.NoError(t, principal.Reserve(1000))
+	principal.Refund(1000)
+}
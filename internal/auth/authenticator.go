@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"car-price-prediction/internal/domain"
+	"errors"
+	"fmt"
+)
+
+// Credential types reported in audit events.
+const (
+	credentialAPIKey = "api_key"
+	credentialJWT    = "jwt"
+)
+
+// Authenticator combines the configured authentication methods and audit-logs
+// every rejected request. Either method may be nil.
+type Authenticator struct {
+	// APIKeys authenticates callers by API key.
+	APIKeys *Manager
+	// JWT authenticates callers by bearer token.
+	JWT *JWTVerifier
+	// Audit receives rejected requests. If nil they go to the standard logger.
+	Audit *AuditLog
+}
+
+// Credentials are the credentials presented with a request.
+type Credentials struct {
+	APIKey      string
+	BearerToken string
+}
+
+// Request describes the request being authenticated, for the audit log.
+type Request struct {
+	Method     string // HTTP method, or "gRPC"
+	Resource   string // URL path or full gRPC method name
+	RemoteAddr string
+}
+
+// Authenticate resolves the credentials to a principal. A bearer token takes
+// precedence over an API key. It returns an UNAUTHORIZED or RATE_LIMITED
+// *domain.Error on failure.
+func (a *Authenticator) Authenticate(creds Credentials, req Request) (*Principal, error) {
+	var (
+		principal  *Principal
+		err        error
+		credential string
+		subject    string
+	)
+	switch {
+	case creds.BearerToken != "" && a.JWT != nil:
+		credential = credentialJWT
+		principal, err = a.JWT.Verify(creds.BearerToken)
+		if err != nil {
+			subject = unverifiedSubject(creds.BearerToken)
+		}
+	case creds.APIKey != "" && a.APIKeys != nil:
+		credential = credentialAPIKey
+		principal, err = a.APIKeys.Authenticate(creds.APIKey)
+		if err != nil {
+			subject, _ = parseToken(creds.APIKey)
+		}
+	default:
+		err = domain.NewError(domain.CodeUnauthorized, a.missingCredentialsMessage(), nil)
+	}
+
+	if err != nil {
+		a.reject("authentication_failed", subject, credential, req, err)
+		return nil, err
+	}
+	return principal, nil
+}
+
+// Authorize checks that p was granted scope. It returns a FORBIDDEN *domain.Error
+// otherwise.
+func (a *Authenticator) Authorize(p *Principal, scope string, req Request) error {
+	if p.HasScope(scope) {
+		return nil
+	}
+	err := domain.NewError(domain.CodeForbidden, fmt.Sprintf("The credentials do not grant the %q scope.", scope), nil)
+	subject := ""
+	if p != nil {
+		subject = p.Subject
+	}
+	a.reject("authorization_failed", subject, "", req, err)
+	return err
+}
+
+// JWTEnabled reports whether bearer tokens are accepted.
+func (a *Authenticator) JWTEnabled() bool {
+	return a.JWT != nil
+}
+
+// missingCredentialsMessage tells the client which credentials are accepted.
+func (a *Authenticator) missingCredentialsMessage() string {
+	switch {
+	case a.APIKeys != nil && a.JWT != nil:
+		return "An API key or bearer token is required."
+	case a.JWT != nil:
+		return "A bearer token is required."
+	default:
+		return "An API key is required."
+	}
+}
+
+// reject audit-logs a rejected request.
+func (a *Authenticator) reject(event, subject, credential string, req Request, err error) {
+	e := AuditEvent{
+		Event:      event,
+		Subject:    subject,
+		Credential: credential,
+		Method:     req.Method,
+		Resource:   req.Resource,
+		RemoteAddr: req.RemoteAddr,
+		Reason:     err.Error(),
+	}
+	var derr *domain.Error
+	if errors.As(err, &derr) {
+		e.Code = derr.Code
+	}
+	a.Audit.Record(e)
+}
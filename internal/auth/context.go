@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller, e.g. the API key ID.
+	Subject string
+	// Name is a human-readable name for the caller.
+	Name string
+	// Admin is set for callers that may see the usage of all keys.
+	Admin bool
+	// Scopes lists the operations the caller may perform, e.g. "predict:read".
+	Scopes []string
+
+	key     *Key
+	manager *Manager
+}
+
+// HasScope reports whether the caller was granted scope.
+func (p *Principal) HasScope(scope string) bool {
+	return p != nil && slices.Contains(p.Scopes, scope)
+}
+
+// Reserve charges n predictions against the caller's monthly quota. It returns a
+// QUOTA_EXCEEDED *domain.Error if the quota does not cover all of them.
+func (p *Principal) Reserve(n int) error {
+	if p == nil || p.key == nil {
+		return nil
+	}
+	return p.manager.reserve(p.key, n)
+}
+
+// Refund returns n reserved but unused predictions, e.g. failed batch rows.
+func (p *Principal) Refund(n int) {
+	if p == nil || p.key == nil {
+		return
+	}
+	p.manager.refund(p.key, n)
+}
+
+// contextKey is the type of context keys defined in this package.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, or nil if the request is not authenticated.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(contextKey{}).(*Principal)
+	return p
+}
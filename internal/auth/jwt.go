@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"car-price-prediction/internal/domain"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtLeeway tolerates clock skew between the issuer and this service.
+const jwtLeeway = 30 * time.Second
+
+// jwtMethods lists the accepted signing algorithms. HMAC is excluded because the
+// service only holds the issuer's public keys.
+var jwtMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// JWTOptions configures which tokens a JWTVerifier accepts.
+type JWTOptions struct {
+	// Issuer is the required "iss" claim. Empty accepts any issuer.
+	Issuer string
+	// Audience is a required entry of the "aud" claim. Empty accepts any audience.
+	Audience string
+}
+
+// JWTVerifier authenticates callers by OIDC access tokens signed by a key in a JWKS.
+type JWTVerifier struct {
+	keys   *JWKS
+	parser *jwt.Parser
+}
+
+// tokenClaims are the claims read from an access token. Scopes are taken from the
+// space-separated "scope" claim (RFC 8693) or the "scp" list used by some issuers.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Name  string     `json:"name,omitempty"`
+	Scope scopeClaim `json:"scope,omitempty"`
+	Scp   scopeClaim `json:"scp,omitempty"`
+}
+
+// scopeClaim decodes a scope claim given either as a space-separated string or as
+// a list of strings.
+type scopeClaim []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *scopeClaim) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*s = list
+		return nil
+	}
+	var joined string
+	if err := json.Unmarshal(data, &joined); err != nil {
+		return errors.New("scope claim must be a string or a list of strings")
+	}
+	*s = strings.Fields(joined)
+	return nil
+}
+
+// NewJWTVerifier creates a verifier that checks token signatures against keys.
+func NewJWTVerifier(keys *JWKS, opts JWTOptions) *JWTVerifier {
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(jwtMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+	return &JWTVerifier{keys: keys, parser: jwt.NewParser(parserOpts...)}
+}
+
+// Verify validates token and returns the principal it identifies. Callers granted
+// ScopeModelsAdmin are treated as admins. It returns an UNAUTHORIZED *domain.Error
+// if the token is malformed, expired, not yet valid or not signed by a known key.
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	var claims tokenClaims
+	_, err := v.parser.ParseWithClaims(token, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(kid)
+	})
+	if err != nil {
+		return nil, domain.NewError(domain.CodeUnauthorized, "The bearer token is invalid or has expired.", err)
+	}
+	if claims.Subject == "" {
+		return nil, domain.NewError(domain.CodeUnauthorized, "The bearer token has no subject.", nil)
+	}
+
+	scopes := append(claims.Scope, claims.Scp...)
+	principal := &Principal{Subject: claims.Subject, Name: claims.Name, Scopes: scopes}
+	principal.Admin = principal.HasScope(ScopeModelsAdmin)
+	if principal.Name == "" {
+		principal.Name = claims.Subject
+	}
+	return principal, nil
+}
+
+// unverifiedSubject returns the "sub" claim of token without checking its
+// signature. It is only used to attribute rejected requests in the audit log.
+func unverifiedSubject(token string) string {
+	var claims jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil {
+		return ""
+	}
+	return claims.Subject
+}
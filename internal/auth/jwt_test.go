@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"bytes"
+	"car-price-prediction/internal/auth/authtest"
+	"car-price-prediction/internal/domain"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestVerifier creates a verifier trusting a stub issuer.
+func newTestVerifier(t *testing.T) (*JWTVerifier, *authtest.Issuer) {
+	iss := authtest.NewIssuer(t)
+	keys, err := OpenJWKS(iss.JWKSPath)
+	require.NoError(t, err)
+	return NewJWTVerifier(keys, JWTOptions{Issuer: iss.URL, Audience: iss.Audience}), iss
+}
+
+func TestJWTVerifier_Verify(t *testing.T) {
+	v, iss := newTestVerifier(t)
+
+	principal, err := v.Verify(iss.Token(t, "alice", ScopePredictRead, ScopeExplainRead))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", principal.Subject)
+	assert.True(t, principal.HasScope(ScopePredictRead))
+	assert.True(t, principal.HasScope(ScopeExplainRead))
+	assert.False(t, principal.Admin)
+
+	// Scopes may also be given as an "scp" list; models:admin makes the caller an admin
+	principal, err = v.Verify(iss.Sign(t, jwt.MapClaims{
+		"iss": iss.URL,
+		"aud": iss.Audience,
+		"sub": "ops",
+		"exp": time.Now().Add(time.Minute).Unix(),
+		"scp": []string{ScopeModelsAdmin},
+	}))
+	require.NoError(t, err)
+	assert.True(t, principal.HasScope(ScopeModelsAdmin))
+	assert.True(t, principal.Admin)
+}
+
+func TestJWTVerifier_Rejects(t *testing.T) {
+	v, iss := newTestVerifier(t)
+	other := authtest.NewIssuer(t)
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{"iss": iss.URL, "aud": iss.Audience, "sub": "alice", "exp": time.Now().Add(time.Minute).Unix()}
+	}
+	with := func(key string, value any) jwt.MapClaims {
+		claims := valid()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, valid()).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	tokens := map[string]string{
+		"malformed":      "not-a-jwt",
+		"expired":        iss.Sign(t, with("exp", time.Now().Add(-time.Hour).Unix())),
+		"no expiry":      iss.Sign(t, with("exp", nil)),
+		"not yet valid":  iss.Sign(t, with("nbf", time.Now().Add(time.Hour).Unix())),
+		"wrong issuer":   iss.Sign(t, with("iss", "https://evil.test")),
+		"wrong audience": iss.Sign(t, with("aud", "other-service")),
+		"no subject":     iss.Sign(t, with("sub", nil)),
+		"unknown key":    other.Token(t, "alice", ScopePredictRead),
+		"hmac":           hmac,
+	}
+	for name, token := range tokens {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Verify(token)
+			var derr *domain.Error
+			require.ErrorAs(t, err, &derr)
+			assert.Equal(t, domain.CodeUnauthorized, derr.Code)
+		})
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	_, err := parseJWKS([]byte(`{"keys":[{"kty":"RSA","use":"enc","n":"AQAB","e":"AQAB"}]}`))
+	assert.ErrorContains(t, err, "no signing keys")
+
+	_, err = parseJWKS([]byte(`{"keys":[{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}]}`))
+	assert.ErrorContains(t, err, "not on the curve")
+
+	keys, err := parseJWKS([]byte(`{"keys":[{"kty":"OKP","crv":"Ed25519","kid":"ed","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},{"kty":"oct","k":"c2VjcmV0"}]}`))
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
+
+func TestAuthenticator_AuditsRejections(t *testing.T) {
+	v, iss := newTestVerifier(t)
+	var buf bytes.Buffer
+	a := &Authenticator{APIKeys: openTestManager(t), JWT: v, Audit: NewAuditLog(&buf)}
+	req := Request{Method: "POST", Resource: "/predict", RemoteAddr: "10.0.0.1"}
+
+	// An expired token is attributed to its (unverified) subject
+	expired := iss.Sign(t, jwt.MapClaims{"iss": iss.URL, "aud": iss.Audience, "sub": "mallory", "exp": time.Now().Add(-time.Hour).Unix()})
+	_, err := a.Authenticate(Credentials{BearerToken: expired}, req)
+	require.Error(t, err)
+
+	// A valid token without the required scope is forbidden
+	principal, err := a.Authenticate(Credentials{BearerToken: iss.Token(t, "bob", ScopeExplainRead)}, req)
+	require.NoError(t, err)
+	err = a.Authorize(principal, ScopePredictRead, req)
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeForbidden, derr.Code)
+
+	// Missing credentials
+	_, err = a.Authenticate(Credentials{}, req)
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, "An API key or bearer token is required.", derr.Message)
+
+	var events []AuditEvent
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e AuditEvent
+		require.NoError(t, dec.Decode(&e))
+		events = append(events, e)
+	}
+	require.Len(t, events, 3)
+	assert.Equal(t, "authentication_failed", events[0].Event)
+	assert.Equal(t, "mallory", events[0].Subject)
+	assert.Equal(t, credentialJWT, events[0].Credential)
+	assert.Equal(t, domain.CodeUnauthorized, events[0].Code)
+	assert.Equal(t, "authorization_failed", events[1].Event)
+	assert.Equal(t, "bob", events[1].Subject)
+	assert.Equal(t, domain.CodeForbidden, events[1].Code)
+	assert.Equal(t, "/predict", events[1].Resource)
+	assert.Equal(t, "10.0.0.1", events[1].RemoteAddr)
+	assert.Empty(t, events[2].Subject)
+}
+
+func TestManager_KeyScopes(t *testing.T) {
+	m := openTestManager(t)
+	token, _, err := m.Keys().Issue(KeyOptions{Name: "Dealer A"})
+	require.NoError(t, err)
+	adminToken, _, err := m.Keys().Issue(KeyOptions{Name: "Ops", Admin: true})
+	require.NoError(t, err)
+
+	principal, err := m.Authenticate(token)
+	require.NoError(t, err)
+	assert.True(t, principal.HasScope(ScopePredictRead))
+	assert.True(t, principal.HasScope(ScopeExplainRead))
+	assert.False(t, principal.HasScope(ScopeModelsAdmin))
+
+	admin, err := m.Authenticate(adminToken)
+	require.NoError(t, err)
+	assert.True(t, admin.HasScope(ScopeModelsAdmin))
+}
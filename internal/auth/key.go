@@ -0,0 +1,84 @@
+// Package auth implements API key authentication with per-key rate limits,
+// monthly prediction quotas and usage accounting, JWT bearer token validation
+// against a JWKS, scope-based authorization and an audit log of rejected requests.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// tokenPrefix starts every API key so that keys are easy to recognise in logs and secret scanners.
+const tokenPrefix = "cpp_"
+
+// Key is an issued API key. Only a hash of the secret part is stored.
+type Key struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Hash          string     `json:"hash"`
+	RatePerSecond float64    `json:"rate_per_second"`
+	Burst         int        `json:"burst"`
+	MonthlyQuota  int64      `json:"monthly_quota"` // 0 means unlimited
+	Admin         bool       `json:"admin"`
+	CreatedAt     time.Time  `json:"created_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+}
+
+// KeyOptions holds the limits for a new API key.
+type KeyOptions struct {
+	Name          string
+	RatePerSecond float64
+	Burst         int
+	MonthlyQuota  int64
+	Admin         bool
+}
+
+// Revoked reports whether the key has been revoked.
+func (k *Key) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// newToken generates a key ID and the full token handed to the client.
+// The token has the form cpp_<id>_<secret>.
+func newToken() (id, token string, err error) {
+	idBytes := make([]byte, 6)
+	secretBytes := make([]byte, 24)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate key id: %w", err)
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate key secret: %w", err)
+	}
+	id = hex.EncodeToString(idBytes)
+	return id, tokenPrefix + id + "_" + hex.EncodeToString(secretBytes), nil
+}
+
+// parseToken extracts the key ID from a token.
+func parseToken(token string) (id string, ok bool) {
+	rest, found := strings.CutPrefix(token, tokenPrefix)
+	if !found {
+		return "", false
+	}
+	id, secret, found := strings.Cut(rest, "_")
+	if !found || id == "" || secret == "" {
+		return "", false
+	}
+	return id, true
+}
+
+// hashToken returns the stored representation of a token. Tokens are long random
+// strings, so a single SHA-256 is sufficient; no salt or key stretching is needed.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// matches reports whether token belongs to the key, in constant time.
+func (k *Key) matches(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(k.Hash), []byte(hashToken(token))) == 1
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"car-price-prediction/internal/domain"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Manager authenticates API keys and enforces their rate limits and quotas.
+type Manager struct {
+	keys  *KeyStore
+	usage *UsageLedger
+	now   func() time.Time
+
+	mu       sync.Mutex
+	limiters map[string]*keyLimiter
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// keyLimiter is the token bucket of a key together with the settings it was built from.
+type keyLimiter struct {
+	limiter *rate.Limiter
+	rps     float64
+	burst   int
+}
+
+// UsageReport describes the consumption of a key in a billing period.
+type UsageReport struct {
+	KeyID        string `json:"key_id"`
+	Name         string `json:"name"`
+	Period       string `json:"period"`
+	Used         int64  `json:"used"`
+	MonthlyQuota int64  `json:"monthly_quota"`       // 0 means unlimited
+	Remaining    *int64 `json:"remaining,omitempty"` // omitted for unlimited keys
+	Revoked      bool   `json:"revoked"`
+}
+
+// Open opens the key and usage files in dir and starts flushing usage to disk every
+// flushInterval. Close must be called to stop flushing and write the final counters.
+func Open(dir string, flushInterval time.Duration) (*Manager, error) {
+	keys, err := OpenKeyStore(filepath.Join(dir, "keys.json"))
+	if err != nil {
+		return nil, err
+	}
+	usage, err := OpenUsageLedger(filepath.Join(dir, "usage.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	m := NewManager(keys, usage)
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.flushLoop(flushInterval)
+	return m, nil
+}
+
+// NewManager creates a manager from an existing key store and usage ledger. The
+// caller is responsible for flushing the ledger.
+func NewManager(keys *KeyStore, usage *UsageLedger) *Manager {
+	return &Manager{
+		keys:     keys,
+		usage:    usage,
+		now:      time.Now,
+		limiters: map[string]*keyLimiter{},
+	}
+}
+
+// Keys returns the underlying key store.
+func (m *Manager) Keys() *KeyStore {
+	return m.keys
+}
+
+// Authenticate resolves token to a principal and takes one request from the key's
+// rate limit. It returns an UNAUTHORIZED or RATE_LIMITED *domain.Error on failure.
+func (m *Manager) Authenticate(token string) (*Principal, error) {
+	if token == "" {
+		return nil, domain.NewError(domain.CodeUnauthorized, "An API key is required.", nil)
+	}
+	key, err := m.keys.Lookup(token)
+	if err != nil {
+		return nil, domain.NewError(domain.CodeUnauthorized, "The API key could not be verified.", err)
+	}
+	if key == nil {
+		return nil, domain.NewError(domain.CodeUnauthorized, "The API key is invalid or has been revoked.", nil)
+	}
+
+	if !m.limiter(key).Allow() {
+		return nil, domain.NewError(domain.CodeRateLimited, "The rate limit for this API key has been exceeded.", nil)
+	}
+
+	return &Principal{Subject: key.ID, Name: key.Name, Admin: key.Admin, Scopes: keyScopes(key), key: key, manager: m}, nil
+}
+
+// limiter returns the token bucket for key, rebuilding it if the key's limits changed.
+func (m *Manager) limiter(key *Key) *rate.Limiter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.limiters[key.ID]
+	if !ok || l.rps != key.RatePerSecond || l.burst != key.Burst {
+		limit := rate.Limit(key.RatePerSecond)
+		if key.RatePerSecond <= 0 {
+			limit = rate.Inf
+		}
+		burst := key.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		l = &keyLimiter{limiter: rate.NewLimiter(limit, burst), rps: key.RatePerSecond, burst: key.Burst}
+		m.limiters[key.ID] = l
+	}
+	return l.limiter
+}
+
+// reserve charges n predictions against key's quota for the current period.
+func (m *Manager) reserve(key *Key, n int) error {
+	if !m.usage.Reserve(key.ID, Period(m.now()), int64(n), key.MonthlyQuota) {
+		return domain.NewError(domain.CodeQuotaExceeded, fmt.Sprintf("The monthly quota of %d predictions for this API key has been exceeded.", key.MonthlyQuota), nil)
+	}
+	return nil
+}
+
+// refund returns n unused predictions to key's quota for the current period.
+func (m *Manager) refund(key *Key, n int) {
+	m.usage.Refund(key.ID, Period(m.now()), int64(n))
+}
+
+// Usage reports the consumption of the given keys in period. If keyIDs is empty,
+// all keys are reported.
+func (m *Manager) Usage(period string, keyIDs ...string) ([]UsageReport, error) {
+	keys, err := m.keys.List()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := map[string]bool{}
+	for _, id := range keyIDs {
+		wanted[id] = true
+	}
+
+	reports := []UsageReport{}
+	for _, key := range keys {
+		if len(wanted) > 0 && !wanted[key.ID] {
+			continue
+		}
+		report := UsageReport{
+			KeyID:        key.ID,
+			Name:         key.Name,
+			Period:       period,
+			Used:         m.usage.Used(key.ID, period),
+			MonthlyQuota: key.MonthlyQuota,
+			Revoked:      key.Revoked(),
+		}
+		if key.MonthlyQuota > 0 {
+			remaining := max(key.MonthlyQuota-report.Used, 0)
+			report.Remaining = &remaining
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// CurrentPeriod returns the billing period of the current time.
+func (m *Manager) CurrentPeriod() string {
+	return Period(m.now())
+}
+
+// flushLoop periodically writes usage counters to disk until Close is called.
+func (m *Manager) flushLoop(interval time.Duration) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.usage.Flush(); err != nil {
+				log.Printf("Failed to flush API key usage: %v", err)
+			}
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// Close stops the background flush and writes the final usage counters.
+func (m *Manager) Close() error {
+	if m.stop != nil {
+		close(m.stop)
+		<-m.done
+	}
+	return m.usage.Flush()
+}
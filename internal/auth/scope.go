@@ -0,0 +1,24 @@
+package auth
+
+// Scopes name the operations a caller may perform. JWTs carry them in the "scope"
+// or "scp" claim; API keys are granted a fixed set.
+const (
+	// ScopePredictRead allows single and batch predictions.
+	ScopePredictRead = "predict:read"
+	// ScopeExplainRead allows prediction explanations.
+	ScopeExplainRead = "explain:read"
+	// ScopeFeedbackWrite allows reporting actual sale prices.
+	ScopeFeedbackWrite = "feedback:write"
+	// ScopeModelsAdmin allows managing models, e.g. the model registry and reloads.
+	ScopeModelsAdmin = "models:admin"
+)
+
+// keyScopes returns the scopes granted to an API key. Every key may predict,
+// explain and report sale prices; admin keys may also manage models.
+func keyScopes(key *Key) []string {
+	scopes := []string{ScopePredictRead, ScopeExplainRead, ScopeFeedbackWrite}
+	if key.Admin {
+		scopes = append(scopes, ScopeModelsAdmin)
+	}
+	return scopes
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// KeyStore keeps API keys in a JSON file. Changes made to the file by another
+// process (e.g. the apikey command) are picked up on the next lookup.
+type KeyStore struct {
+	path string
+
+	mu      sync.Mutex
+	keys    map[string]*Key
+	modTime time.Time
+	now     func() time.Time
+}
+
+// OpenKeyStore opens the key file at path, creating an empty store if it does not exist.
+func OpenKeyStore(path string) (*KeyStore, error) {
+	s := &KeyStore{path: path, keys: map[string]*Key{}, now: time.Now}
+	if err := s.reloadLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Issue creates a new key and returns the token to hand to the client. The token
+// cannot be recovered later.
+func (s *KeyStore) Issue(opts KeyOptions) (string, *Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reloadLocked(); err != nil {
+		return "", nil, err
+	}
+
+	id, token, err := newToken()
+	if err != nil {
+		return "", nil, err
+	}
+	key := &Key{
+		ID:            id,
+		Name:          opts.Name,
+		Hash:          hashToken(token),
+		RatePerSecond: opts.RatePerSecond,
+		Burst:         opts.Burst,
+		MonthlyQuota:  opts.MonthlyQuota,
+		Admin:         opts.Admin,
+		CreatedAt:     s.now().UTC(),
+	}
+	s.keys[id] = key
+	if err := s.saveLocked(); err != nil {
+		delete(s.keys, id)
+		return "", nil, err
+	}
+	return token, key, nil
+}
+
+// Revoke marks the key with the given ID as revoked.
+func (s *KeyStore) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reloadLocked(); err != nil {
+		return err
+	}
+
+	key, ok := s.keys[id]
+	if !ok {
+		return fmt.Errorf("key %q not found", id)
+	}
+	if key.Revoked() {
+		return nil
+	}
+	revokedAt := s.now().UTC()
+	key.RevokedAt = &revokedAt
+	return s.saveLocked()
+}
+
+// List returns all keys, including revoked ones, ordered by creation time.
+func (s *KeyStore) List() ([]Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reloadLocked(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+// Lookup returns the active key matching token, or nil if there is none.
+func (s *KeyStore) Lookup(token string) (*Key, error) {
+	id, ok := parseToken(token)
+	if !ok {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reloadLocked(); err != nil {
+		return nil, err
+	}
+
+	key, ok := s.keys[id]
+	if !ok || key.Revoked() || !key.matches(token) {
+		return nil, nil
+	}
+	copied := *key
+	return &copied, nil
+}
+
+// reloadLocked reads the key file again if it changed since the last read.
+func (s *KeyStore) reloadLocked() error {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat key file: %w", err)
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read key file: %w", err)
+	}
+	var keys []*Key
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("failed to parse key file: %w", err)
+	}
+
+	s.keys = make(map[string]*Key, len(keys))
+	for _, key := range keys {
+		s.keys[key.ID] = key
+	}
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// saveLocked writes all keys to the key file atomically.
+func (s *KeyStore) saveLocked() error {
+	keys := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode keys: %w", err)
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat key file: %w", err)
+	}
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path, so that
+// readers never see a partially written file. Key files hold secret hashes and are
+// created with owner-only permissions.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// periodLayout formats the billing period (calendar month, UTC) of a usage counter.
+const periodLayout = "2006-01"
+
+// Period returns the billing period containing t, e.g. "2026-10".
+func Period(t time.Time) string {
+	return t.UTC().Format(periodLayout)
+}
+
+// UsageLedger counts predictions per key and billing period and persists the
+// counters to a JSON file.
+type UsageLedger struct {
+	path string
+
+	mu     sync.Mutex
+	counts map[string]map[string]int64 // key ID -> period -> predictions
+	dirty  bool
+}
+
+// OpenUsageLedger opens the usage file at path, starting empty if it does not exist.
+func OpenUsageLedger(path string) (*UsageLedger, error) {
+	l := &UsageLedger{path: path, counts: map[string]map[string]int64{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage file: %w", err)
+	}
+	if err := json.Unmarshal(data, &l.counts); err != nil {
+		return nil, fmt.Errorf("failed to parse usage file: %w", err)
+	}
+	return l, nil
+}
+
+// Used returns the number of predictions made with the key in the period.
+func (l *UsageLedger) Used(keyID, period string) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.counts[keyID][period]
+}
+
+// Reserve adds n predictions to the key's counter for the period if that keeps it
+// within quota (0 means unlimited). It reports whether the reservation was made.
+func (l *UsageLedger) Reserve(keyID, period string, n, quota int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	used := l.counts[keyID][period]
+	if quota > 0 && used+n > quota {
+		return false
+	}
+	l.addLocked(keyID, period, n)
+	return true
+}
+
+// Refund removes n previously reserved predictions from the key's counter.
+func (l *UsageLedger) Refund(keyID, period string, n int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.addLocked(keyID, period, -n)
+}
+
+// addLocked adjusts a counter and marks the ledger as needing a flush.
+func (l *UsageLedger) addLocked(keyID, period string, n int64) {
+	if n == 0 {
+		return
+	}
+	periods, ok := l.counts[keyID]
+	if !ok {
+		periods = map[string]int64{}
+		l.counts[keyID] = periods
+	}
+	periods[period] += n
+	l.dirty = true
+}
+
+// Flush writes the counters to disk if they changed since the last flush.
+func (l *UsageLedger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(l.counts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage: %w", err)
+	}
+	if err := writeFileAtomic(l.path, data); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	l.dirty = false
+	return nil
+}
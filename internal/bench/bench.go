@@ -0,0 +1,264 @@
+package bench
+
+import (
+	"bytes"
+	"car-price-prediction/internal/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Target is the inference path under test.
+type Target interface {
+	// Predict prices a batch of inputs.
+	Predict(ctx context.Context, inputs []domain.UserInput) error
+}
+
+// BatchPredictor is implemented by prediction services that price a batch of
+// inputs in a single model run.
+type BatchPredictor interface {
+	PredictBatch(inputs []domain.UserInput) ([]*domain.PredictionResult, error)
+}
+
+// serviceTarget calls a prediction service in process.
+type serviceTarget struct {
+	service domain.PredictionService
+}
+
+// ServiceTarget drives a prediction service in process. Batches go to
+// PredictBatch if the service implements BatchPredictor, and are otherwise
+// predicted one input at a time like the API's batch endpoint.
+func ServiceTarget(service domain.PredictionService) Target {
+	return serviceTarget{service: service}
+}
+
+func (t serviceTarget) Predict(_ context.Context, inputs []domain.UserInput) error {
+	if b, ok := t.service.(BatchPredictor); ok && len(inputs) > 1 {
+		_, err := b.PredictBatch(inputs)
+		return err
+	}
+	for _, input := range inputs {
+		if _, err := t.service.Predict(input); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// httpTarget calls the REST API.
+type httpTarget struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// HTTPTarget drives the REST API at baseURL: single inputs are sent to
+// POST /predict and larger batches to POST /predict/batch. apiKey, if set, is sent
+// in the X-API-Key header.
+func HTTPTarget(baseURL, apiKey string, client *http.Client) Target {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return httpTarget{baseURL: strings.TrimSuffix(baseURL, "/"), apiKey: apiKey, client: client}
+}
+
+func (t httpTarget) Predict(ctx context.Context, inputs []domain.UserInput) error {
+	path, body := "/predict", any(inputs[0])
+	if len(inputs) > 1 {
+		path, body = "/predict/batch", domain.BatchInput{Inputs: inputs}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.apiKey != "" {
+		req.Header.Set("X-API-Key", t.apiKey)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	if len(inputs) == 1 {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	// Rows of a batch fail individually
+	var result domain.BatchResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	for _, item := range result.Results {
+		if item.Error != nil {
+			return fmt.Errorf("batch row failed: %s", item.Error.Code)
+		}
+	}
+	return nil
+}
+
+// Config describes a load test.
+type Config struct {
+	// BatchSize is the number of inputs per request. Defaults to 1.
+	BatchSize int
+	// Concurrency is the number of requests in flight. Defaults to 1.
+	Concurrency int
+	// RPS caps the requests started per second; 0 is unlimited.
+	RPS float64
+	// Duration bounds the test. Requests bounds it by count instead; with both
+	// set, the test stops at whichever comes first.
+	Duration time.Duration
+	Requests int
+	// Seed seeds the input generators.
+	Seed uint64
+}
+
+// Result is the outcome of a load test.
+type Result struct {
+	BatchSize   int           `json:"batch_size"`
+	Concurrency int           `json:"concurrency"`
+	Requests    int           `json:"requests"`
+	Errors      int           `json:"errors"`
+	Elapsed     time.Duration `json:"elapsed"`
+	// Throughput is the number of successful requests per second, and
+	// RowsPerSecond the number of inputs they priced.
+	Throughput    float64 `json:"throughput"`
+	RowsPerSecond float64 `json:"rows_per_second"`
+	// Latency is measured from the start of each successful request.
+	Latency Latency `json:"latency"`
+	// FirstError is an example error, if any request failed.
+	FirstError string `json:"first_error,omitempty"`
+}
+
+// Latency summarizes request latencies.
+type Latency struct {
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+}
+
+// Run load-tests target with inputs from gen until the configured duration or
+// request count is reached, or ctx is done.
+func Run(ctx context.Context, target Target, gen *Generator, cfg Config) (*Result, error) {
+	cfg.BatchSize = max(cfg.BatchSize, 1)
+	cfg.Concurrency = max(cfg.Concurrency, 1)
+	if cfg.Duration <= 0 && cfg.Requests <= 0 {
+		return nil, errors.New("a load test needs a duration or a request count")
+	}
+	if cfg.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+	var limiter *rate.Limiter
+	if cfg.RPS > 0 {
+		limiter = rate.NewLimiter(rate.Limit(cfg.RPS), 1)
+	}
+
+	var (
+		mu        sync.Mutex
+		started   int
+		latencies []time.Duration
+		result    = &Result{BatchSize: cfg.BatchSize, Concurrency: cfg.Concurrency}
+	)
+	// next reserves the next request, or reports that the test is over.
+	next := func() bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if cfg.Requests > 0 && started >= cfg.Requests {
+			return false
+		}
+		started++
+		return true
+	}
+
+	begin := time.Now()
+	var wg sync.WaitGroup
+	for w := range cfg.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gen := gen.Clone(cfg.Seed + uint64(w))
+			for next() {
+				if limiter != nil && limiter.Wait(ctx) != nil {
+					return
+				}
+				inputs := gen.Batch(cfg.BatchSize)
+				start := time.Now()
+				err := target.Predict(ctx, inputs)
+				latency := time.Since(start)
+				// Requests cut off by the end of the test do not count.
+				if err != nil && ctx.Err() != nil {
+					return
+				}
+
+				mu.Lock()
+				result.Requests++
+				if err != nil {
+					result.Errors++
+					if result.FirstError == "" {
+						result.FirstError = err.Error()
+					}
+				} else {
+					latencies = append(latencies, latency)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	result.Elapsed = time.Since(begin)
+	if s := result.Elapsed.Seconds(); s > 0 {
+		result.Throughput = float64(len(latencies)) / s
+		result.RowsPerSecond = result.Throughput * float64(cfg.BatchSize)
+	}
+	result.Latency = summarize(latencies)
+	return result, nil
+}
+
+// summarize computes the latency statistics, with nearest-rank percentiles.
+func summarize(latencies []time.Duration) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+	slices.Sort(latencies)
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	percentile := func(p float64) time.Duration {
+		i := int(math.Ceil(p*float64(len(latencies)))) - 1
+		return latencies[max(i, 0)]
+	}
+	return Latency{
+		Mean: total / time.Duration(len(latencies)),
+		P50:  percentile(0.50),
+		P90:  percentile(0.90),
+		P99:  percentile(0.99),
+		Max:  latencies[len(latencies)-1],
+	}
+}
@@ -0,0 +1,199 @@
+package bench
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/onnxmodel"
+	"car-price-prediction/internal/prediction"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const servedModel = "../../model/best_model.onnx"
+
+// testGenerator generates inputs like the data set fixture.
+func testGenerator(t testing.TB) *Generator {
+	t.Helper()
+	samples, err := dataset.ReadFile("../dataset/testdata/carprice_sample.csv")
+	require.NoError(t, err)
+	gen, err := NewGenerator(samples, DefaultJitter, 1)
+	require.NoError(t, err)
+	return gen
+}
+
+func TestGenerator(t *testing.T) {
+	gen := testGenerator(t)
+	inputs := gen.Batch(500)
+	for _, input := range inputs {
+		require.NoError(t, prediction.Validate(input))
+		for _, f := range numericFields {
+			r := gen.ranges[f.name]
+			v := f.get(&input)
+			assert.True(t, v >= r[0] && v <= r[1], "%s = %v outside %v", f.name, v, r)
+		}
+	}
+	assert.NotEqual(t, inputs[0], inputs[1])
+
+	// Clones with the same seed generate the same inputs
+	assert.Equal(t, gen.Clone(7).Batch(10), gen.Clone(7).Batch(10))
+
+	// Without jitter the training rows are replayed
+	samples := []dataset.Sample{{Input: inputs[0]}}
+	replay, err := NewGenerator(samples, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, inputs[0], replay.Next())
+
+	_, err = NewGenerator(nil, DefaultJitter, 1)
+	assert.Error(t, err)
+}
+
+// countingTarget counts inputs and fails every failEvery-th request.
+type countingTarget struct {
+	requests, inputs atomic.Int64
+	failEvery        int64
+}
+
+func (t *countingTarget) Predict(_ context.Context, inputs []domain.UserInput) error {
+	n := t.requests.Add(1)
+	t.inputs.Add(int64(len(inputs)))
+	if t.failEvery > 0 && n%t.failEvery == 0 {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+func TestRun_Requests(t *testing.T) {
+	target := &countingTarget{failEvery: 10}
+	result, err := Run(context.Background(), target, testGenerator(t), Config{BatchSize: 5, Concurrency: 4, Requests: 100})
+	require.NoError(t, err)
+
+	assert.Equal(t, 100, result.Requests)
+	assert.Equal(t, 10, result.Errors)
+	assert.Equal(t, "boom", result.FirstError)
+	assert.Equal(t, int64(500), target.inputs.Load())
+	assert.Greater(t, result.Throughput, 0.0)
+	assert.InDelta(t, 5*result.Throughput, result.RowsPerSecond, 1e-6)
+	assert.LessOrEqual(t, result.Latency.P50, result.Latency.P99)
+}
+
+func TestRun_RPS(t *testing.T) {
+	target := &countingTarget{}
+	result, err := Run(context.Background(), target, testGenerator(t), Config{Concurrency: 8, RPS: 50, Duration: 300 * time.Millisecond})
+	require.NoError(t, err)
+
+	// One request at once, then one every 20ms
+	assert.LessOrEqual(t, result.Requests, 17)
+	assert.GreaterOrEqual(t, result.Requests, 10)
+
+	_, err = Run(context.Background(), target, testGenerator(t), Config{})
+	assert.Error(t, err)
+}
+
+func TestSummarize(t *testing.T) {
+	var latencies []time.Duration
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, Latency{
+		Mean: 50500 * time.Microsecond,
+		P50:  50 * time.Millisecond,
+		P90:  90 * time.Millisecond,
+		P99:  99 * time.Millisecond,
+		Max:  100 * time.Millisecond,
+	}, summarize(latencies))
+	assert.Equal(t, Latency{}, summarize(nil))
+}
+
+func TestHTTPTarget(t *testing.T) {
+	var paths []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /predict", func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+		var input domain.UserInput
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		json.NewEncoder(w).Encode(domain.PredictionResult{PredictedPrice: 1})
+	})
+	mux.HandleFunc("POST /predict/batch", func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		var batch domain.BatchInput
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		result := domain.BatchResult{Results: make([]domain.BatchItem, len(batch.Inputs))}
+		if batch.Inputs[0].Brand == "tesla" {
+			result.Results[0].Error = &domain.Problem{Code: domain.CodeUnknownCategory}
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	target := HTTPTarget(server.URL+"/", "secret", nil)
+	gen := testGenerator(t)
+	require.NoError(t, target.Predict(context.Background(), gen.Batch(1)))
+	require.NoError(t, target.Predict(context.Background(), gen.Batch(3)))
+	assert.Equal(t, []string{"/predict", "/predict/batch"}, paths)
+
+	batch := gen.Batch(2)
+	batch[0].Brand = "tesla"
+	assert.ErrorContains(t, target.Predict(context.Background(), batch), "UNKNOWN_CATEGORY")
+
+	assert.ErrorContains(t, HTTPTarget(server.URL+"/missing", "", nil).Predict(context.Background(), gen.Batch(1)), "404")
+}
+
+// benchmarkService measures a prediction service on batches of synthetic inputs.
+func benchmarkService(b *testing.B, service domain.PredictionService) {
+	target := ServiceTarget(service)
+	for _, size := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("batch=%d", size), func(b *testing.B) {
+			gen := testGenerator(b)
+			batches := make([][]domain.UserInput, 64)
+			for i := range batches {
+				batches[i] = gen.Batch(size)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := target.Predict(context.Background(), batches[i%len(batches)]); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.N*size)/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
+
+func BenchmarkGo(b *testing.B) {
+	service, err := onnxmodel.OpenService(servedModel, onnxmodel.RuntimeGo, "")
+	require.NoError(b, err)
+	benchmarkService(b, service)
+}
+
+func BenchmarkONNXRuntime(b *testing.B) {
+	benchmarkService(b, onnxService(b, onnxmodel.RuntimeONNXRuntime))
+}
+
+func BenchmarkONNXRuntimePooled(b *testing.B) {
+	benchmarkService(b, onnxService(b, onnxmodel.RuntimeONNXRuntimePooled))
+}
+
+// onnxService serves the model with an onnxruntime runtime, skipping the benchmark
+// without the shared library.
+func onnxService(b *testing.B, runtime string) domain.PredictionService {
+	lib := os.Getenv("ONNXRUNTIME_LIB")
+	if lib == "" {
+		b.Skip("set ONNXRUNTIME_LIB to the onnxruntime shared library to run")
+	}
+	service, err := onnxmodel.OpenService(servedModel, runtime, lib)
+	require.NoError(b, err)
+	return service
+}
@@ -0,0 +1,106 @@
+// Package bench load-tests the inference path: it generates realistic synthetic
+// inputs, drives a prediction service in process or the API over HTTP at a given
+// concurrency and request rate, and reports throughput and latency percentiles.
+package bench
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/domain"
+	"errors"
+	"math"
+	"math/rand/v2"
+)
+
+// DefaultJitter is the standard deviation of the relative noise added to numeric
+// fields, as a fraction of their value.
+const DefaultJitter = 0.05
+
+// Generator draws synthetic inputs from the training distribution. Each input is
+// a training row, chosen at random, whose numeric fields are perturbed by a little
+// noise and clamped to the range seen in training. Resampling whole rows keeps the
+// correlations between fields, such as engine size and horsepower, so the inputs
+// take realistic paths through the model. A Generator is not safe for concurrent
+// use.
+type Generator struct {
+	rows   []domain.UserInput
+	jitter float64
+	ranges map[string][2]float64
+	rng    *rand.Rand
+}
+
+// numericFields reads and writes the numeric fields of an input by name.
+var numericFields = []struct {
+	name string
+	get  func(*domain.UserInput) float64
+	set  func(*domain.UserInput, float64)
+}{
+	{"wheelbase", func(in *domain.UserInput) float64 { return float64(in.Wheelbase) }, func(in *domain.UserInput, v float64) { in.Wheelbase = float32(v) }},
+	{"carlength", func(in *domain.UserInput) float64 { return float64(in.Carlength) }, func(in *domain.UserInput, v float64) { in.Carlength = float32(v) }},
+	{"carwidth", func(in *domain.UserInput) float64 { return float64(in.Carwidth) }, func(in *domain.UserInput, v float64) { in.Carwidth = float32(v) }},
+	{"carheight", func(in *domain.UserInput) float64 { return float64(in.Carheight) }, func(in *domain.UserInput, v float64) { in.Carheight = float32(v) }},
+	{"curbweight", func(in *domain.UserInput) float64 { return float64(in.Curbweight) }, func(in *domain.UserInput, v float64) { in.Curbweight = int(math.Round(v)) }},
+	{"enginesize", func(in *domain.UserInput) float64 { return float64(in.Enginesize) }, func(in *domain.UserInput, v float64) { in.Enginesize = int(math.Round(v)) }},
+	{"boreratio", func(in *domain.UserInput) float64 { return float64(in.Boreratio) }, func(in *domain.UserInput, v float64) { in.Boreratio = float32(v) }},
+	{"stroke", func(in *domain.UserInput) float64 { return float64(in.Stroke) }, func(in *domain.UserInput, v float64) { in.Stroke = float32(v) }},
+	{"compressionratio", func(in *domain.UserInput) float64 { return float64(in.Compressionratio) }, func(in *domain.UserInput, v float64) { in.Compressionratio = float32(v) }},
+	{"horsepower", func(in *domain.UserInput) float64 { return float64(in.Horsepower) }, func(in *domain.UserInput, v float64) { in.Horsepower = int(math.Round(v)) }},
+	{"peakrpm", func(in *domain.UserInput) float64 { return float64(in.Peakrpm) }, func(in *domain.UserInput, v float64) { in.Peakrpm = int(math.Round(v)) }},
+	{"citympg", func(in *domain.UserInput) float64 { return float64(in.Citympg) }, func(in *domain.UserInput, v float64) { in.Citympg = int(math.Round(v)) }},
+	{"highwaympg", func(in *domain.UserInput) float64 { return float64(in.Highwaympg) }, func(in *domain.UserInput, v float64) { in.Highwaympg = int(math.Round(v)) }},
+}
+
+// NewGenerator creates a generator of inputs like the training samples. jitter is
+// the relative noise of numeric fields; 0 replays the training rows unchanged.
+func NewGenerator(samples []dataset.Sample, jitter float64, seed uint64) (*Generator, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("cannot generate inputs without training samples")
+	}
+	g := &Generator{
+		rows:   make([]domain.UserInput, len(samples)),
+		jitter: jitter,
+		ranges: make(map[string][2]float64, len(numericFields)),
+		rng:    rand.New(rand.NewPCG(seed, 0)),
+	}
+	for i, s := range samples {
+		g.rows[i] = s.Input
+	}
+	for _, f := range numericFields {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for i := range g.rows {
+			v := f.get(&g.rows[i])
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		g.ranges[f.name] = [2]float64{lo, hi}
+	}
+	return g, nil
+}
+
+// Clone returns an independent generator over the same rows, for another goroutine.
+func (g *Generator) Clone(seed uint64) *Generator {
+	c := *g
+	c.rng = rand.New(rand.NewPCG(seed, 0))
+	return &c
+}
+
+// Next returns a new synthetic input.
+func (g *Generator) Next() domain.UserInput {
+	input := g.rows[g.rng.IntN(len(g.rows))]
+	if g.jitter == 0 {
+		return input
+	}
+	for _, f := range numericFields {
+		r := g.ranges[f.name]
+		v := f.get(&input) * (1 + g.jitter*g.rng.NormFloat64())
+		f.set(&input, min(max(v, r[0]), r[1]))
+	}
+	return input
+}
+
+// Batch returns n new synthetic inputs.
+func (g *Generator) Batch(n int) []domain.UserInput {
+	inputs := make([]domain.UserInput, n)
+	for i := range inputs {
+		inputs[i] = g.Next()
+	}
+	return inputs
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Backend is a cache shared between service instances, e.g. backed by Redis or
+// memcached. Keys include the model version, so entries of a replaced model are
+// never read again and simply expire.
+type Backend interface {
+	// Get returns the value stored under key and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl. A ttl of zero means no expiry.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// MemoryBackend is an in-process Backend. It stands in for a shared cache in tests
+// and single-instance deployments.
+type MemoryBackend struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+// memoryEntry is a value stored in a MemoryBackend.
+type memoryEntry struct {
+	value   []byte
+	expires time.Time // zero means no expiry
+}
+
+// NewMemoryBackend creates an empty in-process backend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{entries: map[string]memoryEntry{}, now: time.Now}
+}
+
+// Get implements Backend.
+func (b *MemoryBackend) Get(_ context.Context, key string) ([]byte, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry, ok := b.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !entry.expires.IsZero() && !b.now().Before(entry.expires) {
+		delete(b.entries, key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Set implements Backend.
+func (b *MemoryBackend) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry := memoryEntry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expires = b.now().Add(ttl)
+	}
+	b.entries[key] = entry
+	return nil
+}
+
+// Len returns the number of stored entries, including expired ones not yet removed.
+func (b *MemoryBackend) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
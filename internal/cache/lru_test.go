@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[string, int](2, 0)
+	evictions := 0
+	c.onEvict = func() { evictions++ }
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Get("a") // "b" is now the least recently used
+	c.Add("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, 1, evictions)
+}
+
+func TestLRU_TTL(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	c := NewLRU[string, int](10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Add("a", 1)
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRU_Purge(t *testing.T) {
+	c := NewLRU[string, int](10, 0)
+	c.Add("a", 1)
+	c.Add("a", 2)
+	assert.Equal(t, 1, c.Len())
+
+	c.Purge()
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestMemoryBackend_TTL(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	b := NewMemoryBackend()
+	b.now = func() time.Time { return now }
+
+	assert.NoError(t, b.Set(t.Context(), "a", []byte("1"), time.Minute))
+	assert.NoError(t, b.Set(t.Context(), "b", []byte("2"), 0))
+	now = now.Add(time.Hour)
+
+	_, ok, err := b.Get(t.Context(), "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	v, ok, err := b.Get(t.Context(), "b")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("2"), v)
+}
@@ -0,0 +1,57 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Cache tiers reported in the hit metric.
+const (
+	tierLocal  = "local"
+	tierShared = "shared"
+)
+
+// metrics holds the cache's Prometheus metrics.
+type metrics struct {
+	hits          *prometheus.CounterVec
+	misses        prometheus.Counter
+	evictions     prometheus.Counter
+	invalidations prometheus.Counter
+	backendErrors prometheus.Counter
+	entries       prometheus.Gauge
+}
+
+// newMetrics creates the cache metrics and registers them with reg if it is not nil.
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "carprice_prediction_cache_hits_total",
+			Help: "Predictions served from the cache, by tier (local or shared).",
+		}, []string{"tier"}),
+		misses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "carprice_prediction_cache_misses_total",
+			Help: "Predictions not found in any cache tier.",
+		}),
+		evictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "carprice_prediction_cache_evictions_total",
+			Help: "Predictions evicted from the local cache to stay within its size.",
+		}),
+		invalidations: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "carprice_prediction_cache_invalidations_total",
+			Help: "Times the local cache was purged because the model was reloaded.",
+		}),
+		backendErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "carprice_prediction_cache_backend_errors_total",
+			Help: "Failed reads and writes of the shared cache backend.",
+		}),
+		entries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "carprice_prediction_cache_entries",
+			Help: "Predictions currently held in the local cache.",
+		}),
+	}
+	// Initialise both tiers so that they are exported before the first hit.
+	m.hits.WithLabelValues(tierLocal)
+	m.hits.WithLabelValues(tierShared)
+
+	if reg != nil {
+		reg.MustRegister(m.hits, m.misses, m.evictions, m.invalidations, m.backendErrors, m.entries)
+	}
+	return m
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Model is a prediction service whose model version is known, so that cache keys
+// can include it.
+type Model interface {
+	domain.PredictionService
+	domain.ModelManager
+}
+
+// Config configures a caching prediction service.
+type Config struct {
+	// Size bounds the number of predictions cached in process. It must be positive.
+	Size int
+	// TTL is how long a prediction is cached. Zero keeps predictions until evicted.
+	TTL time.Duration
+	// Backend is an optional cache shared between instances, consulted on a local miss.
+	Backend Backend
+	// BackendTimeout bounds each backend call. Defaults to DefaultBackendTimeout.
+	BackendTimeout time.Duration
+	// Registerer receives the cache metrics. Metrics are not exported if nil.
+	Registerer prometheus.Registerer
+}
+
+// DefaultBackendTimeout bounds backend calls unless configured otherwise.
+const DefaultBackendTimeout = 50 * time.Millisecond
+
+// Service caches the predictions of a model, keyed by the model version and the
+// feature vector the input is transformed into. Inputs that differ only in case or
+// category aliases therefore share an entry. Reloading the model through the
+// service purges the local cache.
+type Service struct {
+	model   Model
+	local   *LRU[string, domain.PredictionResult]
+	backend Backend
+	ttl     time.Duration
+	timeout time.Duration
+	metrics *metrics
+}
+
+// Ensure Service implements domain.PredictionService and domain.ModelManager interfaces
+var (
+	_ domain.PredictionService = (*Service)(nil)
+	_ domain.ModelManager      = (*Service)(nil)
+)
+
+// explainingService is a Service whose model also supports explanations.
+// Explanations are not cached.
+type explainingService struct {
+	*Service
+	domain.Explainer
+}
+
+// New wraps model with a cache. The returned service implements domain.Explainer
+// if model does.
+func New(model Model, cfg Config) domain.PredictionService {
+	s := &Service{
+		model:   model,
+		local:   NewLRU[string, domain.PredictionResult](cfg.Size, cfg.TTL),
+		backend: cfg.Backend,
+		ttl:     cfg.TTL,
+		timeout: cfg.BackendTimeout,
+		metrics: newMetrics(cfg.Registerer),
+	}
+	if s.timeout <= 0 {
+		s.timeout = DefaultBackendTimeout
+	}
+	s.local.onEvict = s.metrics.evictions.Inc
+
+	if explainer, ok := model.(domain.Explainer); ok {
+		return &explainingService{Service: s, Explainer: explainer}
+	}
+	return s
+}
+
+// Predict returns the cached prediction for input or computes and caches it.
+// Invalid inputs are rejected before the cache is consulted, as unknown categories
+// would otherwise share the key of the baseline category.
+func (s *Service) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	if err := prediction.Validate(input); err != nil {
+		return nil, err
+	}
+	features, err := prediction.Transform(input)
+	if err != nil {
+		return nil, domain.NewError(domain.CodeInferenceFailed, "The input could not be preprocessed.", err)
+	}
+	key := cacheKey(s.model.Model().Version, features)
+
+	if result, ok := s.local.Get(key); ok {
+		s.metrics.hits.WithLabelValues(tierLocal).Inc()
+		return &result, nil
+	}
+	if result, ok := s.getShared(key); ok {
+		s.metrics.hits.WithLabelValues(tierShared).Inc()
+		s.store(key, *result, false)
+		return result, nil
+	}
+	s.metrics.misses.Inc()
+
+	result, err := s.model.Predict(input)
+	if err != nil {
+		return nil, err
+	}
+	s.store(key, *result, s.backend != nil)
+	return result, nil
+}
+
+// Model describes the model currently serving predictions.
+func (s *Service) Model() domain.ModelInfo {
+	return s.model.Model()
+}
+
+// Reload reloads the model and purges the local cache.
+func (s *Service) Reload() (domain.ModelInfo, error) {
+	info, err := s.model.Reload()
+	if err != nil {
+		return info, err
+	}
+	s.local.Purge()
+	s.metrics.entries.Set(0)
+	s.metrics.invalidations.Inc()
+	return info, nil
+}
+
+// getShared looks key up in the shared backend. Backend failures count as misses.
+func (s *Service) getShared(key string) (*domain.PredictionResult, bool) {
+	if s.backend == nil {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	data, ok, err := s.backend.Get(ctx, key)
+	if err != nil {
+		s.metrics.backendErrors.Inc()
+		log.Printf("Failed to read prediction cache: %v", err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	var result domain.PredictionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		s.metrics.backendErrors.Inc()
+		log.Printf("Failed to decode cached prediction: %v", err)
+		return nil, false
+	}
+	return &result, true
+}
+
+// store caches result locally and, if shared is set, in the backend.
+func (s *Service) store(key string, result domain.PredictionResult, shared bool) {
+	s.local.Add(key, result)
+	s.metrics.entries.Set(float64(s.local.Len()))
+	if !shared {
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	if err := s.backend.Set(ctx, key, data, s.ttl); err != nil {
+		s.metrics.backendErrors.Inc()
+		log.Printf("Failed to write prediction cache: %v", err)
+	}
+}
+
+// cacheKey hashes the model version and the feature vector.
+func cacheKey(version string, features []float32) string {
+	h := sha256.New()
+	h.Write([]byte(version))
+	h.Write([]byte{0})
+	var buf [4]byte
+	for _, f := range features {
+		binary.LittleEndian.PutUint32(buf[:], math.Float32bits(f))
+		h.Write(buf[:])
+	}
+	return "prediction:" + hex.EncodeToString(h.Sum(nil))
+}
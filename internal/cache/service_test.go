@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"car-price-prediction/internal/domain"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingModel prices every car at 100 per horsepower and counts its predictions.
+type countingModel struct {
+	mu      sync.Mutex
+	calls   int
+	version string
+}
+
+func (m *countingModel) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	return &domain.PredictionResult{PredictedPrice: float32(input.Horsepower) * 100}, nil
+}
+
+func (m *countingModel) Model() domain.ModelInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return domain.ModelInfo{Version: m.version}
+}
+
+func (m *countingModel) Reload() (domain.ModelInfo, error) {
+	m.mu.Lock()
+	m.version += "'"
+	m.mu.Unlock()
+	return m.Model(), nil
+}
+
+func (m *countingModel) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+// explainingModel is a countingModel that supports explanations.
+type explainingModel struct {
+	countingModel
+}
+
+func (m *explainingModel) Explain(domain.UserInput) (*domain.Explanation, error) {
+	return &domain.Explanation{}, nil
+}
+
+// failingBackend fails every call.
+type failingBackend struct{}
+
+func (failingBackend) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (failingBackend) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+
+// testInput returns a valid input.
+func testInput() domain.UserInput {
+	return domain.UserInput{
+		Symboling: 1, Wheelbase: 97, Carlength: 173.2, Carwidth: 65.5, Carheight: 54.1,
+		Curbweight: 2414, Enginesize: 120, Boreratio: 3.31, Stroke: 3.29, Compressionratio: 9,
+		Horsepower: 95, Peakrpm: 5200, Citympg: 24, Highwaympg: 30,
+		Fueltype: "gas", Aspiration: "std", Doornumber: "four", Carbody: "sedan", Drivewheel: "fwd",
+		Enginelocation: "front", Enginetype: "ohc", Cylindernumber: "four", Fuelsystem: "mpfi", Brand: "volkswagen",
+	}
+}
+
+func TestService_CachesByFeatureVector(t *testing.T) {
+	model := &countingModel{version: "v1"}
+	reg := prometheus.NewRegistry()
+	s := New(model, Config{Size: 10, Registerer: reg}).(*Service)
+
+	first, err := s.Predict(testInput())
+	require.NoError(t, err)
+
+	// Case and alias differences map to the same entry
+	variant := testInput()
+	variant.Brand = "VW"
+	variant.Carbody = "Sedan"
+	variant.Doornumber = "4"
+	second, err := s.Predict(variant)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, model.Calls())
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.hits.WithLabelValues(tierLocal)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.misses))
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.entries))
+
+	// A different car is a miss
+	other := testInput()
+	other.Horsepower = 120
+	_, err = s.Predict(other)
+	require.NoError(t, err)
+	assert.Equal(t, 2, model.Calls())
+
+	count, err := testutil.GatherAndCount(reg, "carprice_prediction_cache_hits_total")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestService_RejectsInvalidInputBeforeCaching(t *testing.T) {
+	model := &countingModel{version: "v1"}
+	s := New(model, Config{Size: 10})
+	_, err := s.Predict(testInput())
+	require.NoError(t, err)
+
+	// An unknown brand would encode like the baseline brand; it must not hit the cache
+	invalid := testInput()
+	invalid.Brand = "tesla"
+	_, err = s.Predict(invalid)
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeUnknownCategory, derr.Code)
+}
+
+func TestService_SizeBound(t *testing.T) {
+	model := &countingModel{version: "v1"}
+	s := New(model, Config{Size: 2}).(*Service)
+
+	for hp := 100; hp < 105; hp++ {
+		input := testInput()
+		input.Horsepower = hp
+		_, err := s.Predict(input)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, s.local.Len())
+	assert.Equal(t, 3.0, testutil.ToFloat64(s.metrics.evictions))
+}
+
+func TestService_ReloadInvalidates(t *testing.T) {
+	model := &countingModel{version: "v1"}
+	backend := NewMemoryBackend()
+	s := New(model, Config{Size: 10, Backend: backend}).(*Service)
+
+	_, err := s.Predict(testInput())
+	require.NoError(t, err)
+	info, err := s.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, "v1'", info.Version)
+	assert.Equal(t, 0, s.local.Len())
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.invalidations))
+
+	// The shared entry of the old version is not used either
+	_, err = s.Predict(testInput())
+	require.NoError(t, err)
+	assert.Equal(t, 2, model.Calls())
+	assert.Equal(t, 2, backend.Len())
+}
+
+func TestService_SharedBackend(t *testing.T) {
+	backend := NewMemoryBackend()
+	modelA := &countingModel{version: "v1"}
+	modelB := &countingModel{version: "v1"}
+	a := New(modelA, Config{Size: 10, Backend: backend})
+	b := New(modelB, Config{Size: 10, Backend: backend}).(*Service)
+
+	_, err := a.Predict(testInput())
+	require.NoError(t, err)
+	result, err := b.Predict(testInput())
+	require.NoError(t, err)
+
+	assert.Equal(t, float32(9500), result.PredictedPrice)
+	assert.Equal(t, 0, modelB.Calls())
+	assert.Equal(t, 1.0, testutil.ToFloat64(b.metrics.hits.WithLabelValues(tierShared)))
+}
+
+func TestService_BackendFailuresAreMisses(t *testing.T) {
+	model := &countingModel{version: "v1"}
+	s := New(model, Config{Size: 10, Backend: failingBackend{}}).(*Service)
+
+	_, err := s.Predict(testInput())
+	require.NoError(t, err)
+	_, err = s.Predict(testInput())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, model.Calls())
+	assert.Equal(t, 2.0, testutil.ToFloat64(s.metrics.backendErrors))
+}
+
+func TestNew_KeepsExplainer(t *testing.T) {
+	_, ok := New(&countingModel{}, Config{Size: 1}).(domain.Explainer)
+	assert.False(t, ok)
+	_, ok = New(&explainingModel{}, Config{Size: 1}).(domain.Explainer)
+	assert.True(t, ok)
+}
@@ -0,0 +1,227 @@
+// Package catalog looks up the technical specs of known car models, so that a
+// prediction request can name a model and override only the fields it knows.
+package catalog
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"car-price-prediction/internal/validation"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultLimit is the number of search results returned unless asked otherwise.
+const DefaultLimit = 10
+
+// Entry is a car model and its full specification.
+type Entry struct {
+	// ID identifies the entry in prediction requests, e.g. "audi-100ls".
+	// Models listed more than once get a numeric suffix ("toyota-corolla-2").
+	ID string `json:"id" example:"audi-100ls"`
+	// Name is the model name as listed in the data set.
+	Name  string           `json:"name" example:"audi 100ls"`
+	Brand string           `json:"brand" example:"audi"`
+	Spec  domain.UserInput `json:"spec"`
+
+	// terms are the lowercased words of the name and brand that searches match.
+	terms []string
+}
+
+// Catalog is an immutable, searchable set of entries.
+type Catalog struct {
+	entries []*Entry
+	byID    map[string]*Entry
+}
+
+// New builds a catalog from entries. Entries without an ID get one derived from
+// their name, and categorical values are normalized like prediction requests.
+func New(entries []Entry) (*Catalog, error) {
+	c := &Catalog{byID: make(map[string]*Entry, len(entries))}
+	seen := make(map[string]int, len(entries))
+	for i := range entries {
+		e := entries[i]
+		e.Name = strings.TrimSpace(e.Name)
+		if e.Name == "" {
+			return nil, fmt.Errorf("entry %d has no name", i+1)
+		}
+		if e.Spec.Brand == "" {
+			e.Spec.Brand = e.Brand
+		}
+		if e.Spec.Brand == "" {
+			e.Spec.Brand = dataset.Brand(e.Name)
+		}
+		e.Spec = prediction.Normalize(e.Spec)
+		e.Brand = e.Spec.Brand
+
+		e.ID = strings.ToLower(strings.TrimSpace(e.ID))
+		if e.ID == "" {
+			base := slug(e.Name)
+			seen[base]++
+			e.ID = base
+			if n := seen[base]; n > 1 {
+				e.ID = base + "-" + strconv.Itoa(n)
+			}
+		}
+		if _, ok := c.byID[e.ID]; ok {
+			return nil, fmt.Errorf("duplicate catalog id %q", e.ID)
+		}
+		e.terms = append(tokenize(e.Name), e.Brand)
+
+		c.entries = append(c.entries, &e)
+		c.byID[e.ID] = &e
+	}
+	if len(c.entries) == 0 {
+		return nil, errors.New("catalog is empty")
+	}
+	return c, nil
+}
+
+// Load reads a catalog from a CSV file in the format of the CarPrice data set
+// (CarPrice_Assignment.csv), or from a JSON array of entries if the file name
+// ends in .json.
+func Load(path string) (*Catalog, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read catalog: %w", err)
+		}
+		var entries []Entry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse catalog: %w", err)
+		}
+		return New(entries)
+	}
+
+	samples, err := dataset.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, len(samples))
+	for i, s := range samples {
+		entries[i] = Entry{Name: s.Name, Spec: s.Input}
+	}
+	return New(entries)
+}
+
+// Len returns the number of entries.
+func (c *Catalog) Len() int {
+	return len(c.entries)
+}
+
+// Get returns the entry with the given ID.
+func (c *Catalog) Get(id string) (*Entry, bool) {
+	e, ok := c.byID[strings.ToLower(strings.TrimSpace(id))]
+	return e, ok
+}
+
+// Search returns up to limit entries matching query, best matches first. An entry
+// matches if every word of the query starts one of the words of its name or is
+// its brand; brand aliases such as "vw" are understood. Entries named exactly
+// like the query come first, the rest are ordered by name.
+func (c *Catalog) Search(query string, limit int) []*Entry {
+	words := tokenize(query)
+	if len(words) == 0 || limit <= 0 {
+		return nil
+	}
+	exact := strings.Join(words, " ")
+
+	type match struct {
+		entry *Entry
+		exact bool
+	}
+	var matches []match
+	for _, e := range c.entries {
+		if e.matches(words) {
+			matches = append(matches, match{e, strings.Join(tokenize(e.Name), " ") == exact})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int {
+		if a.exact != b.exact {
+			if a.exact {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(strings.ToLower(a.entry.Name), strings.ToLower(b.entry.Name))
+	})
+
+	results := make([]*Entry, 0, min(limit, len(matches)))
+	for _, m := range matches[:min(limit, len(matches))] {
+		results = append(results, m.entry)
+	}
+	return results
+}
+
+// matches reports whether every query word matches one of the entry's terms.
+func (e *Entry) matches(words []string) bool {
+	for _, w := range words {
+		brand := prediction.Normalize(domain.UserInput{Brand: w}).Brand
+		if !slices.ContainsFunc(e.terms, func(term string) bool {
+			return strings.HasPrefix(term, w) || term == brand
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge returns the entry's spec with the fields present in the JSON object
+// overrides replaced, and the JSON names of the fields that kept the catalog
+// value. Fields that are null count as absent; other keys are ignored.
+func (e *Entry) Merge(overrides []byte) (domain.UserInput, []string, error) {
+	defaulted, err := validation.MissingFields(overrides)
+	if err != nil {
+		return domain.UserInput{}, nil, err
+	}
+	input := e.Spec
+	if err := json.Unmarshal(overrides, &input); err != nil {
+		return domain.UserInput{}, nil, err
+	}
+	return input, defaulted, nil
+}
+
+// tokenize splits s into lowercased words at spaces and hyphens.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '\t'
+	})
+}
+
+// slug turns a name into an ID of lowercase letters, digits and hyphens.
+func slug(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the catalog.
+func NewContext(ctx context.Context, c *Catalog) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the catalog stored in ctx, or nil if there is none.
+func FromContext(ctx context.Context) *Catalog {
+	c, _ := ctx.Value(contextKey{}).(*Catalog)
+	return c
+}
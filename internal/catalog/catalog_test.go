@@ -0,0 +1,145 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleData = "../dataset/testdata/carprice_sample.csv"
+
+func names(entries []*Entry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Name)
+	}
+	return out
+}
+
+func TestLoad_CSV(t *testing.T) {
+	c, err := Load(sampleData)
+	require.NoError(t, err)
+	assert.Equal(t, 30, c.Len())
+
+	e, ok := c.Get("audi-100ls")
+	require.True(t, ok)
+	assert.Equal(t, "audi 100ls", e.Name)
+	assert.Equal(t, "audi", e.Brand)
+	assert.Equal(t, "audi", e.Spec.Brand)
+
+	// The second row with the same name gets a suffix
+	second, ok := c.Get("Audi-100LS-2")
+	require.True(t, ok)
+	assert.Equal(t, "audi 100ls", second.Name)
+	assert.NotEqual(t, e.Spec, second.Spec)
+
+	e, ok = c.Get("audi-5000s-diesel")
+	require.True(t, ok)
+	assert.Equal(t, "audi 5000s (diesel)", e.Name)
+
+	// Misspelled brands are normalized
+	e, ok = c.Get("maxda-rx3")
+	require.True(t, ok)
+	assert.Equal(t, "mazda", e.Brand)
+
+	_, ok = c.Get("audi-100ls-3")
+	assert.False(t, ok)
+}
+
+func TestLoad_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"name": "audi 100ls", "spec": {"horsepower": 102, "fueltype": "GAS"}},
+		{"id": "Custom", "name": "vw rabbit", "spec": {"horsepower": 69}}
+	]`), 0o644))
+
+	c, err := Load(path)
+	require.NoError(t, err)
+
+	e, ok := c.Get("audi-100ls")
+	require.True(t, ok)
+	assert.Equal(t, 102, e.Spec.Horsepower)
+	assert.Equal(t, "gas", e.Spec.Fueltype)
+	assert.Equal(t, "audi", e.Brand)
+
+	e, ok = c.Get("custom")
+	require.True(t, ok)
+	assert.Equal(t, "volkswagen", e.Brand)
+}
+
+func TestNew_Errors(t *testing.T) {
+	_, err := New(nil)
+	assert.ErrorContains(t, err, "empty")
+
+	_, err = New([]Entry{{Name: " "}})
+	assert.ErrorContains(t, err, "no name")
+
+	_, err = New([]Entry{{ID: "a", Name: "audi 100ls"}, {ID: "A", Name: "audi 5000"}})
+	assert.ErrorContains(t, err, `duplicate catalog id "a"`)
+}
+
+func TestSearch(t *testing.T) {
+	c, err := Load(sampleData)
+	require.NoError(t, err)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"audi 100ls", []string{"audi 100ls", "audi 100ls"}},
+		{"AUDI", []string{"audi 100 ls", "audi 100ls", "audi 100ls", "audi 4000", "audi 5000", "audi 5000s (diesel)", "audi fox"}},
+		{"bmw 3", []string{"bmw 320i", "bmw 320i"}},
+		{"volkswagen", []string{"vokswagen rabbit", "vw dasher"}},
+		{"vw", []string{"vokswagen rabbit", "vw dasher"}},
+		{"alfa romero", []string{"alfa-romero giulia", "alfa-romero Quadrifoglio", "alfa-romero stelvio"}},
+		{"Nissan", []string{"Nissan versa"}},
+		{"ferrari", nil},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.want, names(c.Search(tt.query, DefaultLimit)))
+		})
+	}
+
+	assert.Len(t, c.Search("audi", 2), 2)
+	assert.Empty(t, c.Search("audi", 0))
+}
+
+func TestSearch_ExactNameFirst(t *testing.T) {
+	c, err := New([]Entry{{Name: "audi 100ls turbo"}, {Name: "audi 100"}, {Name: "audi 100ls"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"audi 100ls", "audi 100ls turbo"}, names(c.Search("audi 100ls", DefaultLimit)))
+	assert.Equal(t, []string{"audi 100", "audi 100ls", "audi 100ls turbo"}, names(c.Search("audi 100", DefaultLimit)))
+}
+
+func TestEntry_Merge(t *testing.T) {
+	c, err := Load(sampleData)
+	require.NoError(t, err)
+	e, ok := c.Get("audi-100ls")
+	require.True(t, ok)
+
+	input, defaulted, err := e.Merge([]byte(`{"catalog_id": "audi-100ls", "horsepower": 150, "carbody": "wagon", "stroke": null}`))
+	require.NoError(t, err)
+
+	want := e.Spec
+	want.Horsepower = 150
+	want.Carbody = "wagon"
+	assert.Equal(t, want, input)
+	assert.NotContains(t, defaulted, "horsepower")
+	assert.NotContains(t, defaulted, "carbody")
+	assert.Contains(t, defaulted, "stroke")
+	assert.Len(t, defaulted, 22)
+
+	// The catalog entry itself is unchanged
+	assert.NotEqual(t, 150, e.Spec.Horsepower)
+
+	_, _, err = e.Merge([]byte(`{"horsepower": "fast"}`))
+	assert.Error(t, err)
+	_, _, err = e.Merge([]byte(`[]`))
+	assert.Error(t, err)
+}
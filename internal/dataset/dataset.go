@@ -0,0 +1,155 @@
+// Package dataset reads the CarPrice training data set (CarPrice_Assignment.csv).
+package dataset
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Sample is a car from the data set and the price it sold for.
+type Sample struct {
+	// Name is the CarName, e.g. "audi 100ls".
+	Name  string
+	Input domain.UserInput
+	Price float64
+}
+
+// columns lists the columns used from the CSV. car_ID is ignored and CarName is
+// reduced to the brand, as in the training notebook.
+var columns = []string{
+	"symboling", "CarName", "fueltype", "aspiration", "doornumber", "carbody",
+	"drivewheel", "enginelocation", "wheelbase", "carlength", "carwidth", "carheight",
+	"curbweight", "enginetype", "cylindernumber", "enginesize", "fuelsystem",
+	"boreratio", "stroke", "compressionratio", "horsepower", "peakrpm", "citympg",
+	"highwaympg", "price",
+}
+
+// ReadFile reads the data set from a CSV file.
+func ReadFile(path string) ([]Sample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data set: %w", err)
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read reads the data set from CSV with a header row. Columns are matched by name,
+// so their order does not matter. Categorical values are normalized the same way
+// as prediction requests, which also fixes the misspelled brands in CarName.
+func Read(r io.Reader) ([]Sample, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data set header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+	for _, name := range columns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("data set has no %q column", name)
+		}
+	}
+
+	var samples []Sample
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read data set: %w", err)
+		}
+		sample, err := parse(record, index)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		samples = append(samples, sample)
+	}
+	if len(samples) == 0 {
+		return nil, errors.New("data set is empty")
+	}
+	return samples, nil
+}
+
+// parse converts a CSV record into a sample.
+func parse(record []string, index map[string]int) (Sample, error) {
+	p := parser{record: record, index: index}
+	input := domain.UserInput{
+		Symboling:        p.int("symboling"),
+		Wheelbase:        p.float32("wheelbase"),
+		Carlength:        p.float32("carlength"),
+		Carwidth:         p.float32("carwidth"),
+		Carheight:        p.float32("carheight"),
+		Curbweight:       p.int("curbweight"),
+		Enginesize:       p.int("enginesize"),
+		Boreratio:        p.float32("boreratio"),
+		Stroke:           p.float32("stroke"),
+		Compressionratio: p.float32("compressionratio"),
+		Horsepower:       p.int("horsepower"),
+		Peakrpm:          p.int("peakrpm"),
+		Citympg:          p.int("citympg"),
+		Highwaympg:       p.int("highwaympg"),
+		Fueltype:         p.string("fueltype"),
+		Aspiration:       p.string("aspiration"),
+		Doornumber:       p.string("doornumber"),
+		Carbody:          p.string("carbody"),
+		Drivewheel:       p.string("drivewheel"),
+		Enginelocation:   p.string("enginelocation"),
+		Enginetype:       p.string("enginetype"),
+		Cylindernumber:   p.string("cylindernumber"),
+		Fuelsystem:       p.string("fuelsystem"),
+		Brand:            Brand(p.string("CarName")),
+	}
+	price := p.float64("price")
+	if p.err != nil {
+		return Sample{}, p.err
+	}
+	return Sample{Name: p.string("CarName"), Input: prediction.Normalize(input), Price: price}, nil
+}
+
+// Brand extracts the brand from a CarName value such as "alfa-romero giulia".
+func Brand(carName string) string {
+	brand, _, _ := strings.Cut(strings.TrimSpace(carName), " ")
+	return brand
+}
+
+// parser reads typed fields from a record, keeping the first error.
+type parser struct {
+	record []string
+	index  map[string]int
+	err    error
+}
+
+func (p *parser) string(name string) string {
+	return strings.TrimSpace(p.record[p.index[name]])
+}
+
+func (p *parser) float64(name string) float64 {
+	v, err := strconv.ParseFloat(p.string(name), 64)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("invalid %s: %q", name, p.string(name))
+	}
+	return v
+}
+
+func (p *parser) float32(name string) float32 {
+	return float32(p.float64(name))
+}
+
+func (p *parser) int(name string) int {
+	v, err := strconv.Atoi(p.string(name))
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("invalid %s: %q", name, p.string(name))
+	}
+	return v
+}
@@ -0,0 +1,52 @@
+package dataset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFile(t *testing.T) {
+	samples, err := ReadFile("testdata/carprice_sample.csv")
+	require.NoError(t, err)
+	require.Len(t, samples, 30)
+
+	first := samples[0]
+	assert.Equal(t, "alfa-romero giulia", first.Name)
+	assert.Equal(t, 3, first.Input.Symboling)
+	assert.Equal(t, "alfa-romero", first.Input.Brand)
+	assert.Equal(t, float32(88.6), first.Input.Wheelbase)
+	assert.Equal(t, 111, first.Input.Horsepower)
+	assert.Equal(t, 13495.0, first.Price)
+
+	// Misspelled and capitalized brands are normalized
+	brands := map[string]bool{}
+	for _, s := range samples {
+		brands[s.Input.Brand] = true
+	}
+	for _, brand := range []string{"mazda", "porsche", "toyota", "volkswagen", "nissan"} {
+		assert.True(t, brands[brand], brand)
+	}
+	assert.False(t, brands["vw"])
+}
+
+func TestRead_Errors(t *testing.T) {
+	_, err := Read(strings.NewReader("car_ID,symboling\n1,3\n"))
+	assert.ErrorContains(t, err, `no "CarName" column`)
+
+	header := strings.Join(append([]string{"car_ID"}, columns...), ",")
+	_, err = Read(strings.NewReader(header + "\n"))
+	assert.ErrorContains(t, err, "empty")
+
+	row := "1,3,audi 100,gas,std,two,sedan,fwd,front,88.6,168.8,64.1,48.8,heavy,ohc,four,130,mpfi,3.47,2.68,9,111,5000,21,27,13495"
+	_, err = Read(strings.NewReader(header + "\n" + row + "\n"))
+	assert.ErrorContains(t, err, "line 2: invalid curbweight")
+}
+
+func TestBrand(t *testing.T) {
+	assert.Equal(t, "alfa-romero", Brand("alfa-romero giulia"))
+	assert.Equal(t, "audi", Brand(" audi 100 ls"))
+	assert.Equal(t, "saab", Brand("saab"))
+}
@@ -0,0 +1,225 @@
+// Package depreciation projects the value of a used car from the price the
+// model predicts for its spec new, its age and its mileage, with depreciation
+// curves configured per brand and body type.
+package depreciation
+
+import (
+	"bytes"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ScheduleYears is the last vehicle age of a projected schedule.
+const ScheduleYears = 10
+
+// BasisDefault is the basis of the default curve.
+const BasisDefault = "default"
+
+//go:embed curves.yaml
+var defaultCurves []byte
+
+// curveSpec is a curve as written in the curve file. Unset fields are taken
+// from the default curve.
+type curveSpec struct {
+	Brand          string   `yaml:"brand"`
+	Carbody        string   `yaml:"carbody"`
+	FirstYear      *float64 `yaml:"first_year"`
+	Annual         *float64 `yaml:"annual"`
+	MileagePerYear *float64 `yaml:"mileage_per_year"`
+	MileageRate    *float64 `yaml:"mileage_rate"`
+	Floor          *float64 `yaml:"floor"`
+}
+
+// curveFile is the format of the curve file.
+type curveFile struct {
+	Default curveSpec   `yaml:"default"`
+	Curves  []curveSpec `yaml:"curves"`
+}
+
+// Curves are the depreciation curves of every brand and body type.
+type Curves struct {
+	// curves are keyed by basis: "brand=bmw,carbody=sedan", "brand=bmw", "carbody=sedan" or "default".
+	curves map[string]domain.DepreciationCurve
+}
+
+// Default returns the bundled curves.
+func Default() *Curves {
+	c, err := Parse(defaultCurves)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Load reads curves from a YAML file in the format of the bundled curves.yaml.
+func Load(path string) (*Curves, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read depreciation curves: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse reads curves from YAML. The default curve must set every field.
+func Parse(data []byte) (*Curves, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var file curveFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse depreciation curves: %w", err)
+	}
+	d := file.Default
+	if d.FirstYear == nil || d.Annual == nil || d.MileagePerYear == nil || d.MileageRate == nil || d.Floor == nil {
+		return nil, errors.New("the default depreciation curve must set first_year, annual, mileage_per_year, mileage_rate and floor")
+	}
+	if d.Brand != "" || d.Carbody != "" {
+		return nil, errors.New("the default depreciation curve cannot have a brand or carbody")
+	}
+
+	base := domain.DepreciationCurve{Basis: BasisDefault}
+	c := &Curves{curves: map[string]domain.DepreciationCurve{}}
+	for i, spec := range append([]curveSpec{d}, file.Curves...) {
+		curve := base
+		if i > 0 {
+			basis, err := spec.basis()
+			if err != nil {
+				return nil, fmt.Errorf("depreciation curve %d: %w", i, err)
+			}
+			if _, ok := c.curves[basis]; ok {
+				return nil, fmt.Errorf("depreciation curve %d: %s is defined twice", i, basis)
+			}
+			curve.Basis = basis
+		}
+		set(&curve.FirstYear, spec.FirstYear)
+		set(&curve.Annual, spec.Annual)
+		set(&curve.MileagePerYear, spec.MileagePerYear)
+		set(&curve.MileageRate, spec.MileageRate)
+		set(&curve.Floor, spec.Floor)
+		if err := check(curve); err != nil {
+			return nil, fmt.Errorf("depreciation curve %s: %w", curve.Basis, err)
+		}
+		if i == 0 {
+			base = curve
+		}
+		c.curves[curve.Basis] = curve
+	}
+	return c, nil
+}
+
+// basis returns the key of a brand or body type curve, checking that it names
+// known categories in their canonical spelling.
+func (s curveSpec) basis() (string, error) {
+	if s.Brand == "" && s.Carbody == "" {
+		return "", errors.New("a curve needs a brand, a carbody or both")
+	}
+	var basis string
+	for _, f := range []struct{ name, value string }{{"brand", s.Brand}, {"carbody", s.Carbody}} {
+		if f.value == "" {
+			continue
+		}
+		v := prediction.NormalizeCategory(f.name, f.value)
+		if !slices.Contains(prediction.Categories(f.name), v) {
+			return "", fmt.Errorf("unknown %s %q", f.name, f.value)
+		}
+		if basis != "" {
+			basis += ","
+		}
+		basis += f.name + "=" + v
+	}
+	return basis, nil
+}
+
+// set copies v to dst if it is set.
+func set(dst *float64, v *float64) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// check validates the parameters of a curve.
+func check(c domain.DepreciationCurve) error {
+	for _, f := range []struct {
+		name  string
+		value float64
+	}{{"first_year", c.FirstYear}, {"annual", c.Annual}, {"mileage_rate", c.MileageRate}, {"floor", c.Floor}} {
+		if f.value < 0 || f.value >= 1 {
+			return fmt.Errorf("%s must be at least 0 and less than 1", f.name)
+		}
+	}
+	if c.MileagePerYear <= 0 {
+		return errors.New("mileage_per_year must be positive")
+	}
+	return nil
+}
+
+// Curve returns the most specific curve for a car: its brand and body type,
+// its brand, its body type, or the default curve.
+func (c *Curves) Curve(input domain.UserInput) domain.DepreciationCurve {
+	input = prediction.Normalize(input)
+	for _, basis := range []string{
+		"brand=" + input.Brand + ",carbody=" + input.Carbody,
+		"brand=" + input.Brand,
+		"carbody=" + input.Carbody,
+	} {
+		if curve, ok := c.curves[basis]; ok {
+			return curve
+		}
+	}
+	return c.curves[BasisDefault]
+}
+
+// Retention returns the fraction of the price new a car of curve keeps at an
+// age in years with a mileage. The first year's loss accrues linearly; later
+// years compound. Mileage above what the curve expects for the age lowers the
+// value, and mileage below raises it, but never above the price new or below the floor.
+func Retention(curve domain.DepreciationCurve, age, mileage float64) float64 {
+	r := 1 - curve.FirstYear*min(age, 1)
+	if age > 1 {
+		r *= math.Pow(1-curve.Annual, age-1)
+	}
+	excess := mileage - curve.MileagePerYear*age
+	r *= 1 - curve.MileageRate*excess/10000
+	return min(max(r, curve.Floor), 1)
+}
+
+// Project returns the value of a car that costs price new at age and mileage,
+// and its values at the ages 0 to ScheduleYears. The schedule assumes the car
+// keeps being driven as much per year as it has been, or as the curve expects
+// if it is new.
+func Project(curve domain.DepreciationCurve, price float32, age, mileage float64) (float32, float64, []domain.DepreciationPoint) {
+	perYear := curve.MileagePerYear
+	if age > 0 {
+		perYear = mileage / age
+	}
+	schedule := make([]domain.DepreciationPoint, ScheduleYears+1)
+	for year := range schedule {
+		m := math.Round(perYear * float64(year))
+		r := Retention(curve, float64(year), m)
+		schedule[year] = domain.DepreciationPoint{
+			Year:      year,
+			Mileage:   m,
+			Value:     value(price, r),
+			Retention: round4(r),
+		}
+	}
+	r := Retention(curve, age, mileage)
+	return value(price, r), round4(r), schedule
+}
+
+// value returns a price times a retention, rounded to cents.
+func value(price float32, retention float64) float32 {
+	return float32(math.Round(float64(price)*retention*100) / 100)
+}
+
+// round4 rounds a fraction to four decimals.
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
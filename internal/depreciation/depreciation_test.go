@@ -0,0 +1,97 @@
+package depreciation
+
+import (
+	"car-price-prediction/internal/domain"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurves_Curve(t *testing.T) {
+	curves := Default()
+
+	bmw := curves.Curve(domain.UserInput{Brand: "BMW", Carbody: "sedan"})
+	assert.Equal(t, domain.DepreciationCurve{Basis: "brand=bmw", FirstYear: 0.24, Annual: 0.16, MileagePerYear: 12000, MileageRate: 0.02, Floor: 0.1}, bmw)
+
+	// The most specific curve wins, inheriting unset fields from the default
+	assert.Equal(t, "brand=porsche,carbody=convertible", curves.Curve(domain.UserInput{Brand: "porcshce", Carbody: "cabriolet"}).Basis)
+	assert.Equal(t, "brand=porsche", curves.Curve(domain.UserInput{Brand: "porsche", Carbody: "hardtop"}).Basis)
+	wagon := curves.Curve(domain.UserInput{Brand: "audi", Carbody: "wagon"})
+	assert.Equal(t, "carbody=wagon", wagon.Basis)
+	assert.Equal(t, 0.20, wagon.FirstYear)
+	assert.Equal(t, 0.14, wagon.Annual)
+	assert.Equal(t, BasisDefault, curves.Curve(domain.UserInput{Brand: "audi", Carbody: "sedan"}).Basis)
+}
+
+func TestRetention(t *testing.T) {
+	curve := domain.DepreciationCurve{FirstYear: 0.2, Annual: 0.1, MileagePerYear: 10000, MileageRate: 0.02, Floor: 0.1}
+
+	assert.Equal(t, 1.0, Retention(curve, 0, 0))
+	assert.InDelta(t, 0.9, Retention(curve, 0.5, 5000), 1e-9)
+	assert.InDelta(t, 0.8, Retention(curve, 1, 10000), 1e-9)
+	assert.InDelta(t, 0.648, Retention(curve, 3, 30000), 1e-9)
+	// 20,000 miles above the expected mileage cost 4%, 10,000 below gain 2%
+	assert.InDelta(t, 0.648*0.96, Retention(curve, 3, 50000), 1e-9)
+	assert.InDelta(t, 0.648*1.02, Retention(curve, 3, 20000), 1e-9)
+	// Never above the price new or below the floor
+	lowMileage := curve
+	lowMileage.MileageRate = 0.5
+	assert.Equal(t, 1.0, Retention(lowMileage, 0.1, 0))
+	assert.Equal(t, 0.1, Retention(curve, 30, 900000))
+}
+
+func TestProject(t *testing.T) {
+	curve := domain.DepreciationCurve{FirstYear: 0.2, Annual: 0.1, MileagePerYear: 10000, MileageRate: 0.02, Floor: 0.1}
+
+	value, retention, schedule := Project(curve, 10000, 3, 45000)
+	assert.Equal(t, float32(6285.6), value)
+	assert.Equal(t, 0.6286, retention)
+	require.Len(t, schedule, ScheduleYears+1)
+	assert.Equal(t, domain.DepreciationPoint{Year: 0, Mileage: 0, Value: 10000, Retention: 1}, schedule[0])
+	// The schedule continues at 15,000 miles a year
+	assert.Equal(t, domain.DepreciationPoint{Year: 3, Mileage: 45000, Value: 6285.6, Retention: 0.6286}, schedule[3])
+	assert.Equal(t, 150000.0, schedule[10].Mileage)
+	for i := 1; i < len(schedule); i++ {
+		assert.Less(t, schedule[i].Value, schedule[i-1].Value)
+	}
+
+	// New cars are projected at the expected mileage
+	_, _, schedule = Project(curve, 10000, 0, 0)
+	assert.Equal(t, 50000.0, schedule[5].Mileage)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	const defaults = "default: {first_year: 0.2, annual: 0.15, mileage_per_year: 12000, mileage_rate: 0.02, floor: 0.1}\n"
+	tests := []struct {
+		name string
+		yaml string
+		err  string
+	}{
+		{"incomplete default", "default: {first_year: 0.2}\n", "must set first_year"},
+		{"unknown brand", defaults + "curves: [{brand: tesla, annual: 0.1}]\n", `unknown brand "tesla"`},
+		{"no key", defaults + "curves: [{annual: 0.1}]\n", "needs a brand, a carbody or both"},
+		{"duplicate", defaults + "curves: [{brand: bmw}, {brand: BMW}]\n", "brand=bmw is defined twice"},
+		{"out of range", defaults + "curves: [{carbody: sedan, annual: 1.5}]\n", "annual must be at least 0 and less than 1"},
+		{"unknown field", defaults + "curves: [{brand: bmw, yearly: 0.1}]\n", "yearly"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "depreciation.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("default: {first_year: 0.3, annual: 0.2, mileage_per_year: 15000, mileage_rate: 0.01, floor: 0}\n"), 0o644))
+	curves, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 0.3, curves.Curve(domain.UserInput{Brand: "bmw"}).FirstYear)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,55 @@
+package domain
+
+// DepreciationInput represents the JSON request body for the depreciation API:
+// a car as for the prediction API, with its age and mileage. The body may use
+// catalog_id, impute and units like a prediction request.
+type DepreciationInput struct {
+	UserInput
+	// AgeYears is the age of the car in years, from 0 to 30.
+	AgeYears float64 `json:"age_years" binding:"required" example:"3"`
+	// Mileage is the distance the car has been driven, in miles. If left out,
+	// the mileage its depreciation curve expects for the age is assumed.
+	Mileage *float64 `json:"mileage,omitempty" example:"45000"`
+}
+
+// DepreciationResult represents the JSON response body for the depreciation API.
+type DepreciationResult struct {
+	// Prediction is the price the model predicts for the car new.
+	Prediction *PredictionResult `json:"prediction"`
+	AgeYears   float64           `json:"age_years" example:"3"`
+	Mileage    float64           `json:"mileage" example:"45000"`
+	// MileageAssumed is set if the request left out the mileage.
+	MileageAssumed bool `json:"mileage_assumed,omitempty"`
+	// Value is the projected value of the car at its age and mileage.
+	Value float32 `json:"value" example:"7106.51"`
+	// Retention is Value as a fraction of the predicted price.
+	Retention float64           `json:"retention" example:"0.5266"`
+	Curve     DepreciationCurve `json:"curve"`
+	// Schedule is the projected value at the ages 0 to 10, driven as much per year as so far.
+	Schedule []DepreciationPoint `json:"schedule"`
+}
+
+// DepreciationCurve describes how fast cars of a brand and body type lose value.
+// Fractions are of the price new.
+type DepreciationCurve struct {
+	// Basis names the cars the curve is configured for, e.g. "brand=bmw,carbody=sedan", or "default".
+	Basis string `json:"basis" example:"brand=bmw"`
+	// FirstYear is the fraction lost in the first year.
+	FirstYear float64 `json:"first_year" example:"0.24"`
+	// Annual is the fraction of the remaining value lost in every later year.
+	Annual float64 `json:"annual" example:"0.16"`
+	// MileagePerYear is the mileage a car is expected to be driven per year.
+	MileagePerYear float64 `json:"mileage_per_year" example:"12000"`
+	// MileageRate is the fraction lost per 10,000 miles above the expected mileage, or regained below it.
+	MileageRate float64 `json:"mileage_rate" example:"0.02"`
+	// Floor is the fraction a car never falls below.
+	Floor float64 `json:"floor" example:"0.1"`
+}
+
+// DepreciationPoint is the projected value of a car at an age.
+type DepreciationPoint struct {
+	Year      int     `json:"year" example:"5"`
+	Mileage   float64 `json:"mileage" example:"75000"`
+	Value     float32 `json:"value" example:"4953.08"`
+	Retention float64 `json:"retention" example:"0.367"`
+}
@@ -0,0 +1,84 @@
+package domain
+
+import "fmt"
+
+// ErrorCode is a stable, machine-readable identifier for a class of errors.
+// Codes are part of the public API and must not be renamed.
+type ErrorCode string
+
+const (
+	// CodeValidationFailed means the request body is malformed or misses required fields.
+	CodeValidationFailed ErrorCode = "VALIDATION_FAILED"
+	// CodeUnknownCategory means a categorical field holds a value the model was not trained on.
+	CodeUnknownCategory ErrorCode = "UNKNOWN_CATEGORY"
+	// CodeOutOfRange means a numerical field is outside the range the model can handle.
+	CodeOutOfRange ErrorCode = "OUT_OF_RANGE"
+	// CodeModelUnavailable means the model could not be loaded or does not support the operation.
+	CodeModelUnavailable ErrorCode = "MODEL_UNAVAILABLE"
+	// CodeInferenceFailed means the model failed while computing the prediction.
+	CodeInferenceFailed ErrorCode = "INFERENCE_FAILED"
+	// CodeTimeout means the prediction did not finish within the allowed time.
+	CodeTimeout ErrorCode = "TIMEOUT"
+	// CodeUnauthorized means the request carries no valid credentials.
+	CodeUnauthorized ErrorCode = "UNAUTHORIZED"
+	// CodeForbidden means the credentials are valid but do not allow the operation.
+	CodeForbidden ErrorCode = "FORBIDDEN"
+	// CodeRateLimited means the caller sent too many requests in a short time.
+	CodeRateLimited ErrorCode = "RATE_LIMITED"
+	// CodeQuotaExceeded means the caller used up its monthly prediction quota.
+	CodeQuotaExceeded ErrorCode = "QUOTA_EXCEEDED"
+	// CodeNotFound means the requested resource does not exist.
+	CodeNotFound ErrorCode = "NOT_FOUND"
+	// CodeStorageUnavailable means a record could not be read from or written to storage.
+	CodeStorageUnavailable ErrorCode = "STORAGE_UNAVAILABLE"
+)
+
+// Violation describes a problem with a single request field.
+type Violation struct {
+	Field   string    `json:"field"`
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// Error is the error type returned by the domain layer. Message and Violations are
+// safe to show to clients; Cause carries internal details and is only logged.
+type Error struct {
+	Code       ErrorCode
+	Message    string
+	Violations []Violation
+	Cause      error
+}
+
+// NewError creates an Error with the given code, client-facing message and internal cause.
+func NewError(code ErrorCode, message string, cause error) *Error {
+	return &Error{Code: code, Message: message, Cause: cause}
+}
+
+// NewValidationError creates an Error from field violations. The error takes the code
+// shared by all violations, or CodeValidationFailed if they differ.
+func NewValidationError(violations []Violation) *Error {
+	code := CodeValidationFailed
+	if len(violations) > 0 {
+		code = violations[0].Code
+		for _, v := range violations[1:] {
+			if v.Code != code {
+				code = CodeValidationFailed
+				break
+			}
+		}
+	}
+	return &Error{Code: code, Message: "The request contains invalid fields.", Violations: violations}
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the internal cause of the error.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
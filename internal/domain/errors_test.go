@@ -0,0 +1,40 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"car-price-prediction/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewValidationError_SharedCode(t *testing.T) {
+	err := domain.NewValidationError([]domain.Violation{
+		{Field: "brand", Code: domain.CodeUnknownCategory},
+		{Field: "carbody", Code: domain.CodeUnknownCategory},
+	})
+
+	assert.Equal(t, domain.CodeUnknownCategory, err.Code)
+	assert.Len(t, err.Violations, 2)
+}
+
+func TestNewValidationError_MixedCodes(t *testing.T) {
+	err := domain.NewValidationError([]domain.Violation{
+		{Field: "brand", Code: domain.CodeUnknownCategory},
+		{Field: "horsepower", Code: domain.CodeOutOfRange},
+	})
+
+	assert.Equal(t, domain.CodeValidationFailed, err.Code)
+}
+
+func TestError_UnwrapsCause(t *testing.T) {
+	cause := errors.New("onnxruntime: session failed")
+	err := domain.NewError(domain.CodeModelUnavailable, "The prediction model is not available.", cause)
+
+	assert.ErrorIs(t, err, cause)
+	assert.Contains(t, err.Error(), "MODEL_UNAVAILABLE")
+
+	var derr *domain.Error
+	assert.True(t, errors.As(error(err), &derr))
+}
@@ -0,0 +1,11 @@
+package domain
+
+// FeedbackInput represents the JSON request body for the feedback API. It reports
+// the price a car actually sold for, either for a recorded prediction or for an
+// input that is predicted when the feedback arrives. Exactly one of PredictionID
+// and Input must be set.
+type FeedbackInput struct {
+	PredictionID string     `json:"prediction_id,omitempty" example:"f4fc1c2a1d2da2b90dfacff13d4d8840"`
+	Input        *UserInput `json:"input,omitempty"`
+	ActualPrice  float64    `json:"actual_price" binding:"required,gt=0" example:"13950"`
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// ModelInfo describes a loaded prediction model.
+type ModelInfo struct {
+	// Version identifies the model; it is derived from the model's content hash.
+	Version  string    `json:"version" example:"5b1f0c3e9a2d"`
+	SHA256   string    `json:"sha256"`
+	Path     string    `json:"path"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
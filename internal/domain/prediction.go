@@ -35,9 +35,200 @@ type UserInput struct {
 // PredictionResult represents the JSON response body for the prediction API.
 type PredictionResult struct {
 	PredictedPrice float32 `json:"predicted_price"`
+	// PredictionID identifies the recorded prediction when the prediction log is enabled.
+	PredictionID string `json:"prediction_id,omitempty"`
+	// Model names the registered model that made the prediction, if the request selected one.
+	Model string `json:"model,omitempty" example:"linear"`
+	// CatalogID is the catalog entry the input was completed from, if the request named one.
+	CatalogID string `json:"catalog_id,omitempty" example:"audi-100ls"`
+	// Defaulted lists the input fields that were taken from the catalog entry.
+	Defaulted []string `json:"defaulted,omitempty"`
+	// Imputed lists the input fields that were filled in because the request asked
+	// for imputation and left them out.
+	Imputed []ImputedField `json:"imputed,omitempty"`
+	// PriceRange is the span of prices the imputed fields could plausibly lead to.
+	PriceRange *PriceRange `json:"price_range,omitempty"`
+	// Conversions lists the input fields that were converted from the units the request gave them in.
+	Conversions []UnitConversion `json:"conversions,omitempty"`
+	// NormalizedInput is the complete input the model was given, in model units and with
+	// normalized categories. It is only set if the request used a catalog entry, imputation or units.
+	NormalizedInput *UserInput `json:"normalized_input,omitempty"`
+	// Regional is the predicted price adjusted by the rules of the region the request asked for, if any.
+	Regional *RegionalPrice `json:"regional,omitempty"`
+	// Quote is the predicted price in the currency and year the request asked for, if any.
+	// If the price was adjusted for a region, the adjusted price is quoted.
+	Quote *Quote `json:"quote,omitempty"`
+	// Linear breaks the price down into the terms of a linear model, if one made the prediction.
+	Linear *LinearBreakdown `json:"linear,omitempty"`
+	// Debug shows how an ensemble of models arrived at the price. It is meant for
+	// comparing models and may change between releases.
+	Debug *PredictionDebug `json:"debug,omitempty"`
 }
 
-// ErrorResponse represents the JSON response body for an error.
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
\ No newline at end of file
+// LinearBreakdown is a prediction of a linear model: the intercept plus a term per
+// feature. If the model was trained on a transformed price, such as its log, the
+// terms add up to the transformed price.
+type LinearBreakdown struct {
+	Intercept float64 `json:"intercept" example:"-12040.5"`
+	// Terms lists the encoded features with a non-zero value, in the model's feature order.
+	Terms []LinearTerm `json:"terms"`
+}
+
+// LinearTerm is the contribution of one encoded feature to a linear model's prediction.
+type LinearTerm struct {
+	Feature     string  `json:"feature" example:"horsepower"`
+	Coefficient float64 `json:"coefficient" example:"54.2"`
+	// Value is the encoded value of the feature, e.g. 1 for a one-hot category the car has.
+	Value float32 `json:"value" example:"111"`
+	// Contribution is Coefficient times Value.
+	Contribution float64 `json:"contribution" example:"6016.2"`
+}
+
+// PredictionDebug holds the predictions of the members of an ensemble.
+type PredictionDebug struct {
+	// Aggregation is how the members' predictions were combined: mean, median or stacking.
+	Aggregation string             `json:"aggregation" example:"mean"`
+	Members     []MemberPrediction `json:"members"`
+}
+
+// MemberPrediction is the prediction of one member of an ensemble.
+type MemberPrediction struct {
+	Name         string `json:"name" example:"random_forest"`
+	ModelVersion string `json:"model_version,omitempty" example:"3f2a9c1b7d4e"`
+	// Weight is the member's weight in the mean or its coefficient in the stacking
+	// model. A median ensemble does not weigh its members.
+	Weight         *float64 `json:"weight,omitempty" example:"2"`
+	PredictedPrice float32  `json:"predicted_price" example:"13870.5"`
+}
+
+// RegionalPrice is a predicted price adjusted by the rules of a regional market,
+// such as import duties and taxes. Price is BasePrice plus the amounts of the line items.
+type RegionalPrice struct {
+	Region         string     `json:"region" example:"id"`
+	RuleSetVersion string     `json:"rule_set_version" example:"2025-10-01"`
+	BasePrice      float32    `json:"base_price" example:"13495"`
+	Price          float32    `json:"price" example:"16868.75"`
+	LineItems      []LineItem `json:"line_items"`
+}
+
+// LineItem is the contribution of one rule to a regional price.
+type LineItem struct {
+	Rule   string  `json:"rule" example:"luxury import duty"`
+	When   string  `json:"when,omitempty" example:"brand in [bmw, porsche] and enginesize > 180"`
+	Adjust string  `json:"adjust" example:"+25%"`
+	Amount float32 `json:"amount" example:"3373.75"`
+}
+
+// Quote is a predicted price converted to another currency and/or adjusted for inflation.
+// Prices are adjusted for inflation in the base currency first, then converted.
+type Quote struct {
+	// BasePrice is the predicted price, or the regional price if there is one, in BaseCurrency of BaseYear.
+	BasePrice    float32 `json:"base_price" example:"13495"`
+	BaseCurrency string  `json:"base_currency" example:"USD"`
+	BaseYear     int     `json:"base_year" example:"1985"`
+	Price        float64 `json:"price" example:"639312612.86"`
+	Currency     string  `json:"currency" example:"IDR"`
+	Year         int     `json:"year" example:"2024"`
+	// Inflation is the price index adjustment to Year, if it differs from BaseYear.
+	Inflation *Inflation `json:"inflation,omitempty"`
+	// ExchangeRate is the rate the price was converted at, if Currency differs from BaseCurrency.
+	ExchangeRate *ExchangeRate `json:"exchange_rate,omitempty"`
+}
+
+// Inflation is a price index adjustment between two years.
+type Inflation struct {
+	Index       string  `json:"index" example:"US CPI-U (BLS CUUR0000SA0, annual average)"`
+	BaseIndex   float64 `json:"base_index" example:"107.6"`
+	TargetIndex float64 `json:"target_index" example:"313.689"`
+	Factor      float64 `json:"factor" example:"2.9153"`
+}
+
+// ExchangeRate is the number of units of To one unit of From bought on EffectiveDate.
+type ExchangeRate struct {
+	From          string  `json:"from" example:"USD"`
+	To            string  `json:"to" example:"IDR"`
+	Rate          float64 `json:"rate" example:"16250"`
+	EffectiveDate string  `json:"effective_date" example:"2025-06-30"`
+}
+
+// UnitConversion is an input value converted to the unit the model was trained on.
+type UnitConversion struct {
+	Field          string  `json:"field" example:"wheelbase"`
+	Value          float64 `json:"value" example:"2500"`
+	Unit           string  `json:"unit" example:"mm"`
+	Normalized     float64 `json:"normalized" example:"98.43"`
+	NormalizedUnit string  `json:"normalized_unit" example:"in"`
+}
+
+// ImputedField is an input field that was filled in from the training data.
+type ImputedField struct {
+	Field string `json:"field" example:"stroke"`
+	// Value is the value the field was given: a number or a string.
+	Value any `json:"value" swaggertype:"string" example:"3.4"`
+	// Basis names the training cars the value was taken from, e.g. "brand=audi,enginetype=ohc", or "all".
+	Basis string `json:"basis" example:"brand=audi"`
+}
+
+// PriceRange is a range of prices.
+type PriceRange struct {
+	Lower float32 `json:"lower"`
+	Upper float32 `json:"upper"`
+}
+
+// Problem represents an RFC 7807 problem details response body (application/problem+json).
+type Problem struct {
+	// Type is a URI identifying the problem type. It embeds the version of the error model.
+	Type string `json:"type" example:"urn:car-price-prediction:problem:v1:validation-failed"`
+	// Title is a short, human-readable summary of the problem type.
+	Title string `json:"title" example:"Validation failed"`
+	// Status is the HTTP status code.
+	Status int `json:"status" example:"400"`
+	// Detail is a human-readable explanation specific to this occurrence.
+	Detail string `json:"detail,omitempty" example:"The request contains invalid fields."`
+	// Instance identifies the request path that caused the problem.
+	Instance string `json:"instance,omitempty" example:"/predict"`
+	// Code is the stable, machine-readable error code.
+	Code ErrorCode `json:"code" example:"VALIDATION_FAILED"`
+	// Violations lists the problems with individual request fields.
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// BatchInput represents the JSON request body for the batch prediction API.
+// It holds 1 to 1000 rows, which are validated individually so that one invalid
+// row does not fail the whole batch.
+type BatchInput struct {
+	Inputs []UserInput `json:"inputs" binding:"required,min=1,max=1000"`
+}
+
+// BatchItem is the outcome of a single batch row: either a result or an error.
+type BatchItem struct {
+	Result *PredictionResult `json:"result,omitempty"`
+	Error  *Problem          `json:"error,omitempty"`
+}
+
+// BatchResult represents the JSON response body for the batch prediction API.
+// Items are in the same order as the request rows.
+type BatchResult struct {
+	Results []BatchItem `json:"results"`
+}
+
+// FeatureContribution describes how much a single input field moved the prediction.
+type FeatureContribution struct {
+	Feature      string  `json:"feature"`
+	Contribution float32 `json:"contribution"`
+}
+
+// Explanation represents the JSON response body for the explanation API.
+// Contributions are measured against BaselinePrice, the price predicted for a
+// typical car from the training data.
+type Explanation struct {
+	PredictedPrice float32               `json:"predicted_price"`
+	BaselinePrice  float32               `json:"baseline_price"`
+	Contributions  []FeatureContribution `json:"contributions"`
+	// CatalogID, Defaulted, Imputed, Conversions and NormalizedInput are set as in PredictionResult.
+	CatalogID       string           `json:"catalog_id,omitempty" example:"audi-100ls"`
+	Defaulted       []string         `json:"defaulted,omitempty"`
+	Imputed         []ImputedField   `json:"imputed,omitempty"`
+	Conversions     []UnitConversion `json:"conversions,omitempty"`
+	NormalizedInput *UserInput       `json:"normalized_input,omitempty"`
+}
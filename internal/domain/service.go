@@ -1,7 +1,57 @@
 package domain
 
+import (
+	"context"
+	"time"
+)
+
 // PredictionService defines the interface for prediction services.
 type PredictionService interface {
 	// Predict takes a UserInput and returns a PredictionResult or an error.
 	Predict(input UserInput) (*PredictionResult, error)
-}
\ No newline at end of file
+}
+
+// Explainer is implemented by prediction services that can attribute a
+// prediction to the individual input fields.
+type Explainer interface {
+	// Explain returns the prediction for the input together with per-field contributions.
+	Explain(input UserInput) (*Explanation, error)
+}
+
+// ModelManager is implemented by prediction services whose model can be inspected
+// and reloaded at runtime.
+type ModelManager interface {
+	// Model describes the model currently serving predictions.
+	Model() ModelInfo
+	// Reload loads the model again from its source, e.g. after the file was replaced.
+	Reload() (ModelInfo, error)
+}
+
+// WithTimeout runs fn and returns a TIMEOUT error if it does not finish within the
+// timeout or before ctx is done. A timeout of 0 leaves only ctx's deadline. fn keeps
+// running in the background when it times out.
+func WithTimeout[T any](ctx context.Context, timeout time.Duration, fn func() (T, error)) (T, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type outcome struct {
+		value T
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		value, err := fn()
+		done <- outcome{value, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.value, o.err
+	case <-ctx.Done():
+		var zero T
+		return zero, NewError(CodeTimeout, "The prediction did not finish in time.", ctx.Err())
+	}
+}
@@ -0,0 +1,188 @@
+package drift
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/domain"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trainingInputs returns the inputs of the sample data set.
+func trainingInputs(t *testing.T) []domain.UserInput {
+	samples, err := dataset.ReadFile("../dataset/testdata/carprice_sample.csv")
+	require.NoError(t, err)
+	inputs := make([]domain.UserInput, len(samples))
+	for i, s := range samples {
+		inputs[i] = s.Input
+	}
+	return inputs
+}
+
+// newTestMonitor creates a monitor for the sample data set whose clock is
+// controlled by the returned pointer.
+func newTestMonitor(t *testing.T, cfg Config) (*Monitor, *time.Time) {
+	profile, err := NewProfile(trainingInputs(t), DefaultBins)
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	m := NewMonitor(profile, cfg)
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+// feature returns the drift of the named feature.
+func feature(t *testing.T, w WindowReport, name string) FeatureDrift {
+	for _, f := range w.Features {
+		if f.Feature == name {
+			return f
+		}
+	}
+	t.Fatalf("feature %s not reported", name)
+	return FeatureDrift{}
+}
+
+func TestStats(t *testing.T) {
+	assert.InDelta(t, 0, psi([]float64{0.5, 0.5}, []float64{0.5, 0.5}), 1e-12)
+	assert.InDelta(t, 0.3*math.Log(0.8/0.5)+0.3*math.Log(0.5/0.2), psi([]float64{0.5, 0.5}, []float64{0.8, 0.2}), 1e-12)
+
+	d, p := ks([]float64{1, 2, 3, 4}, []float64{1, 2, 3, 4})
+	assert.Equal(t, 0.0, d)
+	assert.Equal(t, 1.0, p)
+	d, p = ks([]float64{1, 2, 3, 4}, []float64{5, 6, 7, 8})
+	assert.Equal(t, 1.0, d)
+	assert.Less(t, p, 0.05)
+
+	// Q(1, x) = exp(-x), and chi-square with 2 degrees of freedom has p = exp(-x/2)
+	assert.InDelta(t, math.Exp(-0.5), gammaQ(1, 0.5), 1e-12)
+	assert.InDelta(t, math.Exp(-5), gammaQ(1, 5), 1e-12)
+	stat, p := chiSquare([]float64{0.5, 0.25, 0.25}, []int{50, 30, 20})
+	assert.InDelta(t, 2, stat, 1e-9)
+	assert.InDelta(t, math.Exp(-1), p, 1e-9)
+}
+
+func TestProfile(t *testing.T) {
+	profile, err := NewProfile(trainingInputs(t), DefaultBins)
+	require.NoError(t, err)
+
+	assert.Equal(t, 30, profile.Samples)
+	hp := profile.Numeric["horsepower"]
+	assert.Len(t, hp.Fractions, len(hp.Edges)+1)
+	assert.InDelta(t, 1, sum(hp.Fractions), 1e-9)
+	assert.InDelta(t, 1, sum(values(profile.Categorical["brand"].Fractions)), 1e-9)
+	assert.InDelta(t, 8.0/30, profile.Categorical["brand"].Fractions["bmw"], 1e-9)
+
+	path := filepath.Join(t.TempDir(), "profile.json")
+	require.NoError(t, profile.WriteFile(path))
+	loaded, err := LoadProfile(path)
+	require.NoError(t, err)
+	assert.Equal(t, profile, loaded)
+
+	_, err = NewProfile(nil, DefaultBins)
+	assert.Error(t, err)
+}
+
+func TestMonitor_InsufficientData(t *testing.T) {
+	m, _ := newTestMonitor(t, Config{MinSamples: 10})
+	m.Observe(trainingInputs(t)[0])
+
+	w := m.Report().Windows[0]
+	assert.Equal(t, 1, w.Samples)
+	assert.Equal(t, StatusInsufficientData, w.Status)
+	assert.Empty(t, w.Features)
+}
+
+func TestMonitor_TrainingDistributionIsStable(t *testing.T) {
+	m, _ := newTestMonitor(t, Config{MinSamples: 10})
+	for range 4 {
+		for _, in := range trainingInputs(t) {
+			m.Observe(in)
+		}
+	}
+
+	w := m.Report().Windows[0]
+	assert.Equal(t, 120, w.Samples)
+	assert.Equal(t, StatusOK, w.Status)
+	hp := feature(t, w, "horsepower")
+	assert.InDelta(t, 0, hp.PSI, 1e-9)
+	require.NotNil(t, hp.KS)
+	assert.Equal(t, 0.0, *hp.KS)
+	assert.Empty(t, feature(t, w, "brand").Shifts)
+}
+
+func TestMonitor_DetectsBrandShift(t *testing.T) {
+	m, clock := newTestMonitor(t, Config{MinSamples: 10})
+	for _, in := range trainingInputs(t) {
+		m.Observe(in)
+	}
+	*clock = clock.Add(2 * time.Hour)
+
+	// Buyers move to a brand the training data does not cover
+	for _, in := range trainingInputs(t)[:20] {
+		in.Brand = "Tesla"
+		in.Horsepower *= 3
+		m.Observe(in)
+	}
+
+	report := m.Report()
+	hour, day := report.Windows[0], report.Windows[1]
+	assert.Equal(t, 20, hour.Samples)
+	assert.Equal(t, 50, day.Samples)
+	assert.Equal(t, StatusDrift, hour.Status)
+
+	brand := feature(t, hour, "brand")
+	assert.Equal(t, StatusDrift, brand.Status)
+	assert.Less(t, brand.PValue, 0.001)
+	require.NotEmpty(t, brand.Shifts)
+	assert.Equal(t, CategoryShift{Value: "tesla", Reference: 0, Current: 1}, brand.Shifts[0])
+	assert.Equal(t, StatusDrift, feature(t, hour, "horsepower").Status)
+	assert.Equal(t, "brand", hour.Features[0].Feature, "features are sorted by PSI")
+}
+
+func TestMonitor_Metrics(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	m, _ := newTestMonitor(t, Config{MinSamples: 10, Registerer: reg})
+	for _, in := range trainingInputs(t) {
+		in.Brand = "tesla"
+		m.Observe(in)
+	}
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	detected := map[string]float64{}
+	for _, f := range families {
+		for _, metric := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range metric.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			switch {
+			case f.GetName() == "carprice_drift_samples":
+				assert.Equal(t, 30.0, metric.GetGauge().GetValue())
+			case f.GetName() == "carprice_drift_detected" && labels["feature"] == "brand":
+				detected[labels["window"]] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{"1h": 1, "24h": 1, "7d": 1}, detected)
+}
+
+func sum(xs []float64) float64 {
+	var s float64
+	for _, x := range xs {
+		s += x
+	}
+	return s
+}
+
+func values(m map[string]float64) []float64 {
+	out := make([]float64, 0, len(m))
+	for _, v := range m {
+		out = append(out, v)
+	}
+	return out
+}
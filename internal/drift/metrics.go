@@ -0,0 +1,44 @@
+package drift
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	featureLabels = []string{"window", "feature"}
+
+	psiDesc = prometheus.NewDesc("carprice_drift_psi",
+		"Population stability index of an input field against the training data.", featureLabels, nil)
+	pValueDesc = prometheus.NewDesc("carprice_drift_p_value",
+		"P-value of the Kolmogorov-Smirnov (numeric) or chi-square (categorical) test of an input field against the training data.", featureLabels, nil)
+	detectedDesc = prometheus.NewDesc("carprice_drift_detected",
+		"1 if the PSI of an input field is at least 0.25, 0 otherwise.", featureLabels, nil)
+	samplesDesc = prometheus.NewDesc("carprice_drift_samples",
+		"Prediction inputs observed within the window.", []string{"window"}, nil)
+)
+
+// collector exports the monitor's drift report, computed when the metrics are scraped.
+type collector struct {
+	monitor *Monitor
+}
+
+// Describe implements prometheus.Collector.
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{psiDesc, pValueDesc, detectedDesc, samplesDesc} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	for _, w := range c.monitor.Report().Windows {
+		ch <- prometheus.MustNewConstMetric(samplesDesc, prometheus.GaugeValue, float64(w.Samples), w.Window)
+		for _, f := range w.Features {
+			detected := 0.0
+			if f.Status == StatusDrift {
+				detected = 1
+			}
+			ch <- prometheus.MustNewConstMetric(psiDesc, prometheus.GaugeValue, f.PSI, w.Window, f.Feature)
+			ch <- prometheus.MustNewConstMetric(pValueDesc, prometheus.GaugeValue, f.PValue, w.Window, f.Feature)
+			ch <- prometheus.MustNewConstMetric(detectedDesc, prometheus.GaugeValue, detected, w.Window, f.Feature)
+		}
+	}
+}
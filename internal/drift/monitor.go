@@ -0,0 +1,340 @@
+package drift
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Monitor defaults.
+const (
+	DefaultMaxSamples = 100000
+	DefaultMinSamples = 100
+)
+
+// PSI thresholds. A PSI below WarningPSI means the distribution is stable; at or
+// above DriftPSI it has shifted significantly.
+const (
+	WarningPSI = 0.1
+	DriftPSI   = 0.25
+)
+
+// Drift statuses of a feature or window.
+const (
+	StatusOK               = "ok"
+	StatusWarning          = "warning"
+	StatusDrift            = "drift"
+	StatusInsufficientData = "insufficient_data"
+)
+
+// maxShifts is the number of category shifts reported per categorical feature.
+const maxShifts = 5
+
+// reportTTL is how long a computed report is reused if no inputs were observed.
+const reportTTL = 10 * time.Second
+
+// Window is a sliding time window over which drift is reported.
+type Window struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Windows are the sliding windows reported by the API and the metrics.
+var Windows = []Window{
+	{Name: "1h", Duration: time.Hour},
+	{Name: "24h", Duration: 24 * time.Hour},
+	{Name: "7d", Duration: 7 * 24 * time.Hour},
+}
+
+// Config configures a Monitor.
+type Config struct {
+	// MaxSamples bounds the number of recent inputs kept in memory. Defaults to
+	// DefaultMaxSamples.
+	MaxSamples int
+	// MinSamples is the number of inputs a window needs before drift is computed.
+	// Defaults to DefaultMinSamples.
+	MinSamples int
+	// Registerer receives the drift metrics. Metrics are not exported if nil.
+	Registerer prometheus.Registerer
+}
+
+// Report is the drift of the recent inputs over every window.
+type Report struct {
+	Windows []WindowReport `json:"windows"`
+}
+
+// WindowReport is the drift of the inputs observed within one window.
+type WindowReport struct {
+	Window  string    `json:"window" example:"24h"`
+	Since   time.Time `json:"since"`
+	Samples int       `json:"samples" example:"1250"`
+	// Status is the worst status of any feature.
+	Status string `json:"status" example:"warning"`
+	// Features are sorted by decreasing PSI.
+	Features []FeatureDrift `json:"features,omitempty"`
+}
+
+// FeatureDrift compares the distribution of one input field with the reference.
+type FeatureDrift struct {
+	Feature string `json:"feature" example:"brand"`
+	// Kind is "numeric" or "categorical".
+	Kind string  `json:"kind" example:"categorical"`
+	PSI  float64 `json:"psi" example:"0.31"`
+	// KS is the Kolmogorov-Smirnov statistic of numeric fields.
+	KS *float64 `json:"ks,omitempty" example:"0.12"`
+	// ChiSquare is Pearson's chi-square statistic of categorical fields.
+	ChiSquare *float64 `json:"chi_square,omitempty" example:"84.2"`
+	// PValue is the p-value of the KS or chi-square test.
+	PValue float64 `json:"p_value" example:"0.0001"`
+	// Status is derived from the PSI: ok, warning (>= 0.1) or drift (>= 0.25).
+	Status string `json:"status" example:"drift"`
+	// Shifts lists the categories whose share changed most.
+	Shifts []CategoryShift `json:"shifts,omitempty"`
+}
+
+// CategoryShift is the share of a category in the reference and recent inputs.
+type CategoryShift struct {
+	Value     string  `json:"value" example:"tesla"`
+	Reference float64 `json:"reference" example:"0"`
+	Current   float64 `json:"current" example:"0.08"`
+}
+
+// sample is an observed input reduced to its feature values.
+type sample struct {
+	time        time.Time
+	numeric     []float64
+	categorical []string
+}
+
+// Monitor keeps the recent prediction inputs and compares their distribution with
+// a reference profile.
+type Monitor struct {
+	profile *Profile
+	cfg     Config
+	now     func() time.Time
+
+	mu       sync.Mutex
+	samples  []sample // in time order
+	observed uint64
+	cached   *Report
+	cachedAt time.Time
+	cachedN  uint64
+}
+
+// NewMonitor creates a monitor comparing inputs with profile.
+func NewMonitor(profile *Profile, cfg Config) *Monitor {
+	if cfg.MaxSamples <= 0 {
+		cfg.MaxSamples = DefaultMaxSamples
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = DefaultMinSamples
+	}
+	m := &Monitor{profile: profile, cfg: cfg, now: time.Now}
+	if cfg.Registerer != nil {
+		cfg.Registerer.MustRegister(&collector{monitor: m})
+	}
+	return m
+}
+
+// Observe records a prediction input. Categorical values are normalized, so
+// aliases count as the category they stand for. A nil monitor observes nothing.
+func (m *Monitor) Observe(input domain.UserInput) {
+	if m == nil {
+		return
+	}
+	input = prediction.Normalize(input)
+	s := sample{
+		numeric:     make([]float64, len(numericFeatures)),
+		categorical: make([]string, len(categoricalFeatures)),
+	}
+	for i, f := range numericFeatures {
+		s.numeric[i] = f.value(input)
+	}
+	for i, f := range categoricalFeatures {
+		s.categorical[i] = f.value(input)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.time = m.now()
+	m.samples = append(m.samples, s)
+	m.observed++
+	m.pruneLocked(s.time)
+}
+
+// pruneLocked discards the samples outside the longest window or beyond the limit.
+func (m *Monitor) pruneLocked(now time.Time) {
+	first := m.firstSince(now.Add(-Windows[len(Windows)-1].Duration))
+	first = max(first, len(m.samples)-m.cfg.MaxSamples)
+	m.samples = m.samples[first:]
+}
+
+// firstSince returns the index of the first sample observed at or after since.
+func (m *Monitor) firstSince(since time.Time) int {
+	return sort.Search(len(m.samples), func(i int) bool { return !m.samples[i].time.Before(since) })
+}
+
+// Report compares the inputs of every window with the reference profile.
+func (m *Monitor) Report() Report {
+	m.mu.Lock()
+	now := m.now()
+	if m.cached != nil && m.cachedN == m.observed && now.Sub(m.cachedAt) < reportTTL {
+		defer m.mu.Unlock()
+		return *m.cached
+	}
+	m.pruneLocked(now)
+	samples := m.samples
+	observed := m.observed
+	m.mu.Unlock()
+
+	// Samples are only appended, so the snapshot can be read without the lock
+	r := Report{Windows: make([]WindowReport, 0, len(Windows))}
+	for _, w := range Windows {
+		since := now.Add(-w.Duration)
+		first := sort.Search(len(samples), func(i int) bool { return !samples[i].time.Before(since) })
+		r.Windows = append(r.Windows, m.compare(w.Name, since.UTC(), samples[first:]))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cached, m.cachedAt, m.cachedN = &r, now, observed
+	return r
+}
+
+// compare computes the drift of the samples of one window.
+func (m *Monitor) compare(window string, since time.Time, samples []sample) WindowReport {
+	wr := WindowReport{Window: window, Since: since, Samples: len(samples), Status: StatusInsufficientData}
+	if len(samples) < m.cfg.MinSamples {
+		return wr
+	}
+
+	for i, f := range numericFeatures {
+		wr.Features = append(wr.Features, m.compareNumeric(f.name, samples, i))
+	}
+	for i, f := range categoricalFeatures {
+		wr.Features = append(wr.Features, m.compareCategorical(f.name, samples, i))
+	}
+	sort.SliceStable(wr.Features, func(i, j int) bool { return wr.Features[i].PSI > wr.Features[j].PSI })
+
+	wr.Status = StatusOK
+	for _, f := range wr.Features {
+		if severity(f.Status) > severity(wr.Status) {
+			wr.Status = f.Status
+		}
+	}
+	return wr
+}
+
+// compareNumeric compares the values of a numeric feature with its histogram.
+func (m *Monitor) compareNumeric(name string, samples []sample, index int) FeatureDrift {
+	ref := m.profile.Numeric[name]
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = s.numeric[index]
+	}
+	slices.Sort(values)
+
+	statistic, pValue := ks(ref.Values, values)
+	fd := FeatureDrift{
+		Feature: name,
+		Kind:    "numeric",
+		PSI:     psi(ref.Fractions, ref.histogram(values)),
+		KS:      &statistic,
+		PValue:  pValue,
+	}
+	fd.Status = status(fd.PSI)
+	return fd
+}
+
+// compareCategorical compares the values of a categorical feature with its
+// frequency table. Categories missing from the training data are included.
+func (m *Monitor) compareCategorical(name string, samples []sample, index int) FeatureDrift {
+	ref := m.profile.Categorical[name]
+	counts := map[string]int{}
+	for _, s := range samples {
+		counts[s.categorical[index]]++
+	}
+
+	categories := make([]string, 0, len(ref.Fractions))
+	for c := range ref.Fractions {
+		categories = append(categories, c)
+	}
+	for c := range counts {
+		if _, ok := ref.Fractions[c]; !ok {
+			categories = append(categories, c)
+		}
+	}
+	slices.Sort(categories)
+
+	reference := make([]float64, len(categories))
+	current := make([]float64, len(categories))
+	observed := make([]int, len(categories))
+	shifts := make([]CategoryShift, len(categories))
+	for i, c := range categories {
+		reference[i] = ref.Fractions[c]
+		observed[i] = counts[c]
+		current[i] = float64(counts[c]) / float64(len(samples))
+		shifts[i] = CategoryShift{Value: c, Reference: reference[i], Current: current[i]}
+	}
+
+	statistic, pValue := chiSquare(reference, observed)
+	fd := FeatureDrift{
+		Feature:   name,
+		Kind:      "categorical",
+		PSI:       psi(reference, current),
+		ChiSquare: &statistic,
+		PValue:    pValue,
+	}
+	fd.Status = status(fd.PSI)
+
+	// Report the categories that contribute most to the PSI
+	contribution := func(s CategoryShift) float64 {
+		r, c := max(s.Reference, minFraction), max(s.Current, minFraction)
+		return (c - r) * math.Log(c/r)
+	}
+	sort.SliceStable(shifts, func(i, j int) bool { return contribution(shifts[i]) > contribution(shifts[j]) })
+	for _, s := range shifts[:min(maxShifts, len(shifts))] {
+		if s.Reference != s.Current {
+			fd.Shifts = append(fd.Shifts, s)
+		}
+	}
+	return fd
+}
+
+// status classifies a PSI.
+func status(psi float64) string {
+	switch {
+	case psi >= DriftPSI:
+		return StatusDrift
+	case psi >= WarningPSI:
+		return StatusWarning
+	default:
+		return StatusOK
+	}
+}
+
+// severity orders statuses from best to worst.
+func severity(status string) int {
+	return slices.Index([]string{StatusInsufficientData, StatusOK, StatusWarning, StatusDrift}, status)
+}
+
+// contextKey is the type of context keys defined in this package.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the monitor.
+func NewContext(ctx context.Context, m *Monitor) context.Context {
+	return context.WithValue(ctx, contextKey{}, m)
+}
+
+// FromContext returns the monitor stored in ctx, or nil if drift is not monitored.
+func FromContext(ctx context.Context) *Monitor {
+	m, _ := ctx.Value(contextKey{}).(*Monitor)
+	return m
+}
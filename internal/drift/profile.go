@@ -0,0 +1,194 @@
+// Package drift detects when prediction inputs stop resembling the data the model
+// was trained on, by comparing them with a reference profile of the training set.
+package drift
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+)
+
+// DefaultBins is the number of quantile bins of numeric features in a profile.
+const DefaultBins = 10
+
+// numericFeature extracts a numeric feature from an input.
+type numericFeature struct {
+	name  string
+	value func(domain.UserInput) float64
+}
+
+// categoricalFeature extracts a categorical feature from a normalized input.
+type categoricalFeature struct {
+	name  string
+	value func(domain.UserInput) string
+}
+
+// numericFeatures are the numeric input fields, in request order.
+var numericFeatures = []numericFeature{
+	{"symboling", func(in domain.UserInput) float64 { return float64(in.Symboling) }},
+	{"wheelbase", func(in domain.UserInput) float64 { return float64(in.Wheelbase) }},
+	{"carlength", func(in domain.UserInput) float64 { return float64(in.Carlength) }},
+	{"carwidth", func(in domain.UserInput) float64 { return float64(in.Carwidth) }},
+	{"carheight", func(in domain.UserInput) float64 { return float64(in.Carheight) }},
+	{"curbweight", func(in domain.UserInput) float64 { return float64(in.Curbweight) }},
+	{"enginesize", func(in domain.UserInput) float64 { return float64(in.Enginesize) }},
+	{"boreratio", func(in domain.UserInput) float64 { return float64(in.Boreratio) }},
+	{"stroke", func(in domain.UserInput) float64 { return float64(in.Stroke) }},
+	{"compressionratio", func(in domain.UserInput) float64 { return float64(in.Compressionratio) }},
+	{"horsepower", func(in domain.UserInput) float64 { return float64(in.Horsepower) }},
+	{"peakrpm", func(in domain.UserInput) float64 { return float64(in.Peakrpm) }},
+	{"citympg", func(in domain.UserInput) float64 { return float64(in.Citympg) }},
+	{"highwaympg", func(in domain.UserInput) float64 { return float64(in.Highwaympg) }},
+}
+
+// categoricalFeatures are the categorical input fields, in request order.
+var categoricalFeatures = []categoricalFeature{
+	{"fueltype", func(in domain.UserInput) string { return in.Fueltype }},
+	{"aspiration", func(in domain.UserInput) string { return in.Aspiration }},
+	{"doornumber", func(in domain.UserInput) string { return in.Doornumber }},
+	{"carbody", func(in domain.UserInput) string { return in.Carbody }},
+	{"drivewheel", func(in domain.UserInput) string { return in.Drivewheel }},
+	{"enginelocation", func(in domain.UserInput) string { return in.Enginelocation }},
+	{"enginetype", func(in domain.UserInput) string { return in.Enginetype }},
+	{"cylindernumber", func(in domain.UserInput) string { return in.Cylindernumber }},
+	{"fuelsystem", func(in domain.UserInput) string { return in.Fuelsystem }},
+	{"brand", func(in domain.UserInput) string { return in.Brand }},
+}
+
+// Profile describes the distribution of every input field in the training data.
+type Profile struct {
+	// Samples is the number of training rows the profile was built from.
+	Samples     int                            `json:"samples"`
+	Numeric     map[string]*NumericProfile     `json:"numeric"`
+	Categorical map[string]*CategoricalProfile `json:"categorical"`
+}
+
+// NumericProfile is the histogram of a numeric field over quantile bins. Bin i
+// holds the values in (Edges[i-1], Edges[i]]; the first and last bins are open.
+type NumericProfile struct {
+	Edges     []float64 `json:"edges"`
+	Fractions []float64 `json:"fractions"`
+	// Values are the sorted training values, used for the Kolmogorov-Smirnov test.
+	Values []float64 `json:"values"`
+}
+
+// CategoricalProfile is the frequency table of a categorical field.
+type CategoricalProfile struct {
+	Fractions map[string]float64 `json:"fractions"`
+}
+
+// NewProfile builds the profile of the given training inputs, binning numeric
+// fields into bins quantiles.
+func NewProfile(inputs []domain.UserInput, bins int) (*Profile, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("cannot profile an empty data set")
+	}
+	if bins < 2 {
+		bins = DefaultBins
+	}
+	normalized := make([]domain.UserInput, len(inputs))
+	for i, in := range inputs {
+		normalized[i] = prediction.Normalize(in)
+	}
+
+	p := &Profile{
+		Samples:     len(inputs),
+		Numeric:     make(map[string]*NumericProfile, len(numericFeatures)),
+		Categorical: make(map[string]*CategoricalProfile, len(categoricalFeatures)),
+	}
+	for _, f := range numericFeatures {
+		values := make([]float64, len(normalized))
+		for i, in := range normalized {
+			values[i] = f.value(in)
+		}
+		slices.Sort(values)
+		np := &NumericProfile{Edges: quantileEdges(values, bins), Values: values}
+		np.Fractions = np.histogram(values)
+		p.Numeric[f.name] = np
+	}
+	for _, f := range categoricalFeatures {
+		counts := map[string]int{}
+		for _, in := range normalized {
+			counts[f.value(in)]++
+		}
+		cp := &CategoricalProfile{Fractions: make(map[string]float64, len(counts))}
+		for value, n := range counts {
+			cp.Fractions[value] = float64(n) / float64(len(normalized))
+		}
+		p.Categorical[f.name] = cp
+	}
+	return p, nil
+}
+
+// quantileEdges returns the distinct inner quantiles of sorted values that split
+// them into bins bins.
+func quantileEdges(sorted []float64, bins int) []float64 {
+	var edges []float64
+	for i := 1; i < bins; i++ {
+		k := int(math.Ceil(float64(i)*float64(len(sorted))/float64(bins))) - 1
+		edge := sorted[max(k, 0)]
+		if edge == sorted[len(sorted)-1] {
+			break
+		}
+		if len(edges) == 0 || edge > edges[len(edges)-1] {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// histogram returns the fraction of values in each bin.
+func (p *NumericProfile) histogram(values []float64) []float64 {
+	fractions := make([]float64, len(p.Edges)+1)
+	for _, v := range values {
+		fractions[p.bin(v)]++
+	}
+	for i := range fractions {
+		fractions[i] /= float64(len(values))
+	}
+	return fractions
+}
+
+// bin returns the bin a value falls into.
+func (p *NumericProfile) bin(v float64) int {
+	i, _ := slices.BinarySearch(p.Edges, v)
+	return i
+}
+
+// LoadProfile reads a profile written by WriteFile.
+func LoadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reference profile: %w", err)
+	}
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse reference profile: %w", err)
+	}
+	for _, f := range numericFeatures {
+		np := p.Numeric[f.name]
+		if np == nil || len(np.Fractions) != len(np.Edges)+1 || len(np.Values) == 0 || !slices.IsSorted(np.Values) {
+			return nil, fmt.Errorf("reference profile has no valid histogram of %s", f.name)
+		}
+	}
+	for _, f := range categoricalFeatures {
+		if cp := p.Categorical[f.name]; cp == nil || len(cp.Fractions) == 0 {
+			return nil, fmt.Errorf("reference profile has no frequency table of %s", f.name)
+		}
+	}
+	return &p, nil
+}
+
+// WriteFile writes the profile as JSON.
+func (p *Profile) WriteFile(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,128 @@
+package drift
+
+import "math"
+
+// minFraction replaces empty bins and unseen categories so that PSI and the
+// chi-square statistic stay finite.
+const minFraction = 1e-4
+
+// psi returns the population stability index of the current distribution against
+// the reference distribution, given as fractions over the same bins.
+func psi(reference, current []float64) float64 {
+	var sum float64
+	for i := range reference {
+		r := max(reference[i], minFraction)
+		c := max(current[i], minFraction)
+		sum += (c - r) * math.Log(c/r)
+	}
+	return sum
+}
+
+// ks returns the two-sample Kolmogorov-Smirnov statistic of two sorted samples and
+// its asymptotic p-value.
+func ks(reference, current []float64) (statistic, pValue float64) {
+	n1, n2 := len(reference), len(current)
+	var i, j int
+	for i < n1 && j < n2 {
+		x := min(reference[i], current[j])
+		for i < n1 && reference[i] <= x {
+			i++
+		}
+		for j < n2 && current[j] <= x {
+			j++
+		}
+		statistic = max(statistic, math.Abs(float64(i)/float64(n1)-float64(j)/float64(n2)))
+	}
+
+	ne := math.Sqrt(float64(n1) * float64(n2) / float64(n1+n2))
+	return statistic, kolmogorovQ((ne + 0.12 + 0.11/ne) * statistic)
+}
+
+// kolmogorovQ returns the complementary cumulative Kolmogorov distribution.
+func kolmogorovQ(lambda float64) float64 {
+	if lambda < 0.2 {
+		return 1
+	}
+	var sum float64
+	sign := 1.0
+	for j := 1; j <= 100; j++ {
+		term := sign * 2 * math.Exp(-2*float64(j*j)*lambda*lambda)
+		sum += term
+		if math.Abs(term) < 1e-12 {
+			break
+		}
+		sign = -sign
+	}
+	return math.Min(math.Max(sum, 0), 1)
+}
+
+// chiSquare returns Pearson's chi-square statistic of observed counts against the
+// expected fractions, and its p-value.
+func chiSquare(expected []float64, observed []int) (statistic, pValue float64) {
+	var n int
+	for _, o := range observed {
+		n += o
+	}
+	var total float64
+	for _, e := range expected {
+		total += max(e, minFraction)
+	}
+	for i, e := range expected {
+		exp := float64(n) * max(e, minFraction) / total
+		d := float64(observed[i]) - exp
+		statistic += d * d / exp
+	}
+	df := float64(len(expected) - 1)
+	if df < 1 {
+		return statistic, 1
+	}
+	return statistic, gammaQ(df/2, statistic/2)
+}
+
+// gammaQ returns the regularized upper incomplete gamma function Q(a, x), using
+// the series expansion for x < a+1 and the continued fraction otherwise.
+func gammaQ(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	lgamma, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lgamma)
+
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1; n < 500; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-15 {
+				break
+			}
+		}
+		return 1 - sum*prefix
+	}
+
+	// Lentz's method
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < 500; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return h * prefix
+}
@@ -0,0 +1,205 @@
+// Package evaluation measures the accuracy of a prediction service on a labeled
+// data set, overall and by slices of the data, and renders the results as JSON or
+// Markdown reports.
+package evaluation
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/domain"
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// DefaultPriceBuckets are the upper bounds of the price buckets in dollars.
+var DefaultPriceBuckets = []float64{10000, 20000, 30000}
+
+// DefaultWorst is the number of largest residuals listed in a report.
+const DefaultWorst = 10
+
+// Dimensions by which the data is sliced.
+const (
+	DimensionBrand       = "brand"
+	DimensionCarbody     = "carbody"
+	DimensionFueltype    = "fueltype"
+	DimensionPriceBucket = "price_bucket"
+)
+
+// Dimensions lists the slicing dimensions in report order.
+var Dimensions = []string{DimensionBrand, DimensionCarbody, DimensionFueltype, DimensionPriceBucket}
+
+// Metrics is the accuracy of predictions on a set of rows.
+type Metrics struct {
+	Count int     `json:"count"`
+	MAE   float64 `json:"mae"`
+	RMSE  float64 `json:"rmse"`
+	// MAPE is the mean absolute percentage error, in percent.
+	MAPE float64 `json:"mape"`
+	// R2 is omitted for fewer than two rows or rows with the same price.
+	R2 *float64 `json:"r2,omitempty"`
+}
+
+// Compute returns the accuracy of predicted against actual prices.
+func Compute(predicted, actual []float64) Metrics {
+	m := Metrics{Count: len(actual)}
+	if m.Count == 0 {
+		return m
+	}
+	var abs, sq, pct, mean float64
+	for i, a := range actual {
+		d := predicted[i] - a
+		abs += math.Abs(d)
+		sq += d * d
+		pct += math.Abs(d) / a
+		mean += a
+	}
+	n := float64(m.Count)
+	mean /= n
+	m.MAE = abs / n
+	m.RMSE = math.Sqrt(sq / n)
+	m.MAPE = 100 * pct / n
+
+	var total float64
+	for _, a := range actual {
+		total += (a - mean) * (a - mean)
+	}
+	if m.Count >= 2 && total > 0 {
+		r2 := 1 - sq/total
+		m.R2 = &r2
+	}
+	return m
+}
+
+// Residual is the prediction error on one row.
+type Residual struct {
+	// Row is the 1-based row of the data set, not counting the header.
+	Row       int     `json:"row"`
+	Name      string  `json:"name"`
+	Actual    float64 `json:"actual"`
+	Predicted float64 `json:"predicted"`
+	// Error is Predicted - Actual; Percent is the error relative to Actual.
+	Error   float64 `json:"error"`
+	Percent float64 `json:"percent"`
+}
+
+// Failure is a row the service could not predict.
+type Failure struct {
+	Row   int    `json:"row"`
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// Report is the accuracy of a model on a data set.
+type Report struct {
+	Model   string  `json:"model"`
+	Version string  `json:"version,omitempty"`
+	Runtime string  `json:"runtime,omitempty"`
+	Dataset string  `json:"dataset"`
+	Rows    int     `json:"rows"`
+	Overall Metrics `json:"overall"`
+	// Slices maps each dimension to the metrics of its values.
+	Slices map[string]map[string]Metrics `json:"slices"`
+	// Worst are the rows with the largest absolute errors.
+	Worst    []Residual `json:"worst"`
+	Failures []Failure  `json:"failures,omitempty"`
+}
+
+// Options configures an evaluation.
+type Options struct {
+	// PriceBuckets are the ascending upper bounds of the price buckets. Defaults to
+	// DefaultPriceBuckets.
+	PriceBuckets []float64
+	// Worst is the number of largest residuals to report. Defaults to DefaultWorst.
+	Worst int
+}
+
+// Evaluate predicts every sample with service, the same way the API does, and
+// reports the accuracy. Rows that fail to predict are listed as failures and
+// excluded from the metrics.
+func Evaluate(service domain.PredictionService, samples []dataset.Sample, opts Options) *Report {
+	if opts.PriceBuckets == nil {
+		opts.PriceBuckets = DefaultPriceBuckets
+	}
+	if opts.Worst <= 0 {
+		opts.Worst = DefaultWorst
+	}
+
+	r := &Report{Rows: len(samples), Slices: map[string]map[string]Metrics{}}
+	type row struct {
+		sample    dataset.Sample
+		predicted float64
+	}
+	var rows []row
+	var residuals []Residual
+	for i, s := range samples {
+		result, err := service.Predict(s.Input)
+		if err != nil {
+			r.Failures = append(r.Failures, Failure{Row: i + 1, Name: s.Name, Error: err.Error()})
+			continue
+		}
+		predicted := float64(result.PredictedPrice)
+		rows = append(rows, row{s, predicted})
+		residuals = append(residuals, Residual{
+			Row:       i + 1,
+			Name:      s.Name,
+			Actual:    s.Price,
+			Predicted: predicted,
+			Error:     predicted - s.Price,
+			Percent:   100 * (predicted - s.Price) / s.Price,
+		})
+	}
+
+	// Compute the metrics of every group of rows
+	groups := map[string]map[string][]row{}
+	for _, d := range Dimensions {
+		groups[d] = map[string][]row{}
+	}
+	var predicted, actual []float64
+	for _, rw := range rows {
+		predicted = append(predicted, rw.predicted)
+		actual = append(actual, rw.sample.Price)
+		in := rw.sample.Input
+		groups[DimensionBrand][in.Brand] = append(groups[DimensionBrand][in.Brand], rw)
+		groups[DimensionCarbody][in.Carbody] = append(groups[DimensionCarbody][in.Carbody], rw)
+		groups[DimensionFueltype][in.Fueltype] = append(groups[DimensionFueltype][in.Fueltype], rw)
+		bucket := priceBucket(rw.sample.Price, opts.PriceBuckets)
+		groups[DimensionPriceBucket][bucket] = append(groups[DimensionPriceBucket][bucket], rw)
+	}
+	r.Overall = Compute(predicted, actual)
+	for d, values := range groups {
+		r.Slices[d] = make(map[string]Metrics, len(values))
+		for value, group := range values {
+			p := make([]float64, len(group))
+			a := make([]float64, len(group))
+			for i, rw := range group {
+				p[i], a[i] = rw.predicted, rw.sample.Price
+			}
+			r.Slices[d][value] = Compute(p, a)
+		}
+	}
+
+	slices.SortStableFunc(residuals, func(a, b Residual) int {
+		return cmp.Compare(math.Abs(b.Error), math.Abs(a.Error))
+	})
+	r.Worst = residuals[:min(opts.Worst, len(residuals))]
+	return r
+}
+
+// priceBucket names the bucket of a price, e.g. "10k-20k".
+func priceBucket(price float64, bounds []float64) string {
+	lower := 0.0
+	for _, upper := range bounds {
+		if price < upper {
+			return fmt.Sprintf("%s-%s", thousands(lower), thousands(upper))
+		}
+		lower = upper
+	}
+	return thousands(lower) + "+"
+}
+
+// thousands formats a dollar amount in thousands, e.g. 12500 as "12.5k".
+func thousands(v float64) string {
+	return strconv.FormatFloat(v/1000, 'f', -1, 64) + "k"
+}
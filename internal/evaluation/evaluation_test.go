@@ -0,0 +1,107 @@
+package evaluation
+
+import (
+	"bytes"
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/domain"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// priceService predicts a fixed price per car name.
+type priceService map[string]float32
+
+func (s priceService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	price, ok := s[input.Brand+" "+input.Carbody]
+	if !ok {
+		return nil, errors.New("unknown car")
+	}
+	return &domain.PredictionResult{PredictedPrice: price}, nil
+}
+
+func sample(name, brand, carbody, fueltype string, price float64) dataset.Sample {
+	return dataset.Sample{
+		Name:  name,
+		Input: domain.UserInput{Brand: brand, Carbody: carbody, Fueltype: fueltype},
+		Price: price,
+	}
+}
+
+func TestCompute(t *testing.T) {
+	m := Compute([]float64{110, 190, 300}, []float64{100, 200, 300})
+	assert.Equal(t, 3, m.Count)
+	assert.InDelta(t, 20.0/3, m.MAE, 1e-9)
+	assert.InDelta(t, 8.165, m.RMSE, 1e-3)
+	assert.InDelta(t, 5, m.MAPE, 1e-9)
+	require.NotNil(t, m.R2)
+	assert.InDelta(t, 1-200.0/20000, *m.R2, 1e-9)
+}
+
+func TestCompute_NoVariance(t *testing.T) {
+	assert.Nil(t, Compute([]float64{90}, []float64{100}).R2)
+	assert.Equal(t, Metrics{}, Compute(nil, nil))
+}
+
+func TestEvaluate(t *testing.T) {
+	service := priceService{
+		"toyota sedan":     9000,
+		"toyota hatchback": 7000,
+		"bmw sedan":        36000,
+	}
+	samples := []dataset.Sample{
+		sample("toyota corolla", "toyota", "sedan", "gas", 8000),
+		sample("toyota starlet", "toyota", "hatchback", "gas", 7000),
+		sample("bmw 320i", "bmw", "sedan", "diesel", 30000),
+		sample("audi 100ls", "audi", "sedan", "gas", 15000),
+	}
+
+	r := Evaluate(service, samples, Options{Worst: 2})
+	assert.Equal(t, 4, r.Rows)
+	assert.Equal(t, 3, r.Overall.Count)
+	assert.InDelta(t, 7000.0/3, r.Overall.MAE, 1e-9)
+
+	assert.Equal(t, 2, r.Slices[DimensionBrand]["toyota"].Count)
+	assert.InDelta(t, 500, r.Slices[DimensionBrand]["toyota"].MAE, 1e-9)
+	assert.InDelta(t, 3500, r.Slices[DimensionCarbody]["sedan"].MAE, 1e-9)
+	assert.InDelta(t, 6000, r.Slices[DimensionFueltype]["diesel"].MAE, 1e-9)
+	assert.Equal(t, map[string]Metrics{
+		"0k-10k": r.Slices[DimensionBrand]["toyota"],
+		"30k+":   r.Slices[DimensionBrand]["bmw"],
+	}, r.Slices[DimensionPriceBucket])
+
+	require.Len(t, r.Worst, 2)
+	assert.Equal(t, Residual{Row: 3, Name: "bmw 320i", Actual: 30000, Predicted: 36000, Error: 6000, Percent: 20}, r.Worst[0])
+	assert.Equal(t, "toyota corolla", r.Worst[1].Name)
+
+	assert.Equal(t, []Failure{{Row: 4, Name: "audi 100ls", Error: "unknown car"}}, r.Failures)
+}
+
+func TestPriceBucket(t *testing.T) {
+	bounds := []float64{10000, 12500}
+	assert.Equal(t, "0k-10k", priceBucket(9999, bounds))
+	assert.Equal(t, "10k-12.5k", priceBucket(10000, bounds))
+	assert.Equal(t, "12.5k+", priceBucket(40000, bounds))
+}
+
+func TestReport_WriteMarkdown(t *testing.T) {
+	service := priceService{"toyota sedan": 9000, "bmw sedan": 27000}
+	r := Evaluate(service, []dataset.Sample{
+		sample("toyota corolla", "toyota", "sedan", "gas", 8000),
+		sample("bmw 320i", "bmw", "sedan", "gas", 30000),
+		sample("audi 100ls", "audi", "sedan", "gas", 15000),
+	}, Options{})
+	r.Model, r.Version, r.Dataset = "model.json", "abc123", "cars.csv"
+
+	var b bytes.Buffer
+	require.NoError(t, r.WriteMarkdown(&b))
+	md := b.String()
+	assert.Contains(t, md, "- Model: model.json (version abc123)\n")
+	assert.Contains(t, md, "- Data: cars.csv, 3 rows, 1 failed\n")
+	assert.Contains(t, md, "| 2 | 2000.00 | 2236.07 | 11.25% | 0.959 |\n")
+	assert.Contains(t, md, "| bmw | 1 | 3000.00 | 3000.00 | 10.00% | - |\n| toyota | 1 |")
+	assert.Contains(t, md, "| 2 | bmw 320i | 30000 | 27000 | -3000 | -10.0% |\n")
+	assert.Contains(t, md, "| 3 | audi 100ls | unknown car |\n")
+}
@@ -0,0 +1,75 @@
+package evaluation
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// WriteMarkdown renders the report as a Markdown document.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Model Evaluation\n\n")
+	model := r.Model
+	if r.Version != "" {
+		model += " (version " + r.Version + ")"
+	}
+	if r.Runtime != "" {
+		model += ", " + r.Runtime
+	}
+	fmt.Fprintf(&b, "- Model: %s\n", model)
+	fmt.Fprintf(&b, "- Data: %s, %d rows", r.Dataset, r.Rows)
+	if len(r.Failures) > 0 {
+		fmt.Fprintf(&b, ", %d failed", len(r.Failures))
+	}
+	b.WriteString("\n\n## Overall\n\n")
+	b.WriteString("| Rows | MAE | RMSE | MAPE | R2 |\n|---:|---:|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| %s |\n", metricCells(r.Overall))
+
+	for _, d := range Dimensions {
+		values := r.Slices[d]
+		if len(values) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## By %s\n\n", strings.ReplaceAll(d, "_", " "))
+		fmt.Fprintf(&b, "| %s | Rows | MAE | RMSE | MAPE | R2 |\n|---|---:|---:|---:|---:|---:|\n", d)
+		// Slices with the largest error come first
+		keys := slices.SortedFunc(maps.Keys(values), func(a, b string) int {
+			return cmp.Or(cmp.Compare(values[b].MAE, values[a].MAE), strings.Compare(a, b))
+		})
+		for _, k := range keys {
+			fmt.Fprintf(&b, "| %s | %s |\n", k, metricCells(values[k]))
+		}
+	}
+
+	if len(r.Worst) > 0 {
+		b.WriteString("\n## Worst Residuals\n\n")
+		b.WriteString("| Row | Car | Actual | Predicted | Error | Error % |\n|---:|---|---:|---:|---:|---:|\n")
+		for _, res := range r.Worst {
+			fmt.Fprintf(&b, "| %d | %s | %.0f | %.0f | %+.0f | %+.1f%% |\n",
+				res.Row, res.Name, res.Actual, res.Predicted, res.Error, res.Percent)
+		}
+	}
+
+	if len(r.Failures) > 0 {
+		b.WriteString("\n## Failures\n\n| Row | Car | Error |\n|---:|---|---|\n")
+		for _, f := range r.Failures {
+			fmt.Fprintf(&b, "| %d | %s | %s |\n", f.Row, f.Name, strings.ReplaceAll(f.Error, "|", `\|`))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// metricCells formats metrics as Markdown table cells.
+func metricCells(m Metrics) string {
+	r2 := "-"
+	if m.R2 != nil {
+		r2 = fmt.Sprintf("%.3f", *m.R2)
+	}
+	return fmt.Sprintf("%d | %.2f | %.2f | %.2f%% | %s", m.Count, m.MAE, m.RMSE, m.MAPE, r2)
+}
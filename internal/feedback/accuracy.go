@@ -0,0 +1,100 @@
+package feedback
+
+import (
+	"math"
+	"time"
+)
+
+// Window is a rolling time window over which accuracy is reported.
+type Window struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Windows are the rolling windows reported by the API and the metrics.
+var Windows = []Window{
+	{Name: "1h", Duration: time.Hour},
+	{Name: "24h", Duration: 24 * time.Hour},
+	{Name: "7d", Duration: 7 * 24 * time.Hour},
+	{Name: "30d", Duration: 30 * 24 * time.Hour},
+}
+
+// Accuracy summarizes how close predictions were to the actual sale prices.
+type Accuracy struct {
+	// Count is the number of sales with feedback.
+	Count int `json:"count" example:"120"`
+	// MAE is the mean absolute error in dollars.
+	MAE float64 `json:"mae" example:"1288.4"`
+	// RMSE is the root mean squared error in dollars.
+	RMSE float64 `json:"rmse" example:"1835.2"`
+	// MAPE is the mean absolute percentage error, in percent.
+	MAPE float64 `json:"mape" example:"9.7"`
+	// R2 is the coefficient of determination. It is omitted for fewer than two
+	// sales or if all sold for the same price.
+	R2 *float64 `json:"r2,omitempty" example:"0.958"`
+}
+
+// accuracy computes the accuracy of the given observations.
+func accuracy(obs []Observation) Accuracy {
+	a := Accuracy{Count: len(obs)}
+	if len(obs) == 0 {
+		return a
+	}
+
+	var absErr, sqErr, pctErr, sumActual float64
+	for _, o := range obs {
+		diff := o.ActualPrice - o.PredictedPrice
+		absErr += math.Abs(diff)
+		sqErr += diff * diff
+		pctErr += math.Abs(diff) / o.ActualPrice
+		sumActual += o.ActualPrice
+	}
+	n := float64(len(obs))
+	a.MAE = absErr / n
+	a.RMSE = math.Sqrt(sqErr / n)
+	a.MAPE = 100 * pctErr / n
+
+	mean := sumActual / n
+	var total float64
+	for _, o := range obs {
+		total += (o.ActualPrice - mean) * (o.ActualPrice - mean)
+	}
+	if len(obs) > 1 && total > 0 {
+		r2 := 1 - sqErr/total
+		a.R2 = &r2
+	}
+	return a
+}
+
+// Report is the accuracy over one window, overall and broken down by model
+// version, brand and body type.
+type Report struct {
+	Window         string              `json:"window" example:"24h"`
+	Since          time.Time           `json:"since"`
+	Overall        Accuracy            `json:"overall"`
+	ByModelVersion map[string]Accuracy `json:"by_model_version"`
+	ByBrand        map[string]Accuracy `json:"by_brand"`
+	ByCarbody      map[string]Accuracy `json:"by_carbody"`
+}
+
+// report computes the report for the observations made since the given time.
+func report(window string, since time.Time, obs []Observation) Report {
+	r := Report{Window: window, Since: since, Overall: accuracy(obs)}
+	r.ByModelVersion = breakdown(obs, func(o Observation) string { return o.ModelVersion })
+	r.ByBrand = breakdown(obs, func(o Observation) string { return o.Brand })
+	r.ByCarbody = breakdown(obs, func(o Observation) string { return o.Carbody })
+	return r
+}
+
+// breakdown computes the accuracy per value of the given dimension.
+func breakdown(obs []Observation, key func(Observation) string) map[string]Accuracy {
+	groups := map[string][]Observation{}
+	for _, o := range obs {
+		groups[key(o)] = append(groups[key(o)], o)
+	}
+	out := make(map[string]Accuracy, len(groups))
+	for k, g := range groups {
+		out[k] = accuracy(g)
+	}
+	return out
+}
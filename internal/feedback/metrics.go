@@ -0,0 +1,88 @@
+package feedback
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Dimensions an accuracy metric can be broken down by. The overall accuracy has
+// dimension "all" and an empty value.
+const (
+	dimensionAll          = "all"
+	dimensionModelVersion = "model_version"
+	dimensionBrand        = "brand"
+	dimensionCarbody      = "carbody"
+)
+
+var (
+	accuracyLabels = []string{"window", "dimension", "value"}
+
+	maeDesc = prometheus.NewDesc("carprice_accuracy_mae",
+		"Mean absolute error of predictions against actual sale prices, in dollars.", accuracyLabels, nil)
+	rmseDesc = prometheus.NewDesc("carprice_accuracy_rmse",
+		"Root mean squared error of predictions against actual sale prices, in dollars.", accuracyLabels, nil)
+	mapeDesc = prometheus.NewDesc("carprice_accuracy_mape",
+		"Mean absolute percentage error of predictions against actual sale prices.", accuracyLabels, nil)
+	r2Desc = prometheus.NewDesc("carprice_accuracy_r2",
+		"Coefficient of determination of predictions against actual sale prices.", accuracyLabels, nil)
+	samplesDesc = prometheus.NewDesc("carprice_accuracy_samples",
+		"Sales with feedback that the accuracy is computed from.", accuracyLabels, nil)
+	alertDesc = prometheus.NewDesc("carprice_accuracy_mae_alert",
+		"1 if the MAE of the model version exceeds the alert threshold, 0 otherwise.", []string{"model_version"}, nil)
+)
+
+// collector exports the tracker's accuracy, computed when the metrics are scraped.
+type collector struct {
+	tracker *Tracker
+}
+
+// Describe implements prometheus.Collector.
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{maeDesc, rmseDesc, mapeDesc, r2Desc, samplesDesc, alertDesc} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	summary := c.tracker.Summary()
+	for _, r := range summary.Windows {
+		collectAccuracy(ch, r.Window, dimensionAll, "", r.Overall)
+		for v, a := range r.ByModelVersion {
+			collectAccuracy(ch, r.Window, dimensionModelVersion, v, a)
+		}
+		for v, a := range r.ByBrand {
+			collectAccuracy(ch, r.Window, dimensionBrand, v, a)
+		}
+		for v, a := range r.ByCarbody {
+			collectAccuracy(ch, r.Window, dimensionCarbody, v, a)
+		}
+	}
+
+	if c.tracker.cfg.MAEThreshold <= 0 {
+		return
+	}
+	firing := map[string]bool{}
+	for _, a := range summary.Alerts {
+		firing[a.ModelVersion] = true
+	}
+	longest := summary.Windows[len(summary.Windows)-1]
+	for v := range longest.ByModelVersion {
+		value := 0.0
+		if firing[v] {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(alertDesc, prometheus.GaugeValue, value, v)
+	}
+}
+
+// collectAccuracy sends the metrics of one accuracy breakdown.
+func collectAccuracy(ch chan<- prometheus.Metric, window, dimension, value string, a Accuracy) {
+	ch <- prometheus.MustNewConstMetric(samplesDesc, prometheus.GaugeValue, float64(a.Count), window, dimension, value)
+	if a.Count == 0 {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(maeDesc, prometheus.GaugeValue, a.MAE, window, dimension, value)
+	ch <- prometheus.MustNewConstMetric(rmseDesc, prometheus.GaugeValue, a.RMSE, window, dimension, value)
+	ch <- prometheus.MustNewConstMetric(mapeDesc, prometheus.GaugeValue, a.MAPE, window, dimension, value)
+	if a.R2 != nil {
+		ch <- prometheus.MustNewConstMetric(r2Desc, prometheus.GaugeValue, *a.R2, window, dimension, value)
+	}
+}
@@ -0,0 +1,317 @@
+// Package feedback tracks how accurate the model is on real sales by comparing
+// predictions with the prices the cars actually sold for.
+package feedback
+
+import (
+	"bufio"
+	"car-price-prediction/internal/domain"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Alert defaults.
+const (
+	DefaultAlertWindow     = 24 * time.Hour
+	DefaultAlertMinSamples = 30
+)
+
+// Observation is a prediction paired with the price the car actually sold for.
+type Observation struct {
+	Time time.Time `json:"time"`
+	// PredictionID is the recorded prediction the sale belongs to. It is empty for
+	// feedback on an input that was predicted when the feedback arrived.
+	PredictionID   string  `json:"prediction_id,omitempty"`
+	ModelVersion   string  `json:"model_version"`
+	Brand          string  `json:"brand"`
+	Carbody        string  `json:"carbody"`
+	PredictedPrice float64 `json:"predicted_price"`
+	ActualPrice    float64 `json:"actual_price"`
+}
+
+// Alert reports a model version whose MAE exceeds the configured threshold.
+type Alert struct {
+	ModelVersion string  `json:"model_version" example:"238dbbdd6d08"`
+	Window       string  `json:"window" example:"24h0m0s"`
+	MAE          float64 `json:"mae" example:"2350.7"`
+	Threshold    float64 `json:"threshold" example:"2000"`
+	Count        int     `json:"count" example:"42"`
+	// Since is when the alert started firing.
+	Since time.Time `json:"since"`
+}
+
+// Summary is the accuracy over every window and the firing alerts.
+type Summary struct {
+	Windows []Report `json:"windows"`
+	Alerts  []Alert  `json:"alerts"`
+}
+
+// Config configures a Tracker.
+type Config struct {
+	// Path is a file that observations are appended to as JSON lines, so that they
+	// survive restarts. Observations are only kept in memory if empty.
+	Path string
+	// MAEThreshold raises an alert for a model version whose MAE over AlertWindow
+	// exceeds it. Zero disables alerts.
+	MAEThreshold float64
+	// AlertWindow is the window the alert MAE is computed over. Defaults to
+	// DefaultAlertWindow.
+	AlertWindow time.Duration
+	// AlertMinSamples is the number of sales needed before an alert can fire.
+	// Defaults to DefaultAlertMinSamples.
+	AlertMinSamples int
+	// Registerer receives the accuracy metrics. Metrics are not exported if nil.
+	Registerer prometheus.Registerer
+}
+
+// Tracker collects observations and computes the rolling accuracy of the model.
+// Observations older than the longest window are discarded.
+type Tracker struct {
+	cfg       Config
+	retention time.Duration
+	now       func() time.Time
+	feedback  *prometheus.CounterVec
+
+	mu     sync.Mutex
+	obs    []Observation // in time order
+	file   *os.File
+	firing map[string]time.Time // model version -> alert start
+}
+
+// Open creates a tracker, loading the observations stored at cfg.Path if set.
+// Close must be called to close the file.
+func Open(cfg Config) (*Tracker, error) {
+	if cfg.AlertWindow <= 0 {
+		cfg.AlertWindow = DefaultAlertWindow
+	}
+	if cfg.AlertMinSamples <= 0 {
+		cfg.AlertMinSamples = DefaultAlertMinSamples
+	}
+	t := &Tracker{
+		cfg:       cfg,
+		retention: max(Windows[len(Windows)-1].Duration, cfg.AlertWindow),
+		now:       time.Now,
+		firing:    map[string]time.Time{},
+		feedback: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "carprice_feedback_total",
+			Help: "Sale prices received, by whether they refer to a recorded prediction or a new input.",
+		}, []string{"source"}),
+	}
+
+	if cfg.Path != "" {
+		if err := t.load(); err != nil {
+			return nil, err
+		}
+		t.alertsLocked(t.now())
+	}
+	if cfg.Registerer != nil {
+		cfg.Registerer.MustRegister(t.feedback, &collector{tracker: t})
+	}
+	return t, nil
+}
+
+// load reads the stored observations, rewrites the file without the expired and
+// replaced ones and opens it for appending.
+func (t *Tracker) load() error {
+	f, err := os.Open(t.cfg.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to open feedback file: %w", err)
+	}
+	if err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var o Observation
+			if err := json.Unmarshal(scanner.Bytes(), &o); err != nil {
+				log.Printf("Skipping corrupt feedback record in %s: %v", t.cfg.Path, err)
+				continue
+			}
+			t.insertLocked(o)
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read feedback file: %w", err)
+		}
+	}
+	t.pruneLocked(t.now())
+
+	// Compact the file so that it only holds the observations still in use
+	tmp, err := os.CreateTemp(filepath.Dir(t.cfg.Path), ".feedback-*")
+	if err != nil {
+		return fmt.Errorf("failed to create feedback file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, o := range t.obs {
+		if err := enc.Encode(o); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write feedback file: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write feedback file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write feedback file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), t.cfg.Path); err != nil {
+		return fmt.Errorf("failed to replace feedback file: %w", err)
+	}
+
+	t.file, err = os.OpenFile(t.cfg.Path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open feedback file: %w", err)
+	}
+	return nil
+}
+
+// Add records an observation, stamping it with the current time if it has none,
+// and returns it. Feedback on a prediction that already has feedback replaces it,
+// so that mistakes can be corrected. It returns a STORAGE_UNAVAILABLE
+// *domain.Error if the observation could not be saved.
+func (t *Tracker) Add(o Observation) (Observation, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if o.Time.IsZero() {
+		o.Time = now.UTC()
+	}
+	if t.file != nil {
+		data, err := json.Marshal(o)
+		if err != nil {
+			return Observation{}, domain.NewError(domain.CodeStorageUnavailable, "The feedback could not be saved.", err)
+		}
+		if _, err := t.file.Write(append(data, '\n')); err != nil {
+			return Observation{}, domain.NewError(domain.CodeStorageUnavailable, "The feedback could not be saved.", err)
+		}
+	}
+
+	t.insertLocked(o)
+	t.pruneLocked(now)
+	t.evaluateLocked(o.ModelVersion, now)
+
+	source := "input"
+	if o.PredictionID != "" {
+		source = "prediction_id"
+	}
+	t.feedback.WithLabelValues(source).Inc()
+	return o, nil
+}
+
+// insertLocked adds o in time order, removing an earlier observation of the same
+// prediction.
+func (t *Tracker) insertLocked(o Observation) {
+	if o.PredictionID != "" {
+		t.obs = slices.DeleteFunc(t.obs, func(e Observation) bool { return e.PredictionID == o.PredictionID })
+	}
+	i := sort.Search(len(t.obs), func(i int) bool { return t.obs[i].Time.After(o.Time) })
+	t.obs = slices.Insert(t.obs, i, o)
+}
+
+// pruneLocked discards the observations older than the retention.
+func (t *Tracker) pruneLocked(now time.Time) {
+	t.obs = t.obs[t.firstSince(now.Add(-t.retention)):]
+}
+
+// firstSince returns the index of the first observation made at or after since.
+func (t *Tracker) firstSince(since time.Time) int {
+	return sort.Search(len(t.obs), func(i int) bool { return !t.obs[i].Time.Before(since) })
+}
+
+// Summary returns the accuracy over every window and the firing alerts.
+func (t *Tracker) Summary() Summary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.pruneLocked(now)
+	s := Summary{Windows: make([]Report, 0, len(Windows)), Alerts: t.alertsLocked(now)}
+	for _, w := range Windows {
+		since := now.Add(-w.Duration)
+		s.Windows = append(s.Windows, report(w.Name, since.UTC(), t.obs[t.firstSince(since):]))
+	}
+	return s
+}
+
+// alertsLocked evaluates the alert of every model version with recent feedback or
+// a firing alert.
+func (t *Tracker) alertsLocked(now time.Time) []Alert {
+	versions := map[string]bool{}
+	for _, o := range t.obs[t.firstSince(now.Add(-t.cfg.AlertWindow)):] {
+		versions[o.ModelVersion] = true
+	}
+	for v := range t.firing {
+		versions[v] = true
+	}
+
+	alerts := []Alert{}
+	for _, v := range slices.Sorted(maps.Keys(versions)) {
+		if a := t.evaluateLocked(v, now); a != nil {
+			alerts = append(alerts, *a)
+		}
+	}
+	return alerts
+}
+
+// evaluateLocked checks the MAE of a model version against the threshold, logging
+// when its alert starts or stops firing. It returns the alert if it fires.
+func (t *Tracker) evaluateLocked(version string, now time.Time) *Alert {
+	if t.cfg.MAEThreshold <= 0 {
+		return nil
+	}
+	var obs []Observation
+	for _, o := range t.obs[t.firstSince(now.Add(-t.cfg.AlertWindow)):] {
+		if o.ModelVersion == version {
+			obs = append(obs, o)
+		}
+	}
+	a := accuracy(obs)
+	firing := a.Count >= t.cfg.AlertMinSamples && a.MAE > t.cfg.MAEThreshold
+
+	since, wasFiring := t.firing[version]
+	switch {
+	case firing && !wasFiring:
+		since = now.UTC()
+		t.firing[version] = since
+		log.Printf("Accuracy alert: MAE of model %s over the last %s is %.0f, above the threshold of %.0f (%d sales)",
+			version, t.cfg.AlertWindow, a.MAE, t.cfg.MAEThreshold, a.Count)
+	case !firing && wasFiring:
+		delete(t.firing, version)
+		log.Printf("Accuracy alert resolved: MAE of model %s over the last %s is %.0f (%d sales)",
+			version, t.cfg.AlertWindow, a.MAE, a.Count)
+	}
+	if !firing {
+		return nil
+	}
+	return &Alert{
+		ModelVersion: version,
+		Window:       t.cfg.AlertWindow.String(),
+		MAE:          a.MAE,
+		Threshold:    t.cfg.MAEThreshold,
+		Count:        a.Count,
+		Since:        since,
+	}
+}
+
+// Close closes the feedback file.
+func (t *Tracker) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
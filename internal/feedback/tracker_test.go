@@ -0,0 +1,175 @@
+package feedback
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestTracker opens a tracker whose clock is controlled by the returned pointer.
+func openTestTracker(t *testing.T, cfg Config) (*Tracker, *time.Time) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	clock := &now
+	tr, err := Open(cfg)
+	require.NoError(t, err)
+	tr.now = func() time.Time { return *clock }
+	t.Cleanup(func() { tr.Close() })
+	return tr, clock
+}
+
+func observation(id, version, brand string, predicted, actual float64) Observation {
+	return Observation{PredictionID: id, ModelVersion: version, Brand: brand, Carbody: "sedan", PredictedPrice: predicted, ActualPrice: actual}
+}
+
+func TestAccuracy(t *testing.T) {
+	a := accuracy([]Observation{
+		{PredictedPrice: 9000, ActualPrice: 10000},
+		{PredictedPrice: 22000, ActualPrice: 20000},
+	})
+
+	assert.Equal(t, 2, a.Count)
+	assert.InDelta(t, 1500, a.MAE, 1e-9)
+	assert.InDelta(t, 1581.14, a.RMSE, 0.01)
+	assert.InDelta(t, 10, a.MAPE, 1e-9)
+	require.NotNil(t, a.R2)
+	assert.InDelta(t, 1-5e6/5e7, *a.R2, 1e-9)
+
+	// R2 is undefined for a single sale
+	assert.Nil(t, accuracy([]Observation{{PredictedPrice: 9000, ActualPrice: 10000}}).R2)
+	assert.Equal(t, Accuracy{}, accuracy(nil))
+}
+
+func TestTracker_WindowsAndBreakdowns(t *testing.T) {
+	tr, clock := openTestTracker(t, Config{})
+
+	_, err := tr.Add(observation("a", "v1", "audi", 9000, 10000))
+	require.NoError(t, err)
+	*clock = clock.Add(2 * time.Hour)
+	_, err = tr.Add(observation("b", "v2", "bmw", 21000, 20000))
+	require.NoError(t, err)
+
+	s := tr.Summary()
+	require.Len(t, s.Windows, len(Windows))
+	hour, day := s.Windows[0], s.Windows[1]
+	assert.Equal(t, "1h", hour.Window)
+	assert.Equal(t, 1, hour.Overall.Count)
+	assert.Equal(t, 2, day.Overall.Count)
+	assert.InDelta(t, 1000, day.ByModelVersion["v1"].MAE, 1e-9)
+	assert.InDelta(t, 1000, day.ByBrand["bmw"].MAE, 1e-9)
+	assert.Equal(t, 2, day.ByCarbody["sedan"].Count)
+
+	// Observations age out of every window
+	*clock = clock.Add(31 * 24 * time.Hour)
+	assert.Equal(t, 0, tr.Summary().Windows[3].Overall.Count)
+}
+
+func TestTracker_FeedbackReplacesEarlierFeedback(t *testing.T) {
+	tr, _ := openTestTracker(t, Config{})
+
+	_, err := tr.Add(observation("a", "v1", "audi", 9000, 1000))
+	require.NoError(t, err)
+	_, err = tr.Add(observation("a", "v1", "audi", 9000, 10000))
+	require.NoError(t, err)
+
+	overall := tr.Summary().Windows[0].Overall
+	assert.Equal(t, 1, overall.Count)
+	assert.InDelta(t, 1000, overall.MAE, 1e-9)
+}
+
+func TestTracker_PersistsObservations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feedback.jsonl")
+	tr, clock := openTestTracker(t, Config{Path: path})
+	old := observation("a", "v1", "audi", 9000, 10000)
+	old.Time = clock.Add(-40 * 24 * time.Hour)
+	_, err := tr.Add(old)
+	require.NoError(t, err)
+	_, err = tr.Add(observation("b", "v1", "audi", 9000, 10000))
+	require.NoError(t, err)
+	_, err = tr.Add(observation("b", "v1", "audi", 9500, 10000))
+	require.NoError(t, err)
+	require.NoError(t, tr.Close())
+
+	reopened, err := Open(Config{Path: path})
+	require.NoError(t, err)
+	defer reopened.Close()
+	reopened.now = tr.now
+
+	overall := reopened.Summary().Windows[3].Overall
+	assert.Equal(t, 1, overall.Count)
+	assert.InDelta(t, 500, overall.MAE, 1e-9)
+
+	// The file is compacted when opened
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\n"))
+}
+
+func TestTracker_MAEAlert(t *testing.T) {
+	tr, clock := openTestTracker(t, Config{MAEThreshold: 2000, AlertMinSamples: 2, AlertWindow: time.Hour})
+
+	_, err := tr.Add(observation("a", "v1", "audi", 5000, 10000))
+	require.NoError(t, err)
+	assert.Empty(t, tr.Summary().Alerts, "too few samples")
+
+	_, err = tr.Add(observation("b", "v1", "audi", 7000, 10000))
+	require.NoError(t, err)
+	_, err = tr.Add(observation("c", "v2", "audi", 9900, 10000))
+	require.NoError(t, err)
+
+	alerts := tr.Summary().Alerts
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "v1", alerts[0].ModelVersion)
+	assert.InDelta(t, 4000, alerts[0].MAE, 1e-9)
+	assert.Equal(t, *clock, alerts[0].Since)
+
+	// The alert resolves once the bad predictions leave the window
+	*clock = clock.Add(2 * time.Hour)
+	assert.Empty(t, tr.Summary().Alerts)
+}
+
+func TestTracker_Metrics(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	tr, _ := openTestTracker(t, Config{MAEThreshold: 2000, AlertMinSamples: 1, Registerer: reg})
+
+	_, err := tr.Add(observation("a", "v1", "audi", 5000, 10000))
+	require.NoError(t, err)
+	_, err = tr.Add(observation("", "v1", "audi", 9000, 10000))
+	require.NoError(t, err)
+
+	expected := `
+# HELP carprice_accuracy_mae_alert 1 if the MAE of the model version exceeds the alert threshold, 0 otherwise.
+# TYPE carprice_accuracy_mae_alert gauge
+carprice_accuracy_mae_alert{model_version="v1"} 1
+# HELP carprice_feedback_total Sale prices received, by whether they refer to a recorded prediction or a new input.
+# TYPE carprice_feedback_total counter
+carprice_feedback_total{source="input"} 1
+carprice_feedback_total{source="prediction_id"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "carprice_accuracy_mae_alert", "carprice_feedback_total"))
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	var mae float64
+	for _, f := range families {
+		if f.GetName() != "carprice_accuracy_mae" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["window"] == "24h" && labels["dimension"] == "brand" && labels["value"] == "audi" {
+				mae = m.GetGauge().GetValue()
+			}
+		}
+	}
+	assert.InDelta(t, 3000, mae, 1e-9)
+}
@@ -0,0 +1,144 @@
+// Package forest implements random forest regression: growing an ensemble of
+// regression trees on bootstrap samples and predicting with their mean.
+package forest
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Leaf is the Feature of leaf nodes.
+const Leaf = -1
+
+// Node is a node of a regression tree. Internal nodes send a sample to Left if its
+// value of Feature is less than or equal to Threshold and to Right otherwise;
+// leaves predict Value.
+type Node struct {
+	Feature   int     `json:"feature"`
+	Threshold float32 `json:"threshold,omitempty"`
+	Left      int     `json:"left,omitempty"`
+	Right     int     `json:"right,omitempty"`
+	Value     float64 `json:"value,omitempty"`
+	// Samples is the number of training samples that reached the node.
+	Samples int `json:"samples"`
+}
+
+// IsLeaf reports whether the node is a leaf.
+func (n Node) IsLeaf() bool {
+	return n.Feature == Leaf
+}
+
+// Tree is a regression tree whose nodes are stored in depth-first order, starting
+// with the root.
+type Tree struct {
+	Nodes []Node `json:"nodes"`
+}
+
+// Predict returns the value of the leaf x falls into.
+func (t *Tree) Predict(x []float32) float64 {
+	n := t.Nodes[0]
+	for !n.IsLeaf() {
+		if x[n.Feature] <= n.Threshold {
+			n = t.Nodes[n.Left]
+		} else {
+			n = t.Nodes[n.Right]
+		}
+	}
+	return n.Value
+}
+
+// Depth returns the number of splits on the longest path from the root to a leaf.
+func (t *Tree) Depth() int {
+	var depth func(i int) int
+	depth = func(i int) int {
+		n := t.Nodes[i]
+		if n.IsLeaf() {
+			return 0
+		}
+		return 1 + max(depth(n.Left), depth(n.Right))
+	}
+	return depth(0)
+}
+
+// Leaves returns the number of leaves.
+func (t *Tree) Leaves() int {
+	var leaves int
+	for _, n := range t.Nodes {
+		if n.IsLeaf() {
+			leaves++
+		}
+	}
+	return leaves
+}
+
+// Forest is an ensemble of regression trees over a fixed number of features.
+type Forest struct {
+	Features int    `json:"features"`
+	Trees    []Tree `json:"trees"`
+}
+
+// Predict returns the mean prediction of the trees.
+func (f *Forest) Predict(x []float32) float64 {
+	var sum float64
+	for i := range f.Trees {
+		sum += f.Trees[i].Predict(x)
+	}
+	return sum / float64(len(f.Trees))
+}
+
+// TreePredictions returns the prediction of every tree.
+func (f *Forest) TreePredictions(x []float32) []float64 {
+	predictions := make([]float64, len(f.Trees))
+	for i := range f.Trees {
+		predictions[i] = f.Trees[i].Predict(x)
+	}
+	return predictions
+}
+
+// Validate checks that every tree is well formed: children follow their parent and
+// split features exist, so that Predict cannot loop or index out of range.
+func (f *Forest) Validate() error {
+	if len(f.Trees) == 0 {
+		return fmt.Errorf("forest has no trees")
+	}
+	for i, t := range f.Trees {
+		if len(t.Nodes) == 0 {
+			return fmt.Errorf("tree %d has no nodes", i)
+		}
+		for j, n := range t.Nodes {
+			if n.IsLeaf() {
+				continue
+			}
+			if n.Feature < 0 || n.Feature >= f.Features {
+				return fmt.Errorf("tree %d node %d splits on unknown feature %d", i, j, n.Feature)
+			}
+			if n.Left <= j || n.Right <= j || n.Left >= len(t.Nodes) || n.Right >= len(t.Nodes) {
+				return fmt.Errorf("tree %d node %d has invalid children", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// SplitCounts returns how often each feature is split on across the forest.
+func (f *Forest) SplitCounts() []int {
+	counts := make([]int, f.Features)
+	for _, t := range f.Trees {
+		for _, n := range t.Nodes {
+			if !n.IsLeaf() {
+				counts[n.Feature]++
+			}
+		}
+	}
+	return counts
+}
+
+// Depths returns the depth of every tree, sorted.
+func (f *Forest) Depths() []int {
+	depths := make([]int, len(f.Trees))
+	for i := range f.Trees {
+		depths[i] = f.Trees[i].Depth()
+	}
+	slices.Sort(depths)
+	return depths
+}
@@ -0,0 +1,115 @@
+package forest
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stepData returns rows whose target is 10 below x0 = 5 and 20 above, plus noise
+// features the target does not depend on.
+func stepData(n int) ([][]float32, []float64) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	x := make([][]float32, n)
+	y := make([]float64, n)
+	for i := range x {
+		x[i] = []float32{float32(rng.IntN(10)), rng.Float32(), rng.Float32()}
+		y[i] = 10
+		if x[i][0] > 5 {
+			y[i] = 20
+		}
+	}
+	return x, y
+}
+
+func TestTree_Predict(t *testing.T) {
+	tree := Tree{Nodes: []Node{
+		{Feature: 0, Threshold: 1.5, Left: 1, Right: 2},
+		{Feature: Leaf, Value: 1},
+		{Feature: 1, Threshold: 0, Left: 3, Right: 4},
+		{Feature: Leaf, Value: 2},
+		{Feature: Leaf, Value: 3},
+	}}
+	assert.Equal(t, 1.0, tree.Predict([]float32{1.5, 9}))
+	assert.Equal(t, 2.0, tree.Predict([]float32{2, 0}))
+	assert.Equal(t, 3.0, tree.Predict([]float32{2, 0.1}))
+	assert.Equal(t, 2, tree.Depth())
+	assert.Equal(t, 3, tree.Leaves())
+}
+
+func TestTrain_LearnsStep(t *testing.T) {
+	x, y := stepData(200)
+	f, err := Train(x, y, DefaultParams())
+	require.NoError(t, err)
+	require.NoError(t, f.Validate())
+
+	assert.Len(t, f.Trees, 100)
+	assert.InDelta(t, 10, f.Predict([]float32{2, 0.5, 0.5}), 1e-9)
+	assert.InDelta(t, 20, f.Predict([]float32{8, 0.5, 0.5}), 1e-9)
+	for _, tree := range f.Trees {
+		root := tree.Nodes[0]
+		assert.Equal(t, 0, root.Feature)
+		assert.Equal(t, float32(5.5), root.Threshold)
+		assert.Equal(t, 1, tree.Depth(), "both sides are pure after one split")
+	}
+	assert.Equal(t, []int{100, 0, 0}, f.SplitCounts())
+}
+
+func TestTrain_Params(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	x := make([][]float32, 300)
+	y := make([]float64, len(x))
+	for i := range x {
+		x[i] = []float32{rng.Float32(), rng.Float32()}
+		y[i] = math.Sin(6*float64(x[i][0])) + float64(x[i][1])
+	}
+
+	p := DefaultParams()
+	p.Trees, p.MaxDepth, p.MinSamplesLeaf = 10, 3, 5
+	f, err := Train(x, y, p)
+	require.NoError(t, err)
+	for _, tree := range f.Trees {
+		assert.LessOrEqual(t, tree.Depth(), 3)
+		for _, n := range tree.Nodes {
+			if n.IsLeaf() {
+				assert.GreaterOrEqual(t, n.Samples, 5)
+			}
+		}
+	}
+
+	// Training is reproducible for a seed, regardless of scheduling
+	again, err := Train(x, y, p)
+	require.NoError(t, err)
+	assert.Equal(t, f, again)
+	p.Seed++
+	other, err := Train(x, y, p)
+	require.NoError(t, err)
+	assert.NotEqual(t, f, other)
+}
+
+func TestTrain_InvalidInput(t *testing.T) {
+	_, err := Train(nil, nil, DefaultParams())
+	assert.Error(t, err)
+	_, err = Train([][]float32{{1}, {1, 2}}, []float64{1, 2}, DefaultParams())
+	assert.Error(t, err)
+	_, err = Train([][]float32{{1}}, []float64{1}, Params{})
+	assert.Error(t, err)
+}
+
+func TestForest_Validate(t *testing.T) {
+	f := &Forest{Features: 1, Trees: []Tree{{Nodes: []Node{{Feature: 0, Left: 0, Right: 1}, {Feature: Leaf}}}}}
+	assert.Error(t, f.Validate(), "a node must not be its own child")
+	f.Trees[0].Nodes[0] = Node{Feature: 3, Left: 1, Right: 1}
+	assert.Error(t, f.Validate(), "unknown feature")
+	assert.Error(t, (&Forest{Features: 1}).Validate())
+}
+
+func TestMidpoint(t *testing.T) {
+	assert.Equal(t, float32(1.5), midpoint(1, 2))
+	lo := float32(1)
+	hi := math.Nextafter32(lo, 2)
+	assert.Equal(t, lo, midpoint(lo, hi), "adjacent values split at the lower one")
+}
@@ -0,0 +1,184 @@
+package forest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// Params configures training. The defaults match scikit-learn's
+// RandomForestRegressor, which the served model was trained with.
+type Params struct {
+	// Trees is the number of trees in the forest.
+	Trees int `json:"trees"`
+	// MaxDepth limits the depth of each tree; 0 grows trees until their leaves are pure.
+	MaxDepth int `json:"max_depth"`
+	// MinSamplesSplit is the minimum number of samples a node needs to be split.
+	MinSamplesSplit int `json:"min_samples_split"`
+	// MinSamplesLeaf is the minimum number of samples in each leaf.
+	MinSamplesLeaf int `json:"min_samples_leaf"`
+	// MaxFeatures is the fraction of features considered for each split; 0 or 1
+	// considers all of them.
+	MaxFeatures float64 `json:"max_features"`
+	// Seed makes bootstrap samples and feature subsets reproducible.
+	Seed uint64 `json:"seed"`
+}
+
+// DefaultParams returns the default training parameters.
+func DefaultParams() Params {
+	return Params{Trees: 100, MinSamplesSplit: 2, MinSamplesLeaf: 1, MaxFeatures: 1, Seed: 42}
+}
+
+// Train grows a forest on the rows of x and their targets y. Each tree is grown on
+// a bootstrap sample by choosing, at every node, the split that reduces the squared
+// error the most. Trees are grown in parallel; the result only depends on p.Seed.
+func Train(x [][]float32, y []float64, p Params) (*Forest, error) {
+	if len(x) == 0 || len(x) != len(y) {
+		return nil, fmt.Errorf("need the same positive number of rows and targets, got %d and %d", len(x), len(y))
+	}
+	features := len(x[0])
+	for i, row := range x {
+		if len(row) != features {
+			return nil, fmt.Errorf("row %d has %d features, want %d", i, len(row), features)
+		}
+	}
+	if p.Trees <= 0 {
+		return nil, errors.New("need at least one tree")
+	}
+	p.MinSamplesSplit = max(p.MinSamplesSplit, 2)
+	p.MinSamplesLeaf = max(p.MinSamplesLeaf, 1)
+	if p.MaxFeatures < 0 || p.MaxFeatures > 1 {
+		return nil, fmt.Errorf("max features must be a fraction between 0 and 1, got %g", p.MaxFeatures)
+	}
+
+	f := &Forest{Features: features, Trees: make([]Tree, p.Trees)}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), p.Trees) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				f.Trees[i] = grow(x, y, p, rand.New(rand.NewPCG(p.Seed, uint64(i))))
+			}
+		}()
+	}
+	for i := range p.Trees {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return f, nil
+}
+
+// grow grows one tree on a bootstrap sample drawn with rng.
+func grow(x [][]float32, y []float64, p Params, rng *rand.Rand) Tree {
+	sample := make([]int, len(x))
+	for i := range sample {
+		sample[i] = rng.IntN(len(x))
+	}
+	b := &builder{x: x, y: y, p: p, rng: rng, features: make([]int, len(x[0]))}
+	for i := range b.features {
+		b.features[i] = i
+	}
+	b.grow(sample, 0)
+	return Tree{Nodes: b.nodes}
+}
+
+// builder grows a tree node by node.
+type builder struct {
+	x        [][]float32
+	y        []float64
+	p        Params
+	rng      *rand.Rand
+	features []int
+	nodes    []Node
+}
+
+// grow adds the subtree for the samples to the tree and returns the index of its root.
+func (b *builder) grow(samples []int, depth int) int {
+	var sum float64
+	for _, s := range samples {
+		sum += b.y[s]
+	}
+	i := len(b.nodes)
+	b.nodes = append(b.nodes, Node{Feature: Leaf, Value: sum / float64(len(samples)), Samples: len(samples)})
+
+	if len(samples) < b.p.MinSamplesSplit || len(samples) < 2*b.p.MinSamplesLeaf ||
+		(b.p.MaxDepth > 0 && depth >= b.p.MaxDepth) {
+		return i
+	}
+	feature, threshold, ok := b.split(samples, sum)
+	if !ok {
+		return i
+	}
+
+	// Partition the samples so that those going left come first
+	k := 0
+	for j, s := range samples {
+		if b.x[s][feature] <= threshold {
+			samples[j], samples[k] = samples[k], samples[j]
+			k++
+		}
+	}
+	left := b.grow(samples[:k], depth+1)
+	right := b.grow(samples[k:], depth+1)
+	b.nodes[i] = Node{Feature: feature, Threshold: threshold, Left: left, Right: right, Samples: len(samples)}
+	return i
+}
+
+// split finds the feature and threshold that minimize the squared error of the two
+// children. It reports false if no split reduces the error.
+func (b *builder) split(samples []int, sum float64) (feature int, threshold float32, ok bool) {
+	candidates := b.features
+	if b.p.MaxFeatures > 0 && b.p.MaxFeatures < 1 {
+		b.rng.Shuffle(len(b.features), func(i, j int) { b.features[i], b.features[j] = b.features[j], b.features[i] })
+		candidates = b.features[:max(1, int(b.p.MaxFeatures*float64(len(b.features))))]
+	}
+
+	// Minimizing the squared error is maximizing sum²/n summed over both children
+	n := len(samples)
+	best := sum * sum / float64(n)
+	bestScore := best + 1e-9*max(1, best)
+	sorted := make([]int, n)
+	for _, f := range candidates {
+		copy(sorted, samples)
+		slices.SortFunc(sorted, func(a, c int) int {
+			switch va, vc := b.x[a][f], b.x[c][f]; {
+			case va < vc:
+				return -1
+			case va > vc:
+				return 1
+			}
+			return 0
+		})
+
+		var left float64
+		for pos := 1; pos < n; pos++ {
+			left += b.y[sorted[pos-1]]
+			lo, hi := b.x[sorted[pos-1]][f], b.x[sorted[pos]][f]
+			if pos < b.p.MinSamplesLeaf || n-pos < b.p.MinSamplesLeaf || lo == hi {
+				continue
+			}
+			right := sum - left
+			score := left*left/float64(pos) + right*right/float64(n-pos)
+			if score > bestScore {
+				bestScore, feature, threshold, ok = score, f, midpoint(lo, hi), true
+			}
+		}
+	}
+	return feature, threshold, ok
+}
+
+// midpoint returns a threshold between lo and hi that separates them, preferring
+// their midpoint.
+func midpoint(lo, hi float32) float32 {
+	m := float32((float64(lo) + float64(hi)) / 2)
+	if m >= hi {
+		return lo
+	}
+	return m
+}
@@ -0,0 +1,81 @@
+package grpcapi
+
+import (
+	"car-price-prediction/internal/auth"
+	pb "car-price-prediction/internal/grpcapi/carpricev1"
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// apiKeyMetadata is the metadata key carrying the API key, matching the REST X-API-Key header.
+const apiKeyMetadata = "x-api-key"
+
+// authorizationMetadata is the metadata key carrying a bearer token.
+const authorizationMetadata = "authorization"
+
+// methodScopes maps each CarPriceService method to the scope it requires.
+var methodScopes = map[string]string{
+	pb.CarPriceService_Predict_FullMethodName:       auth.ScopePredictRead,
+	pb.CarPriceService_PredictBatch_FullMethodName:  auth.ScopePredictRead,
+	pb.CarPriceService_PredictStream_FullMethodName: auth.ScopePredictRead,
+	pb.CarPriceService_Explain_FullMethodName:       auth.ScopeExplainRead,
+}
+
+// WithAuth returns server options that require valid credentials on every
+// CarPriceService call, check the method's scope and apply the API key's rate
+// limit. Health and reflection services stay open. Quota is charged per
+// prediction by the service itself.
+func WithAuth(authenticator *auth.Authenticator) []grpc.ServerOption {
+	authenticate := func(ctx context.Context, method string) (context.Context, error) {
+		scope, ok := methodScopes[method]
+		if !ok {
+			return ctx, nil
+		}
+
+		var creds auth.Credentials
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(apiKeyMetadata); len(values) > 0 {
+				creds.APIKey = values[0]
+			}
+			if values := md.Get(authorizationMetadata); len(values) > 0 {
+				if scheme, token, found := strings.Cut(values[0], " "); found && strings.EqualFold(scheme, "bearer") {
+					creds.BearerToken = strings.TrimSpace(token)
+				}
+			}
+		}
+		req := auth.Request{Method: "gRPC", Resource: method}
+		if p, ok := peer.FromContext(ctx); ok {
+			req.RemoteAddr = p.Addr.String()
+		}
+
+		principal, err := authenticator.Authenticate(creds, req)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		if err := authenticator.Authorize(principal, scope, req); err != nil {
+			return nil, toStatus(err)
+		}
+		return auth.NewContext(ctx, principal), nil
+	}
+
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+
+	return []grpc.ServerOption{grpc.ChainUnaryInterceptor(unary), grpc.ChainStreamInterceptor(stream)}
+}
@@ -0,0 +1,782 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: carprice/v1/carprice.proto
+
+package carpricev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// UserInput mirrors domain.UserInput.
+type UserInput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Numerical features
+	Symboling        int32   `protobuf:"varint,1,opt,name=symboling,proto3" json:"symboling,omitempty"`
+	Wheelbase        float32 `protobuf:"fixed32,2,opt,name=wheelbase,proto3" json:"wheelbase,omitempty"`
+	Carlength        float32 `protobuf:"fixed32,3,opt,name=carlength,proto3" json:"carlength,omitempty"`
+	Carwidth         float32 `protobuf:"fixed32,4,opt,name=carwidth,proto3" json:"carwidth,omitempty"`
+	Carheight        float32 `protobuf:"fixed32,5,opt,name=carheight,proto3" json:"carheight,omitempty"`
+	Curbweight       int32   `protobuf:"varint,6,opt,name=curbweight,proto3" json:"curbweight,omitempty"`
+	Enginesize       int32   `protobuf:"varint,7,opt,name=enginesize,proto3" json:"enginesize,omitempty"`
+	Boreratio        float32 `protobuf:"fixed32,8,opt,name=boreratio,proto3" json:"boreratio,omitempty"`
+	Stroke           float32 `protobuf:"fixed32,9,opt,name=stroke,proto3" json:"stroke,omitempty"`
+	Compressionratio float32 `protobuf:"fixed32,10,opt,name=compressionratio,proto3" json:"compressionratio,omitempty"`
+	Horsepower       int32   `protobuf:"varint,11,opt,name=horsepower,proto3" json:"horsepower,omitempty"`
+	Peakrpm          int32   `protobuf:"varint,12,opt,name=peakrpm,proto3" json:"peakrpm,omitempty"`
+	Citympg          int32   `protobuf:"varint,13,opt,name=citympg,proto3" json:"citympg,omitempty"`
+	Highwaympg       int32   `protobuf:"varint,14,opt,name=highwaympg,proto3" json:"highwaympg,omitempty"`
+	// Categorical features
+	Fueltype       string `protobuf:"bytes,15,opt,name=fueltype,proto3" json:"fueltype,omitempty"`
+	Aspiration     string `protobuf:"bytes,16,opt,name=aspiration,proto3" json:"aspiration,omitempty"`
+	Doornumber     string `protobuf:"bytes,17,opt,name=doornumber,proto3" json:"doornumber,omitempty"`
+	Carbody        string `protobuf:"bytes,18,opt,name=carbody,proto3" json:"carbody,omitempty"`
+	Drivewheel     string `protobuf:"bytes,19,opt,name=drivewheel,proto3" json:"drivewheel,omitempty"`
+	Enginelocation string `protobuf:"bytes,20,opt,name=enginelocation,proto3" json:"enginelocation,omitempty"`
+	Enginetype     string `protobuf:"bytes,21,opt,name=enginetype,proto3" json:"enginetype,omitempty"`
+	Cylindernumber string `protobuf:"bytes,22,opt,name=cylindernumber,proto3" json:"cylindernumber,omitempty"`
+	Fuelsystem     string `protobuf:"bytes,23,opt,name=fuelsystem,proto3" json:"fuelsystem,omitempty"`
+	Brand          string `protobuf:"bytes,24,opt,name=brand,proto3" json:"brand,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UserInput) Reset() {
+	*x = UserInput{}
+	mi := &file_carprice_v1_carprice_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserInput) ProtoMessage() {}
+
+func (x *UserInput) ProtoReflect() protoreflect.Message {
+	mi := &file_carprice_v1_carprice_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserInput.ProtoReflect.Descriptor instead.
+func (*UserInput) Descriptor() ([]byte, []int) {
+	return file_carprice_v1_carprice_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UserInput) GetSymboling() int32 {
+	if x != nil {
+		return x.Symboling
+	}
+	return 0
+}
+
+func (x *UserInput) GetWheelbase() float32 {
+	if x != nil {
+		return x.Wheelbase
+	}
+	return 0
+}
+
+func (x *UserInput) GetCarlength() float32 {
+	if x != nil {
+		return x.Carlength
+	}
+	return 0
+}
+
+func (x *UserInput) GetCarwidth() float32 {
+	if x != nil {
+		return x.Carwidth
+	}
+	return 0
+}
+
+func (x *UserInput) GetCarheight() float32 {
+	if x != nil {
+		return x.Carheight
+	}
+	return 0
+}
+
+func (x *UserInput) GetCurbweight() int32 {
+	if x != nil {
+		return x.Curbweight
+	}
+	return 0
+}
+
+func (x *UserInput) GetEnginesize() int32 {
+	if x != nil {
+		return x.Enginesize
+	}
+	return 0
+}
+
+func (x *UserInput) GetBoreratio() float32 {
+	if x != nil {
+		return x.Boreratio
+	}
+	return 0
+}
+
+func (x *UserInput) GetStroke() float32 {
+	if x != nil {
+		return x.Stroke
+	}
+	return 0
+}
+
+func (x *UserInput) GetCompressionratio() float32 {
+	if x != nil {
+		return x.Compressionratio
+	}
+	return 0
+}
+
+func (x *UserInput) GetHorsepower() int32 {
+	if x != nil {
+		return x.Horsepower
+	}
+	return 0
+}
+
+func (x *UserInput) GetPeakrpm() int32 {
+	if x != nil {
+		return x.Peakrpm
+	}
+	return 0
+}
+
+func (x *UserInput) GetCitympg() int32 {
+	if x != nil {
+		return x.Citympg
+	}
+	return 0
+}
+
+func (x *UserInput) GetHighwaympg() int32 {
+	if x != nil {
+		return x.Highwaympg
+	}
+	return 0
+}
+
+func (x *UserInput) GetFueltype() string {
+	if x != nil {
+		return x.Fueltype
+	}
+	return ""
+}
+
+func (x *UserInput) GetAspiration() string {
+	if x != nil {
+		return x.Aspiration
+	}
+	return ""
+}
+
+func (x *UserInput) GetDoornumber() string {
+	if x != nil {
+		return x.Doornumber
+	}
+	return ""
+}
+
+func (x *UserInput) GetCarbody() string {
+	if x != nil {
+		return x.Carbody
+	}
+	return ""
+}
+
+func (x *UserInput) GetDrivewheel() string {
+	if x != nil {
+		return x.Drivewheel
+	}
+	return ""
+}
+
+func (x *UserInput) GetEnginelocation() string {
+	if x != nil {
+		return x.Enginelocation
+	}
+	return ""
+}
+
+func (x *UserInput) GetEnginetype() string {
+	if x != nil {
+		return x.Enginetype
+	}
+	return ""
+}
+
+func (x *UserInput) GetCylindernumber() string {
+	if x != nil {
+		return x.Cylindernumber
+	}
+	return ""
+}
+
+func (x *UserInput) GetFuelsystem() string {
+	if x != nil {
+		return x.Fuelsystem
+	}
+	return ""
+}
+
+func (x *UserInput) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+// PredictionResult mirrors domain.PredictionResult.
+type PredictionResult struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	PredictedPrice float32                `protobuf:"fixed32,1,opt,name=predicted_price,json=predictedPrice,proto3" json:"predicted_price,omitempty"`
+	// Identifies the recorded prediction when the prediction log is enabled.
+	PredictionId  string `protobuf:"bytes,2,opt,name=prediction_id,json=predictionId,proto3" json:"prediction_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PredictionResult) Reset() {
+	*x = PredictionResult{}
+	mi := &file_carprice_v1_carprice_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PredictionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictionResult) ProtoMessage() {}
+
+func (x *PredictionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_carprice_v1_carprice_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictionResult.ProtoReflect.Descriptor instead.
+func (*PredictionResult) Descriptor() ([]byte, []int) {
+	return file_carprice_v1_carprice_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PredictionResult) GetPredictedPrice() float32 {
+	if x != nil {
+		return x.PredictedPrice
+	}
+	return 0
+}
+
+func (x *PredictionResult) GetPredictionId() string {
+	if x != nil {
+		return x.PredictionId
+	}
+	return ""
+}
+
+type PredictRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional client-chosen identifier, echoed back in the response.
+	RequestId     string     `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Input         *UserInput `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PredictRequest) Reset() {
+	*x = PredictRequest{}
+	mi := &file_carprice_v1_carprice_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PredictRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictRequest) ProtoMessage() {}
+
+func (x *PredictRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_carprice_v1_carprice_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictRequest.ProtoReflect.Descriptor instead.
+func (*PredictRequest) Descriptor() ([]byte, []int) {
+	return file_carprice_v1_carprice_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PredictRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *PredictRequest) GetInput() *UserInput {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+type PredictResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RequestId string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Result    *PredictionResult      `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	// Set instead of result when a streamed item fails. Unary calls use the gRPC status,
+	// which carries the same code in a google.rpc.ErrorInfo detail.
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// Stable error code of a failed streamed item, e.g. "VALIDATION_FAILED".
+	ErrorCode     string `protobuf:"bytes,4,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PredictResponse) Reset() {
+	*x = PredictResponse{}
+	mi := &file_carprice_v1_carprice_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PredictResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictResponse) ProtoMessage() {}
+
+func (x *PredictResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_carprice_v1_carprice_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictResponse.ProtoReflect.Descriptor instead.
+func (*PredictResponse) Descriptor() ([]byte, []int) {
+	return file_carprice_v1_carprice_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PredictResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *PredictResponse) GetResult() *PredictionResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *PredictResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PredictResponse) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+type PredictBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One response per request, in the order they were received.
+	Responses     []*PredictResponse `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PredictBatchResponse) Reset() {
+	*x = PredictBatchResponse{}
+	mi := &file_carprice_v1_carprice_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PredictBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictBatchResponse) ProtoMessage() {}
+
+func (x *PredictBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_carprice_v1_carprice_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictBatchResponse.ProtoReflect.Descriptor instead.
+func (*PredictBatchResponse) Descriptor() ([]byte, []int) {
+	return file_carprice_v1_carprice_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PredictBatchResponse) GetResponses() []*PredictResponse {
+	if x != nil {
+		return x.Responses
+	}
+	return nil
+}
+
+type ExplainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Input         *UserInput             `protobuf:"bytes,1,opt,name=input,proto3" json:"input,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainRequest) Reset() {
+	*x = ExplainRequest{}
+	mi := &file_carprice_v1_carprice_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainRequest) ProtoMessage() {}
+
+func (x *ExplainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_carprice_v1_carprice_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainRequest.ProtoReflect.Descriptor instead.
+func (*ExplainRequest) Descriptor() ([]byte, []int) {
+	return file_carprice_v1_carprice_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ExplainRequest) GetInput() *UserInput {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+// FeatureContribution mirrors domain.FeatureContribution.
+type FeatureContribution struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Feature       string                 `protobuf:"bytes,1,opt,name=feature,proto3" json:"feature,omitempty"`
+	Contribution  float32                `protobuf:"fixed32,2,opt,name=contribution,proto3" json:"contribution,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FeatureContribution) Reset() {
+	*x = FeatureContribution{}
+	mi := &file_carprice_v1_carprice_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FeatureContribution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeatureContribution) ProtoMessage() {}
+
+func (x *FeatureContribution) ProtoReflect() protoreflect.Message {
+	mi := &file_carprice_v1_carprice_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeatureContribution.ProtoReflect.Descriptor instead.
+func (*FeatureContribution) Descriptor() ([]byte, []int) {
+	return file_carprice_v1_carprice_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FeatureContribution) GetFeature() string {
+	if x != nil {
+		return x.Feature
+	}
+	return ""
+}
+
+func (x *FeatureContribution) GetContribution() float32 {
+	if x != nil {
+		return x.Contribution
+	}
+	return 0
+}
+
+// ExplainResponse mirrors domain.Explanation.
+type ExplainResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	PredictedPrice float32                `protobuf:"fixed32,1,opt,name=predicted_price,json=predictedPrice,proto3" json:"predicted_price,omitempty"`
+	BaselinePrice  float32                `protobuf:"fixed32,2,opt,name=baseline_price,json=baselinePrice,proto3" json:"baseline_price,omitempty"`
+	Contributions  []*FeatureContribution `protobuf:"bytes,3,rep,name=contributions,proto3" json:"contributions,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExplainResponse) Reset() {
+	*x = ExplainResponse{}
+	mi := &file_carprice_v1_carprice_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainResponse) ProtoMessage() {}
+
+func (x *ExplainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_carprice_v1_carprice_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainResponse.ProtoReflect.Descriptor instead.
+func (*ExplainResponse) Descriptor() ([]byte, []int) {
+	return file_carprice_v1_carprice_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExplainResponse) GetPredictedPrice() float32 {
+	if x != nil {
+		return x.PredictedPrice
+	}
+	return 0
+}
+
+func (x *ExplainResponse) GetBaselinePrice() float32 {
+	if x != nil {
+		return x.BaselinePrice
+	}
+	return 0
+}
+
+func (x *ExplainResponse) GetContributions() []*FeatureContribution {
+	if x != nil {
+		return x.Contributions
+	}
+	return nil
+}
+
+var File_carprice_v1_carprice_proto protoreflect.FileDescriptor
+
+const file_carprice_v1_carprice_proto_rawDesc = "" +
+	"\n" +
+	"\x1acarprice/v1/carprice.proto\x12\vcarprice.v1\"\xf1\x05\n" +
+	"\tUserInput\x12\x1c\n" +
+	"\tsymboling\x18\x01 \x01(\x05R\tsymboling\x12\x1c\n" +
+	"\twheelbase\x18\x02 \x01(\x02R\twheelbase\x12\x1c\n" +
+	"\tcarlength\x18\x03 \x01(\x02R\tcarlength\x12\x1a\n" +
+	"\bcarwidth\x18\x04 \x01(\x02R\bcarwidth\x12\x1c\n" +
+	"\tcarheight\x18\x05 \x01(\x02R\tcarheight\x12\x1e\n" +
+	"\n" +
+	"curbweight\x18\x06 \x01(\x05R\n" +
+	"curbweight\x12\x1e\n" +
+	"\n" +
+	"enginesize\x18\a \x01(\x05R\n" +
+	"enginesize\x12\x1c\n" +
+	"\tboreratio\x18\b \x01(\x02R\tboreratio\x12\x16\n" +
+	"\x06stroke\x18\t \x01(\x02R\x06stroke\x12*\n" +
+	"\x10compressionratio\x18\n" +
+	" \x01(\x02R\x10compressionratio\x12\x1e\n" +
+	"\n" +
+	"horsepower\x18\v \x01(\x05R\n" +
+	"horsepower\x12\x18\n" +
+	"\apeakrpm\x18\f \x01(\x05R\apeakrpm\x12\x18\n" +
+	"\acitympg\x18\r \x01(\x05R\acitympg\x12\x1e\n" +
+	"\n" +
+	"highwaympg\x18\x0e \x01(\x05R\n" +
+	"highwaympg\x12\x1a\n" +
+	"\bfueltype\x18\x0f \x01(\tR\bfueltype\x12\x1e\n" +
+	"\n" +
+	"aspiration\x18\x10 \x01(\tR\n" +
+	"aspiration\x12\x1e\n" +
+	"\n" +
+	"doornumber\x18\x11 \x01(\tR\n" +
+	"doornumber\x12\x18\n" +
+	"\acarbody\x18\x12 \x01(\tR\acarbody\x12\x1e\n" +
+	"\n" +
+	"drivewheel\x18\x13 \x01(\tR\n" +
+	"drivewheel\x12&\n" +
+	"\x0eenginelocation\x18\x14 \x01(\tR\x0eenginelocation\x12\x1e\n" +
+	"\n" +
+	"enginetype\x18\x15 \x01(\tR\n" +
+	"enginetype\x12&\n" +
+	"\x0ecylindernumber\x18\x16 \x01(\tR\x0ecylindernumber\x12\x1e\n" +
+	"\n" +
+	"fuelsystem\x18\x17 \x01(\tR\n" +
+	"fuelsystem\x12\x14\n" +
+	"\x05brand\x18\x18 \x01(\tR\x05brand\"`\n" +
+	"\x10PredictionResult\x12'\n" +
+	"\x0fpredicted_price\x18\x01 \x01(\x02R\x0epredictedPrice\x12#\n" +
+	"\rprediction_id\x18\x02 \x01(\tR\fpredictionId\"]\n" +
+	"\x0ePredictRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12,\n" +
+	"\x05input\x18\x02 \x01(\v2\x16.carprice.v1.UserInputR\x05input\"\x9c\x01\n" +
+	"\x0fPredictResponse\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x125\n" +
+	"\x06result\x18\x02 \x01(\v2\x1d.carprice.v1.PredictionResultR\x06result\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x04 \x01(\tR\terrorCode\"R\n" +
+	"\x14PredictBatchResponse\x12:\n" +
+	"\tresponses\x18\x01 \x03(\v2\x1c.carprice.v1.PredictResponseR\tresponses\">\n" +
+	"\x0eExplainRequest\x12,\n" +
+	"\x05input\x18\x01 \x01(\v2\x16.carprice.v1.UserInputR\x05input\"S\n" +
+	"\x13FeatureContribution\x12\x18\n" +
+	"\afeature\x18\x01 \x01(\tR\afeature\x12\"\n" +
+	"\fcontribution\x18\x02 \x01(\x02R\fcontribution\"\xa9\x01\n" +
+	"\x0fExplainResponse\x12'\n" +
+	"\x0fpredicted_price\x18\x01 \x01(\x02R\x0epredictedPrice\x12%\n" +
+	"\x0ebaseline_price\x18\x02 \x01(\x02R\rbaselinePrice\x12F\n" +
+	"\rcontributions\x18\x03 \x03(\v2 .carprice.v1.FeatureContributionR\rcontributions2\xbf\x02\n" +
+	"\x0fCarPriceService\x12D\n" +
+	"\aPredict\x12\x1b.carprice.v1.PredictRequest\x1a\x1c.carprice.v1.PredictResponse\x12P\n" +
+	"\fPredictBatch\x12\x1b.carprice.v1.PredictRequest\x1a!.carprice.v1.PredictBatchResponse(\x01\x12N\n" +
+	"\rPredictStream\x12\x1b.carprice.v1.PredictRequest\x1a\x1c.carprice.v1.PredictResponse(\x010\x01\x12D\n" +
+	"\aExplain\x12\x1b.carprice.v1.ExplainRequest\x1a\x1c.carprice.v1.ExplainResponseB=Z;car-price-prediction/internal/grpcapi/carpricev1;carpricev1b\x06proto3"
+
+var (
+	file_carprice_v1_carprice_proto_rawDescOnce sync.Once
+	file_carprice_v1_carprice_proto_rawDescData []byte
+)
+
+func file_carprice_v1_carprice_proto_rawDescGZIP() []byte {
+	file_carprice_v1_carprice_proto_rawDescOnce.Do(func() {
+		file_carprice_v1_carprice_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_carprice_v1_carprice_proto_rawDesc), len(file_carprice_v1_carprice_proto_rawDesc)))
+	})
+	return file_carprice_v1_carprice_proto_rawDescData
+}
+
+var file_carprice_v1_carprice_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_carprice_v1_carprice_proto_goTypes = []any{
+	(*UserInput)(nil),            // 0: carprice.v1.UserInput
+	(*PredictionResult)(nil),     // 1: carprice.v1.PredictionResult
+	(*PredictRequest)(nil),       // 2: carprice.v1.PredictRequest
+	(*PredictResponse)(nil),      // 3: carprice.v1.PredictResponse
+	(*PredictBatchResponse)(nil), // 4: carprice.v1.PredictBatchResponse
+	(*ExplainRequest)(nil),       // 5: carprice.v1.ExplainRequest
+	(*FeatureContribution)(nil),  // 6: carprice.v1.FeatureContribution
+	(*ExplainResponse)(nil),      // 7: carprice.v1.ExplainResponse
+}
+var file_carprice_v1_carprice_proto_depIdxs = []int32{
+	0, // 0: carprice.v1.PredictRequest.input:type_name -> carprice.v1.UserInput
+	1, // 1: carprice.v1.PredictResponse.result:type_name -> carprice.v1.PredictionResult
+	3, // 2: carprice.v1.PredictBatchResponse.responses:type_name -> carprice.v1.PredictResponse
+	0, // 3: carprice.v1.ExplainRequest.input:type_name -> carprice.v1.UserInput
+	6, // 4: carprice.v1.ExplainResponse.contributions:type_name -> carprice.v1.FeatureContribution
+	2, // 5: carprice.v1.CarPriceService.Predict:input_type -> carprice.v1.PredictRequest
+	2, // 6: carprice.v1.CarPriceService.PredictBatch:input_type -> carprice.v1.PredictRequest
+	2, // 7: carprice.v1.CarPriceService.PredictStream:input_type -> carprice.v1.PredictRequest
+	5, // 8: carprice.v1.CarPriceService.Explain:input_type -> carprice.v1.ExplainRequest
+	3, // 9: carprice.v1.CarPriceService.Predict:output_type -> carprice.v1.PredictResponse
+	4, // 10: carprice.v1.CarPriceService.PredictBatch:output_type -> carprice.v1.PredictBatchResponse
+	3, // 11: carprice.v1.CarPriceService.PredictStream:output_type -> carprice.v1.PredictResponse
+	7, // 12: carprice.v1.CarPriceService.Explain:output_type -> carprice.v1.ExplainResponse
+	9, // [9:13] is the sub-list for method output_type
+	5, // [5:9] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_carprice_v1_carprice_proto_init() }
+func file_carprice_v1_carprice_proto_init() {
+	if File_carprice_v1_carprice_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_carprice_v1_carprice_proto_rawDesc), len(file_carprice_v1_carprice_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_carprice_v1_carprice_proto_goTypes,
+		DependencyIndexes: file_carprice_v1_carprice_proto_depIdxs,
+		MessageInfos:      file_carprice_v1_carprice_proto_msgTypes,
+	}.Build()
+	File_carprice_v1_carprice_proto = out.File
+	file_carprice_v1_carprice_proto_goTypes = nil
+	file_carprice_v1_carprice_proto_depIdxs = nil
+}
@@ -0,0 +1,239 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: carprice/v1/carprice.proto
+
+package carpricev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CarPriceService_Predict_FullMethodName       = "/carprice.v1.CarPriceService/Predict"
+	CarPriceService_PredictBatch_FullMethodName  = "/carprice.v1.CarPriceService/PredictBatch"
+	CarPriceService_PredictStream_FullMethodName = "/carprice.v1.CarPriceService/PredictStream"
+	CarPriceService_Explain_FullMethodName       = "/carprice.v1.CarPriceService/Explain"
+)
+
+// CarPriceServiceClient is the client API for CarPriceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CarPriceService predicts car prices. It mirrors the REST API and is backed by
+// the same domain.PredictionService.
+type CarPriceServiceClient interface {
+	// Predict returns the predicted price for a single car.
+	Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictResponse, error)
+	// PredictBatch accepts a stream of cars and returns all predictions once the
+	// client closes the stream.
+	PredictBatch(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PredictRequest, PredictBatchResponse], error)
+	// PredictStream returns one response for every request as it arrives.
+	PredictStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PredictRequest, PredictResponse], error)
+	// Explain returns the prediction together with per-field contributions.
+	Explain(ctx context.Context, in *ExplainRequest, opts ...grpc.CallOption) (*ExplainResponse, error)
+}
+
+type carPriceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCarPriceServiceClient(cc grpc.ClientConnInterface) CarPriceServiceClient {
+	return &carPriceServiceClient{cc}
+}
+
+func (c *carPriceServiceClient) Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PredictResponse)
+	err := c.cc.Invoke(ctx, CarPriceService_Predict_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *carPriceServiceClient) PredictBatch(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PredictRequest, PredictBatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CarPriceService_ServiceDesc.Streams[0], CarPriceService_PredictBatch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PredictRequest, PredictBatchResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CarPriceService_PredictBatchClient = grpc.ClientStreamingClient[PredictRequest, PredictBatchResponse]
+
+func (c *carPriceServiceClient) PredictStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PredictRequest, PredictResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CarPriceService_ServiceDesc.Streams[1], CarPriceService_PredictStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PredictRequest, PredictResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CarPriceService_PredictStreamClient = grpc.BidiStreamingClient[PredictRequest, PredictResponse]
+
+func (c *carPriceServiceClient) Explain(ctx context.Context, in *ExplainRequest, opts ...grpc.CallOption) (*ExplainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExplainResponse)
+	err := c.cc.Invoke(ctx, CarPriceService_Explain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CarPriceServiceServer is the server API for CarPriceService service.
+// All implementations must embed UnimplementedCarPriceServiceServer
+// for forward compatibility.
+//
+// CarPriceService predicts car prices. It mirrors the REST API and is backed by
+// the same domain.PredictionService.
+type CarPriceServiceServer interface {
+	// Predict returns the predicted price for a single car.
+	Predict(context.Context, *PredictRequest) (*PredictResponse, error)
+	// PredictBatch accepts a stream of cars and returns all predictions once the
+	// client closes the stream.
+	PredictBatch(grpc.ClientStreamingServer[PredictRequest, PredictBatchResponse]) error
+	// PredictStream returns one response for every request as it arrives.
+	PredictStream(grpc.BidiStreamingServer[PredictRequest, PredictResponse]) error
+	// Explain returns the prediction together with per-field contributions.
+	Explain(context.Context, *ExplainRequest) (*ExplainResponse, error)
+	mustEmbedUnimplementedCarPriceServiceServer()
+}
+
+// UnimplementedCarPriceServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCarPriceServiceServer struct{}
+
+func (UnimplementedCarPriceServiceServer) Predict(context.Context, *PredictRequest) (*PredictResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Predict not implemented")
+}
+func (UnimplementedCarPriceServiceServer) PredictBatch(grpc.ClientStreamingServer[PredictRequest, PredictBatchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PredictBatch not implemented")
+}
+func (UnimplementedCarPriceServiceServer) PredictStream(grpc.BidiStreamingServer[PredictRequest, PredictResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PredictStream not implemented")
+}
+func (UnimplementedCarPriceServiceServer) Explain(context.Context, *ExplainRequest) (*ExplainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Explain not implemented")
+}
+func (UnimplementedCarPriceServiceServer) mustEmbedUnimplementedCarPriceServiceServer() {}
+func (UnimplementedCarPriceServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeCarPriceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CarPriceServiceServer will
+// result in compilation errors.
+type UnsafeCarPriceServiceServer interface {
+	mustEmbedUnimplementedCarPriceServiceServer()
+}
+
+func RegisterCarPriceServiceServer(s grpc.ServiceRegistrar, srv CarPriceServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCarPriceServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CarPriceService_ServiceDesc, srv)
+}
+
+func _CarPriceService_Predict_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PredictRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CarPriceServiceServer).Predict(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CarPriceService_Predict_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CarPriceServiceServer).Predict(ctx, req.(*PredictRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CarPriceService_PredictBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CarPriceServiceServer).PredictBatch(&grpc.GenericServerStream[PredictRequest, PredictBatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CarPriceService_PredictBatchServer = grpc.ClientStreamingServer[PredictRequest, PredictBatchResponse]
+
+func _CarPriceService_PredictStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CarPriceServiceServer).PredictStream(&grpc.GenericServerStream[PredictRequest, PredictResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CarPriceService_PredictStreamServer = grpc.BidiStreamingServer[PredictRequest, PredictResponse]
+
+func _CarPriceService_Explain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CarPriceServiceServer).Explain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CarPriceService_Explain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CarPriceServiceServer).Explain(ctx, req.(*ExplainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CarPriceService_ServiceDesc is the grpc.ServiceDesc for CarPriceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CarPriceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "carprice.v1.CarPriceService",
+	HandlerType: (*CarPriceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Predict",
+			Handler:    _CarPriceService_Predict_Handler,
+		},
+		{
+			MethodName: "Explain",
+			Handler:    _CarPriceService_Explain_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PredictBatch",
+			Handler:       _CarPriceService_PredictBatch_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "PredictStream",
+			Handler:       _CarPriceService_PredictStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "carprice/v1/carprice.proto",
+}
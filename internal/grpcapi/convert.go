@@ -0,0 +1,52 @@
+package grpcapi
+
+import (
+	"car-price-prediction/internal/domain"
+	pb "car-price-prediction/internal/grpcapi/carpricev1"
+)
+
+// fromProtoInput converts a protobuf UserInput into its domain counterpart.
+func fromProtoInput(in *pb.UserInput) domain.UserInput {
+	return domain.UserInput{
+		Symboling:        int(in.GetSymboling()),
+		Wheelbase:        in.GetWheelbase(),
+		Carlength:        in.GetCarlength(),
+		Carwidth:         in.GetCarwidth(),
+		Carheight:        in.GetCarheight(),
+		Curbweight:       int(in.GetCurbweight()),
+		Enginesize:       int(in.GetEnginesize()),
+		Boreratio:        in.GetBoreratio(),
+		Stroke:           in.GetStroke(),
+		Compressionratio: in.GetCompressionratio(),
+		Horsepower:       int(in.GetHorsepower()),
+		Peakrpm:          int(in.GetPeakrpm()),
+		Citympg:          int(in.GetCitympg()),
+		Highwaympg:       int(in.GetHighwaympg()),
+		Fueltype:         in.GetFueltype(),
+		Aspiration:       in.GetAspiration(),
+		Doornumber:       in.GetDoornumber(),
+		Carbody:          in.GetCarbody(),
+		Drivewheel:       in.GetDrivewheel(),
+		Enginelocation:   in.GetEnginelocation(),
+		Enginetype:       in.GetEnginetype(),
+		Cylindernumber:   in.GetCylindernumber(),
+		Fuelsystem:       in.GetFuelsystem(),
+		Brand:            in.GetBrand(),
+	}
+}
+
+// toExplainResponse converts a domain.Explanation into its protobuf counterpart.
+func toExplainResponse(e *domain.Explanation) *pb.ExplainResponse {
+	resp := &pb.ExplainResponse{
+		PredictedPrice: e.PredictedPrice,
+		BaselinePrice:  e.BaselinePrice,
+		Contributions:  make([]*pb.FeatureContribution, 0, len(e.Contributions)),
+	}
+	for _, c := range e.Contributions {
+		resp.Contributions = append(resp.Contributions, &pb.FeatureContribution{
+			Feature:      c.Feature,
+			Contribution: c.Contribution,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,74 @@
+package grpcapi
+
+import (
+	"car-price-prediction/internal/domain"
+	"errors"
+	"log"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain identifies this service in google.rpc.ErrorInfo details.
+const errorDomain = "car-price-prediction"
+
+// grpcCodes maps each domain error code to its gRPC status code.
+var grpcCodes = map[domain.ErrorCode]codes.Code{
+	domain.CodeValidationFailed:   codes.InvalidArgument,
+	domain.CodeUnknownCategory:    codes.InvalidArgument,
+	domain.CodeOutOfRange:         codes.InvalidArgument,
+	domain.CodeModelUnavailable:   codes.Unavailable,
+	domain.CodeInferenceFailed:    codes.Internal,
+	domain.CodeTimeout:            codes.DeadlineExceeded,
+	domain.CodeUnauthorized:       codes.Unauthenticated,
+	domain.CodeForbidden:          codes.PermissionDenied,
+	domain.CodeRateLimited:        codes.ResourceExhausted,
+	domain.CodeQuotaExceeded:      codes.ResourceExhausted,
+	domain.CodeNotFound:           codes.NotFound,
+	domain.CodeStorageUnavailable: codes.Unavailable,
+}
+
+// asDomainError returns err as a *domain.Error. Other errors are treated as inference
+// failures so that internal messages never reach clients.
+func asDomainError(err error) *domain.Error {
+	var derr *domain.Error
+	if !errors.As(err, &derr) {
+		derr = domain.NewError(domain.CodeInferenceFailed, "The model could not produce a prediction.", err)
+	}
+	return derr
+}
+
+// toStatus converts err into a gRPC status error carrying the stable error code in a
+// google.rpc.ErrorInfo detail and field violations in a google.rpc.BadRequest detail.
+func toStatus(err error) error {
+	derr := asDomainError(err)
+	logInternal(derr)
+
+	st := status.New(grpcCodes[derr.Code], derr.Message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: string(derr.Code), Domain: errorDomain}}
+	if len(derr.Violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range derr.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Message,
+				Reason:      string(v.Code),
+			})
+		}
+		details = append(details, badRequest)
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// logInternal logs errors caused by the server rather than the request, including
+// the internal cause that is not sent to the client.
+func logInternal(derr *domain.Error) {
+	if code := grpcCodes[derr.Code]; code == codes.Internal || code == codes.Unavailable {
+		log.Printf("gRPC request failed: %v", derr)
+	}
+}
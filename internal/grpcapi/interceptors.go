@@ -0,0 +1,67 @@
+package grpcapi
+
+import (
+	"car-price-prediction/internal/drift"
+	"car-price-prediction/internal/predlog"
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// timeoutKey is the context key of the prediction timeout.
+type timeoutKey struct{}
+
+// WithPredictionTimeout returns server options that limit how long a single
+// prediction may take before it fails with DEADLINE_EXCEEDED. A shorter client
+// deadline still applies.
+func WithPredictionTimeout(timeout time.Duration) []grpc.ServerOption {
+	return withContext(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, timeoutKey{}, timeout)
+	})
+}
+
+// predictionTimeout returns the prediction timeout of the call, or 0 if there is none.
+func predictionTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(timeoutKey{}).(time.Duration)
+	return timeout
+}
+
+// WithPredictionLog returns server options that record every prediction of the
+// CarPriceService with recorder.
+func WithPredictionLog(recorder *predlog.Recorder) []grpc.ServerOption {
+	return withContext(func(ctx context.Context) context.Context {
+		return predlog.NewContext(ctx, recorder)
+	})
+}
+
+// WithDriftMonitor returns server options that observe the input of every
+// prediction of the CarPriceService with monitor.
+func WithDriftMonitor(monitor *drift.Monitor) []grpc.ServerOption {
+	return withContext(func(ctx context.Context) context.Context {
+		return drift.NewContext(ctx, monitor)
+	})
+}
+
+// withContext returns server options that pass the context of every call through wrap.
+func withContext(wrap func(context.Context) context.Context) []grpc.ServerOption {
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(wrap(ctx), req)
+	}
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: wrap(ss.Context())})
+	}
+	return []grpc.ServerOption{grpc.ChainUnaryInterceptor(unary), grpc.ChainStreamInterceptor(stream)}
+}
+
+// contextStream overrides the context of a server stream, e.g. with one carrying
+// the principal.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the overriding context.
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,179 @@
+package grpcapi
+
+import (
+	"car-price-prediction/internal/auth"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/drift"
+	pb "car-price-prediction/internal/grpcapi/carpricev1"
+	"car-price-prediction/internal/predlog"
+	"car-price-prediction/internal/validation"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the CarPriceService gRPC service on top of a domain.PredictionService.
+type Server struct {
+	pb.UnimplementedCarPriceServiceServer
+	service domain.PredictionService
+}
+
+// NewServer creates a gRPC server exposing the CarPriceService together with the
+// standard health and reflection services.
+func NewServer(service domain.PredictionService, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	pb.RegisterCarPriceServiceServer(s, &Server{service: service})
+
+	// Report the car price service and the server as a whole as serving.
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(pb.CarPriceService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, healthServer)
+
+	reflection.Register(s)
+
+	return s
+}
+
+// Predict handles a single prediction.
+func (s *Server) Predict(ctx context.Context, req *pb.PredictRequest) (*pb.PredictResponse, error) {
+	result, err := s.predict(ctx, req.GetRequestId(), req.GetInput())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.PredictResponse{RequestId: req.GetRequestId(), Result: result}, nil
+}
+
+// PredictBatch collects a client stream of requests and answers them all at once.
+func (s *Server) PredictBatch(stream grpc.ClientStreamingServer[pb.PredictRequest, pb.PredictBatchResponse]) error {
+	batch := &pb.PredictBatchResponse{}
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(batch)
+		}
+		if err != nil {
+			return err
+		}
+		batch.Responses = append(batch.Responses, s.predictItem(stream.Context(), req))
+	}
+}
+
+// PredictStream answers every request on a bidirectional stream as it arrives.
+func (s *Server) PredictStream(stream grpc.BidiStreamingServer[pb.PredictRequest, pb.PredictResponse]) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(s.predictItem(stream.Context(), req)); err != nil {
+			return err
+		}
+	}
+}
+
+// Explain returns the prediction with per-field contributions, if the underlying
+// service supports explanations.
+func (s *Server) Explain(ctx context.Context, req *pb.ExplainRequest) (*pb.ExplainResponse, error) {
+	explainer, ok := s.service.(domain.Explainer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "The prediction model does not support explanations.")
+	}
+
+	input, err := validInput(req.GetInput())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	// Charge the explanation as one prediction against the caller's quota
+	principal := auth.FromContext(ctx)
+	if err := principal.Reserve(1); err != nil {
+		return nil, toStatus(err)
+	}
+
+	explanation, err := domain.WithTimeout(ctx, predictionTimeout(ctx), func() (*domain.Explanation, error) {
+		return explainer.Explain(input)
+	})
+	if err != nil {
+		principal.Refund(1)
+		return nil, toStatus(err)
+	}
+	return toExplainResponse(explanation), nil
+}
+
+// predict validates the input, observes it for drift monitoring, charges it
+// against the caller's quota, runs it through the prediction service within the
+// prediction timeout and records the prediction if the prediction log is enabled.
+func (s *Server) predict(ctx context.Context, requestID string, in *pb.UserInput) (*pb.PredictionResult, error) {
+	input, err := validInput(in)
+	if err != nil {
+		return nil, err
+	}
+	drift.FromContext(ctx).Observe(input)
+
+	principal := auth.FromContext(ctx)
+	if err := principal.Reserve(1); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := domain.WithTimeout(ctx, predictionTimeout(ctx), func() (*domain.PredictionResult, error) {
+		return s.service.Predict(input)
+	})
+	if err == nil {
+		p := predlog.Prediction{RequestID: requestID, Input: input, Result: result, Latency: time.Since(start)}
+		if principal != nil {
+			p.Subject = principal.Subject
+		}
+		result.PredictionID, err = predlog.FromContext(ctx).Record(p)
+	}
+	if err != nil {
+		principal.Refund(1)
+		return nil, err
+	}
+	return &pb.PredictionResult{PredictedPrice: result.PredictedPrice, PredictionId: result.PredictionID}, nil
+}
+
+// predictItem handles one request of a stream. Failures are reported on the item
+// so that a single bad row does not abort the whole stream.
+func (s *Server) predictItem(ctx context.Context, req *pb.PredictRequest) *pb.PredictResponse {
+	resp := &pb.PredictResponse{RequestId: req.GetRequestId()}
+	result, err := s.predict(ctx, req.GetRequestId(), req.GetInput())
+	if err != nil {
+		derr := asDomainError(err)
+		logInternal(derr)
+		resp.Error = derr.Message
+		resp.ErrorCode = string(derr.Code)
+		return resp
+	}
+	resp.Result = result
+	return resp
+}
+
+// validInput converts the message to a domain.UserInput and applies the same
+// binding rules as the REST API.
+func validInput(in *pb.UserInput) (domain.UserInput, error) {
+	if in == nil {
+		return domain.UserInput{}, domain.NewValidationError([]domain.Violation{{
+			Field:   "input",
+			Code:    domain.CodeValidationFailed,
+			Message: "is required",
+		}})
+	}
+	input := fromProtoInput(in)
+	if err := validation.Struct(&input); err != nil {
+		return domain.UserInput{}, err
+	}
+	return input, nil
+}
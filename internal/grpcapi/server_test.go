@@ -0,0 +1,378 @@
+package grpcapi
+
+import (
+	"bytes"
+	"car-price-prediction/internal/auth"
+	"car-price-prediction/internal/auth/authtest"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/drift"
+	pb "car-price-prediction/internal/grpcapi/carpricev1"
+	"car-price-prediction/internal/predlog"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// mockPredictionService prices every car at 100 per horsepower.
+type mockPredictionService struct{}
+
+// Predict implements the prediction service interface for testing.
+func (m *mockPredictionService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	return &domain.PredictionResult{PredictedPrice: float32(input.Horsepower) * 100}, nil
+}
+
+// Explain implements the domain.Explainer interface for testing.
+func (m *mockPredictionService) Explain(input domain.UserInput) (*domain.Explanation, error) {
+	return &domain.Explanation{
+		PredictedPrice: float32(input.Horsepower) * 100,
+		BaselinePrice:  9500,
+		Contributions:  []domain.FeatureContribution{{Feature: "horsepower", Contribution: float32(input.Horsepower)*100 - 9500}},
+	}, nil
+}
+
+// slowPredictionService takes delay for every prediction.
+type slowPredictionService struct {
+	mockPredictionService
+	delay time.Duration
+}
+
+// Predict implements the prediction service interface for testing.
+func (m *slowPredictionService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	time.Sleep(m.delay)
+	return m.mockPredictionService.Predict(input)
+}
+
+// setupTestClient starts an in-memory gRPC server and returns a connection to it.
+func setupTestClient(t *testing.T, opts ...grpc.ServerOption) *grpc.ClientConn {
+	return setupServiceTestClient(t, &mockPredictionService{}, opts...)
+}
+
+// setupServiceTestClient starts an in-memory gRPC server of service and returns a
+// connection to it.
+func setupServiceTestClient(t *testing.T, service domain.PredictionService, opts ...grpc.ServerOption) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(service, opts...)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// testInput returns a complete, valid input with the given horsepower.
+func testInput(horsepower int32) *pb.UserInput {
+	return &pb.UserInput{
+		Symboling:        3,
+		Wheelbase:        88.6,
+		Carlength:        168.8,
+		Carwidth:         64.1,
+		Carheight:        48.8,
+		Curbweight:       2548,
+		Enginesize:       130,
+		Boreratio:        3.47,
+		Stroke:           2.68,
+		Compressionratio: 9.0,
+		Horsepower:       horsepower,
+		Peakrpm:          5000,
+		Citympg:          21,
+		Highwaympg:       27,
+		Fueltype:         "gas",
+		Aspiration:       "std",
+		Doornumber:       "two",
+		Carbody:          "convertible",
+		Drivewheel:       "rwd",
+		Enginelocation:   "front",
+		Enginetype:       "dohc",
+		Cylindernumber:   "four",
+		Fuelsystem:       "mpfi",
+		Brand:            "alfa-romero",
+	}
+}
+
+func TestPredict_Success(t *testing.T) {
+	client := pb.NewCarPriceServiceClient(setupTestClient(t))
+
+	resp, err := client.Predict(context.Background(), &pb.PredictRequest{RequestId: "a", Input: testInput(111)})
+
+	require.NoError(t, err)
+	assert.Equal(t, "a", resp.GetRequestId())
+	assert.Equal(t, float32(11100), resp.GetResult().GetPredictedPrice())
+}
+
+func TestPredict_InvalidArgument(t *testing.T) {
+	client := pb.NewCarPriceServiceClient(setupTestClient(t))
+
+	_, err := client.Predict(context.Background(), &pb.PredictRequest{Input: &pb.UserInput{Symboling: 3}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Predict(context.Background(), &pb.PredictRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestPredictBatch(t *testing.T) {
+	client := pb.NewCarPriceServiceClient(setupTestClient(t))
+
+	stream, err := client.PredictBatch(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.PredictRequest{RequestId: "1", Input: testInput(100)}))
+	require.NoError(t, stream.Send(&pb.PredictRequest{RequestId: "2", Input: &pb.UserInput{}}))
+	require.NoError(t, stream.Send(&pb.PredictRequest{RequestId: "3", Input: testInput(200)}))
+
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	require.Len(t, resp.GetResponses(), 3)
+	assert.Equal(t, float32(10000), resp.GetResponses()[0].GetResult().GetPredictedPrice())
+	assert.Equal(t, "2", resp.GetResponses()[1].GetRequestId())
+	assert.NotEmpty(t, resp.GetResponses()[1].GetError())
+	assert.Equal(t, "VALIDATION_FAILED", resp.GetResponses()[1].GetErrorCode())
+	assert.Nil(t, resp.GetResponses()[1].GetResult())
+	assert.Equal(t, float32(20000), resp.GetResponses()[2].GetResult().GetPredictedPrice())
+}
+
+func TestPredictStream(t *testing.T) {
+	client := pb.NewCarPriceServiceClient(setupTestClient(t))
+
+	stream, err := client.PredictStream(context.Background())
+	require.NoError(t, err)
+
+	for _, hp := range []int32{90, 150} {
+		require.NoError(t, stream.Send(&pb.PredictRequest{Input: testInput(hp)}))
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, float32(hp)*100, resp.GetResult().GetPredictedPrice())
+	}
+
+	require.NoError(t, stream.CloseSend())
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestExplain(t *testing.T) {
+	client := pb.NewCarPriceServiceClient(setupTestClient(t))
+
+	resp, err := client.Explain(context.Background(), &pb.ExplainRequest{Input: testInput(120)})
+
+	require.NoError(t, err)
+	assert.Equal(t, float32(12000), resp.GetPredictedPrice())
+	assert.Equal(t, float32(9500), resp.GetBaselinePrice())
+	require.Len(t, resp.GetContributions(), 1)
+	assert.Equal(t, "horsepower", resp.GetContributions()[0].GetFeature())
+}
+
+func TestHealth(t *testing.T) {
+	client := healthpb.NewHealthClient(setupTestClient(t))
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: pb.CarPriceService_ServiceDesc.ServiceName})
+
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
+
+func TestPredict_ErrorDetails(t *testing.T) {
+	client := pb.NewCarPriceServiceClient(setupTestClient(t))
+
+	_, err := client.Predict(context.Background(), &pb.PredictRequest{Input: &pb.UserInput{Symboling: 3}})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	var reason string
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			reason = d.GetReason()
+		case *errdetails.BadRequest:
+			violations = d.GetFieldViolations()
+		}
+	}
+	assert.Equal(t, "VALIDATION_FAILED", reason)
+	require.NotEmpty(t, violations)
+	assert.Equal(t, "wheelbase", violations[0].GetField())
+}
+
+func TestToStatus_HidesInternalErrors(t *testing.T) {
+	err := toStatus(errors.New("onnxruntime: session failed"))
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.NotContains(t, st.Message(), "onnxruntime")
+}
+
+func TestWithAuth_APIKeys(t *testing.T) {
+	manager, err := auth.Open(t.TempDir(), time.Hour)
+	require.NoError(t, err)
+	defer manager.Close()
+	token, _, err := manager.Keys().Issue(auth.KeyOptions{Name: "Dealer A", MonthlyQuota: 1})
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(&mockPredictionService{}, WithAuth(&auth.Authenticator{APIKeys: manager, Audit: auth.NewAuditLog(io.Discard)})...)
+	go server.Serve(listener)
+	defer server.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewCarPriceServiceClient(conn)
+
+	// Calls without a key are rejected
+	_, err = client.Predict(context.Background(), &pb.PredictRequest{Input: testInput(100)})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// The health service stays open
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+
+	// A valid key works until its quota is used up
+	ctx := metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, token)
+	_, err = client.Predict(ctx, &pb.PredictRequest{Input: testInput(100)})
+	assert.NoError(t, err)
+	_, err = client.Predict(ctx, &pb.PredictRequest{Input: testInput(100)})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestWithAuth_JWTScopes(t *testing.T) {
+	iss := authtest.NewIssuer(t)
+	keys, err := auth.OpenJWKS(iss.JWKSPath)
+	require.NoError(t, err)
+	var audit bytes.Buffer
+	conn := setupTestClient(t, WithAuth(&auth.Authenticator{
+		JWT:   auth.NewJWTVerifier(keys, auth.JWTOptions{Issuer: iss.URL, Audience: iss.Audience}),
+		Audit: auth.NewAuditLog(&audit),
+	})...)
+	client := pb.NewCarPriceServiceClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), authorizationMetadata, "Bearer "+iss.Token(t, "alice", auth.ScopePredictRead))
+	_, err = client.Predict(ctx, &pb.PredictRequest{Input: testInput(100)})
+	assert.NoError(t, err)
+
+	// Explain needs explain:read
+	_, err = client.Explain(ctx, &pb.ExplainRequest{Input: testInput(100)})
+	st := status.Convert(err)
+	assert.Equal(t, codes.PermissionDenied, st.Code())
+	require.NotEmpty(t, st.Details())
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, string(domain.CodeForbidden), info.Reason)
+
+	// Streams are authorized before the first message
+	stream, err := client.PredictStream(metadata.AppendToOutgoingContext(context.Background(), authorizationMetadata, "Bearer "+iss.Token(t, "bob", auth.ScopeExplainRead)))
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	assert.Contains(t, audit.String(), `"subject":"alice","method":"gRPC","resource":"/carprice.v1.CarPriceService/Explain"`)
+	assert.Contains(t, audit.String(), `"subject":"bob"`)
+}
+
+func TestWithPredictionTimeout(t *testing.T) {
+	manager, err := auth.Open(t.TempDir(), time.Hour)
+	require.NoError(t, err)
+	defer manager.Close()
+	token, key, err := manager.Keys().Issue(auth.KeyOptions{Name: "Dealer A", MonthlyQuota: 10})
+	require.NoError(t, err)
+	opts := append(WithAuth(&auth.Authenticator{APIKeys: manager, Audit: auth.NewAuditLog(io.Discard)}), WithPredictionTimeout(50*time.Millisecond)...)
+	client := pb.NewCarPriceServiceClient(setupServiceTestClient(t, &slowPredictionService{delay: time.Second}, opts...))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, token)
+
+	start := time.Now()
+	_, err = client.Predict(ctx, &pb.PredictRequest{Input: testInput(100)})
+	st := status.Convert(err)
+	assert.Equal(t, codes.DeadlineExceeded, st.Code())
+	assert.Less(t, time.Since(start), time.Second)
+	require.NotEmpty(t, st.Details())
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, string(domain.CodeTimeout), info.Reason)
+
+	// Predictions that time out are not charged
+	used := func() int64 {
+		usage, err := manager.Usage(manager.CurrentPeriod(), key.ID)
+		require.NoError(t, err)
+		require.Len(t, usage, 1)
+		return usage[0].Used
+	}
+	assert.Equal(t, int64(0), used())
+
+	// A shorter client deadline applies too. The client gives up before the
+	// server answers, so the refund follows shortly after.
+	deadlineCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = client.Predict(deadlineCtx, &pb.PredictRequest{Input: testInput(100)})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Eventually(t, func() bool { return used() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+// staticModel reports a fixed model version.
+type staticModel struct{}
+
+func (staticModel) Model() domain.ModelInfo { return domain.ModelInfo{Version: "v1"} }
+
+func (staticModel) Reload() (domain.ModelInfo, error) { return staticModel{}.Model(), nil }
+
+func TestWithPredictionLog(t *testing.T) {
+	store, err := predlog.Open(t.TempDir(), predlog.Options{})
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	recorder := predlog.NewRecorder(store, staticModel{}, nil)
+	client := pb.NewCarPriceServiceClient(setupTestClient(t, WithPredictionLog(recorder)...))
+
+	resp, err := client.Predict(context.Background(), &pb.PredictRequest{RequestId: "quote-42", Input: testInput(111)})
+	require.NoError(t, err)
+	id := resp.GetResult().GetPredictionId()
+	require.NotEmpty(t, id)
+
+	rec, err := recorder.Lookup(id)
+	require.NoError(t, err)
+	assert.Equal(t, "quote-42", rec.RequestID)
+	assert.Equal(t, "v1", rec.ModelVersion)
+	assert.Equal(t, float32(11100), rec.PredictedPrice)
+}
+
+func TestWithDriftMonitor(t *testing.T) {
+	profile, err := drift.NewProfile([]domain.UserInput{fromProtoInput(testInput(111))}, drift.DefaultBins)
+	require.NoError(t, err)
+	monitor := drift.NewMonitor(profile, drift.Config{MinSamples: 2})
+	client := pb.NewCarPriceServiceClient(setupTestClient(t, WithDriftMonitor(monitor)...))
+
+	_, err = client.Predict(context.Background(), &pb.PredictRequest{Input: testInput(111)})
+	require.NoError(t, err)
+	stream, err := client.PredictStream(context.Background())
+	require.NoError(t, err)
+	input := testInput(111)
+	input.Brand = "bmw"
+	require.NoError(t, stream.Send(&pb.PredictRequest{Input: input}))
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	require.NoError(t, stream.CloseSend())
+
+	hour := monitor.Report().Windows[0]
+	assert.Equal(t, 2, hour.Samples)
+	assert.Equal(t, "brand", hour.Features[0].Feature)
+	assert.Equal(t, drift.StatusDrift, hour.Features[0].Status)
+}
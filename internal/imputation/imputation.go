@@ -0,0 +1,399 @@
+// Package imputation fills in the input fields a prediction request leaves out,
+// with the median or most frequent value of similar cars in the training data.
+package imputation
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+)
+
+// DefaultMinGroupSize is the number of training rows a group of similar cars
+// needs before its statistics are used.
+const DefaultMinGroupSize = 3
+
+// BasisAll is the basis of values taken from the whole training set.
+const BasisAll = "all"
+
+// numericFeature reads and writes a numeric input field.
+type numericFeature struct {
+	name    string
+	integer bool
+	value   func(domain.UserInput) float64
+	set     func(*domain.UserInput, float64)
+}
+
+// categoricalFeature reads and writes a categorical input field.
+type categoricalFeature struct {
+	name  string
+	value func(domain.UserInput) string
+	set   func(*domain.UserInput, string)
+}
+
+// numericFeatures are the numeric input fields, in request order.
+var numericFeatures = []numericFeature{
+	{"symboling", true, func(in domain.UserInput) float64 { return float64(in.Symboling) }, func(in *domain.UserInput, v float64) { in.Symboling = int(v) }},
+	{"wheelbase", false, func(in domain.UserInput) float64 { return float64(in.Wheelbase) }, func(in *domain.UserInput, v float64) { in.Wheelbase = float32(v) }},
+	{"carlength", false, func(in domain.UserInput) float64 { return float64(in.Carlength) }, func(in *domain.UserInput, v float64) { in.Carlength = float32(v) }},
+	{"carwidth", false, func(in domain.UserInput) float64 { return float64(in.Carwidth) }, func(in *domain.UserInput, v float64) { in.Carwidth = float32(v) }},
+	{"carheight", false, func(in domain.UserInput) float64 { return float64(in.Carheight) }, func(in *domain.UserInput, v float64) { in.Carheight = float32(v) }},
+	{"curbweight", true, func(in domain.UserInput) float64 { return float64(in.Curbweight) }, func(in *domain.UserInput, v float64) { in.Curbweight = int(v) }},
+	{"enginesize", true, func(in domain.UserInput) float64 { return float64(in.Enginesize) }, func(in *domain.UserInput, v float64) { in.Enginesize = int(v) }},
+	{"boreratio", false, func(in domain.UserInput) float64 { return float64(in.Boreratio) }, func(in *domain.UserInput, v float64) { in.Boreratio = float32(v) }},
+	{"stroke", false, func(in domain.UserInput) float64 { return float64(in.Stroke) }, func(in *domain.UserInput, v float64) { in.Stroke = float32(v) }},
+	{"compressionratio", false, func(in domain.UserInput) float64 { return float64(in.Compressionratio) }, func(in *domain.UserInput, v float64) { in.Compressionratio = float32(v) }},
+	{"horsepower", true, func(in domain.UserInput) float64 { return float64(in.Horsepower) }, func(in *domain.UserInput, v float64) { in.Horsepower = int(v) }},
+	{"peakrpm", true, func(in domain.UserInput) float64 { return float64(in.Peakrpm) }, func(in *domain.UserInput, v float64) { in.Peakrpm = int(v) }},
+	{"citympg", true, func(in domain.UserInput) float64 { return float64(in.Citympg) }, func(in *domain.UserInput, v float64) { in.Citympg = int(v) }},
+	{"highwaympg", true, func(in domain.UserInput) float64 { return float64(in.Highwaympg) }, func(in *domain.UserInput, v float64) { in.Highwaympg = int(v) }},
+}
+
+// categoricalFeatures are the categorical input fields, in the order they are
+// imputed: brand and enginetype first, because other fields are conditioned on them.
+var categoricalFeatures = []categoricalFeature{
+	{"brand", func(in domain.UserInput) string { return in.Brand }, func(in *domain.UserInput, v string) { in.Brand = v }},
+	{"enginetype", func(in domain.UserInput) string { return in.Enginetype }, func(in *domain.UserInput, v string) { in.Enginetype = v }},
+	{"fueltype", func(in domain.UserInput) string { return in.Fueltype }, func(in *domain.UserInput, v string) { in.Fueltype = v }},
+	{"aspiration", func(in domain.UserInput) string { return in.Aspiration }, func(in *domain.UserInput, v string) { in.Aspiration = v }},
+	{"doornumber", func(in domain.UserInput) string { return in.Doornumber }, func(in *domain.UserInput, v string) { in.Doornumber = v }},
+	{"carbody", func(in domain.UserInput) string { return in.Carbody }, func(in *domain.UserInput, v string) { in.Carbody = v }},
+	{"drivewheel", func(in domain.UserInput) string { return in.Drivewheel }, func(in *domain.UserInput, v string) { in.Drivewheel = v }},
+	{"enginelocation", func(in domain.UserInput) string { return in.Enginelocation }, func(in *domain.UserInput, v string) { in.Enginelocation = v }},
+	{"cylindernumber", func(in domain.UserInput) string { return in.Cylindernumber }, func(in *domain.UserInput, v string) { in.Cylindernumber = v }},
+	{"fuelsystem", func(in domain.UserInput) string { return in.Fuelsystem }, func(in *domain.UserInput, v string) { in.Fuelsystem = v }},
+}
+
+// numericConditions are the fields numeric values are conditioned on, most
+// specific first. Categorical values other than brand are conditioned on brand.
+var numericConditions = [][]string{{"brand", "enginetype"}, {"brand"}, {"enginetype"}}
+
+// Table holds the statistics of the training data that missing fields are
+// imputed from, by field and by group of similar cars. Groups are named by
+// their conditions, e.g. "brand=audi,enginetype=ohc", or BasisAll.
+type Table struct {
+	// Samples is the number of training rows the table was built from.
+	Samples int `json:"samples"`
+	// MinGroupSize is the number of rows a group needed to be kept.
+	MinGroupSize int                                     `json:"min_group_size"`
+	Numeric      map[string]map[string]*NumericStats     `json:"numeric"`
+	Categorical  map[string]map[string]*CategoricalStats `json:"categorical"`
+}
+
+// NumericStats summarizes a numeric field over a group of training rows.
+type NumericStats struct {
+	Count  int     `json:"count"`
+	Median float64 `json:"median"`
+	P10    float64 `json:"p10"`
+	P90    float64 `json:"p90"`
+}
+
+// CategoricalStats lists the values of a categorical field over a group of
+// training rows, most frequent first.
+type CategoricalStats struct {
+	Count  int          `json:"count"`
+	Values []ValueCount `json:"values"`
+}
+
+// ValueCount is a categorical value and the number of rows that have it.
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// NewTable builds the imputation table of the given training inputs. Groups
+// with fewer than minGroupSize rows are left out.
+func NewTable(inputs []domain.UserInput, minGroupSize int) (*Table, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("cannot build an imputation table from an empty data set")
+	}
+	if minGroupSize < 1 {
+		minGroupSize = DefaultMinGroupSize
+	}
+	normalized := make([]domain.UserInput, len(inputs))
+	for i, in := range inputs {
+		normalized[i] = prediction.Normalize(in)
+	}
+
+	t := &Table{
+		Samples:      len(inputs),
+		MinGroupSize: minGroupSize,
+		Numeric:      make(map[string]map[string]*NumericStats, len(numericFeatures)),
+		Categorical:  make(map[string]map[string]*CategoricalStats, len(categoricalFeatures)),
+	}
+	for _, f := range numericFeatures {
+		groups := map[string][]float64{}
+		for _, in := range normalized {
+			groups[BasisAll] = append(groups[BasisAll], f.value(in))
+			for _, conditions := range numericConditions {
+				key := groupKey(conditions, in)
+				groups[key] = append(groups[key], f.value(in))
+			}
+		}
+		t.Numeric[f.name] = map[string]*NumericStats{}
+		for key, values := range groups {
+			if len(values) >= minGroupSize || key == BasisAll {
+				t.Numeric[f.name][key] = numericStats(values)
+			}
+		}
+	}
+	for _, f := range categoricalFeatures {
+		groups := map[string][]string{}
+		for _, in := range normalized {
+			groups[BasisAll] = append(groups[BasisAll], f.value(in))
+			if f.name != "brand" {
+				key := groupKey([]string{"brand"}, in)
+				groups[key] = append(groups[key], f.value(in))
+			}
+		}
+		t.Categorical[f.name] = map[string]*CategoricalStats{}
+		for key, values := range groups {
+			if len(values) >= minGroupSize || key == BasisAll {
+				t.Categorical[f.name][key] = categoricalStats(values)
+			}
+		}
+	}
+	return t, nil
+}
+
+// numericStats returns the median and the 10th and 90th percentiles of values,
+// rounded to four decimals to drop the noise of float32 inputs.
+func numericStats(values []float64) *NumericStats {
+	slices.Sort(values)
+	round := func(v float64) float64 { return math.Round(v*1e4) / 1e4 }
+	return &NumericStats{
+		Count:  len(values),
+		Median: round(quantile(values, 0.5)),
+		P10:    round(quantile(values, 0.1)),
+		P90:    round(quantile(values, 0.9)),
+	}
+}
+
+// quantile returns the q-quantile of sorted, interpolating linearly between ranks.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// categoricalStats counts values, most frequent first and ties in alphabetical order.
+func categoricalStats(values []string) *CategoricalStats {
+	counts := map[string]int{}
+	for _, v := range values {
+		counts[v]++
+	}
+	s := &CategoricalStats{Count: len(values)}
+	for v, n := range counts {
+		s.Values = append(s.Values, ValueCount{v, n})
+	}
+	slices.SortFunc(s.Values, func(a, b ValueCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Value, b.Value))
+	})
+	return s
+}
+
+// groupKey names the group of cars sharing the values of the given fields with in.
+func groupKey(fields []string, in domain.UserInput) string {
+	parts := make([]string, len(fields))
+	for i, name := range fields {
+		parts[i] = name + "=" + categorical(name).value(in)
+	}
+	return strings.Join(parts, ",")
+}
+
+// categorical returns the categorical feature with the given name.
+func categorical(name string) categoricalFeature {
+	i := slices.IndexFunc(categoricalFeatures, func(f categoricalFeature) bool { return f.name == name })
+	return categoricalFeatures[i]
+}
+
+// LoadTable reads a table written by WriteFile.
+func LoadTable(path string) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read imputation table: %w", err)
+	}
+	var t Table
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse imputation table: %w", err)
+	}
+	for _, f := range numericFeatures {
+		if t.Numeric[f.name][BasisAll] == nil {
+			return nil, fmt.Errorf("imputation table has no statistics of %s", f.name)
+		}
+	}
+	for _, f := range categoricalFeatures {
+		if s := t.Categorical[f.name][BasisAll]; s == nil || len(s.Values) == 0 {
+			return nil, fmt.Errorf("imputation table has no frequencies of %s", f.name)
+		}
+	}
+	return &t, nil
+}
+
+// WriteFile writes the table as JSON.
+func (t *Table) WriteFile(path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Imputation is a field that was filled in, and the values it could plausibly
+// have had instead.
+type Imputation struct {
+	domain.ImputedField
+	// Alternatives are the 10th and 90th percentiles of a numeric field, or the
+	// next most frequent values of a categorical field, in the same group.
+	Alternatives []any
+}
+
+// maxAlternatives is the number of other categorical values an imputation keeps.
+const maxAlternatives = 2
+
+// Impute fills in the fields of input named in missing (by their JSON names)
+// and returns what it filled in. Categorical fields are imputed first, with the
+// most frequent value among cars of the requested brand; numeric fields then get
+// the median among cars of the requested brand and engine type, the brand, or
+// the engine type, whichever is the most specific group with enough rows. Only
+// values given in the request select a group, never imputed ones.
+func (t *Table) Impute(input domain.UserInput, missing []string) (domain.UserInput, []Imputation) {
+	input = prediction.Normalize(input)
+	known := input
+	given := func(name string) bool { return !slices.Contains(missing, name) }
+
+	var imputed []Imputation
+	for _, f := range categoricalFeatures {
+		if given(f.name) {
+			continue
+		}
+		basis := BasisAll
+		if f.name != "brand" && given("brand") {
+			if key := groupKey([]string{"brand"}, known); t.Categorical[f.name][key] != nil {
+				basis = key
+			}
+		}
+		stats := t.Categorical[f.name][basis]
+		if stats == nil || len(stats.Values) == 0 {
+			continue
+		}
+		f.set(&input, stats.Values[0].Value)
+		imp := Imputation{ImputedField: domain.ImputedField{Field: f.name, Value: stats.Values[0].Value, Basis: basis}}
+		for _, vc := range stats.Values[1:min(len(stats.Values), maxAlternatives+1)] {
+			imp.Alternatives = append(imp.Alternatives, vc.Value)
+		}
+		imputed = append(imputed, imp)
+	}
+
+	for _, f := range numericFeatures {
+		if given(f.name) {
+			continue
+		}
+		basis := BasisAll
+		for _, conditions := range numericConditions {
+			if !all(conditions, given) {
+				continue
+			}
+			if key := groupKey(conditions, known); t.Numeric[f.name][key] != nil {
+				basis = key
+				break
+			}
+		}
+		stats := t.Numeric[f.name][basis]
+		if stats == nil {
+			continue
+		}
+		value := f.round(stats.Median)
+		f.set(&input, value)
+		imputed = append(imputed, Imputation{
+			ImputedField: domain.ImputedField{Field: f.name, Value: value, Basis: basis},
+			Alternatives: []any{f.round(stats.P10), f.round(stats.P90)},
+		})
+	}
+	return input, imputed
+}
+
+// round rounds v to the precision of the field: whole numbers for integer fields
+// and two decimals otherwise.
+func (f numericFeature) round(v float64) float64 {
+	if f.integer {
+		return math.Round(v)
+	}
+	return math.Round(v*100) / 100
+}
+
+// all reports whether every field is given.
+func all(fields []string, given func(string) bool) bool {
+	for _, name := range fields {
+		if !given(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// With returns input with the field set to value, which must be a float64 for
+// numeric fields and a string for categorical ones.
+func With(input domain.UserInput, field string, value any) domain.UserInput {
+	switch v := value.(type) {
+	case float64:
+		for _, f := range numericFeatures {
+			if f.name == field {
+				f.set(&input, v)
+			}
+		}
+	case string:
+		for _, f := range categoricalFeatures {
+			if f.name == field {
+				f.set(&input, v)
+			}
+		}
+	}
+	return input
+}
+
+// PriceRange estimates how far the prediction for an imputed input could move if
+// the imputed fields had their alternative values instead. Each alternative is
+// predicted on its own; the largest decrease and increase of every field are
+// added up and applied to price.
+func PriceRange(service domain.PredictionService, input domain.UserInput, price float32, imputed []Imputation) (*domain.PriceRange, error) {
+	r := &domain.PriceRange{Lower: price, Upper: price}
+	for _, imp := range imputed {
+		var down, up float32
+		for _, alt := range imp.Alternatives {
+			result, err := service.Predict(With(input, imp.Field, alt))
+			if err != nil {
+				return nil, err
+			}
+			delta := result.PredictedPrice - price
+			down, up = min(down, delta), max(up, delta)
+		}
+		r.Lower += down
+		r.Upper += up
+	}
+	r.Lower = max(r.Lower, 0)
+	return r, nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the table.
+func NewContext(ctx context.Context, t *Table) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the table stored in ctx, or nil if imputation is not enabled.
+func FromContext(ctx context.Context) *Table {
+	t, _ := ctx.Value(contextKey{}).(*Table)
+	return t
+}
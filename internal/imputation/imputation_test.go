@@ -0,0 +1,163 @@
+package imputation
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/domain"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleTable(t *testing.T) (*Table, []domain.UserInput) {
+	samples, err := dataset.ReadFile("../dataset/testdata/carprice_sample.csv")
+	require.NoError(t, err)
+	inputs := make([]domain.UserInput, len(samples))
+	for i, s := range samples {
+		inputs[i] = s.Input
+	}
+	table, err := NewTable(inputs, DefaultMinGroupSize)
+	require.NoError(t, err)
+	return table, inputs
+}
+
+func TestNewTable(t *testing.T) {
+	table, _ := sampleTable(t)
+	assert.Equal(t, 30, table.Samples)
+
+	stroke := table.Numeric["stroke"]
+	assert.Equal(t, &NumericStats{Count: 30, Median: 3.26, P10: 2.8, P90: 3.4}, stroke[BasisAll])
+	assert.Equal(t, 8, stroke["brand=bmw,enginetype=ohc"].Count)
+	assert.Equal(t, 3.19, stroke["brand=bmw"].Median)
+	assert.Equal(t, 25, stroke["enginetype=ohc"].Count)
+	// Groups smaller than the minimum are left out
+	assert.NotContains(t, stroke, "brand=mazda")
+	assert.NotContains(t, stroke, "enginetype=dohc")
+
+	brand := table.Categorical["brand"][BasisAll]
+	assert.Equal(t, ValueCount{"bmw", 8}, brand.Values[0])
+	assert.Equal(t, ValueCount{"audi", 7}, brand.Values[1])
+	assert.NotContains(t, table.Categorical["brand"], "brand=bmw")
+	assert.Equal(t, "ohc", table.Categorical["enginetype"]["brand=audi"].Values[0].Value)
+
+	_, err := NewTable(nil, DefaultMinGroupSize)
+	assert.Error(t, err)
+}
+
+func TestTable_Impute(t *testing.T) {
+	table, inputs := sampleTable(t)
+
+	// A quick quote: brand, body, engine size, horsepower and mileage
+	input := domain.UserInput{Brand: "BMW", Carbody: "sedan", Enginesize: 164, Horsepower: 121, Citympg: 21}
+	var missing []string
+	for _, name := range fieldNames() {
+		if !slices.Contains([]string{"brand", "carbody", "enginesize", "horsepower", "citympg"}, name) {
+			missing = append(missing, name)
+		}
+	}
+
+	got, imputed := table.Impute(input, missing)
+	assert.Len(t, imputed, len(missing))
+	assert.Equal(t, "bmw", got.Brand)
+	assert.Equal(t, "sedan", got.Carbody)
+	assert.Equal(t, 164, got.Enginesize)
+
+	byField := map[string]Imputation{}
+	for _, imp := range imputed {
+		byField[imp.Field] = imp
+	}
+	// Categorical fields take the most frequent value among cars of the brand
+	assert.Equal(t, domain.ImputedField{Field: "enginetype", Value: "ohc", Basis: "brand=bmw"}, byField["enginetype"].ImputedField)
+	assert.Equal(t, "rwd", got.Drivewheel)
+	// Numeric fields only condition on values the request gave: the engine type was imputed
+	assert.Equal(t, domain.ImputedField{Field: "stroke", Value: 3.19, Basis: "brand=bmw"}, byField["stroke"].ImputedField)
+	assert.Equal(t, []any{2.8, 3.39}, byField["stroke"].Alternatives)
+	assert.Equal(t, float32(3.19), got.Stroke)
+	// Integer fields are rounded
+	assert.Equal(t, float64(got.Curbweight), byField["curbweight"].Value)
+
+	// Unknown brands fall back to the whole data set
+	got, imputed = table.Impute(domain.UserInput{Brand: "tesla", Enginetype: "ohc"}, []string{"stroke", "carbody"})
+	require.Len(t, imputed, 2)
+	assert.Equal(t, domain.ImputedField{Field: "carbody", Value: "sedan", Basis: BasisAll}, imputed[0].ImputedField)
+	assert.Equal(t, domain.ImputedField{Field: "stroke", Value: 3.39, Basis: "enginetype=ohc"}, imputed[1].ImputedField)
+	assert.Equal(t, "tesla", got.Brand)
+
+	// Given fields are left alone
+	got, imputed = table.Impute(inputs[0], nil)
+	assert.Empty(t, imputed)
+	assert.Equal(t, inputs[0], got)
+}
+
+func TestTable_WriteFileAndLoad(t *testing.T) {
+	table, _ := sampleTable(t)
+	path := filepath.Join(t.TempDir(), "imputation.json")
+	require.NoError(t, table.WriteFile(path))
+
+	loaded, err := LoadTable(path)
+	require.NoError(t, err)
+	assert.Equal(t, table, loaded)
+
+	delete(loaded.Numeric, "stroke")
+	require.NoError(t, loaded.WriteFile(path))
+	_, err = LoadTable(path)
+	assert.ErrorContains(t, err, "no statistics of stroke")
+}
+
+// priceService prices cars by horsepower and body type.
+type priceService struct{}
+
+func (priceService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	price := 100 * float32(input.Horsepower)
+	if input.Carbody == "convertible" {
+		price += 5000
+	}
+	return &domain.PredictionResult{PredictedPrice: price}, nil
+}
+
+func TestPriceRange(t *testing.T) {
+	input := domain.UserInput{Horsepower: 100, Carbody: "sedan"}
+	imputed := []Imputation{
+		{ImputedField: domain.ImputedField{Field: "horsepower", Value: 100.0}, Alternatives: []any{80.0, 150.0}},
+		{ImputedField: domain.ImputedField{Field: "carbody", Value: "sedan"}, Alternatives: []any{"hatchback", "convertible"}},
+	}
+
+	r, err := PriceRange(priceService{}, input, 10000, imputed)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.PriceRange{Lower: 8000, Upper: 20000}, r)
+
+	r, err = PriceRange(priceService{}, input, 10000, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.PriceRange{Lower: 10000, Upper: 10000}, r)
+
+	_, err = PriceRange(failingService{}, input, 10000, imputed)
+	assert.Error(t, err)
+}
+
+type failingService struct{}
+
+func (failingService) Predict(domain.UserInput) (*domain.PredictionResult, error) {
+	return nil, errors.New("model unavailable")
+}
+
+func TestWith(t *testing.T) {
+	input := With(domain.UserInput{}, "curbweight", 2500.0)
+	input = With(input, "boreratio", 3.19)
+	input = With(input, "fuelsystem", "mpfi")
+	assert.Equal(t, domain.UserInput{Curbweight: 2500, Boreratio: 3.19, Fuelsystem: "mpfi"}, input)
+}
+
+// fieldNames returns the JSON names of all input fields.
+func fieldNames() []string {
+	var names []string
+	for _, f := range numericFeatures {
+		names = append(names, f.name)
+	}
+	for _, f := range categoricalFeatures {
+		names = append(names, f.name)
+	}
+	return names
+}
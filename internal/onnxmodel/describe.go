@@ -0,0 +1,99 @@
+package onnxmodel
+
+import (
+	"car-price-prediction/internal/onnxmodel/onnxpb"
+	"strconv"
+	"strings"
+)
+
+// Description summarizes the structure of an ONNX model.
+type Description struct {
+	IRVersion       int64             `json:"ir_version"`
+	Producer        string            `json:"producer,omitempty"`
+	ProducerVersion string            `json:"producer_version,omitempty"`
+	Opsets          []Opset           `json:"opsets"`
+	Inputs          []Tensor          `json:"inputs"`
+	Outputs         []Tensor          `json:"outputs"`
+	Nodes           []Node            `json:"nodes"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+}
+
+// Opset is an operator set the model imports.
+type Opset struct {
+	Domain  string `json:"domain"`
+	Version int64  `json:"version"`
+}
+
+// Tensor is a graph input or output. Dimensions are sizes, symbolic names such
+// as "N", or "?" if unknown.
+type Tensor struct {
+	Name  string   `json:"name"`
+	Type  string   `json:"type"`
+	Shape []string `json:"shape"`
+}
+
+func (t Tensor) String() string {
+	return t.Name + " " + t.Type + "[" + strings.Join(t.Shape, ", ") + "]"
+}
+
+// Node is a graph node.
+type Node struct {
+	Name   string   `json:"name,omitempty"`
+	OpType string   `json:"op_type"`
+	Domain string   `json:"domain,omitempty"`
+	Inputs []string `json:"inputs"`
+	// Outputs are the names of the node's output tensors.
+	Outputs []string `json:"outputs"`
+}
+
+// Describe summarizes the model's operator sets, graph inputs and outputs, and
+// nodes.
+func Describe(m *onnxpb.ModelProto) *Description {
+	d := &Description{
+		IRVersion:       m.GetIrVersion(),
+		Producer:        m.GetProducerName(),
+		ProducerVersion: m.GetProducerVersion(),
+	}
+	for _, o := range m.GetOpsetImport() {
+		d.Opsets = append(d.Opsets, Opset{Domain: o.GetDomain(), Version: o.GetVersion()})
+	}
+	for _, in := range m.GetGraph().GetInput() {
+		d.Inputs = append(d.Inputs, describeTensor(in))
+	}
+	for _, out := range m.GetGraph().GetOutput() {
+		d.Outputs = append(d.Outputs, describeTensor(out))
+	}
+	for _, n := range m.GetGraph().GetNode() {
+		d.Nodes = append(d.Nodes, Node{
+			Name:    n.GetName(),
+			OpType:  n.GetOpType(),
+			Domain:  n.GetDomain(),
+			Inputs:  n.GetInput(),
+			Outputs: n.GetOutput(),
+		})
+	}
+	for _, p := range m.GetMetadataProps() {
+		if d.Metadata == nil {
+			d.Metadata = map[string]string{}
+		}
+		d.Metadata[p.GetKey()] = p.GetValue()
+	}
+	return d
+}
+
+// describeTensor describes a tensor-typed value.
+func describeTensor(v *onnxpb.ValueInfoProto) Tensor {
+	tensor := v.GetType().GetTensorType()
+	t := Tensor{Name: v.GetName(), Type: onnxpb.TensorProto_DataType(tensor.GetElemType()).String()}
+	for _, dim := range tensor.GetShape().GetDim() {
+		switch {
+		case dim.GetDimParam() != "":
+			t.Shape = append(t.Shape, dim.GetDimParam())
+		case dim.GetValue() != nil:
+			t.Shape = append(t.Shape, strconv.FormatInt(dim.GetDimValue(), 10))
+		default:
+			t.Shape = append(t.Shape, "?")
+		}
+	}
+	return t
+}
@@ -0,0 +1,79 @@
+package onnxmodel
+
+import (
+	"car-price-prediction/internal/onnxmodel/onnxpb"
+	"car-price-prediction/internal/prediction"
+	"errors"
+	"fmt"
+)
+
+// LinearRegressor is the ONNX-ML operator of linear regressions, as written by
+// skl2onnx for LinearRegression, Ridge and Lasso.
+const LinearRegressor = "LinearRegressor"
+
+// LinearNode returns the LinearRegressor node of the model, or nil.
+func LinearNode(m *onnxpb.ModelProto) *onnxpb.NodeProto {
+	for _, n := range m.GetGraph().GetNode() {
+		if n.GetOpType() == LinearRegressor && n.GetDomain() == DomainML {
+			return n
+		}
+	}
+	return nil
+}
+
+// ToLinear converts the LinearRegressor of a model into a linear model whose
+// coefficients are keyed by features, the names of the model's input columns.
+// The node must be the whole graph, reading the model input and writing its output.
+func ToLinear(m *onnxpb.ModelProto, features []string) (*prediction.LinearModel, error) {
+	node := LinearNode(m)
+	if node == nil {
+		return nil, errors.New("model has no LinearRegressor node")
+	}
+	graph := m.GetGraph()
+	if len(graph.GetNode()) != 1 || len(node.GetInput()) != 1 || len(node.GetOutput()) != 1 ||
+		len(graph.GetInput()) != 1 || len(graph.GetOutput()) != 1 ||
+		node.GetInput()[0] != graph.GetInput()[0].GetName() || node.GetOutput()[0] != graph.GetOutput()[0].GetName() {
+		return nil, errors.New("the LinearRegressor node must map the model input to its output directly")
+	}
+
+	var coefficients, intercepts []float32
+	targets, postTransform := int64(1), postTransformNone
+	for _, a := range node.GetAttribute() {
+		switch a.GetName() {
+		case "coefficients":
+			coefficients = a.GetFloats()
+		case "intercepts":
+			intercepts = a.GetFloats()
+		case "targets":
+			targets = a.GetI()
+		case "post_transform":
+			postTransform = string(a.GetS())
+		}
+	}
+	if targets != 1 {
+		return nil, fmt.Errorf("%d targets are not supported", targets)
+	}
+	if postTransform != postTransformNone {
+		return nil, fmt.Errorf("post transform %s is not supported", postTransform)
+	}
+	if len(intercepts) > 1 {
+		return nil, errors.New("more than one intercept is not supported")
+	}
+	if width := InputWidth(m); width != len(coefficients) {
+		return nil, fmt.Errorf("model has %d inputs but %d coefficients", width, len(coefficients))
+	}
+	if len(features) != len(coefficients) {
+		return nil, fmt.Errorf("model has %d coefficients but the feature schema has %d columns", len(coefficients), len(features))
+	}
+
+	linear := &prediction.LinearModel{Coefficients: make(map[string]float64, len(features))}
+	if len(intercepts) == 1 {
+		linear.Intercept = float64(intercepts[0])
+	}
+	for i, name := range features {
+		if coefficients[i] != 0 {
+			linear.Coefficients[name] = float64(coefficients[i])
+		}
+	}
+	return linear, nil
+}
@@ -0,0 +1,78 @@
+// Package onnxmodel reads and writes ONNX models without onnxruntime, and converts
+// between random forests and the ONNX TreeEnsembleRegressor operator.
+package onnxmodel
+
+import (
+	"car-price-prediction/internal/onnxmodel/onnxpb"
+	"car-price-prediction/internal/prediction"
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Tensor names the serving code binds to, as written by skl2onnx.
+const (
+	InputName  = prediction.InputTensor
+	OutputName = prediction.OutputTensor
+)
+
+// Operator set domains.
+const (
+	DomainONNX = ""
+	DomainML   = "ai.onnx.ml"
+)
+
+// Parse decodes a serialized ONNX model.
+func Parse(data []byte) (*onnxpb.ModelProto, error) {
+	var m onnxpb.ModelProto
+	if err := proto.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse ONNX model: %w", err)
+	}
+	if m.GetGraph() == nil {
+		return nil, fmt.Errorf("ONNX model has no graph")
+	}
+	return &m, nil
+}
+
+// Load reads an ONNX model from a file.
+func Load(path string) (*onnxpb.ModelProto, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open model: %w", err)
+	}
+	return Parse(data)
+}
+
+// WriteFile serializes the model to a file.
+func WriteFile(path string, m *onnxpb.ModelProto) error {
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to serialize ONNX model: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Metadata returns the value of a metadata property of the model.
+func Metadata(m *onnxpb.ModelProto, key string) (string, bool) {
+	for _, p := range m.GetMetadataProps() {
+		if p.GetKey() == key {
+			return p.GetValue(), true
+		}
+	}
+	return "", false
+}
+
+// tensorInfo describes a float tensor of shape [batch, width], with a symbolic batch size.
+func tensorInfo(name string, width int) *onnxpb.ValueInfoProto {
+	return &onnxpb.ValueInfoProto{
+		Name: proto.String(name),
+		Type: &onnxpb.TypeProto{Value: &onnxpb.TypeProto_TensorType{TensorType: &onnxpb.TypeProto_Tensor{
+			ElemType: proto.Int32(int32(onnxpb.TensorProto_FLOAT)),
+			Shape: &onnxpb.TensorShapeProto{Dim: []*onnxpb.TensorShapeProto_Dimension{
+				{},
+				{Value: &onnxpb.TensorShapeProto_Dimension_DimValue{DimValue: int64(width)}},
+			}},
+		}}},
+	}
+}
@@ -0,0 +1,332 @@
+package onnxmodel
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/forest"
+	"car-price-prediction/internal/onnxmodel/onnxpb"
+	"car-price-prediction/internal/prediction"
+	"car-price-prediction/internal/training"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	onnx "github.com/yalue/onnxruntime_go"
+	"google.golang.org/protobuf/proto"
+)
+
+// trainTestBundle trains a small forest on the sample data set and returns it with
+// the held-out rows.
+func trainTestBundle(t *testing.T) (*prediction.Bundle, []dataset.Sample) {
+	samples, err := dataset.ReadFile("../dataset/testdata/carprice_sample.csv")
+	require.NoError(t, err)
+	params := forest.DefaultParams()
+	params.Trees = 20
+	bundle, err := training.Train(samples, training.Config{Params: params, Holdout: training.DefaultHoldout})
+	require.NoError(t, err)
+	_, test := training.Split(samples, training.DefaultHoldout, params.Seed)
+	return bundle, test
+}
+
+// transform encodes an input in the served feature schema.
+func transform(t *testing.T, input domain.UserInput) []float32 {
+	features, err := prediction.Transform(input)
+	require.NoError(t, err)
+	return features
+}
+
+func TestExportBundle_RoundTrip(t *testing.T) {
+	bundle, test := trainTestBundle(t)
+	m, err := ExportBundle(bundle)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "model.onnx")
+	require.NoError(t, WriteFile(path, m))
+	loaded, err := Load(path)
+	require.NoError(t, err)
+
+	graph := loaded.GetGraph()
+	assert.Equal(t, InputName, graph.GetInput()[0].GetName())
+	assert.Equal(t, OutputName, graph.GetOutput()[0].GetName())
+	assert.Equal(t, prediction.ModelInputSize, InputWidth(loaded))
+	schema, ok := Metadata(loaded, FeatureSchemaKey)
+	require.True(t, ok)
+	assert.Equal(t, prediction.FeatureNames(), strings.Split(schema, ","))
+
+	f, err := ToForest(loaded)
+	require.NoError(t, err)
+	assert.Len(t, f.Trees, 20)
+	for _, s := range test {
+		want := bundle.PredictFeatures(bundle.Encode(s.Input))
+		assert.InDelta(t, want, f.Predict(transform(t, s.Input)), 0.05)
+	}
+}
+
+func TestExportBundle_ONNXRuntime(t *testing.T) {
+	lib := os.Getenv("ONNXRUNTIME_LIB")
+	if lib == "" {
+		t.Skip("set ONNXRUNTIME_LIB to the onnxruntime shared library to run")
+	}
+	onnx.SetSharedLibraryPath(lib)
+	require.NoError(t, onnx.InitializeEnvironment())
+	defer onnx.DestroyEnvironment()
+
+	bundle, test := trainTestBundle(t)
+	m, err := ExportBundle(bundle)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "model.onnx")
+	require.NoError(t, WriteFile(path, m))
+
+	service := prediction.NewPredictionService(path)
+	for _, s := range test {
+		result, err := service.Predict(s.Input)
+		require.NoError(t, err)
+		assert.InDelta(t, bundle.PredictFeatures(bundle.Encode(s.Input)), result.PredictedPrice, 0.05)
+	}
+}
+
+func TestExportBundle_TargetTransform(t *testing.T) {
+	f := &forest.Forest{Features: 1, Trees: []forest.Tree{{Nodes: []forest.Node{{Feature: forest.Leaf, Value: 9.5}}}}}
+	target := &prediction.TargetTransform{Kind: prediction.TargetLog, BiasCorrection: prediction.BiasLognormal, ResidualVariance: 0.03}
+	bundle, err := prediction.NewForestBundle([]string{"horsepower"}, f, prediction.BundleMetadata{Target: target})
+	require.NoError(t, err)
+
+	m, err := ExportBundle(bundle)
+	require.NoError(t, err)
+	_, ok := Metadata(m, prediction.TargetTransformKey)
+	assert.True(t, ok)
+
+	// Serving the export in Go applies the inverse transform like the bundle
+	converted, err := ToBundle(m)
+	require.NoError(t, err)
+	assert.Equal(t, target, converted.Metadata.Target)
+	want := bundle.PredictFeatures(bundle.Encode(domain.UserInput{}))
+	assert.InDelta(t, math.Exp(9.5+0.015), want, 0.01)
+	assert.InDelta(t, want, converted.PredictFeatures(transform(t, domain.UserInput{})), 0.01)
+}
+
+func TestExportBundle_UnknownFeature(t *testing.T) {
+	f := &forest.Forest{Features: 1, Trees: []forest.Tree{{Nodes: []forest.Node{{Feature: forest.Leaf, Value: 1}}}}}
+	bundle, err := prediction.NewForestBundle([]string{"brand_tesla"}, f, prediction.BundleMetadata{})
+	require.NoError(t, err)
+
+	_, err = ExportBundle(bundle)
+	assert.ErrorContains(t, err, "brand_tesla")
+}
+
+func TestToForest_ServedModel(t *testing.T) {
+	m, err := Load("../../model/best_model.onnx")
+	require.NoError(t, err)
+	assert.Equal(t, "skl2onnx", m.GetProducerName())
+
+	f, err := ToForest(m)
+	require.NoError(t, err)
+	assert.Equal(t, prediction.ModelInputSize, f.Features)
+	assert.Len(t, f.Trees, 100)
+
+	// A typical sedan is priced within the range of the training data
+	price := f.Predict(transform(t, domain.UserInput{
+		Symboling: 1, Wheelbase: 97, Carlength: 173.2, Carwidth: 65.5, Carheight: 54.1,
+		Curbweight: 2414, Enginesize: 120, Boreratio: 3.31, Stroke: 3.29, Compressionratio: 9,
+		Horsepower: 95, Peakrpm: 5200, Citympg: 24, Highwaympg: 30, Fueltype: "gas",
+		Aspiration: "std", Doornumber: "four", Carbody: "sedan", Drivewheel: "fwd",
+		Enginelocation: "front", Enginetype: "ohc", Cylindernumber: "four",
+		Fuelsystem: "mpfi", Brand: "toyota",
+	}))
+	assert.Greater(t, price, 5000.0)
+	assert.Less(t, price, 20000.0)
+}
+
+func TestToForest_Modes(t *testing.T) {
+	// One tree: x0 < 2 (true) -> 10, else x0 > 5 (true) -> 30, else 20
+	m := FromForest(&forest.Forest{Features: 1, Trees: []forest.Tree{{Nodes: []forest.Node{{Feature: forest.Leaf}}}}}, []string{"x"}, []int{0})
+	node := TreeEnsemble(m)
+	set := func(name string, a *onnxpb.AttributeProto) {
+		for i, old := range node.Attribute {
+			if old.GetName() == name {
+				node.Attribute[i] = a
+			}
+		}
+	}
+	set("nodes_treeids", intsAttribute("nodes_treeids", []int64{0, 0, 0, 0, 0}))
+	set("nodes_nodeids", intsAttribute("nodes_nodeids", []int64{0, 1, 2, 3, 4}))
+	set("nodes_featureids", intsAttribute("nodes_featureids", []int64{0, 0, 0, 0, 0}))
+	set("nodes_values", floatsAttribute("nodes_values", []float32{2, 0, 5, 0, 0}))
+	set("nodes_modes", stringsAttribute("nodes_modes", [][]byte{[]byte(branchLT), []byte(modeLeaf), []byte(branchGT), []byte(modeLeaf), []byte(modeLeaf)}))
+	set("nodes_truenodeids", intsAttribute("nodes_truenodeids", []int64{1, 0, 3, 0, 0}))
+	set("nodes_falsenodeids", intsAttribute("nodes_falsenodeids", []int64{2, 0, 4, 0, 0}))
+	set("nodes_hitrates", floatsAttribute("nodes_hitrates", nil))
+	set("nodes_missing_value_tracks_true", intsAttribute("nodes_missing_value_tracks_true", nil))
+	set("target_treeids", intsAttribute("target_treeids", []int64{0, 0, 0}))
+	set("target_nodeids", intsAttribute("target_nodeids", []int64{1, 3, 4}))
+	set("target_ids", intsAttribute("target_ids", []int64{0, 0, 0}))
+	set("target_weights", floatsAttribute("target_weights", []float32{10, 30, 20}))
+
+	f, err := ToForest(m)
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, f.Predict([]float32{math.Nextafter32(2, 0)}))
+	assert.Equal(t, 20.0, f.Predict([]float32{2}))
+	assert.Equal(t, 20.0, f.Predict([]float32{5}))
+	assert.Equal(t, 30.0, f.Predict([]float32{5.5}))
+
+	set("aggregate_function", stringAttribute("aggregate_function", "MAX"))
+	_, err = ToForest(m)
+	assert.ErrorContains(t, err, "MAX")
+}
+
+func TestToForest_NoTreeEnsemble(t *testing.T) {
+	_, err := ToForest(&onnxpb.ModelProto{Graph: &onnxpb.GraphProto{Node: []*onnxpb.NodeProto{{OpType: proto.String("Add")}}}})
+	assert.Error(t, err)
+	_, err = Parse([]byte("not a model"))
+	assert.Error(t, err)
+}
+
+func TestToBundle(t *testing.T) {
+	m, err := Load("../../model/best_model.onnx")
+	require.NoError(t, err)
+	bundle, err := ToBundle(m)
+	require.NoError(t, err)
+	assert.Equal(t, prediction.FeatureNames(), bundle.Features)
+
+	input := domain.UserInput{
+		Symboling: 3, Wheelbase: 88.6, Carlength: 168.8, Carwidth: 64.1, Carheight: 48.8,
+		Curbweight: 2548, Enginesize: 130, Boreratio: 3.47, Stroke: 2.68, Compressionratio: 9,
+		Horsepower: 111, Peakrpm: 5000, Citympg: 21, Highwaympg: 27, Fueltype: "gas",
+		Aspiration: "std", Doornumber: "two", Carbody: "convertible", Drivewheel: "rwd",
+		Enginelocation: "front", Enginetype: "dohc", Cylindernumber: "four",
+		Fuelsystem: "mpfi", Brand: "alfa-romero",
+	}
+	result, err := bundle.Predict(input)
+	require.NoError(t, err)
+	assert.InDelta(t, 15000, result.PredictedPrice, 3000, "the first car of the data set sold for $13,495")
+
+	// The schema is checked against the model inputs
+	m.MetadataProps = append(m.MetadataProps, &onnxpb.StringStringEntryProto{Key: proto.String(FeatureSchemaKey), Value: proto.String("horsepower")})
+	_, err = ToBundle(m)
+	assert.Error(t, err)
+}
+
+func TestDescribe(t *testing.T) {
+	m, err := Load("../../model/best_model.onnx")
+	require.NoError(t, err)
+
+	d := Describe(m)
+	assert.Equal(t, int64(10), d.IRVersion)
+	assert.Equal(t, "skl2onnx", d.Producer)
+	assert.Contains(t, d.Opsets, Opset{Domain: DomainML, Version: 1})
+	assert.Equal(t, []Tensor{{Name: InputName, Type: "FLOAT", Shape: []string{"?", "64"}}}, d.Inputs)
+	assert.Equal(t, "variable FLOAT[?, 1]", d.Outputs[0].String())
+	require.Len(t, d.Nodes, 1)
+	assert.Equal(t, Node{Name: TreeEnsembleRegressor, OpType: TreeEnsembleRegressor, Domain: DomainML, Inputs: []string{InputName}, Outputs: []string{OutputName}}, d.Nodes[0])
+	assert.Empty(t, d.Metadata)
+}
+
+// linearModel returns a LinearRegressor model, as skl2onnx writes for Ridge, over
+// the served feature schema with the given coefficients.
+func linearModel(intercept float32, coefficients map[string]float32) *onnxpb.ModelProto {
+	coefs := make([]float32, prediction.ModelInputSize)
+	for i, name := range prediction.FeatureNames() {
+		coefs[i] = coefficients[name]
+	}
+	return &onnxpb.ModelProto{
+		IrVersion:    proto.Int64(irVersion),
+		OpsetImport:  []*onnxpb.OperatorSetIdProto{{Domain: proto.String(DomainML), Version: proto.Int64(opsetML)}},
+		ProducerName: proto.String("skl2onnx"),
+		Graph: &onnxpb.GraphProto{
+			Name: proto.String("ridge"),
+			Node: []*onnxpb.NodeProto{{
+				OpType: proto.String(LinearRegressor),
+				Domain: proto.String(DomainML),
+				Input:  []string{InputName},
+				Output: []string{OutputName},
+				Attribute: []*onnxpb.AttributeProto{
+					floatsAttribute("coefficients", coefs),
+					floatsAttribute("intercepts", []float32{intercept}),
+				},
+			}},
+			Input:  []*onnxpb.ValueInfoProto{tensorInfo(InputName, prediction.ModelInputSize)},
+			Output: []*onnxpb.ValueInfoProto{tensorInfo(OutputName, 1)},
+		},
+	}
+}
+
+// bmwInput is a BMW with 101 horsepower.
+var bmwInput = domain.UserInput{
+	Wheelbase: 101.2, Carlength: 176.8, Carwidth: 64.8, Carheight: 54.3, Curbweight: 2395,
+	Enginesize: 108, Boreratio: 3.5, Stroke: 2.8, Compressionratio: 8.8, Horsepower: 101,
+	Peakrpm: 5800, Citympg: 23, Highwaympg: 29, Fueltype: "gas", Aspiration: "std",
+	Doornumber: "two", Carbody: "sedan", Drivewheel: "rwd", Enginelocation: "front",
+	Enginetype: "ohc", Cylindernumber: "four", Fuelsystem: "mpfi", Brand: "bmw",
+}
+
+func TestToBundle_Linear(t *testing.T) {
+	m := linearModel(-2500, map[string]float32{"horsepower": 120, "brand_bmw": 3000})
+	bundle, err := ToBundle(m)
+	require.NoError(t, err)
+	assert.Equal(t, prediction.KindLinear, bundle.Kind)
+	assert.Equal(t, map[string]float64{"horsepower": 120, "brand_bmw": 3000}, bundle.Linear.Coefficients)
+
+	// Served from a file, linear models run in Go and break their prices down
+	path := filepath.Join(t.TempDir(), "ridge.onnx")
+	require.NoError(t, WriteFile(path, m))
+	assert.True(t, IsLinear(path))
+	service := Open(path)
+	require.IsType(t, &prediction.BundleService{}, service)
+	result, err := service.Predict(bmwInput)
+	require.NoError(t, err)
+	assert.Equal(t, float32(-2500+120*101+3000), result.PredictedPrice)
+	require.NotNil(t, result.Linear)
+	assert.Equal(t, -2500.0, result.Linear.Intercept)
+
+	// Other models are left to prediction.Open
+	forestPath := filepath.Join(t.TempDir(), "forest.onnx")
+	bundle, _ = trainTestBundle(t)
+	exported, err := ExportBundle(bundle)
+	require.NoError(t, err)
+	require.NoError(t, WriteFile(forestPath, exported))
+	assert.False(t, IsLinear(forestPath))
+	assert.IsType(t, &prediction.PredictionService{}, Open(forestPath))
+}
+
+func TestToLinear_Unsupported(t *testing.T) {
+	m := linearModel(0, nil)
+	m.Graph.Node[0].Attribute = append(m.Graph.Node[0].Attribute, stringAttribute("post_transform", "PROBIT"))
+	_, err := ToLinear(m, prediction.FeatureNames())
+	assert.ErrorContains(t, err, "post transform PROBIT")
+
+	m = linearModel(0, nil)
+	m.Graph.Node = append(m.Graph.Node, &onnxpb.NodeProto{OpType: proto.String("Exp"), Input: []string{OutputName}, Output: []string{"price"}})
+	_, err = ToLinear(m, prediction.FeatureNames())
+	assert.ErrorContains(t, err, "must map the model input to its output directly")
+
+	_, err = ToLinear(linearModel(0, nil), []string{"horsepower"})
+	assert.ErrorContains(t, err, "64 coefficients but the feature schema has 1 columns")
+}
+
+func TestArchive_ReplaysLinearModelsInGo(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ridge.onnx")
+	require.NoError(t, WriteFile(path, linearModel(-2500, map[string]float32{"horsepower": 120})))
+	service := Open(path)
+	info := service.Model()
+	archive := NewArchive(filepath.Join(dir, "archive"))
+	require.NoError(t, archive.Save(info))
+
+	// The archived copy is served in Go, without onnxruntime
+	require.NoError(t, os.Remove(path))
+	replayed, err := archive.Open(info.SHA256)
+	require.NoError(t, err)
+	require.IsType(t, &prediction.BundleService{}, replayed)
+	want, err := service.Predict(bmwInput)
+	require.NoError(t, err)
+	got, err := replayed.Predict(bmwInput)
+	require.NoError(t, err)
+	assert.Equal(t, want.PredictedPrice, got.PredictedPrice)
+
+	_, err = archive.Open(strings.Repeat("0", 64))
+	assert.ErrorContains(t, err, "is not archived")
+}
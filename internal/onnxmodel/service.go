@@ -0,0 +1,122 @@
+package onnxmodel
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"fmt"
+
+	onnx "github.com/yalue/onnxruntime_go"
+)
+
+// Runtimes that can run ONNX models.
+const (
+	// RuntimeGo evaluates ONNX tree ensembles in Go; see ToBundle.
+	RuntimeGo = "go"
+	// RuntimeONNXRuntime runs ONNX models with onnxruntime, like the server.
+	RuntimeONNXRuntime = "onnxruntime"
+	// RuntimeONNXRuntimePooled runs ONNX models with reused onnxruntime sessions;
+	// see prediction.PooledService.
+	RuntimeONNXRuntimePooled = "onnxruntime-pooled"
+)
+
+// OpenService returns a prediction service for the model at path, for offline
+// tools. Model bundles are always served in Go; ONNX models are run by runtime,
+// where the onnxruntime runtimes initialize the onnxruntime shared library at lib.
+// The members of an ensemble are opened the same way.
+func OpenService(path, runtime, lib string) (domain.PredictionService, error) {
+	if prediction.IsEnsemble(path) {
+		spec, err := prediction.LoadEnsembleSpec(path)
+		if err != nil {
+			return nil, err
+		}
+		members := make([]domain.PredictionService, len(spec.Members))
+		for i, m := range spec.Members {
+			if members[i], err = OpenService(spec.MemberPath(i), runtime, lib); err != nil {
+				return nil, fmt.Errorf("ensemble member %s: %w", m.Name, err)
+			}
+		}
+		return prediction.NewEnsemble(spec, members)
+	}
+	if prediction.IsBundle(path) {
+		return prediction.LoadBundle(path)
+	}
+	switch runtime {
+	case RuntimeGo:
+		m, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		return ToBundle(m)
+	case RuntimeONNXRuntime, RuntimeONNXRuntimePooled:
+		if err := initializeRuntime(lib); err != nil {
+			return nil, err
+		}
+		if runtime == RuntimeONNXRuntimePooled {
+			return prediction.NewPooledService(path, 0)
+		}
+		return prediction.NewPredictionService(path), nil
+	default:
+		return nil, fmt.Errorf("unknown runtime %q", runtime)
+	}
+}
+
+// initializeRuntime initializes the onnxruntime environment with the shared
+// library at lib, unless it already is.
+func initializeRuntime(lib string) error {
+	if onnx.IsInitialized() {
+		return nil
+	}
+	if lib == "" {
+		return fmt.Errorf("the onnxruntime shared library is not set")
+	}
+	onnx.SetSharedLibraryPath(lib)
+	if err := onnx.InitializeEnvironment(); err != nil {
+		return fmt.Errorf("failed to initialize onnxruntime: %w", err)
+	}
+	return nil
+}
+
+// Open returns a prediction service for the model file at path like
+// prediction.Open, except that ONNX linear regressions are served in Go, with
+// their predictions broken down into terms, and need no onnxruntime.
+func Open(path string) prediction.Model {
+	if IsLinear(path) {
+		return prediction.NewConvertingBundleService(path, func(data []byte) (*prediction.Bundle, error) {
+			m, err := Parse(data)
+			if err != nil {
+				return nil, err
+			}
+			return ToBundle(m)
+		})
+	}
+	return prediction.Open(path)
+}
+
+// IsLinear reports whether path names an ONNX model of a linear regression.
+func IsLinear(path string) bool {
+	if prediction.IsBundle(path) || prediction.IsEnsemble(path) {
+		return false
+	}
+	m, err := Load(path)
+	return err == nil && LinearNode(m) != nil
+}
+
+// Archive is a prediction.Archive that reopens archived models like Open, so that
+// predictions of linear regressions are replayed in Go as they were served.
+type Archive struct {
+	*prediction.Archive
+}
+
+// NewArchive creates an archive storing model files in dir.
+func NewArchive(dir string) *Archive {
+	return &Archive{prediction.NewArchive(dir)}
+}
+
+// Open returns a prediction service for the archived model with the given hash.
+func (a *Archive) Open(sum string) (domain.PredictionService, error) {
+	path, err := a.File(sum)
+	if err != nil {
+		return nil, err
+	}
+	return Open(path), nil
+}
@@ -0,0 +1,449 @@
+package onnxmodel
+
+import (
+	"car-price-prediction/internal/forest"
+	"car-price-prediction/internal/onnxmodel/onnxpb"
+	"car-price-prediction/internal/prediction"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// TreeEnsembleRegressor is the ONNX-ML operator of tree ensemble regressors.
+const TreeEnsembleRegressor = "TreeEnsembleRegressor"
+
+// FeatureSchemaKey is the metadata property listing the input columns,
+// comma-separated, of models exported by this package.
+const FeatureSchemaKey = "feature_schema"
+
+// Versions written into exported models, matching the served model.
+const (
+	irVersion = 10
+	opsetONNX = 21
+	opsetML   = 1
+)
+
+// Attribute values of the TreeEnsembleRegressor operator.
+const (
+	branchLEQ         = "BRANCH_LEQ"
+	branchLT          = "BRANCH_LT"
+	branchGTE         = "BRANCH_GTE"
+	branchGT          = "BRANCH_GT"
+	modeLeaf          = "LEAF"
+	aggregateSum      = "SUM"
+	aggregateAverage  = "AVERAGE"
+	postTransformNone = "NONE"
+)
+
+// ExportBundle converts a random forest bundle into an ONNX model that reads the
+// columns of the served feature schema (prediction.FeatureNames), so that it can
+// replace model/best_model.onnx. It fails if the bundle has preprocessing steps or
+// uses a column the served schema does not have. The bundle's target transform is
+// kept in the target_transform metadata property.
+func ExportBundle(b *prediction.Bundle) (*onnxpb.ModelProto, error) {
+	if b.Kind != prediction.KindRandomForest {
+		return nil, fmt.Errorf("cannot export %s models to ONNX", b.Kind)
+	}
+	if len(b.Preprocessing) > 0 {
+		return nil, errors.New("cannot export models with preprocessing steps to ONNX")
+	}
+	inputs := prediction.FeatureNames()
+	columns := make([]int, len(b.Features))
+	for i, name := range b.Features {
+		columns[i] = slices.Index(inputs, name)
+		if columns[i] < 0 {
+			return nil, fmt.Errorf("feature %q is not an input of the served model", name)
+		}
+	}
+	m := FromForest(b.Forest, inputs, columns)
+	m.DocString = proto.String(fmt.Sprintf("Random forest of %d trees trained on %d rows at %s.",
+		len(b.Forest.Trees), b.Metadata.TrainRows, b.Metadata.TrainedAt.Format("2006-01-02T15:04:05Z")))
+	if b.Metadata.Target != nil {
+		target, err := json.Marshal(b.Metadata.Target)
+		if err != nil {
+			return nil, err
+		}
+		m.MetadataProps = append(m.MetadataProps, &onnxpb.StringStringEntryProto{Key: proto.String(prediction.TargetTransformKey), Value: proto.String(string(target))})
+	}
+	return m, nil
+}
+
+// FromForest converts a forest into an ONNX model with a single
+// TreeEnsembleRegressor node from the float_input tensor, whose columns are named
+// by inputs, to the variable tensor. Feature i of the forest reads input column
+// columns[i]. Leaf values are stored as float32, as ONNX requires.
+func FromForest(f *forest.Forest, inputs []string, columns []int) *onnxpb.ModelProto {
+	var (
+		treeIDs, nodeIDs, featureIDs, trueIDs, falseIDs, missing []int64
+		values, hitRates                                         []float32
+		modes                                                    [][]byte
+		targetTrees, targetNodes, targetIDs                      []int64
+		weights                                                  []float32
+	)
+	n := float64(len(f.Trees))
+	for t, tree := range f.Trees {
+		for i, node := range tree.Nodes {
+			treeIDs = append(treeIDs, int64(t))
+			nodeIDs = append(nodeIDs, int64(i))
+			hitRates = append(hitRates, 1)
+			missing = append(missing, 0)
+			if node.IsLeaf() {
+				modes = append(modes, []byte(modeLeaf))
+				featureIDs = append(featureIDs, 0)
+				values = append(values, 0)
+				trueIDs = append(trueIDs, 0)
+				falseIDs = append(falseIDs, 0)
+
+				// Trees are summed, so every leaf carries its share of the mean
+				targetTrees = append(targetTrees, int64(t))
+				targetNodes = append(targetNodes, int64(i))
+				targetIDs = append(targetIDs, 0)
+				weights = append(weights, float32(node.Value/n))
+				continue
+			}
+			modes = append(modes, []byte(branchLEQ))
+			featureIDs = append(featureIDs, int64(columns[node.Feature]))
+			values = append(values, node.Threshold)
+			trueIDs = append(trueIDs, int64(node.Left))
+			falseIDs = append(falseIDs, int64(node.Right))
+		}
+	}
+
+	attributes := []*onnxpb.AttributeProto{
+		intAttribute("n_targets", 1),
+		intsAttribute("nodes_falsenodeids", falseIDs),
+		intsAttribute("nodes_featureids", featureIDs),
+		floatsAttribute("nodes_hitrates", hitRates),
+		intsAttribute("nodes_missing_value_tracks_true", missing),
+		stringsAttribute("nodes_modes", modes),
+		intsAttribute("nodes_nodeids", nodeIDs),
+		intsAttribute("nodes_treeids", treeIDs),
+		intsAttribute("nodes_truenodeids", trueIDs),
+		floatsAttribute("nodes_values", values),
+		stringAttribute("aggregate_function", aggregateSum),
+		stringAttribute("post_transform", postTransformNone),
+		intsAttribute("target_ids", targetIDs),
+		intsAttribute("target_nodeids", targetNodes),
+		intsAttribute("target_treeids", targetTrees),
+		floatsAttribute("target_weights", weights),
+	}
+
+	return &onnxpb.ModelProto{
+		IrVersion: proto.Int64(irVersion),
+		OpsetImport: []*onnxpb.OperatorSetIdProto{
+			{Domain: proto.String(DomainONNX), Version: proto.Int64(opsetONNX)},
+			{Domain: proto.String(DomainML), Version: proto.Int64(opsetML)},
+		},
+		ProducerName: proto.String("car-price-prediction"),
+		Graph: &onnxpb.GraphProto{
+			Name: proto.String("carprice_forest"),
+			Node: []*onnxpb.NodeProto{{
+				Name:      proto.String(TreeEnsembleRegressor),
+				OpType:    proto.String(TreeEnsembleRegressor),
+				Domain:    proto.String(DomainML),
+				Input:     []string{InputName},
+				Output:    []string{OutputName},
+				Attribute: attributes,
+			}},
+			Input:  []*onnxpb.ValueInfoProto{tensorInfo(InputName, len(inputs))},
+			Output: []*onnxpb.ValueInfoProto{tensorInfo(OutputName, 1)},
+		},
+		MetadataProps: []*onnxpb.StringStringEntryProto{
+			{Key: proto.String(FeatureSchemaKey), Value: proto.String(strings.Join(inputs, ","))},
+		},
+	}
+}
+
+// TreeEnsemble returns the TreeEnsembleRegressor node of the model, or nil.
+func TreeEnsemble(m *onnxpb.ModelProto) *onnxpb.NodeProto {
+	for _, n := range m.GetGraph().GetNode() {
+		if n.GetOpType() == TreeEnsembleRegressor && n.GetDomain() == DomainML {
+			return n
+		}
+	}
+	return nil
+}
+
+// InputWidth returns the number of columns of the model's first input, or 0 if
+// it is not a fixed-width matrix.
+func InputWidth(m *onnxpb.ModelProto) int {
+	inputs := m.GetGraph().GetInput()
+	if len(inputs) == 0 {
+		return 0
+	}
+	dims := inputs[0].GetType().GetTensorType().GetShape().GetDim()
+	if len(dims) != 2 {
+		return 0
+	}
+	return int(dims[1].GetDimValue())
+}
+
+// ensemble holds the attributes of a TreeEnsembleRegressor node.
+type ensemble struct {
+	treeIDs, nodeIDs, featureIDs, trueIDs, falseIDs []int64
+	values                                          []float32
+	modes                                           []string
+	targetTrees, targetNodes, targetIDs             []int64
+	weights, baseValues                             []float32
+	targets                                         int64
+	aggregate, postTransform                        string
+}
+
+// readEnsemble reads the attributes of a TreeEnsembleRegressor node, applying the
+// operator's defaults.
+func readEnsemble(node *onnxpb.NodeProto) (*ensemble, error) {
+	e := &ensemble{targets: 1, aggregate: aggregateSum, postTransform: postTransformNone}
+	for _, a := range node.GetAttribute() {
+		switch a.GetName() {
+		case "n_targets":
+			e.targets = a.GetI()
+		case "nodes_treeids":
+			e.treeIDs = a.GetInts()
+		case "nodes_nodeids":
+			e.nodeIDs = a.GetInts()
+		case "nodes_featureids":
+			e.featureIDs = a.GetInts()
+		case "nodes_truenodeids":
+			e.trueIDs = a.GetInts()
+		case "nodes_falsenodeids":
+			e.falseIDs = a.GetInts()
+		case "nodes_values":
+			e.values = a.GetFloats()
+		case "nodes_modes":
+			for _, s := range a.GetStrings() {
+				e.modes = append(e.modes, string(s))
+			}
+		case "target_treeids":
+			e.targetTrees = a.GetInts()
+		case "target_nodeids":
+			e.targetNodes = a.GetInts()
+		case "target_ids":
+			e.targetIDs = a.GetInts()
+		case "target_weights":
+			e.weights = a.GetFloats()
+		case "base_values":
+			e.baseValues = a.GetFloats()
+		case "aggregate_function":
+			e.aggregate = string(a.GetS())
+		case "post_transform":
+			e.postTransform = string(a.GetS())
+		case "nodes_values_as_tensor", "target_weights_as_tensor", "base_values_as_tensor":
+			return nil, fmt.Errorf("attribute %s is not supported", a.GetName())
+		}
+	}
+
+	n := len(e.nodeIDs)
+	for name, l := range map[string]int{
+		"nodes_treeids": len(e.treeIDs), "nodes_featureids": len(e.featureIDs),
+		"nodes_truenodeids": len(e.trueIDs), "nodes_falsenodeids": len(e.falseIDs),
+		"nodes_values": len(e.values), "nodes_modes": len(e.modes),
+	} {
+		if l != n {
+			return nil, fmt.Errorf("%s has %d entries, nodes_nodeids has %d", name, l, n)
+		}
+	}
+	t := len(e.targetNodes)
+	if len(e.targetTrees) != t || len(e.targetIDs) != t || len(e.weights) != t {
+		return nil, errors.New("target attributes differ in length")
+	}
+	if e.targets != 1 {
+		return nil, fmt.Errorf("%d targets are not supported", e.targets)
+	}
+	if e.aggregate != aggregateSum && e.aggregate != aggregateAverage {
+		return nil, fmt.Errorf("aggregate function %s is not supported", e.aggregate)
+	}
+	if e.postTransform != postTransformNone {
+		return nil, fmt.Errorf("post transform %s is not supported", e.postTransform)
+	}
+	if len(e.baseValues) > 1 {
+		return nil, errors.New("more than one base value is not supported")
+	}
+	return e, nil
+}
+
+// ToForest converts the TreeEnsembleRegressor of a model into a forest that
+// predicts the same values, so that ONNX models can be inspected and evaluated in
+// Go. The forest's features are the columns of the model input.
+func ToForest(m *onnxpb.ModelProto) (*forest.Forest, error) {
+	node := TreeEnsemble(m)
+	if node == nil {
+		return nil, errors.New("model has no TreeEnsembleRegressor node")
+	}
+	width := InputWidth(m)
+	if width <= 0 {
+		return nil, errors.New("model input is not a fixed-width matrix")
+	}
+	e, err := readEnsemble(node)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index the nodes and leaf weights of every tree
+	type key struct{ tree, node int64 }
+	entries := make(map[key]int, len(e.nodeIDs))
+	var trees []int64
+	for i := range e.nodeIDs {
+		k := key{e.treeIDs[i], e.nodeIDs[i]}
+		if _, ok := entries[k]; ok {
+			return nil, fmt.Errorf("tree %d has node %d twice", k.tree, k.node)
+		}
+		entries[k] = i
+		if !slices.Contains(trees, k.tree) {
+			trees = append(trees, k.tree)
+		}
+	}
+	slices.Sort(trees)
+	leafWeights := make(map[key]float64, len(e.targetNodes))
+	for i := range e.targetNodes {
+		leafWeights[key{e.targetTrees[i], e.targetNodes[i]}] += float64(e.weights[i])
+	}
+
+	// Leaves are scaled so that the mean over the trees equals the operator's output
+	scale, base := 1.0, 0.0
+	if e.aggregate == aggregateSum {
+		scale = float64(len(trees))
+	}
+	if len(e.baseValues) == 1 {
+		base = float64(e.baseValues[0])
+	}
+
+	f := &forest.Forest{Features: width, Trees: make([]forest.Tree, len(trees))}
+	for ti, tree := range trees {
+		// The root is the only node no other node of the tree points to
+		children := map[int64]bool{}
+		for i := range e.nodeIDs {
+			if e.treeIDs[i] == tree && e.modes[i] != modeLeaf {
+				children[e.trueIDs[i]], children[e.falseIDs[i]] = true, true
+			}
+		}
+		root := int64(-1)
+		for i := range e.nodeIDs {
+			if e.treeIDs[i] == tree && !children[e.nodeIDs[i]] {
+				if root >= 0 {
+					return nil, fmt.Errorf("tree %d has more than one root", tree)
+				}
+				root = e.nodeIDs[i]
+			}
+		}
+		if root < 0 {
+			return nil, fmt.Errorf("tree %d has no root", tree)
+		}
+
+		var nodes []forest.Node
+		var add func(id int64, depth int) (int, error)
+		add = func(id int64, depth int) (int, error) {
+			i, ok := entries[key{tree, id}]
+			if !ok {
+				return 0, fmt.Errorf("tree %d references missing node %d", tree, id)
+			}
+			if depth > len(e.nodeIDs) {
+				return 0, fmt.Errorf("tree %d has a cycle", tree)
+			}
+			index := len(nodes)
+			if e.modes[i] == modeLeaf {
+				nodes = append(nodes, forest.Node{Feature: forest.Leaf, Value: leafWeights[key{tree, id}]*scale + base})
+				return index, nil
+			}
+			threshold, left, right, err := leqSplit(e.modes[i], e.values[i], e.trueIDs[i], e.falseIDs[i])
+			if err != nil {
+				return 0, fmt.Errorf("tree %d node %d: %w", tree, id, err)
+			}
+			if e.featureIDs[i] < 0 || e.featureIDs[i] >= int64(width) {
+				return 0, fmt.Errorf("tree %d node %d splits on unknown feature %d", tree, id, e.featureIDs[i])
+			}
+			nodes = append(nodes, forest.Node{Feature: int(e.featureIDs[i]), Threshold: threshold})
+			l, err := add(left, depth+1)
+			if err != nil {
+				return 0, err
+			}
+			r, err := add(right, depth+1)
+			if err != nil {
+				return 0, err
+			}
+			nodes[index].Left, nodes[index].Right = l, r
+			return index, nil
+		}
+		if _, err := add(root, 0); err != nil {
+			return nil, err
+		}
+		f.Trees[ti] = forest.Tree{Nodes: nodes}
+	}
+	return f, f.Validate()
+}
+
+// leqSplit rewrites a branch as "x <= threshold goes to left". Comparisons are
+// made in float32, as by onnxruntime, so x < t is x <= the float below t.
+func leqSplit(mode string, value float32, trueID, falseID int64) (threshold float32, left, right int64, err error) {
+	below := math.Nextafter32(value, float32(math.Inf(-1)))
+	switch mode {
+	case branchLEQ:
+		return value, trueID, falseID, nil
+	case branchLT:
+		return below, trueID, falseID, nil
+	case branchGT:
+		return value, falseID, trueID, nil
+	case branchGTE:
+		return below, falseID, trueID, nil
+	}
+	return 0, 0, 0, fmt.Errorf("node mode %s is not supported", mode)
+}
+
+func intAttribute(name string, v int64) *onnxpb.AttributeProto {
+	return &onnxpb.AttributeProto{Name: proto.String(name), Type: onnxpb.AttributeProto_INT.Enum(), I: proto.Int64(v)}
+}
+
+func intsAttribute(name string, v []int64) *onnxpb.AttributeProto {
+	return &onnxpb.AttributeProto{Name: proto.String(name), Type: onnxpb.AttributeProto_INTS.Enum(), Ints: v}
+}
+
+func floatsAttribute(name string, v []float32) *onnxpb.AttributeProto {
+	return &onnxpb.AttributeProto{Name: proto.String(name), Type: onnxpb.AttributeProto_FLOATS.Enum(), Floats: v}
+}
+
+func stringAttribute(name, v string) *onnxpb.AttributeProto {
+	return &onnxpb.AttributeProto{Name: proto.String(name), Type: onnxpb.AttributeProto_STRING.Enum(), S: []byte(v)}
+}
+
+func stringsAttribute(name string, v [][]byte) *onnxpb.AttributeProto {
+	return &onnxpb.AttributeProto{Name: proto.String(name), Type: onnxpb.AttributeProto_STRINGS.Enum(), Strings: v}
+}
+
+// ToBundle converts the TreeEnsembleRegressor or LinearRegressor of a model into
+// a bundle served in Go. The feature schema is read from the feature_schema
+// metadata property, or is the served schema for models with its 64 inputs, such
+// as the scikit-learn export. The target_transform metadata property is carried over.
+func ToBundle(m *onnxpb.ModelProto) (*prediction.Bundle, error) {
+	features := prediction.FeatureNames()
+	if schema, ok := Metadata(m, FeatureSchemaKey); ok {
+		features = strings.Split(schema, ",")
+	}
+	var metadata prediction.BundleMetadata
+	if value, ok := Metadata(m, prediction.TargetTransformKey); ok {
+		var target prediction.TargetTransform
+		if err := json.Unmarshal([]byte(value), &target); err != nil {
+			return nil, fmt.Errorf("invalid %s metadata: %w", prediction.TargetTransformKey, err)
+		}
+		metadata.Target = &target
+	}
+
+	if LinearNode(m) != nil {
+		linear, err := ToLinear(m, features)
+		if err != nil {
+			return nil, err
+		}
+		return prediction.NewLinearBundle(features, linear, metadata)
+	}
+	f, err := ToForest(m)
+	if err != nil {
+		return nil, err
+	}
+	if len(features) != f.Features {
+		return nil, fmt.Errorf("model has %d inputs but the feature schema has %d columns", f.Features, len(features))
+	}
+	return prediction.NewForestBundle(features, f, metadata)
+}
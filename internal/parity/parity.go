@@ -0,0 +1,172 @@
+// Package parity checks for training/serving skew: that the Go feature encoding
+// produces the same model inputs as the Python training pipeline, and that a model
+// served in Go makes the same predictions as the Python runtime. Both are compared
+// with golden files exported once from pandas and onnxruntime.
+package parity
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PredictionColumn is the column of the golden predictions file.
+const PredictionColumn = "price"
+
+// DefaultTolerance is the largest difference in dollars between a prediction and
+// its golden value that is not a mismatch. It allows for the float32 arithmetic of
+// onnxruntime.
+const DefaultTolerance = 0.5
+
+// Matrix is a table of numbers with named columns, such as the encoded model
+// inputs exported from pandas.
+type Matrix struct {
+	Columns []string
+	Rows    [][]float64
+}
+
+// ReadMatrix reads a numeric CSV file with a header row.
+func ReadMatrix(path string) (*Matrix, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open golden file: %w", err)
+	}
+	defer f.Close()
+
+	cr := csv.NewReader(f)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	m := &Matrix{Columns: make([]string, len(header))}
+	for i, name := range header {
+		m.Columns[i] = strings.TrimSpace(name)
+	}
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		row := make([]float64, len(record))
+		for i, field := range record {
+			if row[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64); err != nil {
+				return nil, fmt.Errorf("%s line %d: invalid %s: %q", path, line, m.Columns[i], field)
+			}
+		}
+		m.Rows = append(m.Rows, row)
+	}
+	return m, nil
+}
+
+// Column returns the values of the named column.
+func (m *Matrix) Column(name string) ([]float64, error) {
+	for i, c := range m.Columns {
+		if c == name {
+			values := make([]float64, len(m.Rows))
+			for j, row := range m.Rows {
+				values[j] = row[i]
+			}
+			return values, nil
+		}
+	}
+	return nil, fmt.Errorf("golden file has no %q column", name)
+}
+
+// Mismatch is a value that differs from its golden value.
+type Mismatch struct {
+	// Row is the 1-based row of the data set, not counting the header.
+	Row    int     `json:"row"`
+	Name   string  `json:"name"`
+	Column string  `json:"column"`
+	Want   float64 `json:"want"`
+	Got    float64 `json:"got"`
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("row %d (%s) %s: got %v, want %v", m.Row, m.Name, m.Column, m.Got, m.Want)
+}
+
+// Encoding is the feature encoding of a model: its column names and the function
+// encoding an input into those columns.
+type Encoding struct {
+	Columns []string
+	Encode  func(domain.UserInput) []float32
+}
+
+// TransformEncoding returns the encoding of ONNX models served by the API, which
+// use Transform.
+func TransformEncoding() Encoding {
+	return Encoding{
+		Columns: prediction.FeatureNames(),
+		Encode: func(input domain.UserInput) []float32 {
+			features, _ := prediction.Transform(input)
+			return features
+		},
+	}
+}
+
+// BundleEncoding returns the encoding of a model bundle.
+func BundleEncoding(b *prediction.Bundle) Encoding {
+	return Encoding{Columns: b.Features, Encode: b.Encode}
+}
+
+// CheckFeatures encodes every sample and compares each column with the golden
+// column of the same name. The values must be identical as float32, the type of
+// the model input. Golden columns the encoding does not use are ignored.
+func CheckFeatures(samples []dataset.Sample, golden *Matrix, enc Encoding) ([]Mismatch, error) {
+	if len(golden.Rows) != len(samples) {
+		return nil, fmt.Errorf("golden file has %d rows, the data set %d", len(golden.Rows), len(samples))
+	}
+	index := make(map[string]int, len(golden.Columns))
+	for i, name := range golden.Columns {
+		index[name] = i
+	}
+	for _, name := range enc.Columns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("golden file has no %q column", name)
+		}
+	}
+
+	var mismatches []Mismatch
+	for i, s := range samples {
+		features := enc.Encode(s.Input)
+		for j, name := range enc.Columns {
+			want := golden.Rows[i][index[name]]
+			if features[j] != float32(want) {
+				mismatches = append(mismatches, Mismatch{Row: i + 1, Name: s.Name, Column: name, Want: want, Got: float64(features[j])})
+			}
+		}
+	}
+	return mismatches, nil
+}
+
+// CheckPredictions predicts every sample with service and compares the price with
+// its golden value, allowing a difference of tolerance dollars.
+func CheckPredictions(samples []dataset.Sample, golden []float64, service domain.PredictionService, tolerance float64) ([]Mismatch, error) {
+	if len(golden) != len(samples) {
+		return nil, fmt.Errorf("golden file has %d predictions, the data set %d rows", len(golden), len(samples))
+	}
+	var mismatches []Mismatch
+	for i, s := range samples {
+		result, err := service.Predict(s.Input)
+		if err != nil {
+			return nil, fmt.Errorf("row %d (%s): %w", i+1, s.Name, err)
+		}
+		got := float64(result.PredictedPrice)
+		if math.Abs(got-golden[i]) > tolerance {
+			mismatches = append(mismatches, Mismatch{Row: i + 1, Name: s.Name, Column: PredictionColumn, Want: golden[i], Got: got})
+		}
+	}
+	return mismatches, nil
+}
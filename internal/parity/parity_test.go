@@ -0,0 +1,108 @@
+package parity
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/forest"
+	"car-price-prediction/internal/onnxmodel"
+	"car-price-prediction/internal/prediction"
+	"car-price-prediction/internal/training"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const servedModel = "../../model/best_model.onnx"
+
+// golden reads the fixture rows and their golden features and predictions.
+func golden(t *testing.T) ([]dataset.Sample, *Matrix, []float64) {
+	t.Helper()
+	samples, err := dataset.ReadFile(filepath.Join("testdata", "raw.csv"))
+	require.NoError(t, err)
+	features, err := ReadMatrix(filepath.Join("testdata", "features.csv"))
+	require.NoError(t, err)
+	predictions, err := ReadMatrix(filepath.Join("testdata", "predictions.csv"))
+	require.NoError(t, err)
+	prices, err := predictions.Column(PredictionColumn)
+	require.NoError(t, err)
+	return samples, features, prices
+}
+
+func TestTransform_MatchesPandas(t *testing.T) {
+	samples, features, _ := golden(t)
+	assert.Equal(t, prediction.FeatureNames(), features.Columns, "golden columns differ from model_column.txt")
+
+	mismatches, err := CheckFeatures(samples, features, TransformEncoding())
+	require.NoError(t, err)
+	assert.Empty(t, mismatches)
+}
+
+func TestServedModel_MatchesGoldenPredictions(t *testing.T) {
+	samples, _, prices := golden(t)
+	service, err := onnxmodel.OpenService(servedModel, onnxmodel.RuntimeGo, "")
+	require.NoError(t, err)
+
+	mismatches, err := CheckPredictions(samples, prices, service, DefaultTolerance)
+	require.NoError(t, err)
+	assert.Empty(t, mismatches)
+}
+
+func TestServedModel_MatchesGoldenPredictionsONNXRuntime(t *testing.T) {
+	lib := os.Getenv("ONNXRUNTIME_LIB")
+	if lib == "" {
+		t.Skip("set ONNXRUNTIME_LIB to the onnxruntime shared library to run")
+	}
+	samples, _, prices := golden(t)
+	service, err := onnxmodel.OpenService(servedModel, onnxmodel.RuntimeONNXRuntime, lib)
+	require.NoError(t, err)
+
+	mismatches, err := CheckPredictions(samples, prices, service, DefaultTolerance)
+	require.NoError(t, err)
+	assert.Empty(t, mismatches)
+}
+
+func TestCheckFeatures_ReportsMismatches(t *testing.T) {
+	samples, features, _ := golden(t)
+	features.Rows[2][0]++ // symboling
+	enc := TransformEncoding()
+
+	mismatches, err := CheckFeatures(samples, features, enc)
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, Mismatch{Row: 3, Name: samples[2].Name, Column: "symboling", Want: 2, Got: 1}, mismatches[0])
+
+	enc.Columns = append(enc.Columns, "brand_tesla")
+	_, err = CheckFeatures(samples, features, enc)
+	assert.ErrorContains(t, err, `no "brand_tesla" column`)
+
+	_, err = CheckFeatures(samples[1:], features, TransformEncoding())
+	assert.ErrorContains(t, err, "30 rows, the data set 29")
+}
+
+func TestCheckFeatures_Bundle(t *testing.T) {
+	samples, features, _ := golden(t)
+	params := forest.DefaultParams()
+	params.Trees = 3
+	bundle, err := training.Train(samples, training.Config{Params: params})
+	require.NoError(t, err)
+
+	mismatches, err := CheckFeatures(samples, features, BundleEncoding(bundle))
+	require.NoError(t, err)
+	assert.Empty(t, mismatches)
+}
+
+func TestCheckPredictions_ReportsMismatches(t *testing.T) {
+	samples, _, prices := golden(t)
+	service, err := onnxmodel.OpenService(servedModel, onnxmodel.RuntimeGo, "")
+	require.NoError(t, err)
+	prices[4] += 2 * DefaultTolerance
+
+	mismatches, err := CheckPredictions(samples, prices, service, DefaultTolerance)
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, 5, mismatches[0].Row)
+	assert.Equal(t, PredictionColumn, mismatches[0].Column)
+	assert.InDelta(t, 2*DefaultTolerance, mismatches[0].Want-mismatches[0].Got, 1e-3)
+}
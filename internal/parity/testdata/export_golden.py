@@ -0,0 +1,58 @@
+"""Exports the golden files of the training/serving parity check.
+
+Encodes raw.csv the way the training notebook did and writes the model inputs to
+features.csv and the ONNX model's predictions for them to predictions.csv:
+
+    pip install pandas onnxruntime
+    python export_golden.py [--model ../../../model/best_model.onnx]
+
+Rerun it, and commit the results, whenever the notebook's preprocessing or the
+served model changes.
+"""
+
+import argparse
+import os
+
+import numpy as np
+import onnxruntime as ort
+import pandas as pd
+
+HERE = os.path.dirname(os.path.abspath(__file__))
+ROOT = os.path.join(HERE, "..", "..", "..")
+
+# Misspelled brands in CarName, fixed as in the notebook.
+BRAND_FIXES = {
+    "maxda": "mazda",
+    "porcshce": "porsche",
+    "toyouta": "toyota",
+    "vokswagen": "volkswagen",
+    "vw": "volkswagen",
+}
+
+
+def main():
+    parser = argparse.ArgumentParser()
+    parser.add_argument("--model", default=os.path.join(ROOT, "model", "best_model.onnx"))
+    parser.add_argument("--columns", default=os.path.join(ROOT, "docs", "model", "model_column.txt"))
+    args = parser.parse_args()
+
+    with open(args.columns) as f:
+        columns = [line.strip() for line in f if line.strip()]
+
+    df = pd.read_csv(os.path.join(HERE, "raw.csv"))
+    df["brand"] = df["CarName"].str.split(" ").str[0].str.lower().replace(BRAND_FIXES)
+    df = df.drop(columns=["car_ID", "CarName", "price"])
+
+    # drop_first on the full data set drops the first category of each column, which
+    # is not a model column. The fixture lacks some categories, so encode every
+    # category and keep the model's columns instead.
+    features = pd.get_dummies(df, dtype=int).reindex(columns=columns, fill_value=0)
+    features.to_csv(os.path.join(HERE, "features.csv"), index=False)
+
+    session = ort.InferenceSession(args.model)
+    (prices,) = session.run(None, {"float_input": features.to_numpy(dtype=np.float32)})
+    pd.DataFrame({"price": prices.ravel()}).to_csv(os.path.join(HERE, "predictions.csv"), index=False)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,178 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Archive keeps a copy of every model file by content hash, so that predictions
+// made by a model that has since been replaced can still be reproduced.
+type Archive struct {
+	dir string
+
+	mu    sync.Mutex
+	saved map[string]bool
+}
+
+// NewArchive creates an archive storing model files in dir.
+func NewArchive(dir string) *Archive {
+	return &Archive{dir: dir, saved: map[string]bool{}}
+}
+
+// Save copies the model file described by info into the archive unless a copy with
+// the same hash exists. It fails if the file no longer matches info, e.g. because
+// it was replaced without a reload. Ensembles are archived with their members.
+func (a *Archive) Save(info domain.ModelInfo) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if IsEnsemble(info.Path) {
+		return a.saveEnsemble(info)
+	}
+	return a.save(info)
+}
+
+// save archives a single model file. The caller holds a.mu.
+func (a *Archive) save(info domain.ModelInfo) error {
+	if a.saved[info.SHA256] {
+		return nil
+	}
+	dst := a.path(info.SHA256, modelExt(info.Path))
+	if _, err := os.Stat(dst); err == nil {
+		a.saved[info.SHA256] = true
+		return nil
+	}
+
+	if err := os.MkdirAll(a.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create model archive: %w", err)
+	}
+	if err := copyVerified(info.Path, dst, info.SHA256); err != nil {
+		return err
+	}
+	a.saved[info.SHA256] = true
+	return nil
+}
+
+// saveEnsemble archives the members of an ensemble and the ensemble with its
+// members referring to their archived copies. The caller holds a.mu.
+func (a *Archive) saveEnsemble(info domain.ModelInfo) error {
+	if a.saved[info.SHA256] {
+		return nil
+	}
+	spec, err := LoadEnsembleSpec(info.Path)
+	if err != nil {
+		return err
+	}
+	members := make([]domain.ModelInfo, len(spec.Members))
+	for i := range spec.Members {
+		if members[i], err = loadModelInfo(spec.MemberPath(i)); err != nil {
+			return err
+		}
+	}
+	data, sum, err := spec.resolve(members)
+	if err != nil {
+		return err
+	}
+	if sum != info.SHA256 {
+		return errors.New("ensemble changed since it was loaded; reload the model")
+	}
+	for _, member := range members {
+		if err := a.save(member); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(a.dir, ".model-*")
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), a.path(sum, EnsembleExt)); err != nil {
+		return err
+	}
+	a.saved[sum] = true
+	return nil
+}
+
+// Open returns a prediction service for the archived model with the given hash.
+func (a *Archive) Open(sum string) (domain.PredictionService, error) {
+	path, err := a.File(sum)
+	if err != nil {
+		return nil, err
+	}
+	return Open(path), nil
+}
+
+// File returns the path of the archived model with the given hash.
+func (a *Archive) File(sum string) (string, error) {
+	if _, err := hex.DecodeString(sum); err != nil || len(sum) != 2*sha256.Size {
+		return "", fmt.Errorf("invalid model hash %q", sum)
+	}
+	for _, ext := range []string{".onnx", BundleExt, EnsembleExt} {
+		path := a.path(sum, ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("model %s is not archived", sum)
+}
+
+// path returns the archive file name for a model hash and file extension.
+func (a *Archive) path(sum, ext string) string {
+	return filepath.Join(a.dir, sum+ext)
+}
+
+// modelExt returns the extension of a model file, keeping ensembles and bundles
+// apart from ONNX models.
+func modelExt(path string) string {
+	if IsEnsemble(path) {
+		return EnsembleExt
+	}
+	if IsBundle(path) {
+		return BundleExt
+	}
+	return ".onnx"
+}
+
+// copyVerified copies src to dst and checks that the copy hashes to sum. The copy
+// is written to a temporary file first so that dst is never partial.
+func copyVerified(src, dst, sum string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open model: %w", err)
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".model-*")
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), in); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to copy model: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != sum {
+		return errors.New("model file changed since it was loaded; reload the model")
+	}
+	return os.Rename(tmp.Name(), dst)
+}
@@ -0,0 +1,111 @@
+package prediction_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/forest"
+	"car-price-prediction/internal/prediction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive_SaveAndOpen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "model.onnx")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0o600))
+	info := prediction.NewPredictionService(path).Model()
+
+	archive := prediction.NewArchive(filepath.Join(dir, "archive"))
+	require.NoError(t, archive.Save(info))
+	require.NoError(t, archive.Save(info), "saving again is a no-op")
+
+	data, err := os.ReadFile(filepath.Join(dir, "archive", info.SHA256+".onnx"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(data))
+
+	service, err := archive.Open(info.SHA256)
+	require.NoError(t, err)
+	assert.NotNil(t, service)
+}
+
+func TestArchive_SaveRejectsChangedModel(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "model.onnx")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0o600))
+	info := prediction.NewPredictionService(path).Model()
+
+	// The file is replaced without a reload
+	require.NoError(t, os.WriteFile(path, []byte("v2"), 0o600))
+
+	archive := prediction.NewArchive(filepath.Join(dir, "archive"))
+	assert.Error(t, archive.Save(info))
+	_, err := archive.Open(info.SHA256)
+	assert.Error(t, err)
+}
+
+func TestArchive_OpenRejectsInvalidHash(t *testing.T) {
+	archive := prediction.NewArchive(t.TempDir())
+
+	_, err := archive.Open("../model")
+	assert.Error(t, err)
+}
+
+func TestArchive_KeepsBundles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "model.json")
+	b, err := prediction.NewForestBundle([]string{"horsepower"}, &forest.Forest{
+		Features: 1,
+		Trees:    []forest.Tree{{Nodes: []forest.Node{{Feature: forest.Leaf, Value: 12345}}}},
+	}, prediction.BundleMetadata{})
+	require.NoError(t, err)
+	require.NoError(t, b.WriteFile(path))
+	info := prediction.Open(path).Model()
+
+	archive := prediction.NewArchive(filepath.Join(dir, "archive"))
+	require.NoError(t, archive.Save(info))
+	require.NoError(t, os.Remove(path))
+
+	service, err := archive.Open(info.SHA256)
+	require.NoError(t, err)
+	assert.Equal(t, info.SHA256, service.(domain.ModelManager).Model().SHA256)
+}
+
+func TestArchive_KeepsEnsembles(t *testing.T) {
+	dir := t.TempDir()
+	for name, price := range map[string]float64{"a.json": 10000, "b.json": 14000} {
+		b, err := prediction.NewForestBundle([]string{"horsepower"}, &forest.Forest{
+			Features: 1,
+			Trees:    []forest.Tree{{Nodes: []forest.Node{{Feature: forest.Leaf, Value: price}}}},
+		}, prediction.BundleMetadata{})
+		require.NoError(t, err)
+		require.NoError(t, b.WriteFile(filepath.Join(dir, name)))
+	}
+	path := filepath.Join(dir, "blend"+prediction.EnsembleExt)
+	require.NoError(t, os.WriteFile(path, []byte(`{"format": "carprice-ensemble/v1", "aggregation": "mean",
+		"members": [{"name": "a", "model": "a.json"}, {"name": "b", "model": "b.json"}]}`), 0o600))
+	info := prediction.Open(path).Model()
+	require.NotEmpty(t, info.SHA256)
+
+	archive := prediction.NewArchive(filepath.Join(dir, "archive"))
+	require.NoError(t, archive.Save(info))
+
+	// The archived ensemble no longer depends on the original members
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.json")))
+	service, err := archive.Open(info.SHA256)
+	require.NoError(t, err)
+	assert.Equal(t, info.SHA256, service.(domain.ModelManager).Model().SHA256)
+	input := domain.UserInput{Wheelbase: 94.5, Carlength: 168.8, Carwidth: 64.1, Carheight: 48.8, Curbweight: 2548, Enginesize: 130,
+		Boreratio: 3.47, Stroke: 2.68, Compressionratio: 9, Horsepower: 111, Peakrpm: 5000, Citympg: 21, Highwaympg: 27,
+		Fueltype: "gas", Aspiration: "std", Doornumber: "two", Carbody: "convertible", Drivewheel: "rwd", Enginelocation: "front",
+		Enginetype: "dohc", Cylindernumber: "four", Fuelsystem: "mpfi", Brand: "alfa-romero"}
+	result, err := service.Predict(input)
+	require.NoError(t, err)
+	assert.Equal(t, float32(12000), result.PredictedPrice)
+
+	// A member replaced without a reload is not archived under the old version
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte("{}"), 0o600))
+	assert.Error(t, prediction.NewArchive(filepath.Join(dir, "other")).Save(info))
+}
@@ -0,0 +1,397 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/forest"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// BundleFormat identifies model bundle files.
+const BundleFormat = "carprice-model-bundle/v1"
+
+// Model kinds a bundle can hold.
+const (
+	KindRandomForest = "random_forest"
+	KindLinear       = "linear"
+)
+
+// Bundle is a model trained in Go together with the feature schema it expects and
+// how it was trained. Bundles are served without onnxruntime.
+type Bundle struct {
+	Format string `json:"format"`
+	// Kind is the kind of model, "random_forest" or "linear".
+	Kind string `json:"kind"`
+	// Features names the model's input columns in order. Linear bundles without
+	// features read the served schema.
+	Features []string `json:"features"`
+	// Preprocessing are the steps applied in order to turn inputs into the
+	// features, e.g. scaling. Without steps features are read from the input.
+	Preprocessing []PreprocessingStep `json:"preprocessing,omitempty"`
+	Metadata      BundleMetadata      `json:"metadata"`
+	Forest        *forest.Forest      `json:"forest,omitempty"`
+	Linear        *LinearModel        `json:"linear,omitempty"`
+
+	pipeline *Pipeline
+}
+
+// BundleMetadata describes how a bundled model was trained.
+type BundleMetadata struct {
+	TrainedAt time.Time `json:"trained_at"`
+	// Dataset is the file name and DatasetSHA256 the hash of the training data.
+	Dataset       string         `json:"dataset,omitempty"`
+	DatasetSHA256 string         `json:"dataset_sha256,omitempty"`
+	TrainRows     int            `json:"train_rows"`
+	HoldoutRows   int            `json:"holdout_rows"`
+	Params        *forest.Params `json:"params,omitempty"`
+	// Holdout is the accuracy on the rows held out from training.
+	Holdout *HoldoutMetrics `json:"holdout,omitempty"`
+	// Target is the transform of the price the model was trained on, e.g. log.
+	// Models without one predict prices directly.
+	Target *TargetTransform `json:"target_transform,omitempty"`
+}
+
+// HoldoutMetrics is the accuracy of a model on rows it was not trained on.
+type HoldoutMetrics struct {
+	MAE  float64 `json:"mae"`
+	RMSE float64 `json:"rmse"`
+	R2   float64 `json:"r2"`
+}
+
+// NewForestBundle bundles a random forest trained on the given feature columns.
+func NewForestBundle(features []string, f *forest.Forest, metadata BundleMetadata) (*Bundle, error) {
+	b := &Bundle{Format: BundleFormat, Kind: KindRandomForest, Features: features, Metadata: metadata, Forest: f}
+	if err := b.init(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// init validates the bundle and prepares its preprocessing pipeline.
+func (b *Bundle) init() error {
+	if b.Format != BundleFormat {
+		return fmt.Errorf("unsupported model bundle format %q", b.Format)
+	}
+	if b.Kind == KindLinear && len(b.Features) == 0 {
+		b.Features = FeatureNames()
+	}
+	pipeline, err := NewPipeline(b.Features, b.Preprocessing)
+	if err != nil {
+		return fmt.Errorf("invalid feature schema: %w", err)
+	}
+	switch b.Kind {
+	case KindRandomForest:
+		if b.Forest == nil {
+			return errors.New("random forest bundle has no forest")
+		}
+		if b.Forest.Features != len(b.Features) {
+			return fmt.Errorf("forest expects %d features, schema has %d", b.Forest.Features, len(b.Features))
+		}
+		if err := b.Forest.Validate(); err != nil {
+			return err
+		}
+	case KindLinear:
+		if b.Linear == nil {
+			return errors.New("linear bundle has no linear model")
+		}
+		if err := b.Linear.init(b.Features); err != nil {
+			return fmt.Errorf("invalid linear model: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported model kind %q", b.Kind)
+	}
+	if b.Metadata.Target != nil {
+		if err := b.Metadata.Target.Validate(); err != nil {
+			return fmt.Errorf("invalid target transform: %w", err)
+		}
+	}
+	b.pipeline = pipeline
+	return nil
+}
+
+// ParseBundle parses a bundle written by WriteFile.
+func ParseBundle(data []byte) (*Bundle, error) {
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse model bundle: %w", err)
+	}
+	if err := b.init(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// LoadBundle reads a bundle from a file.
+func LoadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open model: %w", err)
+	}
+	return ParseBundle(data)
+}
+
+// WriteFile writes the bundle as JSON.
+func (b *Bundle) WriteFile(path string) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Encode returns the feature vector of the input in the bundle's schema, after
+// the preprocessing steps.
+func (b *Bundle) Encode(input domain.UserInput) []float32 {
+	return b.pipeline.Encode(input)
+}
+
+// EncodeFeatures encodes a validated input. It fails with INFERENCE_FAILED if the
+// preprocessing steps give a value that is not a finite number, e.g. the log of
+// a negative number.
+func (b *Bundle) EncodeFeatures(input domain.UserInput) ([]float32, error) {
+	features := b.Encode(input)
+	for i, v := range features {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil, domain.NewError(domain.CodeInferenceFailed, "The input could not be preprocessed for the model.",
+				fmt.Errorf("preprocessing gave %s = %v", b.Features[i], v))
+		}
+	}
+	return features, nil
+}
+
+// FeatureSchema returns the bundle's features and preprocessing steps.
+func (b *Bundle) FeatureSchema() (FeatureSchema, error) {
+	return FeatureSchema{Features: b.Features, Preprocessing: b.Preprocessing}, nil
+}
+
+// PredictFeatures returns the model's prediction for an encoded feature vector,
+// as a price even if the model was trained on a transformed price.
+func (b *Bundle) PredictFeatures(features []float32) float64 {
+	return b.target().Price(b.output(features))
+}
+
+// output returns the model's raw output for an encoded feature vector.
+func (b *Bundle) output(features []float32) float64 {
+	if b.Kind == KindLinear {
+		return b.Linear.Predict(features)
+	}
+	return b.Forest.Predict(features)
+}
+
+// target returns the target transform of the model.
+func (b *Bundle) target() TargetTransform {
+	if b.Metadata.Target == nil {
+		return TargetTransform{}
+	}
+	return *b.Metadata.Target
+}
+
+// Predict validates the input and predicts its price with the bundled model. The
+// result of a linear model breaks the price down into its terms.
+func (b *Bundle) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	if err := Validate(input); err != nil {
+		return nil, err
+	}
+	features, err := b.EncodeFeatures(input)
+	if err != nil {
+		return nil, err
+	}
+	result := &domain.PredictionResult{
+		PredictedPrice: float32(b.PredictFeatures(features)),
+	}
+	if b.Kind == KindLinear {
+		result.Linear = b.Linear.Breakdown(b.Features, features)
+	}
+	return result, nil
+}
+
+// Explain predicts the price for the input and attributes the difference from the
+// reference car's price to the individual input fields.
+func (b *Bundle) Explain(input domain.UserInput) (*domain.Explanation, error) {
+	if err := Validate(input); err != nil {
+		return nil, err
+	}
+	return explain(b, input)
+}
+
+// Interval returns the range of prices predicted by the central coverage fraction
+// of the forest's trees, e.g. the 5th to 95th percentile for a coverage of 0.9.
+// It describes how much the trees disagree, not a calibrated prediction interval.
+// The predictions of a model trained on a transformed price are transformed back
+// without bias correction.
+func (b *Bundle) Interval(input domain.UserInput, coverage float64) (lower, upper float32, err error) {
+	if coverage <= 0 || coverage > 1 {
+		return 0, 0, fmt.Errorf("interval coverage must be in (0, 1], got %v", coverage)
+	}
+	if b.Kind != KindRandomForest {
+		return 0, 0, fmt.Errorf("intervals need a random forest, not a %s model", b.Kind)
+	}
+	if err := Validate(input); err != nil {
+		return 0, 0, err
+	}
+	features, err := b.EncodeFeatures(input)
+	if err != nil {
+		return 0, 0, err
+	}
+	predictions := b.Forest.TreePredictions(features)
+	slices.Sort(predictions)
+	tail := (1 - coverage) / 2
+	target := b.target()
+	return float32(target.Inverse(quantile(predictions, tail))), float32(target.Inverse(quantile(predictions, 1-tail))), nil
+}
+
+// quantile returns the q-quantile of sorted values, interpolating linearly between
+// neighbours like numpy's default.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(i)
+	return sorted[i] + frac*(sorted[i+1]-sorted[i])
+}
+
+// Ensure Bundle implements domain.PredictionService and BundleService implements Model
+var (
+	_ domain.PredictionService = (*Bundle)(nil)
+	_ domain.Explainer         = (*Bundle)(nil)
+	_ Model                    = (*BundleService)(nil)
+)
+
+// BundleService serves a model bundle in process.
+type BundleService struct {
+	path string
+	// parse reads the model file into a bundle.
+	parse func(data []byte) (*Bundle, error)
+
+	mu     sync.RWMutex
+	bundle *Bundle
+	model  domain.ModelInfo
+}
+
+// NewBundleService creates a prediction service for the bundle at path. If the
+// bundle cannot be loaded yet, predictions fail with MODEL_UNAVAILABLE until Reload
+// succeeds.
+func NewBundleService(path string) *BundleService {
+	return NewConvertingBundleService(path, ParseBundle)
+}
+
+// NewConvertingBundleService creates a prediction service for a model file that
+// parse converts into a bundle whenever it is loaded, e.g. an ONNX model that can
+// be served in Go. The model's version is the hash of the file.
+func NewConvertingBundleService(path string, parse func(data []byte) (*Bundle, error)) *BundleService {
+	s := &BundleService{path: path, parse: parse}
+	if bundle, info, err := s.load(); err == nil {
+		s.bundle, s.model = bundle, info
+	}
+	return s
+}
+
+// load reads, parses and hashes the model file.
+func (s *BundleService) load() (*Bundle, domain.ModelInfo, error) {
+	path := s.path
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, domain.ModelInfo{}, fmt.Errorf("failed to open model: %w", err)
+	}
+	bundle, err := s.parse(data)
+	if err != nil {
+		return nil, domain.ModelInfo{}, err
+	}
+	h := sha256.Sum256(data)
+	sum := hex.EncodeToString(h[:])
+	return bundle, domain.ModelInfo{
+		Version:  sum[:modelVersionLength],
+		SHA256:   sum,
+		Path:     path,
+		LoadedAt: time.Now().UTC(),
+	}, nil
+}
+
+// Bundle returns the bundle currently serving predictions, or nil if none is loaded.
+func (s *BundleService) Bundle() *Bundle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bundle
+}
+
+// Model describes the model currently serving predictions.
+func (s *BundleService) Model() domain.ModelInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.model
+}
+
+// Reload reads the bundle again and swaps it in if it is valid. Predictions in
+// flight finish with the previous bundle.
+func (s *BundleService) Reload() (domain.ModelInfo, error) {
+	bundle, info, err := s.load()
+	if err != nil {
+		return domain.ModelInfo{}, domain.NewError(domain.CodeModelUnavailable, "The prediction model could not be loaded.", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bundle, s.model = bundle, info
+	return info, nil
+}
+
+// FeatureSchema returns the feature schema of the bundle currently serving
+// predictions.
+func (s *BundleService) FeatureSchema() (FeatureSchema, error) {
+	bundle, err := s.loaded()
+	if err != nil {
+		return FeatureSchema{}, err
+	}
+	return bundle.FeatureSchema()
+}
+
+// EncodeFeatures returns the feature vector the bundle currently serving
+// predictions predicts the input from.
+func (s *BundleService) EncodeFeatures(input domain.UserInput) ([]float32, error) {
+	bundle, err := s.loaded()
+	if err != nil {
+		return nil, err
+	}
+	return bundle.EncodeFeatures(input)
+}
+
+// loaded returns the bundle currently serving predictions, or a
+// MODEL_UNAVAILABLE error if none is loaded.
+func (s *BundleService) loaded() (*Bundle, error) {
+	bundle := s.Bundle()
+	if bundle == nil {
+		return nil, domain.NewError(domain.CodeModelUnavailable, "The prediction model is not available.", fmt.Errorf("failed to load model bundle %s", s.path))
+	}
+	return bundle, nil
+}
+
+// Predict validates the input and predicts its price with the bundled model.
+func (s *BundleService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	if err := Validate(input); err != nil {
+		return nil, err
+	}
+	bundle, err := s.loaded()
+	if err != nil {
+		return nil, err
+	}
+	return bundle.Predict(input)
+}
+
+// Explain predicts the price for the input and attributes the difference from the
+// reference car's price to the individual input fields.
+func (s *BundleService) Explain(input domain.UserInput) (*domain.Explanation, error) {
+	if err := Validate(input); err != nil {
+		return nil, err
+	}
+	return explain(s, input)
+}
@@ -0,0 +1,140 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/forest"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testForest prices cars with more than 100 horsepower at high and others at low.
+func testForest(low, high float64) *forest.Forest {
+	hp := slices.Index(FeatureNames(), "horsepower")
+	return &forest.Forest{Features: ModelInputSize, Trees: []forest.Tree{{Nodes: []forest.Node{
+		{Feature: hp, Threshold: 100, Left: 1, Right: 2},
+		{Feature: forest.Leaf, Value: low},
+		{Feature: forest.Leaf, Value: high},
+	}}}}
+}
+
+// writeTestBundle writes a bundle of testForest(low, high) to path.
+func writeTestBundle(t *testing.T, path string, low, high float64) {
+	b, err := NewForestBundle(FeatureNames(), testForest(low, high), BundleMetadata{TrainRows: 10})
+	require.NoError(t, err)
+	require.NoError(t, b.WriteFile(path))
+}
+
+func TestBundleService_PredictAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.json")
+	writeTestBundle(t, path, 8000, 20000)
+	service := Open(path)
+	require.IsType(t, &BundleService{}, service)
+	v1 := service.Model()
+	assert.Len(t, v1.Version, modelVersionLength)
+
+	input := referenceInput
+	result, err := service.Predict(input)
+	require.NoError(t, err)
+	assert.Equal(t, float32(8000), result.PredictedPrice)
+	input.Horsepower = 150
+	result, err = service.Predict(input)
+	require.NoError(t, err)
+	assert.Equal(t, float32(20000), result.PredictedPrice)
+
+	explanation, err := service.Explain(input)
+	require.NoError(t, err)
+	assert.Equal(t, float32(12000), explanation.PredictedPrice-explanation.BaselinePrice)
+
+	// A broken file keeps the previous bundle
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0o600))
+	_, err = service.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, v1, service.Model())
+
+	writeTestBundle(t, path, 9000, 21000)
+	v2, err := service.Reload()
+	require.NoError(t, err)
+	assert.NotEqual(t, v1.Version, v2.Version)
+	result, err = service.Predict(referenceInput)
+	require.NoError(t, err)
+	assert.Equal(t, float32(9000), result.PredictedPrice)
+}
+
+func TestBundleService_Unavailable(t *testing.T) {
+	service := NewBundleService(filepath.Join(t.TempDir(), "missing.json"))
+
+	_, err := service.Predict(referenceInput)
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeModelUnavailable, derr.Code)
+}
+
+func TestParseBundle_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"not json":        `{`,
+		"unknown format":  `{"format":"other","kind":"random_forest"}`,
+		"unknown kind":    `{"format":"` + BundleFormat + `","kind":"svm","features":["horsepower"]}`,
+		"unknown feature": `{"format":"` + BundleFormat + `","kind":"random_forest","features":["color"]}`,
+		"no forest":       `{"format":"` + BundleFormat + `","kind":"random_forest","features":["horsepower"]}`,
+		"schema mismatch": `{"format":"` + BundleFormat + `","kind":"random_forest","features":["horsepower"],"forest":{"features":2,"trees":[{"nodes":[{"feature":-1}]}]}}`,
+	} {
+		_, err := ParseBundle([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestBundle_Interval(t *testing.T) {
+	f := &forest.Forest{Features: ModelInputSize}
+	for _, v := range []float64{10000, 11000, 12000, 13000, 14000} {
+		f.Trees = append(f.Trees, forest.Tree{Nodes: []forest.Node{{Feature: forest.Leaf, Value: v}}})
+	}
+	b, err := NewForestBundle(FeatureNames(), f, BundleMetadata{})
+	require.NoError(t, err)
+
+	lower, upper, err := b.Interval(referenceInput, 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, float32(11000), lower)
+	assert.Equal(t, float32(13000), upper)
+
+	lower, upper, err = b.Interval(referenceInput, 0.9)
+	require.NoError(t, err)
+	assert.Equal(t, float32(10200), lower)
+	assert.Equal(t, float32(13800), upper)
+
+	_, _, err = b.Interval(referenceInput, 1.5)
+	assert.Error(t, err)
+	invalid := referenceInput
+	invalid.Brand = "tesla"
+	_, _, err = b.Interval(invalid, 0.9)
+	assert.Error(t, err)
+}
+
+func TestBundle_TargetTransform(t *testing.T) {
+	f := testForest(math.Log(8000), math.Log(20000))
+	b, err := NewForestBundle(FeatureNames(), f, BundleMetadata{Target: &TargetTransform{Kind: TargetLog}})
+	require.NoError(t, err)
+
+	result, err := b.Predict(referenceInput)
+	require.NoError(t, err)
+	assert.InDelta(t, 8000, result.PredictedPrice, 0.01)
+	lower, upper, err := b.Interval(referenceInput, 0.9)
+	require.NoError(t, err)
+	assert.InDelta(t, 8000, lower, 0.01)
+	assert.InDelta(t, 8000, upper, 0.01)
+
+	// The transform survives a round trip through the bundle file
+	path := filepath.Join(t.TempDir(), "model.json")
+	require.NoError(t, b.WriteFile(path))
+	loaded, err := LoadBundle(path)
+	require.NoError(t, err)
+	assert.Equal(t, TargetLog, loaded.Metadata.Target.Kind)
+
+	_, err = NewForestBundle(FeatureNames(), f, BundleMetadata{Target: &TargetTransform{Kind: "exp"}})
+	assert.ErrorContains(t, err, "invalid target transform")
+}
@@ -0,0 +1,457 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnsembleFormat identifies ensemble files.
+const EnsembleFormat = "carprice-ensemble/v1"
+
+// EnsembleExt is the file name suffix of ensembles. Ensembles are JSON like model
+// bundles and are told apart by this suffix.
+const EnsembleExt = ".ensemble.json"
+
+// Ways an ensemble combines the predictions of its members.
+const (
+	// AggregationMean is the weighted mean of the members' predictions.
+	AggregationMean = "mean"
+	// AggregationMedian is the median of the members' predictions. Its members have no weights.
+	AggregationMedian = "median"
+	// AggregationStacking feeds the members' predictions to a linear meta-model.
+	AggregationStacking = "stacking"
+)
+
+// EnsembleSpec describes an ensemble: the models it combines and how. Members are
+// ONNX models or bundles that share the same feature schema.
+type EnsembleSpec struct {
+	Format string `json:"format"`
+	// Aggregation is "mean", "median" or "stacking".
+	Aggregation string           `json:"aggregation"`
+	Members     []EnsembleMember `json:"members"`
+	// Stacking is the meta-model of a stacking ensemble.
+	Stacking *StackingModel `json:"stacking,omitempty"`
+
+	// dir is the directory member paths are relative to.
+	dir string
+}
+
+// EnsembleMember is a model of an ensemble.
+type EnsembleMember struct {
+	Name string `json:"name"`
+	// Model is the path of the member's ONNX model or bundle, relative to the ensemble file.
+	Model string `json:"model"`
+	// Weight is the member's weight in the mean. It defaults to 1; a weight of 0
+	// runs the member for comparison without using its prediction. Members of a
+	// median ensemble have no weight.
+	Weight *float64 `json:"weight,omitempty"`
+}
+
+// StackingModel is a linear model of the members' predictions.
+type StackingModel struct {
+	Intercept float64 `json:"intercept"`
+	// Coefficients weigh the members' predictions by member name.
+	Coefficients map[string]float64 `json:"coefficients"`
+}
+
+// ParseEnsembleSpec parses an ensemble whose member paths are relative to dir.
+func ParseEnsembleSpec(data []byte, dir string) (*EnsembleSpec, error) {
+	var spec EnsembleSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse ensemble: %w", err)
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("invalid ensemble: %w", err)
+	}
+	spec.dir = dir
+	return &spec, nil
+}
+
+// LoadEnsembleSpec reads an ensemble from a file.
+func LoadEnsembleSpec(path string) (*EnsembleSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ensemble: %w", err)
+	}
+	return ParseEnsembleSpec(data, filepath.Dir(path))
+}
+
+// validate checks that the members and their weights fit the aggregation.
+func (s *EnsembleSpec) validate() error {
+	if s.Format != EnsembleFormat {
+		return fmt.Errorf("unsupported format %q", s.Format)
+	}
+	if len(s.Members) == 0 {
+		return errors.New("the ensemble has no members")
+	}
+	var total float64
+	names := map[string]bool{}
+	for i, m := range s.Members {
+		switch {
+		case m.Name == "":
+			return fmt.Errorf("member %d has no name", i)
+		case names[m.Name]:
+			return fmt.Errorf("member %s is listed twice", m.Name)
+		case m.Model == "":
+			return fmt.Errorf("member %s has no model", m.Name)
+		case IsEnsemble(m.Model):
+			return fmt.Errorf("member %s is an ensemble; ensembles cannot be nested", m.Name)
+		case m.weight() < 0:
+			return fmt.Errorf("member %s has a negative weight", m.Name)
+		}
+		names[m.Name] = true
+		total += m.weight()
+	}
+
+	switch s.Aggregation {
+	case AggregationMean:
+		if total == 0 {
+			return errors.New("the weights of the members add up to 0")
+		}
+	case AggregationMedian:
+		for _, m := range s.Members {
+			if m.Weight != nil {
+				return fmt.Errorf("member %s has a weight, but a median ensemble does not weigh its members", m.Name)
+			}
+		}
+	case AggregationStacking:
+		if s.Stacking == nil {
+			return errors.New("a stacking ensemble needs a stacking model")
+		}
+		for _, m := range s.Members {
+			if _, ok := s.Stacking.Coefficients[m.Name]; !ok {
+				return fmt.Errorf("the stacking model has no coefficient for member %s", m.Name)
+			}
+		}
+		for name := range s.Stacking.Coefficients {
+			if !names[name] {
+				return fmt.Errorf("the stacking model has a coefficient for unknown member %s", name)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown aggregation %q, want mean, median or stacking", s.Aggregation)
+	}
+	if s.Stacking != nil && s.Aggregation != AggregationStacking {
+		return fmt.Errorf("a stacking model cannot be used with aggregation %s", s.Aggregation)
+	}
+	return nil
+}
+
+// weight returns the member's weight in the mean.
+func (m EnsembleMember) weight() float64 {
+	if m.Weight == nil {
+		return 1
+	}
+	return *m.Weight
+}
+
+// MemberPath returns the path of the i-th member's model.
+func (s *EnsembleSpec) MemberPath(i int) string {
+	path := s.Members[i].Model
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.dir, path)
+}
+
+// memberWeight returns what the i-th member's prediction is multiplied by:
+// its weight in the mean or its coefficient in the stacking model.
+func (s *EnsembleSpec) memberWeight(i int) float64 {
+	if s.Aggregation == AggregationStacking {
+		return s.Stacking.Coefficients[s.Members[i].Name]
+	}
+	return s.Members[i].weight()
+}
+
+// aggregate combines the members' predictions.
+func (s *EnsembleSpec) aggregate(predictions []float64) float64 {
+	switch s.Aggregation {
+	case AggregationMedian:
+		sorted := slices.Clone(predictions)
+		slices.Sort(sorted)
+		return quantile(sorted, 0.5)
+	case AggregationStacking:
+		price := s.Stacking.Intercept
+		for i, p := range predictions {
+			price += s.memberWeight(i) * p
+		}
+		return price
+	default:
+		var sum, total float64
+		for i, p := range predictions {
+			sum += s.memberWeight(i) * p
+			total += s.memberWeight(i)
+		}
+		return sum / total
+	}
+}
+
+// resolve returns the ensemble with its members' paths replaced by their hashes,
+// as stored in a model archive, and the hash of that. The hash identifies the
+// ensemble together with the exact member models.
+func (s *EnsembleSpec) resolve(members []domain.ModelInfo) ([]byte, string, error) {
+	resolved := *s
+	resolved.Members = slices.Clone(s.Members)
+	for i := range resolved.Members {
+		resolved.Members[i].Model = members[i].SHA256 + modelExt(s.MemberPath(i))
+	}
+	data, err := json.MarshalIndent(resolved, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+	h := sha256.Sum256(data)
+	return data, hex.EncodeToString(h[:]), nil
+}
+
+// Ensemble combines the predictions of several models.
+type Ensemble struct {
+	spec    *EnsembleSpec
+	members []domain.PredictionService
+}
+
+// NewEnsemble creates an ensemble of the given services, one per member of spec.
+// It fails unless every member reports the same feature schema.
+func NewEnsemble(spec *EnsembleSpec, members []domain.PredictionService) (*Ensemble, error) {
+	if len(members) != len(spec.Members) {
+		return nil, fmt.Errorf("the ensemble has %d members, got %d models", len(spec.Members), len(members))
+	}
+	var first FeatureSchema
+	for i, member := range members {
+		provider, ok := member.(SchemaProvider)
+		if !ok {
+			return nil, fmt.Errorf("ensemble member %s does not report its feature schema", spec.Members[i].Name)
+		}
+		schema, err := provider.FeatureSchema()
+		if err != nil {
+			return nil, fmt.Errorf("ensemble member %s: %w", spec.Members[i].Name, err)
+		}
+		if i == 0 {
+			first = schema
+		} else if !schema.Equal(first) {
+			return nil, fmt.Errorf("ensemble member %s has a different feature schema than %s", spec.Members[i].Name, spec.Members[0].Name)
+		}
+	}
+	return &Ensemble{spec: spec, members: members}, nil
+}
+
+// Predict validates the input, predicts its price with every member concurrently
+// and combines the predictions. The members' predictions are returned in the
+// debug field of the result.
+func (e *Ensemble) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	if err := Validate(input); err != nil {
+		return nil, err
+	}
+
+	results := make([]*domain.PredictionResult, len(e.members))
+	errs := make([]error, len(e.members))
+	var wg sync.WaitGroup
+	for i, member := range e.members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = member.Predict(input)
+		}()
+	}
+	wg.Wait()
+
+	predictions := make([]float64, len(e.members))
+	debug := &domain.PredictionDebug{Aggregation: e.spec.Aggregation, Members: make([]domain.MemberPrediction, len(e.members))}
+	for i, member := range e.members {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("ensemble member %s: %w", e.spec.Members[i].Name, errs[i])
+		}
+		predictions[i] = float64(results[i].PredictedPrice)
+		debug.Members[i] = domain.MemberPrediction{
+			Name:           e.spec.Members[i].Name,
+			PredictedPrice: results[i].PredictedPrice,
+		}
+		if e.spec.Aggregation != AggregationMedian {
+			weight := e.spec.memberWeight(i)
+			debug.Members[i].Weight = &weight
+		}
+		if m, ok := member.(domain.ModelManager); ok {
+			debug.Members[i].ModelVersion = m.Model().Version
+		}
+	}
+	return &domain.PredictionResult{
+		PredictedPrice: float32(e.spec.aggregate(predictions)),
+		Debug:          debug,
+	}, nil
+}
+
+// FeatureSchema returns the feature schema the members share.
+func (e *Ensemble) FeatureSchema() (FeatureSchema, error) {
+	provider, ok := e.members[0].(SchemaProvider)
+	if !ok {
+		return FeatureSchema{}, fmt.Errorf("ensemble member %s does not report its feature schema", e.spec.Members[0].Name)
+	}
+	return provider.FeatureSchema()
+}
+
+// EncodeFeatures returns the feature vector the members predict the input from.
+func (e *Ensemble) EncodeFeatures(input domain.UserInput) ([]float32, error) {
+	encoder, ok := e.members[0].(FeatureEncoder)
+	if !ok {
+		return nil, fmt.Errorf("ensemble member %s does not encode inputs", e.spec.Members[0].Name)
+	}
+	return encoder.EncodeFeatures(input)
+}
+
+// Explain predicts the price for the input and attributes the difference from the
+// reference car's price to the individual input fields.
+func (e *Ensemble) Explain(input domain.UserInput) (*domain.Explanation, error) {
+	if err := Validate(input); err != nil {
+		return nil, err
+	}
+	return explain(e, input)
+}
+
+// Ensure Ensemble implements domain.PredictionService and EnsembleService implements Model
+var (
+	_ domain.PredictionService = (*Ensemble)(nil)
+	_ domain.Explainer         = (*Ensemble)(nil)
+	_ Model                    = (*EnsembleService)(nil)
+)
+
+// EnsembleService serves an ensemble of ONNX models and bundles. ONNX members
+// need the onnxruntime environment to be initialized.
+type EnsembleService struct {
+	path string
+
+	mu       sync.RWMutex
+	ensemble *Ensemble
+	model    domain.ModelInfo
+}
+
+// NewEnsembleService creates a prediction service for the ensemble at path. If the
+// ensemble or one of its members cannot be loaded yet, predictions fail with
+// MODEL_UNAVAILABLE until Reload succeeds.
+func NewEnsembleService(path string) *EnsembleService {
+	s := &EnsembleService{path: path}
+	if ensemble, info, err := loadEnsemble(path); err == nil {
+		s.ensemble, s.model = ensemble, info
+	}
+	return s
+}
+
+// loadEnsemble reads the ensemble at path and opens its members. The ensemble's
+// version is the hash of the ensemble with the hashes of its members.
+func loadEnsemble(path string) (*Ensemble, domain.ModelInfo, error) {
+	spec, err := LoadEnsembleSpec(path)
+	if err != nil {
+		return nil, domain.ModelInfo{}, err
+	}
+	members := make([]domain.PredictionService, len(spec.Members))
+	infos := make([]domain.ModelInfo, len(spec.Members))
+	for i, m := range spec.Members {
+		member := Open(spec.MemberPath(i))
+		if member.Model().SHA256 == "" {
+			if _, err := member.Reload(); err != nil {
+				return nil, domain.ModelInfo{}, fmt.Errorf("failed to load ensemble member %s: %w", m.Name, err)
+			}
+		}
+		members[i], infos[i] = member, member.Model()
+	}
+	ensemble, err := NewEnsemble(spec, members)
+	if err != nil {
+		return nil, domain.ModelInfo{}, err
+	}
+	_, sum, err := spec.resolve(infos)
+	if err != nil {
+		return nil, domain.ModelInfo{}, err
+	}
+	return ensemble, domain.ModelInfo{
+		Version:  sum[:modelVersionLength],
+		SHA256:   sum,
+		Path:     path,
+		LoadedAt: time.Now().UTC(),
+	}, nil
+}
+
+// Model describes the ensemble currently serving predictions.
+func (s *EnsembleService) Model() domain.ModelInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.model
+}
+
+// Reload reads the ensemble and all its members again and swaps them in if they
+// are valid. Predictions in flight finish with the previous members.
+func (s *EnsembleService) Reload() (domain.ModelInfo, error) {
+	ensemble, info, err := loadEnsemble(s.path)
+	if err != nil {
+		return domain.ModelInfo{}, domain.NewError(domain.CodeModelUnavailable, "The prediction model could not be loaded.", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ensemble, s.model = ensemble, info
+	return info, nil
+}
+
+// Predict validates the input and predicts its price with the ensemble.
+func (s *EnsembleService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	if err := Validate(input); err != nil {
+		return nil, err
+	}
+	ensemble, err := s.loaded()
+	if err != nil {
+		return nil, err
+	}
+	return ensemble.Predict(input)
+}
+
+// FeatureSchema returns the feature schema of the ensemble currently serving
+// predictions.
+func (s *EnsembleService) FeatureSchema() (FeatureSchema, error) {
+	ensemble, err := s.loaded()
+	if err != nil {
+		return FeatureSchema{}, err
+	}
+	return ensemble.FeatureSchema()
+}
+
+// EncodeFeatures returns the feature vector the ensemble currently serving
+// predictions predicts the input from.
+func (s *EnsembleService) EncodeFeatures(input domain.UserInput) ([]float32, error) {
+	ensemble, err := s.loaded()
+	if err != nil {
+		return nil, err
+	}
+	return ensemble.EncodeFeatures(input)
+}
+
+// loaded returns the ensemble currently serving predictions, or a
+// MODEL_UNAVAILABLE error if none is loaded.
+func (s *EnsembleService) loaded() (*Ensemble, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.ensemble == nil {
+		return nil, domain.NewError(domain.CodeModelUnavailable, "The prediction model is not available.", fmt.Errorf("failed to load ensemble %s", s.path))
+	}
+	return s.ensemble, nil
+}
+
+// Explain predicts the price for the input and attributes the difference from the
+// reference car's price to the individual input fields.
+func (s *EnsembleService) Explain(input domain.UserInput) (*domain.Explanation, error) {
+	if err := Validate(input); err != nil {
+		return nil, err
+	}
+	return explain(s, input)
+}
+
+// IsEnsemble reports whether path names an ensemble.
+func IsEnsemble(path string) bool {
+	return strings.HasSuffix(path, EnsembleExt)
+}
@@ -0,0 +1,164 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestEnsemble writes three bundles and an ensemble of them to dir and
+// returns the ensemble's path. For the reference car, the members predict 8000,
+// 10000 and 15000.
+func writeTestEnsemble(t *testing.T, dir, aggregation string) string {
+	writeTestBundle(t, filepath.Join(dir, "forest.json"), 8000, 20000)
+	writeTestBundle(t, filepath.Join(dir, "ridge.json"), 10000, 22000)
+	writeTestBundle(t, filepath.Join(dir, "lasso.json"), 15000, 30000)
+	weight := `, "weight": 2`
+	if aggregation == AggregationMedian {
+		weight = ""
+	}
+	spec := `{
+		"format": "carprice-ensemble/v1",
+		"aggregation": "` + aggregation + `",
+		"members": [
+			{"name": "forest", "model": "forest.json"` + weight + `},
+			{"name": "ridge", "model": "ridge.json"},
+			{"name": "lasso", "model": "lasso.json"}
+		]`
+	if aggregation == AggregationStacking {
+		spec += `, "stacking": {"intercept": 500, "coefficients": {"forest": 0.5, "ridge": 0.3, "lasso": 0.2}}`
+	}
+	path := filepath.Join(dir, "blend"+EnsembleExt)
+	require.NoError(t, os.WriteFile(path, []byte(spec+"}"), 0o644))
+	return path
+}
+
+func TestEnsembleService_Aggregations(t *testing.T) {
+	tests := []struct {
+		aggregation string
+		want        float32
+		weights     []float64
+	}{
+		{AggregationMean, 10250, []float64{2, 1, 1}},
+		{AggregationMedian, 10000, nil},
+		{AggregationStacking, 10500, []float64{0.5, 0.3, 0.2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.aggregation, func(t *testing.T) {
+			service := Open(writeTestEnsemble(t, t.TempDir(), tt.aggregation))
+			require.IsType(t, &EnsembleService{}, service)
+
+			result, err := service.Predict(referenceInput)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.PredictedPrice)
+			require.NotNil(t, result.Debug)
+			assert.Equal(t, tt.aggregation, result.Debug.Aggregation)
+			require.Len(t, result.Debug.Members, 3)
+			for i, name := range []string{"forest", "ridge", "lasso"} {
+				assert.Equal(t, name, result.Debug.Members[i].Name)
+				if tt.weights == nil {
+					assert.Nil(t, result.Debug.Members[i].Weight)
+				} else {
+					require.NotNil(t, result.Debug.Members[i].Weight)
+					assert.Equal(t, tt.weights[i], *result.Debug.Members[i].Weight)
+				}
+				assert.Len(t, result.Debug.Members[i].ModelVersion, modelVersionLength)
+			}
+			assert.Equal(t, float32(15000), result.Debug.Members[2].PredictedPrice)
+		})
+	}
+}
+
+func TestEnsembleService_ExplainAndReload(t *testing.T) {
+	dir := t.TempDir()
+	service := Open(writeTestEnsemble(t, dir, AggregationMean))
+	v1 := service.Model()
+	assert.Len(t, v1.Version, modelVersionLength)
+
+	input := referenceInput
+	input.Horsepower = 150
+	explanation, err := service.Explain(input)
+	require.NoError(t, err)
+	assert.Equal(t, float32(10250), explanation.BaselinePrice)
+	assert.Equal(t, float32(23000), explanation.PredictedPrice)
+
+	_, err = service.Predict(domain.UserInput{})
+	assert.Error(t, err)
+
+	// A broken member keeps the previous ensemble
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ridge.json"), []byte("{}"), 0o644))
+	_, err = service.Reload()
+	assert.ErrorContains(t, err, "member ridge")
+	assert.Equal(t, v1, service.Model())
+
+	// A retrained member changes the ensemble's version
+	writeTestBundle(t, filepath.Join(dir, "ridge.json"), 12000, 22000)
+	v2, err := service.Reload()
+	require.NoError(t, err)
+	assert.NotEqual(t, v1.SHA256, v2.SHA256)
+	result, err := service.Predict(referenceInput)
+	require.NoError(t, err)
+	assert.Equal(t, float32(10750), result.PredictedPrice)
+}
+
+func TestEnsembleService_Unavailable(t *testing.T) {
+	service := Open(filepath.Join(t.TempDir(), "missing"+EnsembleExt))
+	assert.Empty(t, service.Model().Version)
+
+	_, err := service.Predict(referenceInput)
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeModelUnavailable, derr.Code)
+}
+
+func TestEnsembleService_DifferentSchemas(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestEnsemble(t, dir, AggregationMean)
+
+	// A member reading other columns is rejected
+	ridge, err := NewLinearBundle([]string{"horsepower", "enginesize"}, &LinearModel{Coefficients: map[string]float64{"horsepower": 50}}, BundleMetadata{})
+	require.NoError(t, err)
+	require.NoError(t, ridge.WriteFile(filepath.Join(dir, "ridge.json")))
+	_, _, err = loadEnsemble(path)
+	assert.ErrorContains(t, err, "ensemble member ridge has a different feature schema than forest")
+
+	// So is a member reading the same columns after preprocessing
+	ridge, err = NewLinearBundle(nil, testLinear(), BundleMetadata{})
+	require.NoError(t, err)
+	ridge.Preprocessing = []PreprocessingStep{{Op: StepLog, Columns: []string{"horsepower"}}}
+	require.NoError(t, ridge.WriteFile(filepath.Join(dir, "ridge.json")))
+	_, _, err = loadEnsemble(path)
+	assert.ErrorContains(t, err, "different feature schema")
+
+	service := Open(path)
+	assert.Empty(t, service.Model().Version)
+}
+
+func TestParseEnsembleSpec_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{"format", `{"format": "v0", "aggregation": "mean", "members": [{"name": "a", "model": "a.json"}]}`, `unsupported format "v0"`},
+		{"no members", `{"format": "carprice-ensemble/v1", "aggregation": "mean"}`, "no members"},
+		{"duplicate name", `{"format": "carprice-ensemble/v1", "aggregation": "mean", "members": [{"name": "a", "model": "a.json"}, {"name": "a", "model": "b.json"}]}`, "member a is listed twice"},
+		{"nested", `{"format": "carprice-ensemble/v1", "aggregation": "mean", "members": [{"name": "a", "model": "a.ensemble.json"}]}`, "cannot be nested"},
+		{"zero weights", `{"format": "carprice-ensemble/v1", "aggregation": "mean", "members": [{"name": "a", "model": "a.json", "weight": 0}]}`, "add up to 0"},
+		{"aggregation", `{"format": "carprice-ensemble/v1", "aggregation": "max", "members": [{"name": "a", "model": "a.json"}]}`, `unknown aggregation "max"`},
+		{"no meta-model", `{"format": "carprice-ensemble/v1", "aggregation": "stacking", "members": [{"name": "a", "model": "a.json"}]}`, "needs a stacking model"},
+		{"missing coefficient", `{"format": "carprice-ensemble/v1", "aggregation": "stacking", "members": [{"name": "a", "model": "a.json"}], "stacking": {"coefficients": {"b": 1}}}`, "no coefficient for member a"},
+		{"median weight", `{"format": "carprice-ensemble/v1", "aggregation": "median", "members": [{"name": "a", "model": "a.json", "weight": 2}]}`, "median ensemble does not weigh its members"},
+		{"unused meta-model", `{"format": "carprice-ensemble/v1", "aggregation": "median", "members": [{"name": "a", "model": "a.json"}], "stacking": {"coefficients": {"a": 1}}}`, "cannot be used with aggregation median"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEnsembleSpec([]byte(tt.spec), ".")
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
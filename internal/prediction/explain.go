@@ -0,0 +1,113 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"fmt"
+)
+
+// Ensure PredictionService implements domain.Explainer interface
+var _ domain.Explainer = (*PredictionService)(nil)
+
+// referenceInput is a typical car from the training data (median numerical values and
+// most frequent categories). Explanations are measured relative to its predicted price.
+var referenceInput = domain.UserInput{
+	Symboling:        1,
+	Wheelbase:        97.0,
+	Carlength:        173.2,
+	Carwidth:         65.5,
+	Carheight:        54.1,
+	Curbweight:       2414,
+	Enginesize:       120,
+	Boreratio:        3.31,
+	Stroke:           3.29,
+	Compressionratio: 9.0,
+	Horsepower:       95,
+	Peakrpm:          5200,
+	Citympg:          24,
+	Highwaympg:       30,
+	Fueltype:         "gas",
+	Aspiration:       "std",
+	Doornumber:       "four",
+	Carbody:          "sedan",
+	Drivewheel:       "fwd",
+	Enginelocation:   "front",
+	Enginetype:       "ohc",
+	Cylindernumber:   "four",
+	Fuelsystem:       "mpfi",
+	Brand:            "toyota",
+}
+
+// inputField names a UserInput field and knows how to copy it between inputs.
+type inputField struct {
+	name string
+	copy func(dst *domain.UserInput, src domain.UserInput)
+}
+
+// inputFields lists every UserInput field in declaration order.
+var inputFields = []inputField{
+	{"symboling", func(d *domain.UserInput, s domain.UserInput) { d.Symboling = s.Symboling }},
+	{"wheelbase", func(d *domain.UserInput, s domain.UserInput) { d.Wheelbase = s.Wheelbase }},
+	{"carlength", func(d *domain.UserInput, s domain.UserInput) { d.Carlength = s.Carlength }},
+	{"carwidth", func(d *domain.UserInput, s domain.UserInput) { d.Carwidth = s.Carwidth }},
+	{"carheight", func(d *domain.UserInput, s domain.UserInput) { d.Carheight = s.Carheight }},
+	{"curbweight", func(d *domain.UserInput, s domain.UserInput) { d.Curbweight = s.Curbweight }},
+	{"enginesize", func(d *domain.UserInput, s domain.UserInput) { d.Enginesize = s.Enginesize }},
+	{"boreratio", func(d *domain.UserInput, s domain.UserInput) { d.Boreratio = s.Boreratio }},
+	{"stroke", func(d *domain.UserInput, s domain.UserInput) { d.Stroke = s.Stroke }},
+	{"compressionratio", func(d *domain.UserInput, s domain.UserInput) { d.Compressionratio = s.Compressionratio }},
+	{"horsepower", func(d *domain.UserInput, s domain.UserInput) { d.Horsepower = s.Horsepower }},
+	{"peakrpm", func(d *domain.UserInput, s domain.UserInput) { d.Peakrpm = s.Peakrpm }},
+	{"citympg", func(d *domain.UserInput, s domain.UserInput) { d.Citympg = s.Citympg }},
+	{"highwaympg", func(d *domain.UserInput, s domain.UserInput) { d.Highwaympg = s.Highwaympg }},
+	{"fueltype", func(d *domain.UserInput, s domain.UserInput) { d.Fueltype = s.Fueltype }},
+	{"aspiration", func(d *domain.UserInput, s domain.UserInput) { d.Aspiration = s.Aspiration }},
+	{"doornumber", func(d *domain.UserInput, s domain.UserInput) { d.Doornumber = s.Doornumber }},
+	{"carbody", func(d *domain.UserInput, s domain.UserInput) { d.Carbody = s.Carbody }},
+	{"drivewheel", func(d *domain.UserInput, s domain.UserInput) { d.Drivewheel = s.Drivewheel }},
+	{"enginelocation", func(d *domain.UserInput, s domain.UserInput) { d.Enginelocation = s.Enginelocation }},
+	{"enginetype", func(d *domain.UserInput, s domain.UserInput) { d.Enginetype = s.Enginetype }},
+	{"cylindernumber", func(d *domain.UserInput, s domain.UserInput) { d.Cylindernumber = s.Cylindernumber }},
+	{"fuelsystem", func(d *domain.UserInput, s domain.UserInput) { d.Fuelsystem = s.Fuelsystem }},
+	{"brand", func(d *domain.UserInput, s domain.UserInput) { d.Brand = s.Brand }},
+}
+
+// Explain predicts the price for the input and attributes the difference from the
+// reference car's price to the individual input fields.
+func (s *PredictionService) Explain(input domain.UserInput) (*domain.Explanation, error) {
+	if err := Validate(input); err != nil {
+		return nil, err
+	}
+	return explain(s, input)
+}
+
+// explain walks from the reference input to the given input one field at a time and
+// records the price change caused by each step. The contributions are therefore
+// additive: BaselinePrice plus all contributions equals PredictedPrice.
+func explain(service domain.PredictionService, input domain.UserInput) (*domain.Explanation, error) {
+	current := referenceInput
+	baseline, err := service.Predict(current)
+	if err != nil {
+		return nil, fmt.Errorf("baseline prediction error: %w", err)
+	}
+
+	previous := baseline.PredictedPrice
+	contributions := make([]domain.FeatureContribution, 0, len(inputFields))
+	for _, field := range inputFields {
+		field.copy(&current, input)
+		result, err := service.Predict(current)
+		if err != nil {
+			return nil, fmt.Errorf("prediction error for %s: %w", field.name, err)
+		}
+		contributions = append(contributions, domain.FeatureContribution{
+			Feature:      field.name,
+			Contribution: result.PredictedPrice - previous,
+		})
+		previous = result.PredictedPrice
+	}
+
+	return &domain.Explanation{
+		PredictedPrice: previous,
+		BaselinePrice:  baseline.PredictedPrice,
+		Contributions:  contributions,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// linearService prices a car from its horsepower and brand only.
+type linearService struct{}
+
+func (linearService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	price := float32(input.Horsepower) * 100
+	if input.Brand == "bmw" {
+		price += 5000
+	}
+	return &domain.PredictionResult{PredictedPrice: price}, nil
+}
+
+func TestExplain_ContributionsAreAdditive(t *testing.T) {
+	input := referenceInput
+	input.Horsepower = 120
+	input.Brand = "bmw"
+
+	explanation, err := explain(linearService{}, input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, float32(9500), explanation.BaselinePrice)
+	assert.Equal(t, float32(17000), explanation.PredictedPrice)
+	assert.Len(t, explanation.Contributions, len(inputFields))
+
+	var sum float32
+	for _, c := range explanation.Contributions {
+		sum += c.Contribution
+		switch c.Feature {
+		case "horsepower":
+			assert.Equal(t, float32(2500), c.Contribution)
+		case "brand":
+			assert.Equal(t, float32(5000), c.Contribution)
+		default:
+			assert.Zero(t, c.Contribution, c.Feature)
+		}
+	}
+	assert.Equal(t, explanation.PredictedPrice-explanation.BaselinePrice, sum)
+}
+
+func TestInputFields_CoverUserInput(t *testing.T) {
+	var copied domain.UserInput
+	source := domain.UserInput{
+		Symboling: 3, Wheelbase: 88.6, Carlength: 168.8, Carwidth: 64.1, Carheight: 48.8,
+		Curbweight: 2548, Enginesize: 130, Boreratio: 3.47, Stroke: 2.68, Compressionratio: 9.0,
+		Horsepower: 111, Peakrpm: 5000, Citympg: 21, Highwaympg: 27,
+		Fueltype: "gas", Aspiration: "std", Doornumber: "two", Carbody: "convertible",
+		Drivewheel: "rwd", Enginelocation: "front", Enginetype: "dohc", Cylindernumber: "four",
+		Fuelsystem: "mpfi", Brand: "alfa-romero",
+	}
+
+	for _, field := range inputFields {
+		field.copy(&copied, source)
+	}
+
+	assert.Equal(t, source, copied)
+}
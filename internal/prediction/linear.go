@@ -0,0 +1,85 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// LinearModel is a linear regression of the price on the encoded features, such as
+// a ridge or lasso regression. Its predictions break down into one term per
+// feature, coefficient times value.
+type LinearModel struct {
+	// Method is how the model was fit, e.g. "ridge" or "lasso", and Alpha the
+	// strength of the regularization. Both are informational.
+	Method    string  `json:"method,omitempty"`
+	Alpha     float64 `json:"alpha,omitempty"`
+	Intercept float64 `json:"intercept"`
+	// Coefficients are keyed by feature name. Features without a coefficient
+	// do not affect the price, e.g. those a lasso regression dropped.
+	Coefficients map[string]float64 `json:"coefficients"`
+
+	// weights are the coefficients in the order of the bundle's features.
+	weights []float64
+}
+
+// NewLinearBundle bundles a linear model of the given feature columns. If features
+// is empty, the model reads the served schema (FeatureNames).
+func NewLinearBundle(features []string, m *LinearModel, metadata BundleMetadata) (*Bundle, error) {
+	b := &Bundle{Format: BundleFormat, Kind: KindLinear, Features: features, Metadata: metadata, Linear: m}
+	if err := b.init(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// init checks the coefficients and orders them like features.
+func (m *LinearModel) init(features []string) error {
+	if math.IsNaN(m.Intercept) || math.IsInf(m.Intercept, 0) {
+		return errors.New("the intercept is not a finite number")
+	}
+	index := make(map[string]int, len(features))
+	for i, name := range features {
+		index[name] = i
+	}
+	m.weights = make([]float64, len(features))
+	for name, coef := range m.Coefficients {
+		i, ok := index[name]
+		if !ok {
+			return fmt.Errorf("coefficient of unknown feature %q", name)
+		}
+		if math.IsNaN(coef) || math.IsInf(coef, 0) {
+			return fmt.Errorf("the coefficient of %s is not a finite number", name)
+		}
+		m.weights[i] = coef
+	}
+	return nil
+}
+
+// Predict returns the model's output for an encoded feature vector.
+func (m *LinearModel) Predict(features []float32) float64 {
+	output := m.Intercept
+	for i, w := range m.weights {
+		output += w * float64(features[i])
+	}
+	return output
+}
+
+// Breakdown returns the intercept and the term of every feature with a non-zero
+// value, in schema order. They add up to the model's output.
+func (m *LinearModel) Breakdown(names []string, features []float32) *domain.LinearBreakdown {
+	breakdown := &domain.LinearBreakdown{Intercept: m.Intercept, Terms: []domain.LinearTerm{}}
+	for i, w := range m.weights {
+		if features[i] == 0 {
+			continue
+		}
+		breakdown.Terms = append(breakdown.Terms, domain.LinearTerm{
+			Feature:      names[i],
+			Coefficient:  w,
+			Value:        features[i],
+			Contribution: w * float64(features[i]),
+		})
+	}
+	return breakdown
+}
@@ -0,0 +1,80 @@
+package prediction
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLinear prices cars at 50 dollars per horsepower, plus 2000 for BMWs.
+func testLinear() *LinearModel {
+	return &LinearModel{Method: "ridge", Alpha: 1, Intercept: 1000, Coefficients: map[string]float64{
+		"horsepower": 50,
+		"brand_bmw":  2000,
+		"carwidth":   0,
+	}}
+}
+
+func TestLinearBundle_Predict(t *testing.T) {
+	b, err := NewLinearBundle(nil, testLinear(), BundleMetadata{})
+	require.NoError(t, err)
+	assert.Equal(t, FeatureNames(), b.Features)
+
+	input := referenceInput
+	input.Brand = "bmw"
+	result, err := b.Predict(input)
+	require.NoError(t, err)
+	assert.Equal(t, float32(1000+50*95+2000), result.PredictedPrice)
+
+	// The terms and the intercept add up to the price
+	require.NotNil(t, result.Linear)
+	assert.Equal(t, 1000.0, result.Linear.Intercept)
+	sum := result.Linear.Intercept
+	terms := map[string]float64{}
+	for _, term := range result.Linear.Terms {
+		assert.NotZero(t, term.Value)
+		sum += term.Contribution
+		terms[term.Feature] = term.Contribution
+	}
+	assert.InDelta(t, float64(result.PredictedPrice), sum, 1e-6)
+	assert.Equal(t, 4750.0, terms["horsepower"])
+	assert.Equal(t, 2000.0, terms["brand_bmw"])
+	assert.Equal(t, 0.0, terms["carwidth"], "features without effect are still listed")
+	assert.NotContains(t, terms, "brand_audi")
+
+	_, _, err = b.Interval(input, 0.9)
+	assert.ErrorContains(t, err, "intervals need a random forest")
+}
+
+func TestLinearBundle_Invalid(t *testing.T) {
+	_, err := NewLinearBundle(nil, &LinearModel{Coefficients: map[string]float64{"brand_tesla": 1}}, BundleMetadata{})
+	assert.ErrorContains(t, err, `coefficient of unknown feature "brand_tesla"`)
+
+	_, err = ParseBundle([]byte(`{"format": "carprice-model-bundle/v1", "kind": "linear"}`))
+	assert.ErrorContains(t, err, "no linear model")
+}
+
+func TestLinearBundle_Service(t *testing.T) {
+	// A hand-written bundle needs no feature list
+	path := filepath.Join(t.TempDir(), "ridge.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"format": "carprice-model-bundle/v1",
+		"kind": "linear",
+		"metadata": {"trained_at": "2025-10-01T00:00:00Z", "train_rows": 164, "holdout_rows": 41},
+		"linear": {"method": "lasso", "alpha": 0.5, "intercept": -500, "coefficients": {"enginesize": 100}}
+	}`), 0o644))
+	service := Open(path)
+	require.NotEmpty(t, service.Model().Version)
+
+	result, err := service.Predict(referenceInput)
+	require.NoError(t, err)
+	assert.Equal(t, float32(-500+100*120), result.PredictedPrice)
+	require.NotNil(t, result.Linear)
+
+	explanation, err := service.Explain(referenceInput)
+	require.NoError(t, err)
+	assert.Equal(t, result.PredictedPrice, explanation.PredictedPrice)
+}
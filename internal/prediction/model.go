@@ -0,0 +1,38 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"path/filepath"
+)
+
+// Model is a prediction service backed by a model file that can be reloaded.
+type Model interface {
+	domain.PredictionService
+	domain.ModelManager
+	domain.Explainer
+}
+
+// Ensure PredictionService implements Model
+var _ Model = (*PredictionService)(nil)
+
+// BundleExt is the file extension of model bundles.
+const BundleExt = ".json"
+
+// Open returns a prediction service for the model file at path: an ensemble of
+// models for .ensemble.json files, a model bundle served in Go for other .json
+// files and an ONNX model served by onnxruntime otherwise.
+func Open(path string) Model {
+	if IsEnsemble(path) {
+		return NewEnsembleService(path)
+	}
+	if IsBundle(path) {
+		return NewBundleService(path)
+	}
+	return NewPredictionService(path)
+}
+
+// IsBundle reports whether path names a model bundle rather than an ONNX model
+// or an ensemble.
+func IsBundle(path string) bool {
+	return filepath.Ext(path) == BundleExt && !IsEnsemble(path)
+}
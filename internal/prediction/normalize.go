@@ -0,0 +1,78 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"strings"
+)
+
+// categoryAliases maps alternative spellings of categorical values to the value the
+// model was trained on. Keys and values are lower case. The brand entries include
+// the misspellings found in the original dataset.
+var categoryAliases = map[string]map[string]string{
+	"fueltype": {
+		"gasoline": "gas",
+		"petrol":   "gas",
+	},
+	"aspiration": {
+		"standard":     "std",
+		"turbocharged": "turbo",
+	},
+	"doornumber": {
+		"2": "two",
+		"4": "four",
+	},
+	"carbody": {
+		"cabriolet": "convertible",
+		"hatch":     "hatchback",
+		"estate":    "wagon",
+	},
+	"drivewheel": {
+		"awd": "4wd",
+		"4x4": "4wd",
+	},
+	"cylindernumber": {
+		"2":  "two",
+		"3":  "three",
+		"4":  "four",
+		"5":  "five",
+		"6":  "six",
+		"8":  "eight",
+		"12": "twelve",
+	},
+	"brand": {
+		"alfa-romeo": "alfa-romero",
+		"alfa romeo": "alfa-romero",
+		"chevy":      "chevrolet",
+		"maxda":      "mazda",
+		"porcshce":   "porsche",
+		"toyouta":    "toyota",
+		"vokswagen":  "volkswagen",
+		"vw":         "volkswagen",
+	},
+}
+
+// Normalize returns a copy of input with every categorical value trimmed, lower-cased
+// and mapped from a known alias to its canonical spelling, so that inputs that only
+// differ in spelling produce the same feature vector.
+func Normalize(input domain.UserInput) domain.UserInput {
+	input.Fueltype = NormalizeCategory("fueltype", input.Fueltype)
+	input.Aspiration = NormalizeCategory("aspiration", input.Aspiration)
+	input.Doornumber = NormalizeCategory("doornumber", input.Doornumber)
+	input.Carbody = NormalizeCategory("carbody", input.Carbody)
+	input.Drivewheel = NormalizeCategory("drivewheel", input.Drivewheel)
+	input.Enginelocation = NormalizeCategory("enginelocation", input.Enginelocation)
+	input.Enginetype = NormalizeCategory("enginetype", input.Enginetype)
+	input.Cylindernumber = NormalizeCategory("cylindernumber", input.Cylindernumber)
+	input.Fuelsystem = NormalizeCategory("fuelsystem", input.Fuelsystem)
+	input.Brand = NormalizeCategory("brand", input.Brand)
+	return input
+}
+
+// NormalizeCategory returns the canonical spelling of a value of a categorical feature.
+func NormalizeCategory(feature, value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if canonical, ok := categoryAliases[feature][value]; ok {
+		return canonical
+	}
+	return value
+}
@@ -0,0 +1,47 @@
+package prediction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	input := referenceInput
+	input.Fueltype = " Petrol "
+	input.Aspiration = "TURBO"
+	input.Doornumber = "2"
+	input.Cylindernumber = "6"
+	input.Brand = "VW"
+
+	normalized := Normalize(input)
+
+	assert.Equal(t, "gas", normalized.Fueltype)
+	assert.Equal(t, "turbo", normalized.Aspiration)
+	assert.Equal(t, "two", normalized.Doornumber)
+	assert.Equal(t, "six", normalized.Cylindernumber)
+	assert.Equal(t, "volkswagen", normalized.Brand)
+	assert.Equal(t, referenceInput.Horsepower, normalized.Horsepower)
+}
+
+func TestTransform_IgnoresCaseAndAliases(t *testing.T) {
+	canonical := referenceInput
+	canonical.Aspiration = "turbo"
+	canonical.Doornumber = "two"
+	canonical.Brand = "volkswagen"
+
+	variant := canonical
+	variant.Fueltype = "GAS"
+	variant.Aspiration = "Turbocharged"
+	variant.Doornumber = "2"
+	variant.Carbody = "Sedan"
+	variant.Brand = "vw"
+
+	want, err := Transform(canonical)
+	require.NoError(t, err)
+	got, err := Transform(variant)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.NoError(t, Validate(variant))
+}
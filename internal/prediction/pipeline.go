@@ -0,0 +1,331 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Preprocessing operations a bundle's pipeline can apply.
+const (
+	// StepOneHot adds a column "field_category" per category of a categorical field.
+	StepOneHot = "one_hot"
+	// StepStandardScale computes (x - mean) / std.
+	StepStandardScale = "standard_scale"
+	// StepMinMaxScale computes (x - min) / (max - min).
+	StepMinMaxScale = "min_max_scale"
+	// StepLog computes ln(x) and StepLog1p ln(1 + x).
+	StepLog   = "log"
+	StepLog1p = "log1p"
+	// StepClip limits x to [min, max]. Either bound may be left out.
+	StepClip = "clip"
+	// StepRatio adds a column numerator / denominator, e.g. power-to-weight.
+	StepRatio = "ratio"
+)
+
+// PreprocessingStep is one operation of a bundle's preprocessing pipeline. Steps
+// read and write named columns: the numerical input fields, one-hot columns
+// "field_category" and the columns earlier steps added. Scaling, log and clip
+// steps replace the values of their columns.
+type PreprocessingStep struct {
+	Op string `json:"op"`
+	// Field and Categories are the field and categories of a one_hot step.
+	Field      string   `json:"field,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	// Columns are the columns of a log or log1p step.
+	Columns []string `json:"columns,omitempty"`
+	// Mean and Std are the parameters of a standard_scale step by column.
+	Mean map[string]float64 `json:"mean,omitempty"`
+	Std  map[string]float64 `json:"std,omitempty"`
+	// Min and Max are the parameters of a min_max_scale step, or the bounds of a
+	// clip step, by column.
+	Min map[string]float64 `json:"min,omitempty"`
+	Max map[string]float64 `json:"max,omitempty"`
+	// Output is the column a ratio step adds, Numerator and Denominator the
+	// columns it divides.
+	Output      string `json:"output,omitempty"`
+	Numerator   string `json:"numerator,omitempty"`
+	Denominator string `json:"denominator,omitempty"`
+}
+
+// Pipeline converts inputs into the feature vectors of a model by encoding the
+// input columns and applying preprocessing steps in order.
+type Pipeline struct {
+	features []string
+	encoder  *Encoder
+	// inputs is the working column of each encoder column, outputs the working
+	// column of each feature.
+	inputs  []int
+	outputs []int
+	width   int
+	steps   []func(x []float64)
+	// identity is set if the encoder's columns are the features.
+	identity bool
+}
+
+// NewPipeline creates a pipeline that applies steps and returns the named
+// features. Without steps it encodes the features like NewEncoder.
+func NewPipeline(features []string, steps []PreprocessingStep) (*Pipeline, error) {
+	b := &pipelineBuilder{p: &Pipeline{features: slices.Clone(features)}, columns: map[string]int{}}
+	for i, step := range steps {
+		apply, err := step.compile(b)
+		if err != nil {
+			return nil, fmt.Errorf("preprocessing step %d (%s): %w", i+1, step.Op, err)
+		}
+		if apply != nil {
+			b.p.steps = append(b.p.steps, apply)
+		}
+	}
+	p := b.p
+	for _, name := range features {
+		c, err := b.column(name)
+		if err != nil {
+			return nil, err
+		}
+		p.outputs = append(p.outputs, c)
+	}
+	encoder, err := NewEncoder(b.inputs)
+	if err != nil {
+		return nil, err
+	}
+	p.encoder = encoder
+	p.identity = len(p.steps) == 0 && p.width == len(p.outputs)
+	for i, c := range p.outputs {
+		p.identity = p.identity && c == i && p.inputs[i] == i
+	}
+	return p, nil
+}
+
+// pipelineBuilder assigns the columns of a pipeline's working vector.
+type pipelineBuilder struct {
+	p       *Pipeline
+	columns map[string]int
+	// inputs names the encoder columns.
+	inputs []string
+}
+
+// column returns the working column of name, reading it from the input if no
+// step added it.
+func (b *pipelineBuilder) column(name string) (int, error) {
+	if c, ok := b.columns[name]; ok {
+		return c, nil
+	}
+	return b.input(name)
+}
+
+// input adds a column the encoder reads from the input.
+func (b *pipelineBuilder) input(name string) (int, error) {
+	if _, err := NewEncoder([]string{name}); err != nil {
+		return 0, err
+	}
+	c, err := b.add(name)
+	if err != nil {
+		return 0, err
+	}
+	b.inputs = append(b.inputs, name)
+	b.p.inputs = append(b.p.inputs, c)
+	return c, nil
+}
+
+// add adds a column.
+func (b *pipelineBuilder) add(name string) (int, error) {
+	if name == "" {
+		return 0, errors.New("missing output column")
+	}
+	if _, ok := b.columns[name]; ok {
+		return 0, fmt.Errorf("column %q already exists", name)
+	}
+	b.columns[name] = b.p.width
+	b.p.width++
+	return b.columns[name], nil
+}
+
+// compile checks the step and returns the function applying it to a working
+// vector, or nil if the encoder does its work.
+func (s PreprocessingStep) compile(b *pipelineBuilder) (func(x []float64), error) {
+	switch s.Op {
+	case StepOneHot:
+		if _, ok := categoricalFields[s.Field]; !ok {
+			return nil, fmt.Errorf("%q is not a categorical field", s.Field)
+		}
+		if len(s.Categories) == 0 {
+			return nil, errors.New("no categories")
+		}
+		for _, category := range s.Categories {
+			if _, err := b.input(s.Field + "_" + strings.ToLower(category)); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case StepStandardScale:
+		return b.scale(s.Mean, s.Std, "mean", "std", func(mean, std float64) (float64, float64, error) {
+			if !(std > 0) {
+				return 0, 0, errors.New("std must be positive")
+			}
+			return mean, std, nil
+		})
+	case StepMinMaxScale:
+		return b.scale(s.Min, s.Max, "min", "max", func(lo, hi float64) (float64, float64, error) {
+			if !(hi > lo) {
+				return 0, 0, errors.New("max must be greater than min")
+			}
+			return lo, hi - lo, nil
+		})
+	case StepLog, StepLog1p:
+		if len(s.Columns) == 0 {
+			return nil, errors.New("no columns")
+		}
+		cols, err := b.resolve(s.Columns)
+		if err != nil {
+			return nil, err
+		}
+		log := math.Log
+		if s.Op == StepLog1p {
+			log = math.Log1p
+		}
+		return func(x []float64) {
+			for _, c := range cols {
+				x[c] = log(x[c])
+			}
+		}, nil
+	case StepClip:
+		if len(s.Min)+len(s.Max) == 0 {
+			return nil, errors.New("no bounds")
+		}
+		type bound struct {
+			column int
+			lo, hi float64
+		}
+		var bounds []bound
+		for _, name := range sortedKeys(s.Min, s.Max) {
+			lo, ok := s.Min[name]
+			if !ok {
+				lo = math.Inf(-1)
+			}
+			hi, ok := s.Max[name]
+			if !ok {
+				hi = math.Inf(1)
+			}
+			if math.IsNaN(lo) || math.IsNaN(hi) || lo > hi {
+				return nil, fmt.Errorf("invalid bounds of %s", name)
+			}
+			c, err := b.column(name)
+			if err != nil {
+				return nil, err
+			}
+			bounds = append(bounds, bound{c, lo, hi})
+		}
+		return func(x []float64) {
+			for _, bd := range bounds {
+				x[bd.column] = min(max(x[bd.column], bd.lo), bd.hi)
+			}
+		}, nil
+	case StepRatio:
+		cols, err := b.resolve([]string{s.Numerator, s.Denominator})
+		if err != nil {
+			return nil, err
+		}
+		out, err := b.add(s.Output)
+		if err != nil {
+			return nil, err
+		}
+		return func(x []float64) {
+			x[out] = x[cols[0]] / x[cols[1]]
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown preprocessing operation %q", s.Op)
+	}
+}
+
+// scale compiles a step computing (x - offset) / scale per column, where params
+// derives offset and scale from the parameters a and z of each column.
+func (b *pipelineBuilder) scale(a, z map[string]float64, aName, zName string, params func(a, z float64) (float64, float64, error)) (func(x []float64), error) {
+	if len(a)+len(z) == 0 {
+		return nil, errors.New("no columns")
+	}
+	type param struct {
+		column        int
+		offset, scale float64
+	}
+	var ps []param
+	for _, name := range sortedKeys(a, z) {
+		va, okA := a[name]
+		vz, okZ := z[name]
+		if !okA || !okZ {
+			return nil, fmt.Errorf("%s needs both %s and %s", name, aName, zName)
+		}
+		if math.IsNaN(va) || math.IsInf(va, 0) || math.IsNaN(vz) || math.IsInf(vz, 0) {
+			return nil, fmt.Errorf("the parameters of %s are not finite numbers", name)
+		}
+		offset, scale, err := params(va, vz)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		c, err := b.column(name)
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, param{c, offset, scale})
+	}
+	return func(x []float64) {
+		for _, p := range ps {
+			x[p.column] = (x[p.column] - p.offset) / p.scale
+		}
+	}, nil
+}
+
+// resolve returns the working columns of names.
+func (b *pipelineBuilder) resolve(names []string) ([]int, error) {
+	cols := make([]int, len(names))
+	for i, name := range names {
+		c, err := b.column(name)
+		if err != nil {
+			return nil, err
+		}
+		cols[i] = c
+	}
+	return cols, nil
+}
+
+// sortedKeys returns the keys of both maps in order.
+func sortedKeys(a, b map[string]float64) []string {
+	var keys []string
+	for _, m := range []map[string]float64{a, b} {
+		for key := range m {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Features returns the names of the pipeline's output columns.
+func (p *Pipeline) Features() []string {
+	return slices.Clone(p.features)
+}
+
+// Encode returns the feature vector of the input. Values outside the domain of a
+// step, e.g. the log of a negative number, are NaN or infinite.
+func (p *Pipeline) Encode(input domain.UserInput) []float32 {
+	encoded := p.encoder.Encode(input)
+	if p.identity {
+		return encoded
+	}
+	x := make([]float64, p.width)
+	for i, c := range p.inputs {
+		x[c] = float64(encoded[i])
+	}
+	for _, apply := range p.steps {
+		apply(x)
+	}
+	features := make([]float32, len(p.outputs))
+	for i, c := range p.outputs {
+		features[i] = float32(x[c])
+	}
+	return features
+}
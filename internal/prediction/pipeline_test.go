@@ -0,0 +1,111 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSteps derive power-to-weight, tame and scale horsepower and spell out the
+// brand columns.
+var testSteps = []PreprocessingStep{
+	{Op: StepOneHot, Field: "brand", Categories: []string{"BMW", "toyota"}},
+	{Op: StepRatio, Output: "power_to_weight", Numerator: "horsepower", Denominator: "curbweight"},
+	{Op: StepClip, Max: map[string]float64{"horsepower": 90}},
+	{Op: StepLog, Columns: []string{"horsepower"}},
+	{Op: StepStandardScale, Mean: map[string]float64{"enginesize": 100}, Std: map[string]float64{"enginesize": 20}},
+	{Op: StepMinMaxScale, Min: map[string]float64{"carwidth": 60}, Max: map[string]float64{"carwidth": 70}},
+}
+
+func TestPipeline_Encode(t *testing.T) {
+	p, err := NewPipeline([]string{"carwidth", "power_to_weight", "horsepower", "enginesize", "brand_toyota", "brand_bmw", "carbody_sedan"}, testSteps)
+	require.NoError(t, err)
+	assert.Equal(t, []float32{
+		0.55,
+		float32(95.0 / 2414),
+		float32(math.Log(90)),
+		1,
+		1,
+		0,
+		1,
+	}, p.Encode(referenceInput))
+
+	// Without steps the pipeline is the encoder
+	p, err = NewPipeline(FeatureNames(), nil)
+	require.NoError(t, err)
+	want, err := Transform(referenceInput)
+	require.NoError(t, err)
+	assert.Equal(t, want, p.Encode(referenceInput))
+}
+
+func TestPipeline_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		step PreprocessingStep
+		err  string
+	}{
+		{"unknown op", PreprocessingStep{Op: "pca"}, `unknown preprocessing operation "pca"`},
+		{"one-hot numeric", PreprocessingStep{Op: StepOneHot, Field: "horsepower", Categories: []string{"high"}}, `"horsepower" is not a categorical field`},
+		{"one-hot twice", PreprocessingStep{Op: StepOneHot, Field: "brand", Categories: []string{"bmw", "BMW"}}, `column "brand_bmw" already exists`},
+		{"zero std", PreprocessingStep{Op: StepStandardScale, Mean: map[string]float64{"stroke": 3}, Std: map[string]float64{"stroke": 0}}, "stroke: std must be positive"},
+		{"missing std", PreprocessingStep{Op: StepStandardScale, Mean: map[string]float64{"stroke": 3}}, "stroke needs both mean and std"},
+		{"empty range", PreprocessingStep{Op: StepMinMaxScale, Min: map[string]float64{"stroke": 3}, Max: map[string]float64{"stroke": 3}}, "max must be greater than min"},
+		{"unknown column", PreprocessingStep{Op: StepLog, Columns: []string{"price"}}, `unknown feature "price"`},
+		{"crossed bounds", PreprocessingStep{Op: StepClip, Min: map[string]float64{"stroke": 4}, Max: map[string]float64{"stroke": 3}}, "invalid bounds of stroke"},
+		{"ratio over input", PreprocessingStep{Op: StepRatio, Output: "horsepower", Numerator: "enginesize", Denominator: "curbweight"}, `column "horsepower" already exists`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := []PreprocessingStep{{Op: StepLog1p, Columns: []string{"horsepower"}}, tt.step}
+			_, err := NewPipeline([]string{"horsepower"}, steps)
+			assert.ErrorContains(t, err, "preprocessing step 2 ("+tt.step.Op+")")
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	_, err := NewPipeline([]string{"weight_to_power"}, testSteps)
+	assert.ErrorContains(t, err, `unknown feature "weight_to_power"`)
+}
+
+func TestBundle_Preprocessing(t *testing.T) {
+	b := &Bundle{
+		Format:        BundleFormat,
+		Kind:          KindLinear,
+		Features:      []string{"power_to_weight", "horsepower", "enginesize", "brand_bmw", "symboling"},
+		Preprocessing: append(testSteps, PreprocessingStep{Op: StepLog, Columns: []string{"symboling"}}),
+		Linear: &LinearModel{Intercept: 10000, Coefficients: map[string]float64{
+			"power_to_weight": 100000,
+			"enginesize":      1500,
+			"brand_bmw":       5000,
+		}},
+	}
+	data, err := json.Marshal(b)
+	require.NoError(t, err)
+	b, err = ParseBundle(data)
+	require.NoError(t, err)
+
+	// The linear terms are of the preprocessed values
+	result, err := b.Predict(referenceInput)
+	require.NoError(t, err)
+	assert.InDelta(t, 10000+100000*95.0/2414+1500, result.PredictedPrice, 0.01)
+	require.NotNil(t, result.Linear)
+	assert.Equal(t, "enginesize", result.Linear.Terms[2].Feature)
+	assert.Equal(t, float32(1), result.Linear.Terms[2].Value)
+
+	// Values outside a step's domain fail the prediction
+	input := referenceInput
+	input.Symboling = -1
+	_, err = b.Predict(input)
+	var domainErr *domain.Error
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, domain.CodeInferenceFailed, domainErr.Code)
+	assert.ErrorContains(t, err, "preprocessing gave symboling = NaN")
+
+	b.Preprocessing = []PreprocessingStep{{Op: StepClip}}
+	assert.ErrorContains(t, b.init(), "preprocessing step 1 (clip): no bounds")
+}
@@ -0,0 +1,189 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"fmt"
+	"runtime"
+	"sync"
+
+	onnx "github.com/yalue/onnxruntime_go"
+)
+
+// Ensure PooledService implements Model
+var _ Model = (*PooledService)(nil)
+
+// PooledService serves an ONNX model with onnxruntime sessions that are created
+// when the model is loaded and reused, instead of a session per prediction like
+// PredictionService. The onnxruntime environment must be initialized.
+type PooledService struct {
+	path string
+	size int
+
+	mu   sync.RWMutex
+	pool *sessionPool
+}
+
+// sessionPool holds the sessions of one version of the model.
+type sessionPool struct {
+	model    domain.ModelInfo
+	target   TargetTransform
+	sessions chan *onnx.DynamicAdvancedSession
+	// users counts the predictions using the pool, so that a replaced pool is
+	// destroyed only after they finish.
+	users sync.WaitGroup
+}
+
+// NewPooledService loads the model at path into size sessions, or one per CPU if
+// size is not positive.
+func NewPooledService(path string, size int) (*PooledService, error) {
+	if size <= 0 {
+		size = runtime.GOMAXPROCS(0)
+	}
+	pool, err := newSessionPool(path, size)
+	if err != nil {
+		return nil, err
+	}
+	return &PooledService{path: path, size: size, pool: pool}, nil
+}
+
+// newSessionPool creates size sessions of the model at path.
+func newSessionPool(path string, size int) (*sessionPool, error) {
+	info, target, err := loadONNXModel(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &sessionPool{model: info, target: target, sessions: make(chan *onnx.DynamicAdvancedSession, size)}
+	for range size {
+		session, err := onnx.NewDynamicAdvancedSession(path, []string{InputTensor}, []string{OutputTensor}, nil)
+		if err != nil {
+			p.destroy()
+			return nil, fmt.Errorf("failed to create session: %w", err)
+		}
+		p.sessions <- session
+	}
+	return p, nil
+}
+
+// destroy destroys the sessions of the pool. No prediction may be using it.
+func (p *sessionPool) destroy() {
+	close(p.sessions)
+	for session := range p.sessions {
+		session.Destroy()
+	}
+}
+
+// Model describes the model currently serving predictions.
+func (s *PooledService) Model() domain.ModelInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pool.model
+}
+
+// Reload loads the model file into new sessions. Predictions that already started
+// finish with the previous sessions, which are destroyed afterwards.
+func (s *PooledService) Reload() (domain.ModelInfo, error) {
+	pool, err := newSessionPool(s.path, s.size)
+	if err != nil {
+		return domain.ModelInfo{}, domain.NewError(domain.CodeModelUnavailable, "The prediction model could not be loaded.", err)
+	}
+
+	s.mu.Lock()
+	old := s.pool
+	s.pool = pool
+	s.mu.Unlock()
+	go func() {
+		old.users.Wait()
+		old.destroy()
+	}()
+	return pool.model, nil
+}
+
+// Close destroys the sessions once running predictions finish. The service must
+// not be used afterwards.
+func (s *PooledService) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pool.users.Wait()
+	s.pool.destroy()
+}
+
+// FeatureSchema returns the served feature schema, which ONNX models read
+// through Transform.
+func (s *PooledService) FeatureSchema() (FeatureSchema, error) {
+	return FeatureSchema{Features: FeatureNames()}, nil
+}
+
+// EncodeFeatures returns the feature vector the ONNX model predicts the input from.
+func (s *PooledService) EncodeFeatures(input domain.UserInput) ([]float32, error) {
+	features, err := Transform(input)
+	if err != nil {
+		return nil, domain.NewError(domain.CodeInferenceFailed, "The input could not be preprocessed.", err)
+	}
+	return features, nil
+}
+
+// Predict validates the input and predicts its price with a pooled session.
+func (s *PooledService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	results, err := s.PredictBatch([]domain.UserInput{input})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// PredictBatch validates the inputs and predicts their prices in a single model
+// run. It fails if any input is invalid.
+func (s *PooledService) PredictBatch(inputs []domain.UserInput) ([]*domain.PredictionResult, error) {
+	features := make([]float32, 0, len(inputs)*ModelInputSize)
+	for _, input := range inputs {
+		if err := Validate(input); err != nil {
+			return nil, err
+		}
+		row, err := Transform(input)
+		if err != nil {
+			return nil, domain.NewError(domain.CodeInferenceFailed, "The model could not produce a prediction.", fmt.Errorf("failed to transform input: %w", err))
+		}
+		features = append(features, row...)
+	}
+
+	n := int64(len(inputs))
+	inputTensor, err := onnx.NewTensor(onnx.NewShape(n, ModelInputSize), features)
+	if err != nil {
+		return nil, domain.NewError(domain.CodeInferenceFailed, "The model could not produce a prediction.", fmt.Errorf("failed to create input tensor: %w", err))
+	}
+	defer inputTensor.Destroy()
+	outputTensor, err := onnx.NewEmptyTensor[float32](onnx.NewShape(n, 1))
+	if err != nil {
+		return nil, domain.NewError(domain.CodeInferenceFailed, "The model could not produce a prediction.", fmt.Errorf("failed to create output tensor: %w", err))
+	}
+	defer outputTensor.Destroy()
+
+	s.mu.RLock()
+	pool := s.pool
+	pool.users.Add(1)
+	s.mu.RUnlock()
+	defer pool.users.Done()
+
+	session := <-pool.sessions
+	err = session.Run([]onnx.Value{inputTensor}, []onnx.Value{outputTensor})
+	pool.sessions <- session
+	if err != nil {
+		return nil, domain.NewError(domain.CodeInferenceFailed, "The model could not produce a prediction.", fmt.Errorf("model inference error: %w", err))
+	}
+
+	prices := outputTensor.GetData()
+	results := make([]*domain.PredictionResult, len(inputs))
+	for i := range results {
+		results[i] = &domain.PredictionResult{PredictedPrice: float32(pool.target.Price(float64(prices[i])))}
+	}
+	return results, nil
+}
+
+// Explain predicts the price for the input and attributes the difference from the
+// reference car's price to the individual input fields.
+func (s *PooledService) Explain(input domain.UserInput) (*domain.Explanation, error) {
+	if err := Validate(input); err != nil {
+		return nil, err
+	}
+	return explain(s, input)
+}
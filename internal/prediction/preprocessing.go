@@ -8,7 +8,10 @@ import (
 
 // Transform converts a UserInput struct into a feature vector for the ONNX model.
 // It handles both numerical features and categorical features (via one-hot encoding).
+// Categorical values are normalized first, so case and known aliases do not matter.
 func Transform(input domain.UserInput) ([]float32, error) {
+	input = Normalize(input)
+
 	// Initialize the feature vector with zeros
 	features := make([]float32, ModelInputSize)
 
@@ -0,0 +1,75 @@
+package prediction
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+// modelName is the pattern of registered model names.
+var modelName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Registry holds models a server serves by name next to its default model, so
+// that a request can pick one, e.g. a transparent linear model for regulated
+// customers.
+type Registry struct {
+	mu     sync.RWMutex
+	models map[string]Model
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{models: map[string]Model{}}
+}
+
+// Register adds a model under name, which must be lower case letters, digits,
+// dashes and underscores.
+func (r *Registry) Register(name string, model Model) error {
+	if !modelName.MatchString(name) {
+		return fmt.Errorf("invalid model name %q", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.models[name]; ok {
+		return fmt.Errorf("model %s is registered twice", name)
+	}
+	r.models[name] = model
+	return nil
+}
+
+// Get returns the model registered under name.
+func (r *Registry) Get(name string) (Model, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	model, ok := r.models[name]
+	return model, ok
+}
+
+// Names returns the names of the registered models in order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.models))
+	for name := range r.models {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// registryKey is the context key of the registry of selectable models.
+type registryKey struct{}
+
+// NewRegistryContext returns a copy of ctx carrying the registry.
+func NewRegistryContext(ctx context.Context, r *Registry) context.Context {
+	return context.WithValue(ctx, registryKey{}, r)
+}
+
+// RegistryFromContext returns the registry stored in ctx, or nil if model
+// selection is not enabled.
+func RegistryFromContext(ctx context.Context) *Registry {
+	r, _ := ctx.Value(registryKey{}).(*Registry)
+	return r
+}
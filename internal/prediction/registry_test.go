@@ -0,0 +1,32 @@
+package prediction
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.json")
+	writeTestBundle(t, path, 8000, 20000)
+
+	r := NewRegistry()
+	require.NoError(t, r.Register("ridge", Open(path)))
+	require.NoError(t, r.Register("forest_v2", Open(path)))
+	assert.ErrorContains(t, r.Register("ridge", Open(path)), "registered twice")
+	assert.ErrorContains(t, r.Register("Ridge Model", Open(path)), "invalid model name")
+	assert.Equal(t, []string{"forest_v2", "ridge"}, r.Names())
+
+	model, ok := r.Get("ridge")
+	require.True(t, ok)
+	assert.NotEmpty(t, model.Model().Version)
+	_, ok = r.Get("lasso")
+	assert.False(t, ok)
+
+	ctx := NewRegistryContext(context.Background(), r)
+	assert.Same(t, r, RegistryFromContext(ctx))
+	assert.Nil(t, RegistryFromContext(context.Background()))
+}
@@ -0,0 +1,145 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// numericFields reads the numerical UserInput fields by feature name.
+var numericFields = map[string]func(domain.UserInput) float32{
+	"symboling":        func(in domain.UserInput) float32 { return float32(in.Symboling) },
+	"wheelbase":        func(in domain.UserInput) float32 { return in.Wheelbase },
+	"carlength":        func(in domain.UserInput) float32 { return in.Carlength },
+	"carwidth":         func(in domain.UserInput) float32 { return in.Carwidth },
+	"carheight":        func(in domain.UserInput) float32 { return in.Carheight },
+	"curbweight":       func(in domain.UserInput) float32 { return float32(in.Curbweight) },
+	"enginesize":       func(in domain.UserInput) float32 { return float32(in.Enginesize) },
+	"boreratio":        func(in domain.UserInput) float32 { return in.Boreratio },
+	"stroke":           func(in domain.UserInput) float32 { return in.Stroke },
+	"compressionratio": func(in domain.UserInput) float32 { return in.Compressionratio },
+	"horsepower":       func(in domain.UserInput) float32 { return float32(in.Horsepower) },
+	"peakrpm":          func(in domain.UserInput) float32 { return float32(in.Peakrpm) },
+	"citympg":          func(in domain.UserInput) float32 { return float32(in.Citympg) },
+	"highwaympg":       func(in domain.UserInput) float32 { return float32(in.Highwaympg) },
+}
+
+// categoricalFields reads the categorical UserInput fields by feature name.
+var categoricalFields = map[string]func(domain.UserInput) string{
+	"fueltype":       func(in domain.UserInput) string { return in.Fueltype },
+	"aspiration":     func(in domain.UserInput) string { return in.Aspiration },
+	"doornumber":     func(in domain.UserInput) string { return in.Doornumber },
+	"carbody":        func(in domain.UserInput) string { return in.Carbody },
+	"drivewheel":     func(in domain.UserInput) string { return in.Drivewheel },
+	"enginelocation": func(in domain.UserInput) string { return in.Enginelocation },
+	"enginetype":     func(in domain.UserInput) string { return in.Enginetype },
+	"cylindernumber": func(in domain.UserInput) string { return in.Cylindernumber },
+	"fuelsystem":     func(in domain.UserInput) string { return in.Fuelsystem },
+	"brand":          func(in domain.UserInput) string { return in.Brand },
+}
+
+// FeatureNames returns the names of the ONNX model's input columns in order, as
+// listed in docs/model/model_column.txt.
+func FeatureNames() []string {
+	names := make([]string, ModelInputSize)
+	for name, i := range featureIndexMap {
+		names[i] = name
+	}
+	return names
+}
+
+// column reads one feature of a normalized input.
+type column func(domain.UserInput) float32
+
+// Encoder converts inputs into feature vectors with the columns of a feature schema.
+// Columns are either numerical field names or one-hot columns named
+// "field_value", as produced by pd.get_dummies.
+type Encoder struct {
+	features []string
+	columns  []column
+}
+
+// NewEncoder creates an encoder for the named columns. It fails if a column does
+// not name a UserInput field or a category of one.
+func NewEncoder(features []string) (*Encoder, error) {
+	e := &Encoder{features: slices.Clone(features), columns: make([]column, len(features))}
+	for i, name := range features {
+		if value, ok := numericFields[name]; ok {
+			e.columns[i] = value
+			continue
+		}
+		field, category, _ := strings.Cut(name, "_")
+		value, ok := categoricalFields[field]
+		if !ok || category == "" {
+			return nil, fmt.Errorf("unknown feature %q", name)
+		}
+		e.columns[i] = func(in domain.UserInput) float32 {
+			if value(in) == category {
+				return 1
+			}
+			return 0
+		}
+	}
+	return e, nil
+}
+
+// Features returns the names of the encoder's columns.
+func (e *Encoder) Features() []string {
+	return slices.Clone(e.features)
+}
+
+// Encode returns the feature vector of the input. Categorical values are
+// normalized first, so case and known aliases do not matter.
+func (e *Encoder) Encode(input domain.UserInput) []float32 {
+	input = Normalize(input)
+	features := make([]float32, len(e.columns))
+	for i, value := range e.columns {
+		features[i] = value(input)
+	}
+	return features
+}
+
+// FeatureSchema describes the feature vector a model predicts from: its columns
+// and the preprocessing steps that compute them from the input.
+type FeatureSchema struct {
+	Features      []string
+	Preprocessing []PreprocessingStep
+}
+
+// Equal reports whether both schemas encode inputs into the same feature vector.
+func (s FeatureSchema) Equal(other FeatureSchema) bool {
+	if !slices.Equal(s.Features, other.Features) {
+		return false
+	}
+	if len(s.Preprocessing) == 0 || len(other.Preprocessing) == 0 {
+		return len(s.Preprocessing) == len(other.Preprocessing)
+	}
+	return reflect.DeepEqual(s.Preprocessing, other.Preprocessing)
+}
+
+// SchemaProvider is implemented by prediction services that know the feature
+// schema of their model.
+type SchemaProvider interface {
+	FeatureSchema() (FeatureSchema, error)
+}
+
+// FeatureEncoder is implemented by prediction services that can return the
+// feature vector their model predicts an input from, e.g. for the prediction log.
+type FeatureEncoder interface {
+	SchemaProvider
+	// EncodeFeatures returns the feature vector of a valid input in the model's
+	// feature schema, after its preprocessing steps.
+	EncodeFeatures(input domain.UserInput) ([]float32, error)
+}
+
+// Ensure the model services implement FeatureEncoder
+var (
+	_ FeatureEncoder = (*PredictionService)(nil)
+	_ FeatureEncoder = (*PooledService)(nil)
+	_ FeatureEncoder = (*Bundle)(nil)
+	_ FeatureEncoder = (*BundleService)(nil)
+	_ FeatureEncoder = (*Ensemble)(nil)
+	_ FeatureEncoder = (*EnsembleService)(nil)
+)
@@ -0,0 +1,44 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureNames_MatchModelColumns(t *testing.T) {
+	data, err := os.ReadFile("../../docs/model/model_column.txt")
+	require.NoError(t, err)
+	assert.Equal(t, strings.Fields(string(data)), FeatureNames())
+}
+
+func TestEncoder_MatchesTransform(t *testing.T) {
+	e, err := NewEncoder(FeatureNames())
+	require.NoError(t, err)
+
+	other := referenceInput
+	other.Fueltype, other.Aspiration, other.Doornumber, other.Enginelocation = "Diesel", "turbo", "two", "rear"
+	other.Brand, other.Cylindernumber = "vw", "4"
+	for _, input := range []domain.UserInput{referenceInput, other} {
+		want, err := Transform(input)
+		require.NoError(t, err)
+		assert.Equal(t, want, e.Encode(input))
+	}
+}
+
+func TestEncoder_CustomSchema(t *testing.T) {
+	e, err := NewEncoder([]string{"horsepower", "brand_bmw", "carbody_sedan"})
+	require.NoError(t, err)
+	input := referenceInput
+	input.Brand = "BMW"
+	assert.Equal(t, []float32{95, 1, 1}, e.Encode(input))
+
+	_, err = NewEncoder([]string{"horsepower", "color_red"})
+	assert.ErrorContains(t, err, "color_red")
+	_, err = NewEncoder([]string{"brand_"})
+	assert.Error(t, err)
+}
@@ -2,39 +2,144 @@ package prediction
 
 import (
 	"car-price-prediction/internal/domain"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
 
 	onnx "github.com/yalue/onnxruntime_go"
 )
 
-// Ensure PredictionService implements domain.PredictionService interface
-var _ domain.PredictionService = (*PredictionService)(nil)
+// Ensure PredictionService implements domain.PredictionService and domain.ModelManager interfaces
+var (
+	_ domain.PredictionService = (*PredictionService)(nil)
+	_ domain.ModelManager      = (*PredictionService)(nil)
+)
+
+// Tensor names of ONNX models, as written by skl2onnx. `carprice inspect` shows the
+// names of a model's tensors.
+const (
+	InputTensor  = "float_input"
+	OutputTensor = "variable"
+)
+
+// modelVersionLength is the number of hex digits of the model hash used as its version.
+const modelVersionLength = 12
 
 // PredictionService encapsulates the ONNX model and prediction logic.
 type PredictionService struct {
 	modelPath string
+
+	mu    sync.RWMutex
+	model domain.ModelInfo
+	// target is the transform of the price the model was trained on.
+	target TargetTransform
 }
 
 // NewPredictionService creates a new prediction service with the given model path.
+// If the model file cannot be read yet, the model version stays empty until Reload
+// succeeds.
 func NewPredictionService(modelPath string) *PredictionService {
-	return &PredictionService{
+	s := &PredictionService{
 		modelPath: modelPath,
 	}
+	if info, target, err := loadONNXModel(modelPath); err == nil {
+		s.model, s.target = info, target
+	}
+	return s
+}
+
+// Model describes the model currently serving predictions.
+func (s *PredictionService) Model() domain.ModelInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.model
+}
+
+// Reload re-reads the model file and updates the model version. Sessions are created
+// per prediction, so the new file is used by every prediction that starts afterwards.
+func (s *PredictionService) Reload() (domain.ModelInfo, error) {
+	info, target, err := loadONNXModel(s.modelPath)
+	if err != nil {
+		return domain.ModelInfo{}, domain.NewError(domain.CodeModelUnavailable, "The prediction model could not be loaded.", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.model, s.target = info, target
+	return info, nil
+}
+
+// loadONNXModel hashes the ONNX model at path and reads its target transform.
+func loadONNXModel(path string) (domain.ModelInfo, TargetTransform, error) {
+	info, err := loadModelInfo(path)
+	if err != nil {
+		return domain.ModelInfo{}, TargetTransform{}, err
+	}
+	target, err := loadTargetTransform(path)
+	if err != nil {
+		return domain.ModelInfo{}, TargetTransform{}, err
+	}
+	return info, target, nil
+}
+
+// loadModelInfo hashes the model file at path.
+func loadModelInfo(path string) (domain.ModelInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return domain.ModelInfo{}, fmt.Errorf("failed to open model: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return domain.ModelInfo{}, fmt.Errorf("failed to read model: %w", err)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	return domain.ModelInfo{
+		Version:  sum[:modelVersionLength],
+		SHA256:   sum,
+		Path:     path,
+		LoadedAt: time.Now().UTC(),
+	}, nil
+}
+
+// FeatureSchema returns the served feature schema, which ONNX models read
+// through Transform.
+func (s *PredictionService) FeatureSchema() (FeatureSchema, error) {
+	return FeatureSchema{Features: FeatureNames()}, nil
+}
+
+// EncodeFeatures returns the feature vector the ONNX model predicts the input from.
+func (s *PredictionService) EncodeFeatures(input domain.UserInput) ([]float32, error) {
+	features, err := Transform(input)
+	if err != nil {
+		return nil, domain.NewError(domain.CodeInferenceFailed, "The input could not be preprocessed.", err)
+	}
+	return features, nil
 }
 
 // Predict takes a UserInput, preprocesses it, runs the ONNX model, and returns a prediction result.
 func (s *PredictionService) Predict(input domain.UserInput) (*domain.PredictionResult, error) {
+	// Reject categories and values the model cannot handle
+	if err := Validate(input); err != nil {
+		return nil, err
+	}
+
 	// Preprocess the input
 	features, err := Transform(input)
 	if err != nil {
-		return nil, fmt.Errorf("preprocessing error: %w", err)
+		return nil, domain.NewError(domain.CodeInferenceFailed, "The input could not be preprocessed.", err)
 	}
 
 	// Create a new input tensor with our data
 	// The model expects a 2D tensor with shape [1, 64] (batch size of 1, 64 features)
 	inputTensor, err := onnx.NewTensor[float32]([]int64{1, int64(ModelInputSize)}, features)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create input tensor: %w", err)
+		return nil, domain.NewError(domain.CodeInferenceFailed, "The model could not produce a prediction.", fmt.Errorf("failed to create input tensor: %w", err))
 	}
 	defer inputTensor.Destroy()
 
@@ -42,41 +147,44 @@ func (s *PredictionService) Predict(input domain.UserInput) (*domain.PredictionR
 	// The model outputs a single value representing the predicted price
 	outputTensor, err := onnx.NewEmptyTensor[float32]([]int64{1, 1})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create output tensor: %w", err)
+		return nil, domain.NewError(domain.CodeInferenceFailed, "The model could not produce a prediction.", fmt.Errorf("failed to create output tensor: %w", err))
 	}
 	defer outputTensor.Destroy()
 
 	// Create a new session for this prediction using NewAdvancedSession
-	// Use the correct tensor names from the model inspection
 	session, err := onnx.NewAdvancedSession(
 		s.modelPath,
-		[]string{"float_input"}, // Correct input tensor name from model inspection
-		[]string{"variable"}, // Correct output tensor name from model inspection
+		[]string{InputTensor},
+		[]string{OutputTensor},
 		[]onnx.ArbitraryTensor{inputTensor},
 		[]onnx.ArbitraryTensor{outputTensor},
 		nil, // Default options
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create session: %w", err)
+		return nil, domain.NewError(domain.CodeModelUnavailable, "The prediction model is not available.", fmt.Errorf("failed to create session: %w", err))
 	}
 	defer session.Destroy()
 
 	// Run the model inference
 	err = session.Run()
 	if err != nil {
-		return nil, fmt.Errorf("model inference error: %w", err)
+		return nil, domain.NewError(domain.CodeInferenceFailed, "The model could not produce a prediction.", fmt.Errorf("model inference error: %w", err))
 	}
 
 	// Extract the prediction from the output tensor
 	outputData := outputTensor.GetData()
 	if len(outputData) == 0 {
-		return nil, fmt.Errorf("model produced no output")
+		return nil, domain.NewError(domain.CodeInferenceFailed, "The model could not produce a prediction.", fmt.Errorf("model produced no output"))
 	}
 
-	// The first (and only) value in the output tensor is the predicted price
-	predictedPrice := float32(outputData[0])
+	// The first (and only) value in the output tensor is the predicted price,
+	// possibly on the scale the model was trained on
+	s.mu.RLock()
+	target := s.target
+	s.mu.RUnlock()
+	predictedPrice := float32(target.Price(float64(outputData[0])))
 
 	return &domain.PredictionResult{
 		PredictedPrice: predictedPrice,
 	}, nil
-}
\ No newline at end of file
+}
@@ -1,12 +1,15 @@
 package prediction_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"car-price-prediction/internal/domain"
 	"car-price-prediction/internal/prediction"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockSession is a mock implementation of the onnxruntime_go.AdvancedSession
@@ -187,4 +190,33 @@ func TestFeatureMap(t *testing.T) {
 	
 	// Verify the array has the correct length
 	assert.Equal(t, 64, len(features), "Features array should have length 64")
-}
\ No newline at end of file
+}
+
+func TestModel_VersionIsContentHash(t *testing.T) {
+	service := prediction.NewPredictionService("../../model/best_model.onnx")
+
+	info := service.Model()
+	assert.Equal(t, "238dbbdd6d08", info.Version)
+	assert.Len(t, info.SHA256, 64)
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.onnx")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0o600))
+	service := prediction.NewPredictionService(path)
+	v1 := service.Model().Version
+
+	require.NoError(t, os.WriteFile(path, []byte("v2"), 0o600))
+	info, err := service.Reload()
+	require.NoError(t, err)
+	assert.NotEqual(t, v1, info.Version)
+	assert.Equal(t, info, service.Model())
+
+	// A failed reload keeps the current model
+	require.NoError(t, os.Remove(path))
+	_, err = service.Reload()
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeModelUnavailable, derr.Code)
+	assert.Equal(t, info, service.Model())
+}
@@ -0,0 +1,217 @@
+package prediction
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Transforms of the price a model can be trained to predict.
+const (
+	TargetIdentity = "identity"
+	TargetLog      = "log"
+	TargetLog1p    = "log1p"
+	TargetBoxCox   = "boxcox"
+)
+
+// Corrections of the bias of retransformed predictions. The inverse of a log
+// transform gives the median price rather than the mean, which is lower by a
+// factor that grows with the spread of the residuals.
+const (
+	// BiasLognormal assumes normally distributed residuals on the transformed
+	// scale with the variance given in ResidualVariance.
+	BiasLognormal = "lognormal"
+	// BiasSmearing multiplies by Duan's smearing factor, the mean of exp(residual)
+	// on the training data. It needs no assumption about the residuals.
+	BiasSmearing = "smearing"
+)
+
+// TargetTransformKey is the ONNX metadata property holding the target transform
+// of a model as JSON, e.g. {"kind": "log", "bias_correction": "smearing", "smearing_factor": 1.012}.
+const TargetTransformKey = "target_transform"
+
+// TargetTransform is the transform of the price a model was trained on, whose
+// inverse turns the model's output into a price.
+type TargetTransform struct {
+	// Kind is "identity", "log", "log1p" or "boxcox". Empty means identity.
+	Kind string `json:"kind"`
+	// Lambda is the parameter of the Box-Cox transform.
+	Lambda float64 `json:"lambda,omitempty"`
+	// BiasCorrection is empty, "lognormal" or "smearing".
+	BiasCorrection string `json:"bias_correction,omitempty"`
+	// ResidualVariance is the variance of the training residuals on the
+	// transformed scale, for the lognormal correction.
+	ResidualVariance float64 `json:"residual_variance,omitempty"`
+	// SmearingFactor is the mean of exp(residual) over the training data, for the
+	// smearing correction.
+	SmearingFactor float64 `json:"smearing_factor,omitempty"`
+}
+
+// Validate checks that the transform is known and has the parameters its bias
+// correction needs.
+func (t TargetTransform) Validate() error {
+	switch t.Kind {
+	case "", TargetIdentity:
+		if t.BiasCorrection != "" {
+			return errors.New("the identity target transform needs no bias correction")
+		}
+	case TargetLog, TargetLog1p, TargetBoxCox:
+	default:
+		return fmt.Errorf("unknown target transform %q, want identity, log, log1p or boxcox", t.Kind)
+	}
+	if t.Lambda != 0 && t.Kind != TargetBoxCox {
+		return fmt.Errorf("lambda is only used by the boxcox transform, not %s", t.Kind)
+	}
+
+	switch t.BiasCorrection {
+	case "":
+	case BiasLognormal:
+		if t.ResidualVariance <= 0 {
+			return errors.New("the lognormal bias correction needs a positive residual_variance")
+		}
+	case BiasSmearing:
+		if t.Kind == TargetBoxCox && t.Lambda != 0 {
+			return errors.New("the smearing bias correction needs a log or log1p transform")
+		}
+		if t.SmearingFactor <= 0 {
+			return errors.New("the smearing bias correction needs a positive smearing_factor")
+		}
+	default:
+		return fmt.Errorf("unknown bias correction %q, want lognormal or smearing", t.BiasCorrection)
+	}
+	return nil
+}
+
+// Inverse returns the price a model output stands for, without bias correction.
+// For log transforms this is the median rather than the mean price.
+func (t TargetTransform) Inverse(output float64) float64 {
+	switch t.Kind {
+	case TargetLog:
+		return math.Exp(output)
+	case TargetLog1p:
+		return math.Expm1(output)
+	case TargetBoxCox:
+		if t.Lambda == 0 {
+			return math.Exp(output)
+		}
+		base := t.Lambda*output + 1
+		if base <= 0 {
+			return 0
+		}
+		return math.Pow(base, 1/t.Lambda)
+	default:
+		return output
+	}
+}
+
+// Price returns the mean price a model output stands for: its inverse, corrected
+// for bias if the transform asks for it. The Box-Cox correction is the usual
+// second-order approximation, exact for lambda 0.
+func (t TargetTransform) Price(output float64) float64 {
+	price := t.Inverse(output)
+	switch t.BiasCorrection {
+	case BiasLognormal:
+		if t.Kind == TargetBoxCox && t.Lambda != 0 {
+			base := t.Lambda*output + 1
+			return price * (1 + t.ResidualVariance*(1-t.Lambda)/(2*base*base))
+		}
+		factor := math.Exp(t.ResidualVariance / 2)
+		if t.Kind == TargetLog1p {
+			return (price+1)*factor - 1
+		}
+		return price * factor
+	case BiasSmearing:
+		if t.Kind == TargetLog1p {
+			return (price+1)*t.SmearingFactor - 1
+		}
+		return price * t.SmearingFactor
+	default:
+		return price
+	}
+}
+
+// metadataPropsField is the field number of metadata_props in ONNX's ModelProto.
+const metadataPropsField = 14
+
+// loadTargetTransform reads the target transform from the metadata of the ONNX
+// model at path. Models without the property predict prices directly.
+func loadTargetTransform(path string) (TargetTransform, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return TargetTransform{}, fmt.Errorf("failed to open model: %w", err)
+	}
+	var t TargetTransform
+	value, ok := onnxMetadata(data)[TargetTransformKey]
+	if !ok {
+		return t, nil
+	}
+	if err := json.Unmarshal([]byte(value), &t); err != nil {
+		return TargetTransform{}, fmt.Errorf("invalid %s metadata: %w", TargetTransformKey, err)
+	}
+	if err := t.Validate(); err != nil {
+		return TargetTransform{}, fmt.Errorf("invalid %s metadata: %w", TargetTransformKey, err)
+	}
+	return t, nil
+}
+
+// onnxMetadata returns the metadata properties of a serialized ONNX model. Only the
+// top-level fields of the model are decoded, so that large graphs are skipped
+// rather than parsed; data that is not a model has no properties.
+func onnxMetadata(data []byte) map[string]string {
+	props := map[string]string{}
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			break
+		}
+		data = data[n:]
+		if num == metadataPropsField && typ == protowire.BytesType {
+			entry, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				break
+			}
+			key, value := stringEntry(entry)
+			props[key] = value
+			data = data[n:]
+			continue
+		}
+		if n = protowire.ConsumeFieldValue(num, typ, data); n < 0 {
+			break
+		}
+		data = data[n:]
+	}
+	return props
+}
+
+// stringEntry decodes the key (field 1) and value (field 2) of a StringStringEntryProto.
+func stringEntry(data []byte) (key, value string) {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			break
+		}
+		data = data[n:]
+		if typ == protowire.BytesType && (num == 1 || num == 2) {
+			b, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				break
+			}
+			if num == 1 {
+				key = string(b)
+			} else {
+				value = string(b)
+			}
+			data = data[n:]
+			continue
+		}
+		if n = protowire.ConsumeFieldValue(num, typ, data); n < 0 {
+			break
+		}
+		data = data[n:]
+	}
+	return key, value
+}
@@ -0,0 +1,104 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/onnxmodel/onnxpb"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTargetTransform_Price(t *testing.T) {
+	tests := []struct {
+		name   string
+		target TargetTransform
+		output float64
+		median float64
+		mean   float64
+	}{
+		{"none", TargetTransform{}, 10000, 10000, 10000},
+		{"identity", TargetTransform{Kind: TargetIdentity}, 10000, 10000, 10000},
+		{"log", TargetTransform{Kind: TargetLog}, math.Log(10000), 10000, 10000},
+		{"log lognormal", TargetTransform{Kind: TargetLog, BiasCorrection: BiasLognormal, ResidualVariance: 0.04}, math.Log(10000), 10000, 10202.01},
+		{"log smearing", TargetTransform{Kind: TargetLog, BiasCorrection: BiasSmearing, SmearingFactor: 1.05}, math.Log(10000), 10000, 10500},
+		{"log1p smearing", TargetTransform{Kind: TargetLog1p, BiasCorrection: BiasSmearing, SmearingFactor: 1.05}, math.Log1p(9999), 9999, 10499},
+		{"boxcox", TargetTransform{Kind: TargetBoxCox, Lambda: 0.5}, 198, 10000, 10000},
+		{"boxcox lognormal", TargetTransform{Kind: TargetBoxCox, Lambda: 0.5, BiasCorrection: BiasLognormal, ResidualVariance: 0.04}, 198, 10000, 10000.01},
+		{"boxcox lambda 0", TargetTransform{Kind: TargetBoxCox, BiasCorrection: BiasLognormal, ResidualVariance: 0.04}, math.Log(10000), 10000, 10202.01},
+		{"boxcox out of range", TargetTransform{Kind: TargetBoxCox, Lambda: 0.5}, -3, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.target.Validate())
+			assert.InDelta(t, tt.median, tt.target.Inverse(tt.output), 0.005)
+			assert.InDelta(t, tt.mean, tt.target.Price(tt.output), 0.005)
+		})
+	}
+}
+
+func TestTargetTransform_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		target TargetTransform
+		err    string
+	}{
+		{"unknown kind", TargetTransform{Kind: "sqrt"}, `unknown target transform "sqrt"`},
+		{"lambda without boxcox", TargetTransform{Kind: TargetLog, Lambda: 0.5}, "lambda is only used by the boxcox transform"},
+		{"identity correction", TargetTransform{BiasCorrection: BiasSmearing, SmearingFactor: 1.1}, "needs no bias correction"},
+		{"no variance", TargetTransform{Kind: TargetLog, BiasCorrection: BiasLognormal}, "positive residual_variance"},
+		{"no smearing factor", TargetTransform{Kind: TargetLog1p, BiasCorrection: BiasSmearing}, "positive smearing_factor"},
+		{"boxcox smearing", TargetTransform{Kind: TargetBoxCox, Lambda: 0.3, BiasCorrection: BiasSmearing, SmearingFactor: 1.1}, "needs a log or log1p transform"},
+		{"unknown correction", TargetTransform{Kind: TargetLog, BiasCorrection: "duan"}, `unknown bias correction "duan"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.target.Validate(), tt.err)
+		})
+	}
+}
+
+// writeONNXMetadata writes a model with a graph and the given metadata properties to path.
+func writeONNXMetadata(t *testing.T, path string, props map[string]string) {
+	m := &onnxpb.ModelProto{
+		IrVersion: proto.Int64(10),
+		Graph:     &onnxpb.GraphProto{Name: proto.String("model"), Node: []*onnxpb.NodeProto{{OpType: proto.String("Identity")}}},
+	}
+	for key, value := range props {
+		m.MetadataProps = append(m.MetadataProps, &onnxpb.StringStringEntryProto{Key: proto.String(key), Value: proto.String(value)})
+	}
+	data, err := proto.Marshal(m)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+}
+
+func TestPredictionService_TargetTransform(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.onnx")
+	writeONNXMetadata(t, path, map[string]string{
+		"feature_schema":   "horsepower",
+		TargetTransformKey: `{"kind": "log", "bias_correction": "smearing", "smearing_factor": 1.02}`,
+	})
+	service := NewPredictionService(path)
+	assert.NotEmpty(t, service.Model().Version)
+	assert.Equal(t, TargetTransform{Kind: TargetLog, BiasCorrection: BiasSmearing, SmearingFactor: 1.02}, service.target)
+
+	// A model without the property predicts prices
+	writeONNXMetadata(t, path, nil)
+	_, err := service.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, TargetTransform{}, service.target)
+
+	// An invalid transform is not loaded
+	writeONNXMetadata(t, path, map[string]string{TargetTransformKey: `{"kind": "log10"}`})
+	_, err = service.Reload()
+	assert.ErrorContains(t, err, "invalid target_transform metadata")
+	assert.Empty(t, NewPredictionService(path).Model().Version)
+}
+
+func TestONNXMetadata_NotAModel(t *testing.T) {
+	assert.Empty(t, onnxMetadata([]byte("v1")))
+	assert.Empty(t, onnxMetadata(nil))
+}
@@ -0,0 +1,148 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// baselineCategories holds the category of each categorical feature that was dropped
+// by one-hot encoding (drop_first=True). It is valid input but has no model column.
+var baselineCategories = map[string]string{
+	"fueltype":       "diesel",
+	"aspiration":     "std",
+	"doornumber":     "four",
+	"carbody":        "convertible",
+	"drivewheel":     "4wd",
+	"enginelocation": "front",
+	"enginetype":     "dohc",
+	"cylindernumber": "eight",
+	"fuelsystem":     "1bbl",
+	"brand":          "alfa-romero",
+}
+
+// numericRange is the inclusive range of plausible values for a numerical feature.
+type numericRange struct {
+	min, max float64
+}
+
+// numericRanges bounds each numerical feature. The bounds are deliberately wider than
+// the training data and only reject values that cannot describe a real car.
+var numericRanges = map[string]numericRange{
+	"symboling":        {-3, 3},
+	"wheelbase":        {60, 150},
+	"carlength":        {100, 260},
+	"carwidth":         {50, 90},
+	"carheight":        {40, 75},
+	"curbweight":       {1000, 6000},
+	"enginesize":       {40, 400},
+	"boreratio":        {2, 5},
+	"stroke":           {1.5, 5},
+	"compressionratio": {5, 30},
+	"horsepower":       {30, 500},
+	"peakrpm":          {3000, 8000},
+	"citympg":          {5, 70},
+	"highwaympg":       {5, 80},
+}
+
+// Validate checks that every categorical value is known to the model and that every
+// numerical value is within its plausible range. It returns a *domain.Error listing
+// all violations, or nil if the input is valid. Categorical values are compared after
+// normalization, so case and known aliases are accepted.
+func Validate(input domain.UserInput) error {
+	input = Normalize(input)
+	var violations []domain.Violation
+
+	numerical := []struct {
+		name  string
+		value float64
+	}{
+		{"symboling", float64(input.Symboling)},
+		{"wheelbase", float64(input.Wheelbase)},
+		{"carlength", float64(input.Carlength)},
+		{"carwidth", float64(input.Carwidth)},
+		{"carheight", float64(input.Carheight)},
+		{"curbweight", float64(input.Curbweight)},
+		{"enginesize", float64(input.Enginesize)},
+		{"boreratio", float64(input.Boreratio)},
+		{"stroke", float64(input.Stroke)},
+		{"compressionratio", float64(input.Compressionratio)},
+		{"horsepower", float64(input.Horsepower)},
+		{"peakrpm", float64(input.Peakrpm)},
+		{"citympg", float64(input.Citympg)},
+		{"highwaympg", float64(input.Highwaympg)},
+	}
+	for _, f := range numerical {
+		r := numericRanges[f.name]
+		if f.value < r.min || f.value > r.max {
+			violations = append(violations, domain.Violation{
+				Field:   f.name,
+				Code:    domain.CodeOutOfRange,
+				Message: fmt.Sprintf("must be between %g and %g", r.min, r.max),
+			})
+		}
+	}
+
+	categorical := []struct {
+		name  string
+		value string
+	}{
+		{"fueltype", input.Fueltype},
+		{"aspiration", input.Aspiration},
+		{"doornumber", input.Doornumber},
+		{"carbody", input.Carbody},
+		{"drivewheel", input.Drivewheel},
+		{"enginelocation", input.Enginelocation},
+		{"enginetype", input.Enginetype},
+		{"cylindernumber", input.Cylindernumber},
+		{"fuelsystem", input.Fuelsystem},
+		{"brand", input.Brand},
+	}
+	for _, f := range categorical {
+		if !isKnownCategory(f.name, f.value) {
+			violations = append(violations, domain.Violation{
+				Field:   f.name,
+				Code:    domain.CodeUnknownCategory,
+				Message: "must be one of: " + strings.Join(Categories(f.name), ", "),
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return domain.NewValidationError(violations)
+	}
+	return nil
+}
+
+// isKnownCategory reports whether the normalized value is a category the model was trained on.
+func isKnownCategory(feature, value string) bool {
+	if baselineCategories[feature] == value {
+		return true
+	}
+	_, exists := featureIndexMap[feature+"_"+value]
+	return exists
+}
+
+// Categories returns the sorted list of known values for a categorical feature.
+func Categories(feature string) []string {
+	var values []string
+	if baseline, ok := baselineCategories[feature]; ok {
+		values = append(values, baseline)
+	}
+	prefix := feature + "_"
+	for key := range featureIndexMap {
+		if strings.HasPrefix(key, prefix) {
+			values = append(values, strings.TrimPrefix(key, prefix))
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// Range returns the inclusive range of plausible values of a numerical feature,
+// as enforced by Validate.
+func Range(feature string) (lo, hi float64, ok bool) {
+	r, ok := numericRanges[feature]
+	return r.min, r.max, ok
+}
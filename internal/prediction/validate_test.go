@@ -0,0 +1,67 @@
+package prediction
+
+import (
+	"car-price-prediction/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate_ReferenceInput(t *testing.T) {
+	assert.NoError(t, Validate(referenceInput))
+}
+
+func TestValidate_AcceptsBaselineCategories(t *testing.T) {
+	input := referenceInput
+	input.Fueltype = "diesel"
+	input.Carbody = "convertible"
+	input.Brand = "Alfa-Romero"
+
+	assert.NoError(t, Validate(input))
+}
+
+func TestValidate_UnknownCategory(t *testing.T) {
+	input := referenceInput
+	input.Brand = "tesla"
+
+	err := Validate(input)
+
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeUnknownCategory, derr.Code)
+	require.Len(t, derr.Violations, 1)
+	assert.Equal(t, "brand", derr.Violations[0].Field)
+	assert.Contains(t, derr.Violations[0].Message, "alfa-romero")
+}
+
+func TestValidate_OutOfRangeAndUnknownCategory(t *testing.T) {
+	input := referenceInput
+	input.Horsepower = 2000
+	input.Fueltype = "electric"
+
+	err := Validate(input)
+
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeValidationFailed, derr.Code)
+	assert.Equal(t, []domain.Violation{
+		{Field: "horsepower", Code: domain.CodeOutOfRange, Message: "must be between 30 and 500"},
+		{Field: "fueltype", Code: domain.CodeUnknownCategory, Message: "must be one of: diesel, gas"},
+	}, derr.Violations)
+}
+
+func TestCategories(t *testing.T) {
+	assert.Equal(t, []string{"4wd", "fwd", "rwd"}, Categories("drivewheel"))
+	assert.Equal(t, []string{"front", "rear"}, Categories("enginelocation"))
+}
+
+func TestRange(t *testing.T) {
+	lo, hi, ok := Range("wheelbase")
+	assert.True(t, ok)
+	assert.Equal(t, 60.0, lo)
+	assert.Equal(t, 150.0, hi)
+
+	_, _, ok = Range("brand")
+	assert.False(t, ok)
+}
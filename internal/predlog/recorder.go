@@ -0,0 +1,197 @@
+package predlog
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// Record is a stored prediction with everything needed to reproduce it. Features
+// is the feature vector the model predicted from, in the model's own schema.
+type Record struct {
+	ID             string           `json:"id"`
+	RequestID      string           `json:"request_id,omitempty"`
+	Time           time.Time        `json:"time"`
+	Subject        string           `json:"subject,omitempty"`
+	Input          domain.UserInput `json:"input"`
+	Features       []float32        `json:"features"`
+	ModelVersion   string           `json:"model_version"`
+	ModelSHA256    string           `json:"model_sha256"`
+	PredictedPrice float32          `json:"predicted_price"`
+	LatencyMicros  int64            `json:"latency_us"`
+}
+
+// Prediction is a completed prediction to be recorded.
+type Prediction struct {
+	RequestID string
+	Subject   string
+	Input     domain.UserInput
+	Result    *domain.PredictionResult
+	Latency   time.Duration
+	// Model is the model that made the prediction, if not the recorder's model.
+	Model domain.ModelManager
+}
+
+// ModelArchive keeps a copy of every model that served a recorded prediction.
+type ModelArchive interface {
+	// Save archives the model described by info unless it is already archived.
+	Save(info domain.ModelInfo) error
+	// Open returns a prediction service for the archived model with the given hash.
+	Open(sha256 string) (domain.PredictionService, error)
+}
+
+// Replay is the outcome of predicting a recorded input again with the recorded model.
+type Replay struct {
+	ModelVersion string `json:"model_version"`
+	// PredictedPrice is the replayed prediction. It is omitted if the replay failed.
+	PredictedPrice *float32 `json:"predicted_price,omitempty"`
+	// Identical reports whether the replayed price equals the recorded one bit for bit.
+	Identical bool `json:"identical"`
+	// FeaturesIdentical reports whether the recorded model's preprocessing still
+	// encodes the input into the recorded feature vector.
+	FeaturesIdentical bool `json:"features_identical"`
+	// Error explains why the replay failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Recorder records predictions in a Store, archiving the serving model alongside.
+type Recorder struct {
+	store   *Store
+	models  domain.ModelManager
+	archive ModelArchive
+	now     func() time.Time
+}
+
+// NewRecorder creates a recorder that stores predictions of the model described by
+// models, which should be the model itself rather than a cache in front of it, so
+// that the feature vectors it predicts from are recorded. archive may be nil, in
+// which case recorded predictions cannot be replayed.
+func NewRecorder(store *Store, models domain.ModelManager, archive ModelArchive) *Recorder {
+	return &Recorder{store: store, models: models, archive: archive, now: time.Now}
+}
+
+// Record stores p and returns its prediction ID. It returns a STORAGE_UNAVAILABLE
+// *domain.Error if the record could not be written; the prediction must then not be
+// handed out, as it could not be reproduced. A nil recorder records nothing.
+func (r *Recorder) Record(p Prediction) (string, error) {
+	if r == nil {
+		return "", nil
+	}
+	id, err := newID()
+	if err != nil {
+		return "", domain.NewError(domain.CodeStorageUnavailable, "The prediction could not be recorded.", err)
+	}
+	models := r.models
+	if p.Model != nil {
+		models = p.Model
+	}
+	features, err := encode(models, p.Input)
+	if err != nil {
+		return "", domain.NewError(domain.CodeStorageUnavailable, "The prediction could not be recorded.", err)
+	}
+	info := models.Model()
+	if r.archive != nil {
+		if err := r.archive.Save(info); err != nil {
+			return "", domain.NewError(domain.CodeStorageUnavailable, "The prediction could not be recorded.", fmt.Errorf("failed to archive model: %w", err))
+		}
+	}
+
+	rec := Record{
+		ID:             id,
+		RequestID:      p.RequestID,
+		Time:           r.now().UTC(),
+		Subject:        p.Subject,
+		Input:          p.Input,
+		Features:       features,
+		ModelVersion:   info.Version,
+		ModelSHA256:    info.SHA256,
+		PredictedPrice: p.Result.PredictedPrice,
+		LatencyMicros:  p.Latency.Microseconds(),
+	}
+	if err := r.store.Append(rec); err != nil {
+		return "", domain.NewError(domain.CodeStorageUnavailable, "The prediction could not be recorded.", err)
+	}
+	return id, nil
+}
+
+// Lookup returns the record with the given ID. It returns a NOT_FOUND *domain.Error
+// for unknown or expired IDs.
+func (r *Recorder) Lookup(id string) (*Record, error) {
+	if !validID(id) {
+		return nil, domain.NewError(domain.CodeNotFound, "The prediction does not exist or has expired.", nil)
+	}
+	rec, err := r.store.Lookup(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, domain.NewError(domain.CodeNotFound, "The prediction does not exist or has expired.", nil)
+	}
+	if err != nil {
+		return nil, domain.NewError(domain.CodeStorageUnavailable, "The prediction could not be read.", err)
+	}
+	return rec, nil
+}
+
+// Replay predicts the recorded input again with the recorded model version and
+// compares the outcome with the record. Failures are reported in the result.
+func (r *Recorder) Replay(rec *Record) Replay {
+	replay := Replay{ModelVersion: rec.ModelVersion}
+	if r.archive == nil {
+		replay.Error = "models are not archived"
+		return replay
+	}
+	service, err := r.archive.Open(rec.ModelSHA256)
+	if err != nil {
+		replay.Error = fmt.Sprintf("model %s is not available: %v", rec.ModelVersion, err)
+		return replay
+	}
+	if features, err := encode(service, rec.Input); err == nil {
+		replay.FeaturesIdentical = slices.Equal(features, rec.Features)
+	}
+	result, err := service.Predict(rec.Input)
+	if err != nil {
+		replay.Error = fmt.Sprintf("replay failed: %v", err)
+		return replay
+	}
+	replay.PredictedPrice = &result.PredictedPrice
+	replay.Identical = math.Float32bits(result.PredictedPrice) == math.Float32bits(rec.PredictedPrice)
+	return replay
+}
+
+// encode returns the feature vector model predicts input from. Models that do not
+// encode inputs themselves are taken to read the served schema, like the ONNX
+// models served by onnxruntime.
+func encode(model any, input domain.UserInput) ([]float32, error) {
+	if encoder, ok := model.(prediction.FeatureEncoder); ok {
+		return encoder.EncodeFeatures(input)
+	}
+	return prediction.Transform(input)
+}
+
+// newID returns a random 128-bit prediction ID in hex.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate prediction id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// contextKey is the type of context keys defined in this package.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the recorder.
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the recorder stored in ctx, or nil if predictions are not recorded.
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(contextKey{}).(*Recorder)
+	return r
+}
@@ -0,0 +1,171 @@
+package predlog
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeModel serves a fixed price and reports a fixed model version.
+type fakeModel struct {
+	price float32
+	info  domain.ModelInfo
+}
+
+func (m *fakeModel) Predict(domain.UserInput) (*domain.PredictionResult, error) {
+	return &domain.PredictionResult{PredictedPrice: m.price}, nil
+}
+
+func (m *fakeModel) Model() domain.ModelInfo { return m.info }
+
+func (m *fakeModel) Reload() (domain.ModelInfo, error) { return m.info, nil }
+
+// fakeArchive keeps models in memory.
+type fakeArchive struct {
+	models  map[string]domain.PredictionService
+	saveErr error
+}
+
+func (a *fakeArchive) Save(domain.ModelInfo) error { return a.saveErr }
+
+func (a *fakeArchive) Open(sum string) (domain.PredictionService, error) {
+	if m, ok := a.models[sum]; ok {
+		return m, nil
+	}
+	return nil, errors.New("not archived")
+}
+
+// validInput returns an input accepted by the preprocessing.
+func validInput() domain.UserInput {
+	return domain.UserInput{
+		Wheelbase: 88.6, Carlength: 168.8, Carwidth: 64.1, Carheight: 48.8,
+		Curbweight: 2548, Enginesize: 130, Boreratio: 3.47, Stroke: 2.68,
+		Compressionratio: 9, Horsepower: 111, Peakrpm: 5000, Citympg: 21, Highwaympg: 27,
+		Fueltype: "gas", Aspiration: "std", Doornumber: "two", Carbody: "convertible",
+		Drivewheel: "rwd", Enginelocation: "front", Enginetype: "dohc",
+		Cylindernumber: "four", Fuelsystem: "mpfi", Brand: "alfa-romero",
+	}
+}
+
+func newTestRecorder(t *testing.T, archive *fakeArchive) (*Recorder, *fakeModel) {
+	store := openTestStore(t, t.TempDir(), Options{})
+	model := &fakeModel{price: 13495.5, info: domain.ModelInfo{Version: "aaaaaaaaaaaa", SHA256: "aaaa"}}
+	archive.models = map[string]domain.PredictionService{"aaaa": model}
+	r := NewRecorder(store, model, archive)
+	r.now = func() time.Time { return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC) }
+	return r, model
+}
+
+func TestRecorder_RecordLookupReplay(t *testing.T) {
+	r, model := newTestRecorder(t, &fakeArchive{})
+
+	id, err := r.Record(Prediction{
+		RequestID: "req-1",
+		Subject:   "3f9a1c2b7d4e",
+		Input:     validInput(),
+		Result:    &domain.PredictionResult{PredictedPrice: model.price},
+		Latency:   1500 * time.Microsecond,
+	})
+	require.NoError(t, err)
+	assert.Len(t, id, 32)
+
+	rec, err := r.Lookup(id)
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", rec.RequestID)
+	assert.Equal(t, "aaaaaaaaaaaa", rec.ModelVersion)
+	assert.Equal(t, int64(1500), rec.LatencyMicros)
+	assert.Len(t, rec.Features, 64)
+
+	replay := r.Replay(rec)
+	assert.True(t, replay.Identical)
+	assert.True(t, replay.FeaturesIdentical)
+	require.NotNil(t, replay.PredictedPrice)
+	assert.Equal(t, model.price, *replay.PredictedPrice)
+
+	// A different outcome is reported, not hidden
+	model.price++
+	replay = r.Replay(rec)
+	assert.False(t, replay.Identical)
+	assert.Empty(t, replay.Error)
+}
+
+func TestRecorder_ReplayWithoutArchivedModel(t *testing.T) {
+	r, model := newTestRecorder(t, &fakeArchive{})
+	id, err := r.Record(Prediction{Input: validInput(), Result: &domain.PredictionResult{PredictedPrice: model.price}})
+	require.NoError(t, err)
+	rec, err := r.Lookup(id)
+	require.NoError(t, err)
+
+	rec.ModelSHA256 = "bbbb"
+	replay := r.Replay(rec)
+	assert.False(t, replay.Identical)
+	assert.Nil(t, replay.PredictedPrice)
+	assert.NotEmpty(t, replay.Error)
+}
+
+func TestRecorder_ArchiveFailure(t *testing.T) {
+	r, model := newTestRecorder(t, &fakeArchive{saveErr: errors.New("disk full")})
+
+	_, err := r.Record(Prediction{Input: validInput(), Result: &domain.PredictionResult{PredictedPrice: model.price}})
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeStorageUnavailable, derr.Code)
+}
+
+func TestRecorder_LookupUnknown(t *testing.T) {
+	r, _ := newTestRecorder(t, &fakeArchive{})
+
+	for _, id := range []string{"0123456789abcdef", "../segment", ""} {
+		_, err := r.Lookup(id)
+		var derr *domain.Error
+		require.ErrorAs(t, err, &derr, id)
+		assert.Equal(t, domain.CodeNotFound, derr.Code, id)
+	}
+}
+
+func TestRecorder_NilRecordsNothing(t *testing.T) {
+	r := FromContext(context.Background())
+
+	id, err := r.Record(Prediction{Input: validInput()})
+	assert.NoError(t, err)
+	assert.Empty(t, id)
+}
+
+func TestRecorder_RecordsModelEncoding(t *testing.T) {
+	// A bundle with its own schema and preprocessing
+	bundle := &prediction.Bundle{
+		Format:   prediction.BundleFormat,
+		Kind:     prediction.KindLinear,
+		Features: []string{"horsepower", "power_to_weight"},
+		Preprocessing: []prediction.PreprocessingStep{
+			{Op: prediction.StepRatio, Output: "power_to_weight", Numerator: "horsepower", Denominator: "curbweight"},
+			{Op: prediction.StepStandardScale, Mean: map[string]float64{"horsepower": 100}, Std: map[string]float64{"horsepower": 10}},
+		},
+		Linear: &prediction.LinearModel{Intercept: 10000, Coefficients: map[string]float64{"horsepower": 1000}},
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ridge.json")
+	require.NoError(t, bundle.WriteFile(path))
+	model := prediction.Open(path)
+	r := NewRecorder(openTestStore(t, filepath.Join(dir, "log"), Options{}), model, prediction.NewArchive(filepath.Join(dir, "models")))
+
+	result, err := model.Predict(validInput())
+	require.NoError(t, err)
+	id, err := r.Record(Prediction{Input: validInput(), Result: result})
+	require.NoError(t, err)
+	rec, err := r.Lookup(id)
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1.1, float32(111.0 / 2548)}, rec.Features)
+
+	replay := r.Replay(rec)
+	assert.Empty(t, replay.Error)
+	assert.True(t, replay.Identical)
+	assert.True(t, replay.FeaturesIdentical)
+}
@@ -0,0 +1,370 @@
+// Package predlog records every prediction in a durable, append-only store so that
+// any quote can be looked up and reproduced later.
+package predlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default store limits.
+const (
+	DefaultMaxSegmentBytes   = 64 << 20
+	DefaultRetentionInterval = time.Hour
+)
+
+// segmentPattern names segment files by sequence number, so that lexical order is
+// append order.
+const segmentPattern = "segment-%020d.log"
+
+// ErrNotFound is returned by Lookup for unknown or expired record IDs.
+var ErrNotFound = errors.New("prediction record not found")
+
+// Options configures a Store.
+type Options struct {
+	// MaxSegmentBytes is the size after which a new segment file is started.
+	MaxSegmentBytes int64
+	// Retention deletes segments whose newest record is older than this. Zero keeps
+	// records forever.
+	Retention time.Duration
+	// MaxTotalBytes deletes the oldest segments while the store is larger. Zero means
+	// unlimited.
+	MaxTotalBytes int64
+	// RetentionInterval is how often retention is applied. Defaults to an hour.
+	RetentionInterval time.Duration
+}
+
+// Store is an append-only log of prediction records, split into segment files of
+// JSON lines. Every append is synced to disk before it returns. Records are found
+// through an in-memory index rebuilt when the store is opened. Retention deletes
+// whole segments, never the segment being written.
+type Store struct {
+	dir  string
+	opts Options
+	now  func() time.Time
+
+	mu       sync.Mutex
+	segments []*segment // oldest first; the last one is active
+	active   *os.File
+	index    map[string]location
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// segment describes a segment file.
+type segment struct {
+	seq    uint64
+	size   int64
+	oldest time.Time
+	newest time.Time
+	ids    []string
+}
+
+// location is where a record is stored.
+type location struct {
+	seg    *segment
+	offset int64
+	length int
+}
+
+// Open opens the store in dir, creating it if necessary, and starts applying the
+// retention policy in the background. Close must be called to stop it.
+func Open(dir string, opts Options) (*Store, error) {
+	if opts.MaxSegmentBytes <= 0 {
+		opts.MaxSegmentBytes = DefaultMaxSegmentBytes
+	}
+	if opts.RetentionInterval <= 0 {
+		opts.RetentionInterval = DefaultRetentionInterval
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create prediction log directory: %w", err)
+	}
+
+	s := &Store{dir: dir, opts: opts, now: time.Now, index: map[string]location{}}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.openActive(); err != nil {
+		return nil, err
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.retentionLoop()
+	return s, nil
+}
+
+// load scans the existing segments and rebuilds the index.
+func (s *Store) load() error {
+	names, err := filepath.Glob(filepath.Join(s.dir, "segment-*.log"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		var seq uint64
+		if _, err := fmt.Sscanf(filepath.Base(name), segmentPattern, &seq); err != nil {
+			return fmt.Errorf("unexpected file %s in prediction log directory", name)
+		}
+		seg := &segment{seq: seq}
+		if err := s.scan(name, seg, i == len(names)-1); err != nil {
+			return err
+		}
+		s.segments = append(s.segments, seg)
+	}
+	return nil
+}
+
+// scan indexes the records of a segment file. A torn record at the end of the last
+// segment, left by a crash during an append, is truncated.
+func (s *Store) scan(path string, seg *segment, last bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open prediction log segment: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var offset int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			break
+		}
+		var rec Record
+		if err != nil || json.Unmarshal(line, &rec) != nil {
+			if last && (err != nil || isTail(r)) {
+				log.Printf("Truncating torn prediction record at %s:%d", path, offset)
+				seg.size = offset
+				return os.Truncate(path, offset)
+			}
+			return fmt.Errorf("corrupt prediction record at %s:%d", path, offset)
+		}
+		s.indexLocked(seg, rec, offset, len(line))
+		offset += int64(len(line))
+	}
+	seg.size = offset
+	return nil
+}
+
+// isTail reports whether r has no more data.
+func isTail(r *bufio.Reader) bool {
+	_, err := r.Peek(1)
+	return errors.Is(err, io.EOF)
+}
+
+// indexLocked adds a record to the index.
+func (s *Store) indexLocked(seg *segment, rec Record, offset int64, length int) {
+	s.index[rec.ID] = location{seg: seg, offset: offset, length: length}
+	seg.ids = append(seg.ids, rec.ID)
+	if seg.oldest.IsZero() || rec.Time.Before(seg.oldest) {
+		seg.oldest = rec.Time
+	}
+	if rec.Time.After(seg.newest) {
+		seg.newest = rec.Time
+	}
+}
+
+// openActive opens the newest segment for appending, creating the first one if needed.
+func (s *Store) openActive() error {
+	if len(s.segments) == 0 {
+		s.segments = append(s.segments, &segment{seq: 1})
+	}
+	seg := s.segments[len(s.segments)-1]
+	f, err := os.OpenFile(s.path(seg), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open prediction log segment: %w", err)
+	}
+	s.active = f
+	return nil
+}
+
+// path returns the file name of a segment.
+func (s *Store) path(seg *segment) string {
+	return filepath.Join(s.dir, fmt.Sprintf(segmentPattern, seg.seq))
+}
+
+// Append writes rec to the store and syncs it to disk.
+func (s *Store) Append(rec Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode prediction record: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active == nil {
+		return errors.New("prediction log is closed")
+	}
+	if _, exists := s.index[rec.ID]; exists {
+		return fmt.Errorf("duplicate prediction record %q", rec.ID)
+	}
+	seg := s.segments[len(s.segments)-1]
+	if seg.size > 0 && seg.size+int64(len(data)) > s.opts.MaxSegmentBytes {
+		if err := s.rotateLocked(); err != nil {
+			return err
+		}
+		seg = s.segments[len(s.segments)-1]
+	}
+
+	if _, err := s.active.Write(data); err != nil {
+		return fmt.Errorf("failed to write prediction record: %w", err)
+	}
+	if err := s.active.Sync(); err != nil {
+		return fmt.Errorf("failed to sync prediction record: %w", err)
+	}
+	s.indexLocked(seg, rec, seg.size, len(data))
+	seg.size += int64(len(data))
+	return nil
+}
+
+// rotateLocked closes the active segment and starts a new one.
+func (s *Store) rotateLocked() error {
+	if err := s.active.Close(); err != nil {
+		return fmt.Errorf("failed to close prediction log segment: %w", err)
+	}
+	s.segments = append(s.segments, &segment{seq: s.segments[len(s.segments)-1].seq + 1})
+	return s.openActive()
+}
+
+// Lookup returns the record with the given ID.
+func (s *Store) Lookup(id string) (*Record, error) {
+	s.mu.Lock()
+	loc, ok := s.index[id]
+	path := ""
+	if ok {
+		path = s.path(loc.seg)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound // deleted by retention after the index was read
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open prediction log segment: %w", err)
+	}
+	defer f.Close()
+
+	data := make([]byte, loc.length)
+	if _, err := f.ReadAt(data, loc.offset); err != nil {
+		return nil, fmt.Errorf("failed to read prediction record: %w", err)
+	}
+	var rec Record
+	if err := json.Unmarshal(bytes.TrimSuffix(data, []byte("\n")), &rec); err != nil {
+		return nil, fmt.Errorf("failed to decode prediction record: %w", err)
+	}
+	return &rec, nil
+}
+
+// ApplyRetention deletes the segments that fall outside the retention policy. An
+// active segment holding expired records is rotated first so that it can be deleted
+// on the next run.
+func (s *Store) ApplyRetention() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active == nil {
+		return nil
+	}
+	var cutoff time.Time
+	if s.opts.Retention > 0 {
+		cutoff = s.now().Add(-s.opts.Retention)
+	}
+
+	var total int64
+	for _, seg := range s.segments {
+		total += seg.size
+	}
+
+	var errs []error
+	for len(s.segments) > 1 {
+		oldest := s.segments[0]
+		expired := !cutoff.IsZero() && oldest.newest.Before(cutoff)
+		oversized := s.opts.MaxTotalBytes > 0 && total > s.opts.MaxTotalBytes
+		if !expired && !oversized {
+			break
+		}
+		if err := os.Remove(s.path(oldest)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to delete prediction log segment: %w", err))
+			break
+		}
+		for _, id := range oldest.ids {
+			delete(s.index, id)
+		}
+		total -= oldest.size
+		s.segments = s.segments[1:]
+	}
+
+	active := s.segments[len(s.segments)-1]
+	if active.size > 0 && !cutoff.IsZero() && active.oldest.Before(cutoff) {
+		if err := s.rotateLocked(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Len returns the number of records in the store.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.index)
+}
+
+// retentionLoop applies retention periodically until Close is called.
+func (s *Store) retentionLoop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.RetentionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.ApplyRetention(); err != nil {
+				log.Printf("Failed to apply prediction log retention: %v", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close stops the retention loop and closes the active segment.
+func (s *Store) Close() error {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil {
+		return nil
+	}
+	err := s.active.Close()
+	s.active = nil
+	return err
+}
+
+// validID reports whether id can be a record ID. It guards lookups of
+// user-supplied IDs.
+func validID(id string) bool {
+	return len(id) > 0 && len(id) <= 64 && strings.Trim(id, "0123456789abcdef") == ""
+}
@@ -0,0 +1,143 @@
+package predlog
+
+import (
+	"car-price-prediction/internal/domain"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRecord returns a record with the given ID and time.
+func testRecord(id string, at time.Time) Record {
+	return Record{
+		ID:             id,
+		Time:           at,
+		Subject:        "3f9a1c2b7d4e",
+		Input:          domain.UserInput{Horsepower: 111, Brand: "audi"},
+		Features:       []float32{1, 0.5, 111},
+		ModelVersion:   "238dbbdd6d08",
+		PredictedPrice: 13495.5,
+		LatencyMicros:  850,
+	}
+}
+
+// openTestStore opens a store in dir that is closed when the test ends.
+func openTestStore(t *testing.T, dir string, opts Options) *Store {
+	s, err := Open(dir, opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStore_AppendAndLookup(t *testing.T) {
+	s := openTestStore(t, t.TempDir(), Options{})
+	rec := testRecord("a1", time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+
+	require.NoError(t, s.Append(rec))
+	assert.Error(t, s.Append(rec), "duplicate IDs are rejected")
+
+	got, err := s.Lookup("a1")
+	require.NoError(t, err)
+	assert.Equal(t, rec, *got)
+
+	_, err = s.Lookup("b2")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStore_ReopenRebuildsIndex(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, Options{MaxSegmentBytes: 300})
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		require.NoError(t, s.Append(testRecord(fmt.Sprintf("a%d", i), now)))
+	}
+	require.NoError(t, s.Close())
+
+	segments, _ := filepath.Glob(filepath.Join(dir, "segment-*.log"))
+	assert.Greater(t, len(segments), 1, "records are split into segments")
+
+	reopened := openTestStore(t, dir, Options{MaxSegmentBytes: 300})
+	assert.Equal(t, 5, reopened.Len())
+	got, err := reopened.Lookup("a3")
+	require.NoError(t, err)
+	assert.Equal(t, "a3", got.ID)
+
+	// Appends continue in the newest segment
+	require.NoError(t, reopened.Append(testRecord("a5", now)))
+	got, err = reopened.Lookup("a5")
+	require.NoError(t, err)
+	assert.Equal(t, "a5", got.ID)
+}
+
+func TestStore_TruncatesTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, Options{})
+	require.NoError(t, err)
+	require.NoError(t, s.Append(testRecord("a1", time.Now())))
+	require.NoError(t, s.Close())
+
+	// Simulate a crash in the middle of an append
+	path := filepath.Join(dir, fmt.Sprintf(segmentPattern, 1))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"a2","time":"2026-`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reopened := openTestStore(t, dir, Options{})
+	assert.Equal(t, 1, reopened.Len())
+	require.NoError(t, reopened.Append(testRecord("a2", time.Now())))
+	got, err := reopened.Lookup("a2")
+	require.NoError(t, err)
+	assert.Equal(t, "a2", got.ID)
+}
+
+func TestStore_RetentionByAge(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	s := openTestStore(t, t.TempDir(), Options{Retention: 24 * time.Hour})
+	s.now = func() time.Time { return now }
+
+	require.NoError(t, s.Append(testRecord("old", now.Add(-48*time.Hour))))
+
+	// The expired record is in the active segment, which is rotated first...
+	require.NoError(t, s.ApplyRetention())
+	assert.Equal(t, 1, s.Len())
+	require.NoError(t, s.Append(testRecord("new", now)))
+
+	// ...and deleted on the next run
+	require.NoError(t, s.ApplyRetention())
+	_, err := s.Lookup("old")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Lookup("new")
+	assert.NoError(t, err)
+}
+
+func TestStore_RetentionBySize(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestStore(t, dir, Options{MaxSegmentBytes: 300, MaxTotalBytes: 700})
+	now := time.Now().UTC()
+	for i := range 6 {
+		require.NoError(t, s.Append(testRecord(fmt.Sprintf("a%d", i), now)))
+	}
+
+	require.NoError(t, s.ApplyRetention())
+
+	var total int64
+	segments, _ := filepath.Glob(filepath.Join(dir, "segment-*.log"))
+	for _, name := range segments {
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		total += info.Size()
+	}
+	assert.LessOrEqual(t, total, int64(700))
+	_, err := s.Lookup("a0")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Lookup("a5")
+	assert.NoError(t, err)
+}
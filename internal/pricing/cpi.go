@@ -0,0 +1,78 @@
+package pricing
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// cpiUS holds the annual average US Consumer Price Index for All Urban Consumers
+// (CPI-U, U.S. city average, all items, 1982-84=100) published by the Bureau of
+// Labor Statistics, series CUUR0000SA0.
+//
+//go:embed cpi_us.csv
+var cpiUS string
+
+// CPIName names the bundled index in quotes.
+const CPIName = "US CPI-U (BLS CUUR0000SA0, annual average)"
+
+// CPI is a price index by year.
+type CPI struct {
+	Name   string
+	values map[int]float64
+	first  int
+	last   int
+}
+
+// DefaultCPI returns the bundled US CPI-U index.
+func DefaultCPI() *CPI {
+	cpi, err := ReadCPI(CPIName, strings.NewReader(cpiUS))
+	if err != nil {
+		panic(err)
+	}
+	return cpi
+}
+
+// ReadCPI reads an index from CSV with a header row and year,index records.
+func ReadCPI(name string, r io.Reader) (*CPI, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price index: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("price index %s is empty", name)
+	}
+	c := &CPI{Name: name, values: make(map[int]float64, len(records)-1)}
+	for i, record := range records[1:] {
+		year, err := strconv.Atoi(strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid year %q", i+2, record[0])
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("line %d: invalid index %q", i+2, record[1])
+		}
+		c.values[year] = value
+		if i == 0 || year < c.first {
+			c.first = year
+		}
+		if i == 0 || year > c.last {
+			c.last = year
+		}
+	}
+	return c, nil
+}
+
+// Years returns the first and last year of the index.
+func (c *CPI) Years() (first, last int) {
+	return c.first, c.last
+}
+
+// Index returns the index of a year.
+func (c *CPI) Index(year int) (float64, bool) {
+	v, ok := c.values[year]
+	return v, ok
+}
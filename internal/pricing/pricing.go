@@ -0,0 +1,151 @@
+// Package pricing quotes predicted prices, which are in US dollars of the
+// training data's era, in other currencies and years. Prices are adjusted for
+// inflation with a bundled consumer price index and converted with a locally
+// maintained exchange rate table.
+package pricing
+
+import (
+	"car-price-prediction/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// BaseCurrency is the currency of predicted prices.
+const BaseCurrency = "USD"
+
+// DefaultBaseYear is the year of the prices in the CarPrice data set, which
+// describes the 1985 model year.
+const DefaultBaseYear = 1985
+
+// Pricer quotes predicted prices. Prices are first adjusted for US inflation
+// between BaseYear and the requested year, then converted at the exchange
+// rate in effect today.
+type Pricer struct {
+	// BaseYear is the year predicted prices are in.
+	BaseYear int
+	// CPI is the index prices are adjusted with.
+	CPI *CPI
+	// Rates converts prices to other currencies. If nil, prices are only quoted in BaseCurrency.
+	Rates *Rates
+
+	now func() time.Time
+}
+
+// NewPricer returns a pricer for prices of baseYear. rates may be nil.
+func NewPricer(baseYear int, cpi *CPI, rates *Rates) (*Pricer, error) {
+	if _, ok := cpi.Index(baseYear); !ok {
+		return nil, fmt.Errorf("the price index %s has no value for the base year %d", cpi.Name, baseYear)
+	}
+	return &Pricer{BaseYear: baseYear, CPI: cpi, Rates: rates, now: time.Now}, nil
+}
+
+// Check validates a currency and year before a price is quoted in them. An
+// empty currency is the base currency and a zero year the base year. The error
+// is a *domain.Error with a violation for the currency and year.
+func (p *Pricer) Check(currency string, year int) error {
+	var violations []domain.Violation
+	if _, err := p.rate(currency); err != nil {
+		violations = append(violations, violation("currency", err.Error()))
+	}
+	if _, err := p.factor(year); err != nil {
+		violations = append(violations, violation("year", err.Error()))
+	}
+	if len(violations) > 0 {
+		return domain.NewValidationError(violations)
+	}
+	return nil
+}
+
+// Quote returns price in currency and in prices of year, with the index and
+// exchange rate used. An empty currency is the base currency and a zero year
+// the base year. The error is as for Check.
+func (p *Pricer) Quote(price float32, currency string, year int) (*domain.Quote, error) {
+	if err := p.Check(currency, year); err != nil {
+		return nil, err
+	}
+	rate, _ := p.rate(currency)
+	inflation, _ := p.factor(year)
+
+	quote := &domain.Quote{
+		BasePrice:    price,
+		BaseCurrency: BaseCurrency,
+		BaseYear:     p.BaseYear,
+		Price:        float64(price),
+		Currency:     rate.Currency,
+		Year:         p.BaseYear,
+	}
+	if inflation != nil {
+		quote.Year = year
+		quote.Price *= inflation.TargetIndex / inflation.BaseIndex
+		quote.Inflation = inflation
+	}
+	if rate.Currency != BaseCurrency {
+		quote.Price *= rate.Rate
+		quote.ExchangeRate = &domain.ExchangeRate{
+			From:          BaseCurrency,
+			To:            rate.Currency,
+			Rate:          rate.Rate,
+			EffectiveDate: rate.EffectiveDate,
+		}
+	}
+	quote.Price = math.Round(quote.Price*100) / 100
+	return quote, nil
+}
+
+// rate returns the exchange rate of currency in effect today.
+func (p *Pricer) rate(currency string) (Rate, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" || currency == BaseCurrency {
+		return Rate{Currency: BaseCurrency, Rate: 1}, nil
+	}
+	if p.Rates == nil {
+		return Rate{}, errors.New("exchange rates are not configured; prices are quoted in " + BaseCurrency)
+	}
+	rate, err := p.Rates.Rate(currency, p.now())
+	if errors.Is(err, errUnknownCurrency) {
+		return Rate{}, errors.New("must be one of: " + strings.Join(p.Rates.Currencies(), ", "))
+	}
+	return rate, err
+}
+
+// factor returns the inflation adjustment from the base year to year, or nil
+// if year is zero or the base year.
+func (p *Pricer) factor(year int) (*domain.Inflation, error) {
+	if year == 0 || year == p.BaseYear {
+		return nil, nil
+	}
+	target, ok := p.CPI.Index(year)
+	if !ok {
+		first, last := p.CPI.Years()
+		return nil, fmt.Errorf("must be between %d and %d", first, last)
+	}
+	base, _ := p.CPI.Index(p.BaseYear)
+	return &domain.Inflation{
+		Index:       p.CPI.Name,
+		BaseIndex:   base,
+		TargetIndex: target,
+		Factor:      math.Round(target/base*10000) / 10000,
+	}, nil
+}
+
+// violation returns a VALIDATION_FAILED violation.
+func violation(field, message string) domain.Violation {
+	return domain.Violation{Field: field, Code: domain.CodeValidationFailed, Message: message}
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the pricer.
+func NewContext(ctx context.Context, p *Pricer) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the pricer stored in ctx, or nil if price quotes are not enabled.
+func FromContext(ctx context.Context) *Pricer {
+	p, _ := ctx.Value(contextKey{}).(*Pricer)
+	return p
+}
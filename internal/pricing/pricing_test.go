@@ -0,0 +1,164 @@
+package pricing
+
+import (
+	"car-price-prediction/internal/domain"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultCPI(t *testing.T) {
+	cpi := DefaultCPI()
+	first, last := cpi.Years()
+	assert.Equal(t, 1985, first)
+	assert.GreaterOrEqual(t, last, 2024)
+
+	index, ok := cpi.Index(1985)
+	require.True(t, ok)
+	assert.Equal(t, 107.6, index)
+	_, ok = cpi.Index(1900)
+	assert.False(t, ok)
+
+	_, err := ReadCPI("broken", strings.NewReader("year,index\n1985,abc\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+// writeRates writes an exchange rate file modified age ago, so that rewrites
+// within the file system's time resolution are noticed.
+func writeRates(t *testing.T, path, content string, age time.Duration) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	mtime := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func TestRates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exchange_rates.json")
+	writeRates(t, path, `{"base": "USD", "rates": [
+		{"currency": "idr", "rate": 15500, "effective_date": "2025-01-01"},
+		{"currency": "IDR", "rate": 16250, "effective_date": "2025-06-30"},
+		{"currency": "EUR", "rate": 0.92, "effective_date": "2025-06-30"}
+	]}`, time.Hour)
+
+	rates, err := LoadRates(path, "USD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EUR", "IDR", "USD"}, rates.Currencies())
+
+	rate, err := rates.Rate("IDR", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, Rate{Currency: "IDR", Rate: 16250, EffectiveDate: "2025-06-30"}, rate)
+	rate, err = rates.Rate("idr", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 15500.0, rate.Rate)
+	_, err = rates.Rate("IDR", time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, errUnknownCurrency)
+	rate, err = rates.Rate("USD", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, rate.Rate)
+
+	// Updates are picked up without a restart
+	writeRates(t, path, `{"base": "USD", "rates": [{"currency": "IDR", "rate": 16400, "effective_date": "2025-09-30"}]}`, 0)
+	rate, err = rates.Rate("IDR", time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 16400.0, rate.Rate)
+	assert.Equal(t, []string{"IDR", "USD"}, rates.Currencies())
+
+	// A broken update keeps the previous rates
+	writeRates(t, path, `{"base": "USD", "rates": [{"currency": "IDR", "rate": -1, "effective_date": "2025-10-31"}]}`, -time.Hour)
+	rate, err = rates.Rate("IDR", time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 16400.0, rate.Rate)
+}
+
+func TestLoadRates_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"base", `{"base": "EUR", "rates": []}`, `quoted against "EUR"`},
+		{"currency", `{"base": "USD", "rates": [{"currency": "RUPIAH", "rate": 1, "effective_date": "2025-01-01"}]}`, "invalid currency"},
+		{"rate", `{"base": "USD", "rates": [{"currency": "IDR", "rate": 0, "effective_date": "2025-01-01"}]}`, "must be positive"},
+		{"date", `{"base": "USD", "rates": [{"currency": "IDR", "rate": 1, "effective_date": "30/06/2025"}]}`, "invalid effective date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "exchange_rates.json")
+			writeRates(t, path, tt.content, 0)
+			_, err := LoadRates(path, BaseCurrency)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	_, err := LoadRates(filepath.Join(t.TempDir(), "missing.json"), BaseCurrency)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func testPricer(t *testing.T) *Pricer {
+	path := filepath.Join(t.TempDir(), "exchange_rates.json")
+	writeRates(t, path, `{"base": "USD", "rates": [{"currency": "IDR", "rate": 16250, "effective_date": "2025-06-30"}]}`, 0)
+	rates, err := LoadRates(path, BaseCurrency)
+	require.NoError(t, err)
+	p, err := NewPricer(DefaultBaseYear, DefaultCPI(), rates)
+	require.NoError(t, err)
+	p.now = func() time.Time { return time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC) }
+	return p
+}
+
+func TestPricer_Quote(t *testing.T) {
+	p := testPricer(t)
+
+	quote, err := p.Quote(13495, "idr", 2024)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.Quote{
+		BasePrice:    13495,
+		BaseCurrency: "USD",
+		BaseYear:     1985,
+		Price:        639312612.86,
+		Currency:     "IDR",
+		Year:         2024,
+		Inflation:    &domain.Inflation{Index: CPIName, BaseIndex: 107.6, TargetIndex: 313.689, Factor: 2.9153},
+		ExchangeRate: &domain.ExchangeRate{From: "USD", To: "IDR", Rate: 16250, EffectiveDate: "2025-06-30"},
+	}, quote)
+
+	// Only inflation
+	quote, err = p.Quote(13495, "", 2024)
+	require.NoError(t, err)
+	assert.Equal(t, 39342.31, quote.Price)
+	assert.Equal(t, "USD", quote.Currency)
+	assert.Nil(t, quote.ExchangeRate)
+
+	// Only the currency, at prices of the base year
+	quote, err = p.Quote(13495, "IDR", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 219293750.0, quote.Price)
+	assert.Equal(t, 1985, quote.Year)
+	assert.Nil(t, quote.Inflation)
+}
+
+func TestPricer_QuoteInvalid(t *testing.T) {
+	p := testPricer(t)
+
+	_, err := p.Quote(13495, "EUR", 1900)
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeValidationFailed, derr.Code)
+	assert.Equal(t, []domain.Violation{
+		{Field: "currency", Code: domain.CodeValidationFailed, Message: "must be one of: IDR, USD"},
+		{Field: "year", Code: domain.CodeValidationFailed, Message: "must be between 1985 and 2024"},
+	}, derr.Violations)
+
+	// Without an exchange rate table only USD can be quoted
+	p.Rates = nil
+	err = p.Check("IDR", 0)
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, "exchange rates are not configured; prices are quoted in USD", derr.Violations[0].Message)
+	assert.NoError(t, p.Check("usd", 2024))
+
+	_, err = NewPricer(1950, DefaultCPI(), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+package pricing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dateLayout is the format of effective dates.
+const dateLayout = "2006-01-02"
+
+// Rate is the price of one unit of the base currency in another currency,
+// valid from its effective date until the next rate of the same currency.
+type Rate struct {
+	Currency      string  `json:"currency"`
+	Rate          float64 `json:"rate"`
+	EffectiveDate string  `json:"effective_date"`
+}
+
+// rateFile is the format of the exchange rate file.
+type rateFile struct {
+	// Base is the currency rates are quoted against. It must be the currency of the model.
+	Base  string `json:"base"`
+	Rates []Rate `json:"rates"`
+}
+
+// Rates is an exchange rate table kept in a JSON file. The file is read again
+// whenever it changes, so rates can be updated without restarting the server.
+type Rates struct {
+	path string
+	base string
+
+	mu      sync.Mutex
+	rates   map[string][]Rate // by currency, latest effective date first
+	modTime time.Time
+}
+
+// LoadRates reads an exchange rate table quoted against base from path:
+//
+//	{"base": "USD", "rates": [{"currency": "IDR", "rate": 16250, "effective_date": "2025-06-30"}]}
+func LoadRates(path, base string) (*Rates, error) {
+	r := &Rates{path: path, base: strings.ToUpper(base)}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.reloadLocked(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Rate returns the rate of currency in effect at t. The base currency always has rate 1
+// and no effective date.
+func (r *Rates) Rate(currency string, t time.Time) (Rate, error) {
+	currency = strings.ToUpper(currency)
+	if currency == r.base {
+		return Rate{Currency: currency, Rate: 1}, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.reloadLocked(); err != nil {
+		return Rate{}, err
+	}
+	day := t.Format(dateLayout)
+	for _, rate := range r.rates[currency] {
+		if rate.EffectiveDate <= day {
+			return rate, nil
+		}
+	}
+	return Rate{}, fmt.Errorf("%w: %s", errUnknownCurrency, currency)
+}
+
+// errUnknownCurrency is returned for currencies without a rate in effect.
+var errUnknownCurrency = errors.New("no exchange rate")
+
+// reloadLocked reads the rate file again if it changed since it was last read.
+// If the file became invalid or was removed, the previous rates stay in use.
+func (r *Rates) reloadLocked() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		if r.rates != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+	if r.rates != nil && info.ModTime().Equal(r.modTime) {
+		return nil
+	}
+
+	rates, err := readRates(r.path, r.base)
+	if err != nil {
+		if r.rates != nil {
+			log.Printf("Keeping the previous exchange rates: %v", err)
+			r.modTime = info.ModTime()
+			return nil
+		}
+		return err
+	}
+	r.rates = rates
+	r.modTime = info.ModTime()
+	return nil
+}
+
+// readRates parses the rate file and groups its rates by currency, latest first.
+func readRates(path, base string) (map[string][]Rate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+	var file rateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse exchange rates: %w", err)
+	}
+	if !strings.EqualFold(file.Base, base) {
+		return nil, fmt.Errorf("exchange rates are quoted against %q, not %s", file.Base, base)
+	}
+
+	rates := map[string][]Rate{}
+	for i, rate := range file.Rates {
+		rate.Currency = strings.ToUpper(strings.TrimSpace(rate.Currency))
+		if len(rate.Currency) != 3 {
+			return nil, fmt.Errorf("exchange rate %d has an invalid currency %q", i+1, rate.Currency)
+		}
+		if rate.Rate <= 0 {
+			return nil, fmt.Errorf("exchange rate %d (%s) must be positive", i+1, rate.Currency)
+		}
+		if _, err := time.Parse(dateLayout, rate.EffectiveDate); err != nil {
+			return nil, fmt.Errorf("exchange rate %d (%s) has an invalid effective date %q", i+1, rate.Currency, rate.EffectiveDate)
+		}
+		rates[rate.Currency] = append(rates[rate.Currency], rate)
+	}
+	for _, list := range rates {
+		// Dates in the YYYY-MM-DD layout sort like strings
+		slices.SortFunc(list, func(a, b Rate) int { return strings.Compare(b.EffectiveDate, a.EffectiveDate) })
+	}
+	return rates, nil
+}
+
+// Currencies returns the currencies with rates, in alphabetical order, including the base.
+func (r *Rates) Currencies() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	currencies := []string{r.base}
+	for currency := range r.rates {
+		currencies = append(currencies, currency)
+	}
+	slices.Sort(currencies)
+	return currencies
+}
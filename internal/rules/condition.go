@@ -0,0 +1,344 @@
+package rules
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// condition is a parsed rule condition, evaluated against a normalized input.
+type condition interface {
+	eval(input reflect.Value) bool
+}
+
+// field is an input field a condition can test.
+type field struct {
+	name    string
+	index   int
+	numeric bool
+}
+
+// fields maps the JSON names of the UserInput fields to their fields.
+var fields = func() map[string]field {
+	t := reflect.TypeFor[domain.UserInput]()
+	m := make(map[string]field, t.NumField())
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		m[name] = field{name: name, index: i, numeric: f.Type.Kind() != reflect.String}
+	}
+	return m
+}()
+
+// number returns the value of a numeric field. float32 values are widened by
+// their shortest decimal representation, so that 3.19 in a rule matches 3.19 in a request.
+func (f field) number(input reflect.Value) float64 {
+	v := input.Field(f.index)
+	if v.CanInt() {
+		return float64(v.Int())
+	}
+	n, _ := strconv.ParseFloat(strconv.FormatFloat(v.Float(), 'g', -1, 32), 64)
+	return n
+}
+
+type (
+	and     struct{ left, right condition }
+	or      struct{ left, right condition }
+	not     struct{ operand condition }
+	compare struct {
+		field field
+		op    string
+		num   float64
+		str   string
+	}
+	in struct {
+		field field
+		nums  []float64
+		strs  []string
+	}
+)
+
+func (c and) eval(input reflect.Value) bool { return c.left.eval(input) && c.right.eval(input) }
+func (c or) eval(input reflect.Value) bool  { return c.left.eval(input) || c.right.eval(input) }
+func (c not) eval(input reflect.Value) bool { return !c.operand.eval(input) }
+
+func (c compare) eval(input reflect.Value) bool {
+	if !c.field.numeric {
+		return input.Field(c.field.index).String() == c.str
+	}
+	v := c.field.number(input)
+	switch c.op {
+	case "=":
+		return v == c.num
+	case "!=":
+		return v != c.num
+	case "<":
+		return v < c.num
+	case "<=":
+		return v <= c.num
+	case ">":
+		return v > c.num
+	default:
+		return v >= c.num
+	}
+}
+
+func (c in) eval(input reflect.Value) bool {
+	if c.field.numeric {
+		return slices.Contains(c.nums, c.field.number(input))
+	}
+	return slices.Contains(c.strs, input.Field(c.field.index).String())
+}
+
+// token is a word, quoted string, operator or bracket of a condition.
+type token struct {
+	text   string
+	quoted bool
+}
+
+// is reports whether the token is the unquoted keyword or symbol s.
+func (t token) is(s string) bool {
+	return !t.quoted && strings.EqualFold(t.text, s)
+}
+
+// tokenize splits a condition into tokens.
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case strings.IndexByte("()[],", c) >= 0:
+			tokens = append(tokens, token{text: src[i : i+1]})
+			i++
+		case strings.IndexByte("=!<>", c) >= 0:
+			j := i + 1
+			if j < len(src) && src[j] == '=' {
+				j++
+			}
+			op := src[i:j]
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected %q", op)
+			}
+			if op == "==" {
+				op = "="
+			}
+			tokens = append(tokens, token{text: op})
+			i = j
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string %s", src[i:])
+			}
+			tokens = append(tokens, token{text: src[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		default:
+			j := i
+			for j < len(src) && strings.IndexByte(" \t\n()[],=!<>\"'", src[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, token{text: src[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// parser is a recursive descent parser of conditions:
+//
+//	expr       = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" expr ")" | comparison
+//	comparison = field op value | field [ "not" ] "in" "[" value { "," value } "]"
+//	op         = "=" | "==" | "!=" | "<" | "<=" | ">" | ">="
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parseCondition parses a rule condition such as
+// "brand in [bmw, porsche] and enginesize > 180".
+func parseCondition(src string) (condition, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	c, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return c, nil
+}
+
+// peek returns the next token, or an empty token at the end.
+func (p *parser) peek() token {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return token{}
+}
+
+// next consumes the next token.
+func (p *parser) next() (token, error) {
+	if p.pos >= len(p.tokens) {
+		return token{}, fmt.Errorf("unexpected end of condition")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+// expect consumes the symbol s.
+func (p *parser) expect(s string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if !t.is(s) {
+		return fmt.Errorf("expected %q, got %q", s, t.text)
+	}
+	return nil
+}
+
+func (p *parser) expr() (condition, error) {
+	left, err := p.and()
+	for err == nil && p.peek().is("or") {
+		p.pos++
+		var right condition
+		if right, err = p.and(); err == nil {
+			left = or{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) and() (condition, error) {
+	left, err := p.unary()
+	for err == nil && p.peek().is("and") {
+		p.pos++
+		var right condition
+		if right, err = p.unary(); err == nil {
+			left = and{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) unary() (condition, error) {
+	switch t := p.peek(); {
+	case t.is("not"):
+		p.pos++
+		operand, err := p.unary()
+		return not{operand}, err
+	case t.is("("):
+		p.pos++
+		c, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return c, p.expect(")")
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (condition, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	f, ok := fields[strings.ToLower(t.text)]
+	if !ok || t.quoted {
+		return nil, fmt.Errorf("unknown field %q", t.text)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	negate := op.is("not")
+	if negate {
+		if op, err = p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if op.is("in") {
+		c, err := p.list(f)
+		if negate {
+			return not{c}, err
+		}
+		return c, err
+	}
+	if negate || op.quoted || !slices.Contains([]string{"=", "!=", "<", "<=", ">", ">="}, op.text) {
+		return nil, fmt.Errorf("expected a comparison after %s, got %q", f.name, op.text)
+	}
+	if !f.numeric && op.text != "=" && op.text != "!=" {
+		return nil, fmt.Errorf("%s can only be compared with = or !=", f.name)
+	}
+
+	c := compare{field: f, op: op.text}
+	if c.num, c.str, err = p.value(f); err != nil {
+		return nil, err
+	}
+	if c.op == "!=" && !f.numeric {
+		// String comparisons only distinguish equal and not equal
+		return not{compare{field: f, op: "=", str: c.str}}, nil
+	}
+	return c, nil
+}
+
+// list parses the bracketed values of an in comparison.
+func (p *parser) list(f field) (condition, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	c := in{field: f}
+	for {
+		num, str, err := p.value(f)
+		if err != nil {
+			return nil, err
+		}
+		c.nums, c.strs = append(c.nums, num), append(c.strs, str)
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.is("]") {
+			return c, nil
+		}
+		if !t.is(",") {
+			return nil, fmt.Errorf("expected \",\" or \"]\", got %q", t.text)
+		}
+	}
+}
+
+// value parses a value of f: a number for numeric fields, and a known category
+// in its canonical spelling for the others.
+func (p *parser) value(f field) (float64, string, error) {
+	t, err := p.next()
+	if err != nil {
+		return 0, "", err
+	}
+	if !t.quoted && strings.ContainsAny(t.text, "()[],=!<>") {
+		return 0, "", fmt.Errorf("expected a value of %s, got %q", f.name, t.text)
+	}
+	if f.numeric {
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return 0, "", fmt.Errorf("%s is a number, not %q", f.name, t.text)
+		}
+		return n, "", nil
+	}
+	v := prediction.NormalizeCategory(f.name, t.text)
+	if categories := prediction.Categories(f.name); !slices.Contains(categories, v) {
+		return 0, "", fmt.Errorf("unknown %s %q", f.name, t.text)
+	}
+	return 0, v, nil
+}
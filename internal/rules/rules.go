@@ -0,0 +1,261 @@
+// Package rules adjusts predicted prices for regional markets with declarative
+// rules, such as import duties on large engines or discounts on diesel cars.
+// Rule sets are kept in a versioned YAML file that is read again whenever it
+// changes, independently of the model:
+//
+//	version: "2025-10-01"
+//	regions:
+//	  id:
+//	    name: Indonesia
+//	    rules:
+//	      - name: luxury import duty
+//	        when: brand in [bmw, porsche] and enginesize > 180
+//	        adjust: +25%
+//	      - name: diesel discount
+//	        when: fueltype = diesel
+//	        adjust: -3%
+package rules
+
+import (
+	"bytes"
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule adjusts the price of the cars that match its condition.
+type Rule struct {
+	Name string `json:"name" yaml:"name"`
+	// When is the condition a car must meet, e.g. "brand in [bmw, porsche] and enginesize > 180".
+	// Rules without a condition apply to every car.
+	When string `json:"when,omitempty" yaml:"when"`
+	// Adjust is a signed percentage of the predicted price, e.g. "+25%", or a signed amount in USD, e.g. "+500".
+	Adjust string `json:"adjust" yaml:"adjust"`
+
+	condition condition
+	percent   bool
+	value     float64
+}
+
+// Region is a market with its own rules.
+type Region struct {
+	ID    string  `json:"id" yaml:"-"`
+	Name  string  `json:"name,omitempty" yaml:"name"`
+	Rules []*Rule `json:"rules" yaml:"rules"`
+}
+
+// RuleSet is a version of the rules of every region.
+type RuleSet struct {
+	Version string             `json:"version" yaml:"version"`
+	Regions map[string]*Region `json:"-" yaml:"regions"`
+}
+
+// adjustPattern matches rule adjustments: a sign, a number and an optional percent sign.
+var adjustPattern = regexp.MustCompile(`^([+-])\s*(\d+(?:\.\d+)?)\s*(%?)$`)
+
+// Parse reads a rule set from YAML and checks every condition and adjustment.
+func Parse(data []byte) (*RuleSet, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var set RuleSet
+	if err := decoder.Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	if set.Version == "" {
+		return nil, errors.New("the rule set has no version")
+	}
+	if len(set.Regions) == 0 {
+		return nil, errors.New("the rule set has no regions")
+	}
+
+	regions := make(map[string]*Region, len(set.Regions))
+	for id, region := range set.Regions {
+		if region == nil {
+			region = &Region{}
+		}
+		region.ID = strings.ToLower(strings.TrimSpace(id))
+		if _, ok := regions[region.ID]; ok || region.ID == "" {
+			return nil, fmt.Errorf("region %q is defined twice or has no ID", id)
+		}
+		for i, rule := range region.Rules {
+			if err := rule.compile(); err != nil {
+				return nil, fmt.Errorf("region %s, rule %d (%s): %w", region.ID, i+1, rule.Name, err)
+			}
+		}
+		regions[region.ID] = region
+	}
+	set.Regions = regions
+	return &set, nil
+}
+
+// compile parses the condition and adjustment of the rule.
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return errors.New("the rule has no name")
+	}
+	m := adjustPattern.FindStringSubmatch(strings.TrimSpace(r.Adjust))
+	if m == nil {
+		return fmt.Errorf("invalid adjustment %q, expected e.g. +25%%, -3%% or +500", r.Adjust)
+	}
+	r.value, _ = strconv.ParseFloat(m[2], 64)
+	if m[1] == "-" {
+		r.value = -r.value
+	}
+	r.percent = m[3] == "%"
+	if strings.TrimSpace(r.When) == "" {
+		return nil
+	}
+	c, err := parseCondition(r.When)
+	if err != nil {
+		return fmt.Errorf("invalid condition %q: %w", r.When, err)
+	}
+	r.condition = c
+	return nil
+}
+
+// IDs returns the IDs of the regions in alphabetical order.
+func (s *RuleSet) IDs() []string {
+	return slices.Sorted(maps.Keys(s.Regions))
+}
+
+// Apply adjusts a price predicted for input by the rules of a region that the
+// input matches. Percentages are taken of the predicted price rather than
+// compounded, so that every line item is independent of the order of the rules.
+// The adjusted price is never negative.
+func (s *RuleSet) Apply(region string, input domain.UserInput, price float32) (*domain.RegionalPrice, error) {
+	r, ok := s.Regions[strings.ToLower(strings.TrimSpace(region))]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownRegion, region)
+	}
+	values := reflect.ValueOf(prediction.Normalize(input))
+	result := &domain.RegionalPrice{
+		Region:         r.ID,
+		RuleSetVersion: s.Version,
+		BasePrice:      price,
+		LineItems:      []domain.LineItem{},
+	}
+	total := float64(price)
+	for _, rule := range r.Rules {
+		if rule.condition != nil && !rule.condition.eval(values) {
+			continue
+		}
+		amount := rule.value
+		if rule.percent {
+			amount = float64(price) * rule.value / 100
+		}
+		amount = math.Round(amount*100) / 100
+		total += amount
+		result.LineItems = append(result.LineItems, domain.LineItem{
+			Rule:   rule.Name,
+			When:   rule.When,
+			Adjust: strings.TrimSpace(rule.Adjust),
+			Amount: float32(amount),
+		})
+	}
+	result.Price = float32(max(math.Round(total*100)/100, 0))
+	return result, nil
+}
+
+// ErrUnknownRegion is returned for regions without rules.
+var ErrUnknownRegion = errors.New("no rules for region")
+
+// checkInterval is how often the engine checks whether the rule file changed.
+const checkInterval = time.Second
+
+// Engine serves the rule set kept in a YAML file. The file is read again
+// after it changes, so rules can be updated without restarting the server.
+type Engine struct {
+	path string
+	now  func() time.Time
+
+	mu      sync.Mutex
+	set     *RuleSet
+	modTime time.Time
+	checked time.Time
+}
+
+// Load reads the rule set in path.
+func Load(path string) (*Engine, error) {
+	e := &Engine{path: path, now: time.Now}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.checked = e.now()
+	if err := e.reloadLocked(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Current returns the current rule set. The rule file is checked for changes at
+// most once per checkInterval.
+func (e *Engine) Current() *RuleSet {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	if now.Sub(e.checked) < checkInterval {
+		return e.set
+	}
+	e.checked = now
+	if err := e.reloadLocked(); err != nil {
+		log.Printf("Keeping rule set %s: %v", e.set.Version, err)
+	}
+	return e.set
+}
+
+// reloadLocked reads the rule file again if it changed since it was last read.
+// If the file became invalid or was removed, the previous rule set stays in use.
+func (e *Engine) reloadLocked() error {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		if e.set != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to read rules: %w", err)
+	}
+	if e.set != nil && info.ModTime().Equal(e.modTime) {
+		return nil
+	}
+	e.modTime = info.ModTime()
+
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		return fmt.Errorf("failed to read rules: %w", err)
+	}
+	set, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	if e.set != nil && e.set.Version != set.Version {
+		log.Printf("Loaded rule set %s (was %s)", set.Version, e.set.Version)
+	}
+	e.set = set
+	return nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the engine.
+func NewContext(ctx context.Context, e *Engine) context.Context {
+	return context.WithValue(ctx, contextKey{}, e)
+}
+
+// FromContext returns the engine stored in ctx, or nil if regional rules are not enabled.
+func FromContext(ctx context.Context) *Engine {
+	e, _ := ctx.Value(contextKey{}).(*Engine)
+	return e
+}
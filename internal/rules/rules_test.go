@@ -0,0 +1,169 @@
+package rules
+
+import (
+	"car-price-prediction/internal/domain"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `
+version: "2025-10-01"
+regions:
+  ID:
+    name: Indonesia
+    rules:
+      - name: luxury import duty
+        when: brand in [bmw, Porsche] and enginesize > 180
+        adjust: +25%
+      - name: diesel discount
+        when: fueltype=diesel
+        adjust: -3%
+      - name: registration fee
+        adjust: "+500"
+  de:
+    rules: []
+`
+
+func TestParse(t *testing.T) {
+	set, err := Parse([]byte(testRules))
+	require.NoError(t, err)
+	assert.Equal(t, "2025-10-01", set.Version)
+	assert.Equal(t, []string{"de", "id"}, set.IDs())
+	assert.Equal(t, "Indonesia", set.Regions["id"].Name)
+	require.Len(t, set.Regions["id"].Rules, 3)
+	assert.Equal(t, -3.0, set.Regions["id"].Rules[1].value)
+	assert.True(t, set.Regions["id"].Rules[1].percent)
+	assert.False(t, set.Regions["id"].Rules[2].percent)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		err  string
+	}{
+		{"unknown field", "{name: r, when: colour = red, adjust: +1%}", `unknown field "colour"`},
+		{"unknown category", "{name: r, when: brand = porshe, adjust: +1%}", `unknown brand "porshe"`},
+		{"number expected", "{name: r, when: enginesize > big, adjust: +1%}", `enginesize is a number, not "big"`},
+		{"ordering strings", "{name: r, when: brand > bmw, adjust: +1%}", "brand can only be compared with = or !="},
+		{"unclosed list", "{name: r, when: 'brand in [bmw, audi', adjust: +1%}", "unexpected end of condition"},
+		{"trailing tokens", "{name: r, when: brand = bmw audi, adjust: +1%}", `unexpected "audi"`},
+		{"unsigned adjustment", "{name: r, adjust: 25%}", `invalid adjustment "25%"`},
+		{"no name", "{adjust: +1%}", "the rule has no name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte("version: v1\nregions:\n  id:\n    rules:\n      - " + tt.rule + "\n"))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	_, err := Parse([]byte("regions:\n  id:\n    rules: []\n"))
+	assert.ErrorContains(t, err, "no version")
+	_, err = Parse([]byte("version: v1\nregions:\n  id:\n    rulez: []\n"))
+	assert.ErrorContains(t, err, "rulez")
+}
+
+func TestConditions(t *testing.T) {
+	values := reflect.ValueOf(domain.UserInput{Brand: "porsche", Fueltype: "gas", Enginesize: 194, Stroke: 3.19, Doornumber: "two"})
+	tests := []struct {
+		when string
+		want bool
+	}{
+		{"brand = porsche", true},
+		{"brand == 'porsche'", true},
+		{"brand != porsche", false},
+		{"brand not in [bmw, audi]", true},
+		{"stroke = 3.19", true},
+		{"stroke >= 3.2", false},
+		{"enginesize >= 194 and enginesize < 200", true},
+		{"fueltype = diesel or doornumber = 2", true},
+		{"not (fueltype = diesel or doornumber = 2)", false},
+		{"NOT fueltype = diesel AND enginesize in [194, 209]", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.when, func(t *testing.T) {
+			c, err := parseCondition(tt.when)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.eval(values))
+		})
+	}
+
+	// Apply normalizes the input first
+	set, err := Parse([]byte(testRules))
+	require.NoError(t, err)
+	regional, err := set.Apply("id", domain.UserInput{Brand: "Porcshce", Enginesize: 194}, 10000)
+	require.NoError(t, err)
+	assert.Equal(t, "luxury import duty", regional.LineItems[0].Rule)
+}
+
+func TestRuleSet_Apply(t *testing.T) {
+	set, err := Parse([]byte(testRules))
+	require.NoError(t, err)
+
+	regional, err := set.Apply("ID", domain.UserInput{Brand: "bmw", Enginesize: 209, Fueltype: "diesel"}, 13495)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.RegionalPrice{
+		Region:         "id",
+		RuleSetVersion: "2025-10-01",
+		BasePrice:      13495,
+		Price:          16963.9,
+		LineItems: []domain.LineItem{
+			{Rule: "luxury import duty", When: "brand in [bmw, Porsche] and enginesize > 180", Adjust: "+25%", Amount: 3373.75},
+			{Rule: "diesel discount", When: "fueltype=diesel", Adjust: "-3%", Amount: -404.85},
+			{Rule: "registration fee", Adjust: "+500", Amount: 500},
+		},
+	}, regional)
+
+	// Regions without matching rules keep the price
+	regional, err = set.Apply("de", domain.UserInput{Brand: "bmw"}, 13495)
+	require.NoError(t, err)
+	assert.Equal(t, float32(13495), regional.Price)
+	assert.Empty(t, regional.LineItems)
+
+	_, err = set.Apply("us", domain.UserInput{}, 13495)
+	assert.ErrorIs(t, err, ErrUnknownRegion)
+}
+
+// writeRules writes a rule file modified age ago, so that rewrites within the
+// file system's time resolution are noticed.
+func writeRules(t *testing.T, path, content string, age time.Duration) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	mtime := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func TestEngine_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "region_rules.yaml")
+	writeRules(t, path, testRules, time.Hour)
+
+	engine, err := Load(path)
+	require.NoError(t, err)
+	now := time.Now()
+	engine.now = func() time.Time { return now }
+	assert.Equal(t, "2025-10-01", engine.Current().Version)
+
+	// A new version is picked up without a restart once the file is checked again
+	writeRules(t, path, "version: \"2025-11-01\"\nregions:\n  id:\n    rules:\n      - {name: vat, adjust: +11%}\n", 0)
+	assert.Equal(t, "2025-10-01", engine.Current().Version)
+	now = now.Add(checkInterval)
+	set := engine.Current()
+	assert.Equal(t, "2025-11-01", set.Version)
+	regional, err := set.Apply("id", domain.UserInput{}, 10000)
+	require.NoError(t, err)
+	assert.Equal(t, float32(11100), regional.Price)
+
+	// A broken version keeps the previous rules
+	writeRules(t, path, "version: \"2025-12-01\"\nregions:\n  id:\n    rules:\n      - {name: vat, adjust: 11}\n", -time.Hour)
+	now = now.Add(checkInterval)
+	assert.Equal(t, "2025-11-01", engine.Current().Version)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,150 @@
+// Package training trains the car price model in Go: it encodes the CarPrice data
+// set the way the training notebook did, grows a random forest and bundles it with
+// its feature schema for serving.
+package training
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/forest"
+	"car-price-prediction/internal/prediction"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// DefaultHoldout is the fraction of rows held out for evaluation, as in the notebook.
+const DefaultHoldout = 0.2
+
+// numericColumns are the numerical columns of the data set in CSV order.
+var numericColumns = []string{
+	"symboling", "wheelbase", "carlength", "carwidth", "carheight", "curbweight",
+	"enginesize", "boreratio", "stroke", "compressionratio", "horsepower", "peakrpm",
+	"citympg", "highwaympg",
+}
+
+// categoricalColumns are the categorical columns in CSV order, followed by the brand
+// extracted from CarName.
+var categoricalColumns = []struct {
+	name  string
+	value func(dataset.Sample) string
+}{
+	{"fueltype", func(s dataset.Sample) string { return s.Input.Fueltype }},
+	{"aspiration", func(s dataset.Sample) string { return s.Input.Aspiration }},
+	{"doornumber", func(s dataset.Sample) string { return s.Input.Doornumber }},
+	{"carbody", func(s dataset.Sample) string { return s.Input.Carbody }},
+	{"drivewheel", func(s dataset.Sample) string { return s.Input.Drivewheel }},
+	{"enginelocation", func(s dataset.Sample) string { return s.Input.Enginelocation }},
+	{"enginetype", func(s dataset.Sample) string { return s.Input.Enginetype }},
+	{"cylindernumber", func(s dataset.Sample) string { return s.Input.Cylindernumber }},
+	{"fuelsystem", func(s dataset.Sample) string { return s.Input.Fuelsystem }},
+	{"brand", func(s dataset.Sample) string { return s.Input.Brand }},
+}
+
+// Features returns the feature schema pd.get_dummies(drop_first=True) derives from
+// the samples: the numerical columns, then one column per category of each
+// categorical column in sorted order, without the first category.
+func Features(samples []dataset.Sample) []string {
+	features := slices.Clone(numericColumns)
+	for _, c := range categoricalColumns {
+		var values []string
+		for _, s := range samples {
+			values = append(values, c.value(s))
+		}
+		slices.Sort(values)
+		for _, v := range slices.Compact(values)[1:] {
+			features = append(features, c.name+"_"+v)
+		}
+	}
+	return features
+}
+
+// Split shuffles the samples with seed and returns the first 1-holdout of them for
+// training and the rest for evaluation.
+func Split(samples []dataset.Sample, holdout float64, seed uint64) (train, test []dataset.Sample) {
+	shuffled := slices.Clone(samples)
+	rand.New(rand.NewPCG(seed, 0)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	n := len(shuffled) - int(math.Round(holdout*float64(len(shuffled))))
+	return shuffled[:n], shuffled[n:]
+}
+
+// Config configures training.
+type Config struct {
+	Params forest.Params
+	// Holdout is the fraction of rows held out for evaluation; 0 trains on all rows.
+	Holdout float64
+}
+
+// Train encodes the samples, trains a random forest on all but the held-out rows
+// and evaluates it on them. The returned bundle is ready to be written and served.
+func Train(samples []dataset.Sample, cfg Config) (*prediction.Bundle, error) {
+	if cfg.Holdout < 0 || cfg.Holdout >= 1 {
+		return nil, fmt.Errorf("holdout must be a fraction below 1, got %g", cfg.Holdout)
+	}
+	features := Features(samples)
+	encoder, err := prediction.NewEncoder(features)
+	if err != nil {
+		return nil, err
+	}
+	train, test := Split(samples, cfg.Holdout, cfg.Params.Seed)
+	if len(train) == 0 {
+		return nil, errors.New("no rows left to train on")
+	}
+
+	x := make([][]float32, len(train))
+	y := make([]float64, len(train))
+	for i, s := range train {
+		x[i] = encoder.Encode(s.Input)
+		y[i] = s.Price
+	}
+	f, err := forest.Train(x, y, cfg.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	params := cfg.Params
+	metadata := prediction.BundleMetadata{
+		TrainedAt:   time.Now().UTC(),
+		TrainRows:   len(train),
+		HoldoutRows: len(test),
+		Params:      &params,
+	}
+	if len(test) > 0 {
+		predicted := make([]float64, len(test))
+		actual := make([]float64, len(test))
+		for i, s := range test {
+			predicted[i] = f.Predict(encoder.Encode(s.Input))
+			actual[i] = s.Price
+		}
+		metrics := Evaluate(predicted, actual)
+		metadata.Holdout = &metrics
+	}
+	return prediction.NewForestBundle(features, f, metadata)
+}
+
+// Evaluate returns the accuracy of predicted against actual prices.
+func Evaluate(predicted, actual []float64) prediction.HoldoutMetrics {
+	var absSum, sqSum, mean float64
+	for i := range actual {
+		d := predicted[i] - actual[i]
+		absSum += math.Abs(d)
+		sqSum += d * d
+		mean += actual[i]
+	}
+	n := float64(len(actual))
+	mean /= n
+	var total float64
+	for _, a := range actual {
+		total += (a - mean) * (a - mean)
+	}
+
+	m := prediction.HoldoutMetrics{MAE: absSum / n, RMSE: math.Sqrt(sqSum / n)}
+	if total > 0 {
+		m.R2 = 1 - sqSum/total
+	}
+	return m
+}
@@ -0,0 +1,89 @@
+package training
+
+import (
+	"car-price-prediction/internal/dataset"
+	"car-price-prediction/internal/forest"
+	"car-price-prediction/internal/prediction"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readSamples reads the sample data set.
+func readSamples(t *testing.T) []dataset.Sample {
+	samples, err := dataset.ReadFile("../dataset/testdata/carprice_sample.csv")
+	require.NoError(t, err)
+	return samples
+}
+
+func TestFeatures_DropFirst(t *testing.T) {
+	features := Features(readSamples(t))
+
+	assert.Equal(t, "highwaympg", features[13])
+	assert.Contains(t, features, "fueltype_gas")
+	assert.NotContains(t, features, "fueltype_diesel", "the first category is dropped")
+	assert.Contains(t, features, "brand_bmw")
+
+	// Every column of the served model comes from the data set's categories
+	served := prediction.FeatureNames()
+	for _, f := range features {
+		assert.Contains(t, served, f)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	samples := readSamples(t)
+	train, test := Split(samples, 0.2, 42)
+	assert.Len(t, train, 24)
+	assert.Len(t, test, 6)
+
+	again, _ := Split(samples, 0.2, 42)
+	assert.Equal(t, train, again)
+	all, none := Split(samples, 0, 42)
+	assert.Len(t, all, 30)
+	assert.Empty(t, none)
+}
+
+func TestTrain(t *testing.T) {
+	samples := readSamples(t)
+	params := forest.DefaultParams()
+	params.Trees = 20
+	bundle, err := Train(samples, Config{Params: params, Holdout: 0.2})
+	require.NoError(t, err)
+
+	assert.Equal(t, prediction.KindRandomForest, bundle.Kind)
+	assert.Equal(t, 24, bundle.Metadata.TrainRows)
+	assert.Equal(t, 6, bundle.Metadata.HoldoutRows)
+	require.NotNil(t, bundle.Metadata.Holdout)
+	assert.Greater(t, bundle.Metadata.Holdout.MAE, 0.0)
+
+	// Training rows are predicted closely by a forest of deep trees
+	train, _ := Split(samples, 0.2, params.Seed)
+	var predicted, actual []float64
+	for _, s := range train {
+		predicted = append(predicted, bundle.PredictFeatures(bundle.Encode(s.Input)))
+		actual = append(actual, s.Price)
+	}
+	assert.Greater(t, Evaluate(predicted, actual).R2, 0.8)
+
+	// The bundle can be served
+	path := filepath.Join(t.TempDir(), "model.json")
+	require.NoError(t, bundle.WriteFile(path))
+	result, err := prediction.NewBundleService(path).Predict(train[0].Input)
+	require.NoError(t, err)
+	assert.InDelta(t, predicted[0], result.PredictedPrice, 0.01)
+}
+
+func TestTrain_InvalidHoldout(t *testing.T) {
+	_, err := Train(readSamples(t), Config{Params: forest.DefaultParams(), Holdout: 1})
+	assert.Error(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	m := Evaluate([]float64{110, 190, 300}, []float64{100, 200, 300})
+	assert.InDelta(t, 20.0/3, m.MAE, 1e-9)
+	assert.InDelta(t, 8.1649658, m.RMSE, 1e-6)
+	assert.InDelta(t, 1-200.0/20000, m.R2, 1e-9)
+}
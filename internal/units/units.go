@@ -0,0 +1,218 @@
+// Package units converts input fields given in metric or other units into the
+// US units the model was trained on: inches, pounds, cubic inches, horsepower
+// and miles per gallon.
+package units
+
+import (
+	"car-price-prediction/internal/domain"
+	"car-price-prediction/internal/prediction"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Unit systems a request can declare.
+const (
+	// SystemUS is the system of the model: no field is converted.
+	SystemUS = "us"
+	// SystemMetric reads lengths in mm, curbweight in kg, enginesize in cc and
+	// mileage in L/100km. Horsepower stays in hp.
+	SystemMetric = "metric"
+)
+
+// unit converts values of one unit into the model unit of its quantity and back.
+type unit struct {
+	toModel   func(float64) float64
+	fromModel func(float64) float64
+}
+
+// linear returns a unit that is factor model units.
+func linear(factor float64) unit {
+	return unit{
+		toModel:   func(v float64) float64 { return v * factor },
+		fromModel: func(v float64) float64 { return v / factor },
+	}
+}
+
+// reciprocal returns a unit that is inversely proportional to the model unit,
+// such as fuel consumption against mileage.
+func reciprocal(k float64) unit {
+	f := func(v float64) float64 { return k / v }
+	return unit{toModel: f, fromModel: f}
+}
+
+// quantity is a physical quantity: the unit the model uses and the units it can be given in.
+type quantity struct {
+	model  string
+	metric string
+	units  map[string]unit
+	// integer is set if the model field holds whole numbers.
+	integer bool
+}
+
+var (
+	length = quantity{model: "in", metric: "mm", units: map[string]unit{
+		"in": linear(1),
+		"mm": linear(1 / 25.4),
+		"cm": linear(1 / 2.54),
+		"m":  linear(1 / 0.0254),
+	}}
+	weight = quantity{model: "lb", metric: "kg", integer: true, units: map[string]unit{
+		"lb": linear(1),
+		"kg": linear(2.20462262),
+	}}
+	displacement = quantity{model: "cuin", metric: "cc", integer: true, units: map[string]unit{
+		"cuin": linear(1),
+		"cc":   linear(1 / 16.387064),
+		"l":    linear(1000 / 16.387064),
+	}}
+	power = quantity{model: "hp", metric: "hp", integer: true, units: map[string]unit{
+		"hp": linear(1),
+		"kw": linear(1.34102209),
+		"ps": linear(0.98632007),
+	}}
+	mileage = quantity{model: "mpg", metric: "l/100km", integer: true, units: map[string]unit{
+		"mpg":     linear(1),
+		"l/100km": reciprocal(235.214583),
+		"km/l":    linear(2.35214583),
+	}}
+)
+
+// fields maps each input field that has units to its quantity.
+var fields = map[string]quantity{
+	"wheelbase":  length,
+	"carlength":  length,
+	"carwidth":   length,
+	"carheight":  length,
+	"curbweight": weight,
+	"enginesize": displacement,
+	"horsepower": power,
+	"citympg":    mileage,
+	"highwaympg": mileage,
+}
+
+// fieldNames lists the fields that have units, in request order.
+var fieldNames = []string{"wheelbase", "carlength", "carwidth", "carheight", "curbweight", "enginesize", "horsepower", "citympg", "highwaympg"}
+
+// Spec is the unit each field of a request is given in. The zero Spec converts nothing.
+type Spec map[string]string
+
+// NewSpec returns the units of a request that declares a unit system (SystemUS
+// if empty) and per-field units overriding it. Unit names are case-insensitive.
+// The error is a *domain.Error with a violation per invalid system, field or unit.
+func NewSpec(system string, overrides map[string]string) (Spec, error) {
+	spec := Spec{}
+	var violations []domain.Violation
+	switch strings.ToLower(strings.TrimSpace(system)) {
+	case "", SystemUS:
+	case SystemMetric:
+		for name, q := range fields {
+			if q.metric != q.model {
+				spec[name] = q.metric
+			}
+		}
+	default:
+		violations = append(violations, violation("unit_system", "must be one of: "+SystemUS+", "+SystemMetric))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(overrides)) {
+		q, ok := fields[name]
+		if !ok {
+			violations = append(violations, violation("units."+name, "must name one of: "+strings.Join(slices.Sorted(maps.Keys(fields)), ", ")))
+			continue
+		}
+		u := strings.ToLower(strings.TrimSpace(overrides[name]))
+		if _, ok := q.units[u]; !ok {
+			violations = append(violations, violation("units."+name, "must be one of: "+strings.Join(slices.Sorted(maps.Keys(q.units)), ", ")))
+			continue
+		}
+		if u == q.model {
+			delete(spec, name)
+		} else {
+			spec[name] = u
+		}
+	}
+	if len(violations) > 0 {
+		return nil, domain.NewValidationError(violations)
+	}
+	return spec, nil
+}
+
+// Convert rewrites the fields of the JSON object data that the spec gives in
+// other units into model units, and returns the new object and the conversions
+// it made, in field order. Integer fields are rounded to whole numbers and the
+// others to two decimals. A converted value outside the range the model accepts
+// is reported as OUT_OF_RANGE in the unit it was given in, since that is usually
+// a sign of a wrong unit.
+func (s Spec) Convert(data []byte) ([]byte, []domain.UnitConversion, error) {
+	if len(s) == 0 {
+		return data, nil, nil
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, nil, err
+	}
+
+	var conversions []domain.UnitConversion
+	var violations []domain.Violation
+	for _, name := range fieldNames {
+		u, ok := s[name]
+		raw, present := object[name]
+		if !ok || !present || string(raw) == "null" {
+			continue
+		}
+		var value float64
+		if err := json.Unmarshal(raw, &value); err != nil {
+			violations = append(violations, violation(name, "must be of type number"))
+			continue
+		}
+		q := fields[name]
+		converted := q.units[u].toModel(value)
+		if value <= 0 || math.IsInf(converted, 0) || math.IsNaN(converted) {
+			violations = append(violations, violation(name, "must be greater than 0"))
+			continue
+		}
+		if q.integer {
+			converted = math.Round(converted)
+		} else {
+			converted = math.Round(converted*100) / 100
+		}
+		if lo, hi, ok := prediction.Range(name); ok && (converted < lo || converted > hi) {
+			from, to := q.units[u].fromModel(lo), q.units[u].fromModel(hi)
+			violations = append(violations, domain.Violation{
+				Field: name,
+				Code:  domain.CodeOutOfRange,
+				Message: fmt.Sprintf("%s %s is %s %s; must be between %s and %s %s",
+					format(value), u, format(converted), q.model, format(min(from, to)), format(max(from, to)), u),
+			})
+			continue
+		}
+		object[name] = json.RawMessage(strconv.FormatFloat(converted, 'f', -1, 64))
+		conversions = append(conversions, domain.UnitConversion{
+			Field:          name,
+			Value:          value,
+			Unit:           u,
+			Normalized:     converted,
+			NormalizedUnit: q.model,
+		})
+	}
+	if len(violations) > 0 {
+		return nil, nil, domain.NewValidationError(violations)
+	}
+	out, err := json.Marshal(object)
+	return out, conversions, err
+}
+
+// format formats a value with at most two decimals.
+func format(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// violation returns a VALIDATION_FAILED violation.
+func violation(field, message string) domain.Violation {
+	return domain.Violation{Field: field, Code: domain.CodeValidationFailed, Message: message}
+}
@@ -0,0 +1,103 @@
+package units
+
+import (
+	"car-price-prediction/internal/domain"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSpec(t *testing.T) {
+	spec, err := NewSpec("", nil)
+	require.NoError(t, err)
+	assert.Empty(t, spec)
+
+	spec, err = NewSpec("Metric", map[string]string{"horsepower": "kW", "carheight": "in"})
+	require.NoError(t, err)
+	assert.Equal(t, Spec{
+		"wheelbase":  "mm",
+		"carlength":  "mm",
+		"carwidth":   "mm",
+		"curbweight": "kg",
+		"enginesize": "cc",
+		"horsepower": "kw",
+		"citympg":    "l/100km",
+		"highwaympg": "l/100km",
+	}, spec)
+
+	spec, err = NewSpec(SystemUS, map[string]string{"enginesize": "L"})
+	require.NoError(t, err)
+	assert.Equal(t, Spec{"enginesize": "l"}, spec)
+}
+
+func TestNewSpec_Invalid(t *testing.T) {
+	_, err := NewSpec("imperial", map[string]string{"brand": "mm", "wheelbase": "ft"})
+
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeValidationFailed, derr.Code)
+	assert.Equal(t, []domain.Violation{
+		{Field: "unit_system", Code: domain.CodeValidationFailed, Message: "must be one of: us, metric"},
+		{Field: "units.brand", Code: domain.CodeValidationFailed, Message: "must name one of: carheight, carlength, carwidth, citympg, curbweight, enginesize, highwaympg, horsepower, wheelbase"},
+		{Field: "units.wheelbase", Code: domain.CodeValidationFailed, Message: "must be one of: cm, in, m, mm"},
+	}, derr.Violations)
+}
+
+func TestSpec_Convert(t *testing.T) {
+	spec, err := NewSpec(SystemMetric, map[string]string{"horsepower": "kw"})
+	require.NoError(t, err)
+
+	data, conversions, err := spec.Convert([]byte(`{
+		"wheelbase": 2250, "carlength": 4288, "carwidth": 1628, "carheight": null,
+		"curbweight": 1156, "enginesize": 2548, "horsepower": 82,
+		"citympg": 11.2, "highwaympg": 8.7, "stroke": 2.68, "brand": "alfa-romero"
+	}`))
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, map[string]any{
+		"wheelbase": 88.58, "carlength": 168.82, "carwidth": 64.09, "carheight": nil,
+		"curbweight": 2549.0, "enginesize": 155.0, "horsepower": 110.0,
+		"citympg": 21.0, "highwaympg": 27.0, "stroke": 2.68, "brand": "alfa-romero",
+	}, got)
+
+	require.Len(t, conversions, 8)
+	assert.Equal(t, domain.UnitConversion{Field: "wheelbase", Value: 2250, Unit: "mm", Normalized: 88.58, NormalizedUnit: "in"}, conversions[0])
+	assert.Equal(t, domain.UnitConversion{Field: "citympg", Value: 11.2, Unit: "l/100km", Normalized: 21, NormalizedUnit: "mpg"}, conversions[6])
+
+	// The zero spec leaves the input alone
+	data, conversions, err = Spec{}.Convert([]byte(`{"wheelbase": 2250}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"wheelbase": 2250}`, string(data))
+	assert.Empty(t, conversions)
+}
+
+func TestSpec_Convert_SanityErrors(t *testing.T) {
+	spec, err := NewSpec(SystemMetric, nil)
+	require.NoError(t, err)
+
+	// A wheelbase in inches, an implausible weight and a zero consumption
+	_, _, err = spec.Convert([]byte(`{"wheelbase": 88.6, "curbweight": 3000, "citympg": 0, "highwaympg": "low"}`))
+
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeValidationFailed, derr.Code)
+	assert.Equal(t, []domain.Violation{
+		{Field: "wheelbase", Code: domain.CodeOutOfRange, Message: "88.6 mm is 3.49 in; must be between 1524 and 3810 mm"},
+		{Field: "curbweight", Code: domain.CodeOutOfRange, Message: "3000 kg is 6614 lb; must be between 453.59 and 2721.55 kg"},
+		{Field: "citympg", Code: domain.CodeValidationFailed, Message: "must be greater than 0"},
+		{Field: "highwaympg", Code: domain.CodeValidationFailed, Message: "must be of type number"},
+	}, derr.Violations)
+
+	// Ranges of reciprocal units are given low to high
+	_, _, err = spec.Convert([]byte(`{"citympg": 80}`))
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeOutOfRange, derr.Code)
+	assert.Equal(t, "80 l/100km is 3 mpg; must be between 3.36 and 47.04 l/100km", derr.Violations[0].Message)
+
+	_, _, err = spec.Convert([]byte(`[]`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+// Package validation turns request binding errors into domain errors with
+// per-field violations that are safe to return to clients.
+package validation
+
+import (
+	"car-price-prediction/internal/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report JSON field names (e.g. "wheelbase") instead of Go field names in violations.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+// jsonFieldName returns the JSON name of a struct field, falling back to the Go name.
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// Struct validates v using the same binding rules as the REST API.
+func Struct(v any) error {
+	if err := binding.Validator.ValidateStruct(v); err != nil {
+		return Translate(err)
+	}
+	return nil
+}
+
+// inputFields lists the JSON names of the UserInput fields in declaration order.
+var inputFields = func() []string {
+	t := reflect.TypeFor[domain.UserInput]()
+	names := make([]string, t.NumField())
+	for i := range names {
+		names[i] = jsonFieldName(t.Field(i))
+	}
+	return names
+}()
+
+// MissingFields returns the JSON names of the UserInput fields that the JSON
+// object data leaves out or sets to null, in declaration order.
+func MissingFields(data []byte) ([]string, error) {
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(data, &present); err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, name := range inputFields {
+		if raw, ok := present[name]; !ok || string(raw) == "null" {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
+
+// Translate converts an error from request decoding or struct validation into a
+// *domain.Error with code VALIDATION_FAILED. Validator internals are not exposed.
+func Translate(err error) *domain.Error {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return domainErr
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		violations := make([]domain.Violation, 0, len(fieldErrs))
+		for _, fe := range fieldErrs {
+			violations = append(violations, domain.Violation{
+				Field:   fe.Field(),
+				Code:    domain.CodeValidationFailed,
+				Message: describe(fe),
+			})
+		}
+		return domain.NewValidationError(violations)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return domain.NewValidationError([]domain.Violation{{
+			Field:   typeErr.Field,
+			Code:    domain.CodeValidationFailed,
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+		}})
+	}
+
+	message := "The request body could not be parsed."
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.Is(err, io.EOF):
+		message = "The request body is empty."
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		message = "The request body is not valid JSON."
+	}
+	return domain.NewError(domain.CodeValidationFailed, message, err)
+}
+
+// describe returns a client-facing message for a single validator failure.
+func describe(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		return "must be at most " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	default:
+		return "is invalid"
+	}
+}
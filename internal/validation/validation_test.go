@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"car-price-prediction/internal/domain"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStruct_ReportsJSONFieldNames(t *testing.T) {
+	input := domain.UserInput{Symboling: 3}
+
+	err := Struct(&input)
+
+	var derr *domain.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, domain.CodeValidationFailed, derr.Code)
+	assert.Len(t, derr.Violations, 23)
+	assert.Equal(t, domain.Violation{Field: "wheelbase", Code: domain.CodeValidationFailed, Message: "is required"}, derr.Violations[0])
+	assert.NotContains(t, derr.Error(), "Key: ")
+}
+
+func TestTranslate_SyntaxError(t *testing.T) {
+	var input domain.UserInput
+	err := json.Unmarshal([]byte(`{"invalid":json}`), &input)
+
+	derr := Translate(err)
+
+	assert.Equal(t, domain.CodeValidationFailed, derr.Code)
+	assert.Equal(t, "The request body is not valid JSON.", derr.Message)
+	assert.Empty(t, derr.Violations)
+}
+
+func TestTranslate_TypeError(t *testing.T) {
+	var input domain.UserInput
+	err := json.Unmarshal([]byte(`{"horsepower":"lots"}`), &input)
+
+	derr := Translate(err)
+
+	require.Len(t, derr.Violations, 1)
+	assert.Equal(t, "horsepower", derr.Violations[0].Field)
+	assert.Equal(t, "must be of type int", derr.Violations[0].Message)
+}
+
+func TestTranslate_KeepsDomainErrors(t *testing.T) {
+	original := domain.NewError(domain.CodeOutOfRange, "too big", nil)
+
+	assert.Same(t, original, Translate(original))
+}
+
+func TestMissingFields(t *testing.T) {
+	missing, err := MissingFields([]byte(`{"symboling": 0, "wheelbase": 88.6, "stroke": null, "brand": "audi", "extra": 1}`))
+	require.NoError(t, err)
+	assert.Len(t, missing, 21)
+	assert.Equal(t, "carlength", missing[0])
+	assert.Contains(t, missing, "stroke")
+	assert.NotContains(t, missing, "symboling")
+	assert.NotContains(t, missing, "brand")
+
+	missing, err = MissingFields([]byte(`{}`))
+	require.NoError(t, err)
+	assert.Len(t, missing, 24)
+
+	_, err = MissingFields([]byte(`[]`))
+	assert.Error(t, err)
+}